@@ -8,10 +8,11 @@ import (
 	"infer-microservices/pkg/services"
 )
 
-var restPort uint
-var grpcPort uint
-var maxCpuNum int
-var dubboConfFile string
+var restPort *uint
+var grpcPort *uint
+var maxCpuNum *int
+var dubboConfFile *string
+var serviceStartFile *string
 var serviceFactory services.ServiceFactory
 
 type ApiFactory struct {
@@ -25,19 +26,25 @@ func init() {
 	flagFactory := flags.FlagFactory{}
 	//flagServiceConfig
 	flagServiceConfig := flagFactory.CreateFlagServiceConfig()
-	grpcPort = *flagServiceConfig.GetServiceRestPort()
-	restPort = *flagServiceConfig.GetServiceGrpcPort()
-	maxCpuNum = *flagServiceConfig.GetServiceMaxCpuNum()
+	restPort = flagServiceConfig.GetServiceRestPort()
+	grpcPort = flagServiceConfig.GetServiceGrpcPort()
+	maxCpuNum = flagServiceConfig.GetServiceMaxCpuNum()
+	serviceStartFile = flagServiceConfig.GetServiceConfigFile()
 
 	//flagDubbo
 	flagDubbo := flagFactory.CreateFlagDubbo()
-	dubboConfFile = *flagDubbo.GetDubboServiceFile()
+	dubboConfFile = flagDubbo.GetDubboServiceFile()
+}
+
+// load all service configs listed in service_start_file.
+func (f ApiFactory) LoadServiceConfigs() error {
+	return serviceFactory.LoadServiceConfigs(*serviceStartFile)
 }
 
 // create dubbo server
 func (f ApiFactory) CreateDubboServiceApi() *dubbo_api.DubboServiceApi {
 	dubboServiceApi := new(dubbo_api.DubboServiceApi)
-	dubboServiceApi.SetDubboConfFile(dubboConfFile)
+	dubboServiceApi.SetDubboConfFile(*dubboConfFile)
 
 	dubboService := serviceFactory.CreateDubboService()
 	dubboServiceApi.SetDubboService(dubboService)
@@ -48,8 +55,8 @@ func (f ApiFactory) CreateDubboServiceApi() *dubbo_api.DubboServiceApi {
 // create grpc server
 func (f ApiFactory) CreateGrpcServiceApi() *grpc_api.GrpcServiceApi {
 	grpcServiceApi := new(grpc_api.GrpcServiceApi)
-	grpcServiceApi.SetServicePort(grpcPort)
-	grpcServiceApi.SetMaxCpuNum(maxCpuNum)
+	grpcServiceApi.SetServicePort(*grpcPort)
+	grpcServiceApi.SetMaxCpuNum(*maxCpuNum)
 
 	grpcService := serviceFactory.CreateGrpcService()
 	grpcServiceApi.SetGrpcService(grpcService)
@@ -61,8 +68,8 @@ func (f ApiFactory) CreateGrpcServiceApi() *grpc_api.GrpcServiceApi {
 // @deprecated
 func (f ApiFactory) CreateRestServiceHttpApi() *rest_api.HttpServiceApi {
 	httpServiceApi := new(rest_api.HttpServiceApi)
-	httpServiceApi.SetServicePort(restPort)
-	httpServiceApi.SetMaxCpuNum(maxCpuNum)
+	httpServiceApi.SetServicePort(*restPort)
+	httpServiceApi.SetMaxCpuNum(*maxCpuNum)
 
 	httpService := serviceFactory.CreateHttpService()
 	httpServiceApi.SetRestService(httpService)
@@ -73,11 +80,14 @@ func (f ApiFactory) CreateRestServiceHttpApi() *rest_api.HttpServiceApi {
 // create rest server
 func (f ApiFactory) CreateRestServiceEchoApi() *rest_api.EchoServiceApi {
 	echoServiceApi := new(rest_api.EchoServiceApi)
-	echoServiceApi.SetServicePort(restPort)
-	echoServiceApi.SetMaxCpuNum(maxCpuNum)
+	echoServiceApi.SetServicePort(*restPort)
+	echoServiceApi.SetMaxCpuNum(*maxCpuNum)
 
 	echoService := serviceFactory.CreateEchoService()
 	echoServiceApi.SetRestService(echoService)
 
+	adminService := serviceFactory.CreateAdminService()
+	echoServiceApi.SetAdminService(adminService)
+
 	return echoServiceApi
 }
@@ -19,7 +19,8 @@ type DubboServiceApi struct {
 	dubboConfFile string
 	dubboService  *dubbo_service.DubboService
 	// define service func name.
-	RecommenderInfer func(ctx context.Context, req *io.RecRequest) (*io.RecResponse, error)
+	RecommenderInfer  func(ctx context.Context, req *io.RecRequest) (*io.RecResponse, error)
+	SimilarItemsInfer func(ctx context.Context, req *io.RecRequest) (*io.RecResponse, error)
 }
 
 func init() {
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"infer-microservices/internal/jwt"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+)
+
+// register admin apis, such as user / item filters and service configs.
+func (s *EchoServiceApi) registerAdminApi(g *echo.Group) {
+	config := middleware.JWTConfig{
+		Claims:     &jwt.JwtCustomClaims{},
+		SigningKey: []byte("secret"),
+	}
+	g.Use(middleware.JWTWithConfig(config))
+	g.Use(adminOnlyMiddleware)
+
+	//user / item filters.
+	g.POST("/filter/:target/add", s.adminService.AddMembers)
+	g.POST("/filter/:target/remove", s.adminService.RemoveMembers)
+	g.POST("/filter/:target/reload", s.adminService.ReloadMembers)
+	g.POST("/filter/:target/contains", s.adminService.ContainsMembers)
+	g.GET("/filter/events", s.adminService.MembershipEvents)
+
+	//service configs.
+	g.POST("/config/dryrun", s.adminService.ConfigDryRun)
+	g.GET("/config/:dataId/versions", s.adminService.ConfigVersions)
+	g.POST("/config/:dataId/rollback", s.adminService.ConfigRollback)
+	g.GET("/config/:dataId/backends", s.adminService.ConfigBackends)
+	g.GET("/config/:dataId/indexes", s.adminService.ConfigIndexes)
+
+	//grpc pools.
+	g.GET("/pools", s.adminService.PoolStats)
+}
+
+// adminOnlyMiddleware reject the users which are not admin in jwt claims.
+func adminOnlyMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token, ok := c.Get("user").(*jwtgo.Token)
+		if !ok {
+			return echo.ErrUnauthorized
+		}
+
+		claims, ok := token.Claims.(*jwt.JwtCustomClaims)
+		if !ok || !claims.Admin {
+			return echo.ErrForbidden
+		}
+
+		return next(c)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"infer-microservices/internal"
 	"infer-microservices/internal/jwt"
 	"infer-microservices/internal/logs"
+	admin_service "infer-microservices/pkg/services/admin_service"
 	"infer-microservices/pkg/services/rest_service"
 	"runtime"
 
@@ -20,9 +21,10 @@ var skywalkingAddr string
 var skywalkingServerName string
 
 type EchoServiceApi struct {
-	serverPort  uint
-	maxCpuNum   int
-	echoService *rest_service.EchoService
+	serverPort   uint
+	maxCpuNum    int
+	echoService  *rest_service.EchoService
+	adminService *admin_service.AdminService
 }
 
 func init() {
@@ -51,6 +53,10 @@ func (s *EchoServiceApi) SetRestService(echoService *rest_service.EchoService) {
 	s.echoService = echoService
 }
 
+func (s *EchoServiceApi) SetAdminService(adminService *admin_service.AdminService) {
+	s.adminService = adminService
+}
+
 // skywalkingMiddleware
 func skywalkingMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -76,6 +82,15 @@ func (s *EchoServiceApi) ServiceStart() {
 	}
 	r.Use(middleware.JWTWithConfig(config))
 	echoApi.POST("/infer2", s.echoService.SyncRecommenderInfer)
+	echoApi.POST("/infer2/similar", s.echoService.SyncSimilarItemsInfer)
+
+	// v2 api, json body and typed responses, documented at /v2/openapi.json.
+	for _, route := range s.echoService.RoutesV2() {
+		echoApi.Add(route.Method, route.Path, route.Handler)
+	}
+
+	// admin group, only admin users.
+	s.registerAdminApi(echoApi.Group("/admin"))
 
 	skywalkingOpen = s.echoService.GetBaseService().GetSkywalkingWeatherOpen()
 	skywalkingAddr = s.echoService.GetBaseService().GetSkywalkingIp() + ":" + fmt.Sprintf(":%d", s.echoService.GetBaseService().GetSkywalkingPort())
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"infer-microservices/pkg/config_check"
+	"infer-microservices/pkg/config_source"
+	"os"
+)
+
+var noConnect = flag.Bool("no-connect", false, "only check the schema, do not connect redis / tfserving / faiss")
+var outputJson = flag.Bool("json", false, "print the reports as json")
+
+// check service config files before pushing them to nacos.
+//
+//	configcheck [--no-connect] [--json] file.json [file.yaml ...]
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [--no-connect] [--json] file...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	failed := false
+	reports := make([]*config_check.Report, 0, flag.NArg())
+	for _, path := range flag.Args() {
+		report := config_source.DryRunFile(path, !*noConnect)
+		reports = append(reports, report)
+		if report.HasError() {
+			failed = true
+		}
+
+		if !*outputJson {
+			fmt.Printf("%s (dataId %s): %d issues\n", path, report.DataId, len(report.Issues))
+			fmt.Print(report)
+		}
+	}
+
+	if *outputJson {
+		buff, _ := json.MarshalIndent(reports, "", "  ")
+		fmt.Println(string(buff))
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
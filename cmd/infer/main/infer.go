@@ -5,6 +5,7 @@ import (
 	"infer-microservices/api"
 	"infer-microservices/internal"
 	"infer-microservices/internal/logs"
+	"infer-microservices/pkg/membership"
 	"infer-microservices/pkg/model/basemodel"
 	"time"
 )
@@ -41,8 +42,16 @@ func main() {
 	logs.InitLog()
 
 	//watch and reset bloom fliter
-	go basemodel.WatchBloomConfig() //0 o'clock start service and load all users and all items into bloom filter.
-	go resetBloom()                 //0 o'clock clean bloom filter, every 7 days.
+	go basemodel.WatchBloomConfig()         //0 o'clock start service and load all users and all items into bloom filter.
+	go resetBloom()                         //0 o'clock clean bloom filter, every 7 days.
+	go membership.StartMembershipConsumer() //add / remove users and items from the offline pipeline.
+
+	//load all service configs before opening ports.
+	err := apiFactory.LoadServiceConfigs()
+	if err != nil {
+		logs.Fatal("load service configs failed.", err)
+		panic(err)
+	}
 
 	//start services.
 	dubboServiceApi := apiFactory.CreateDubboServiceApi()
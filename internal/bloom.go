@@ -2,34 +2,140 @@ package internal
 
 import (
 	"infer-microservices/internal/flags"
+	"sync"
 
 	bloom "github.com/bits-and-blooms/bloom/v3"
 )
 
-var userBloomFilterInstance *bloom.BloomFilter
-var itemBloomFilterInstance *bloom.BloomFilter
+var userBloomFilterInstance *MembershipFilter
+var itemBloomFilterInstance *MembershipFilter
+var bloomFilterOnce sync.Once
+var userCountLevel *uint
+var itemCountLevel *uint
+
+const bloomFalsePositiveRate = 0.01
+
+// MembershipFilter is a bloom filter which also supports remove.
+// INFO: bloom filter can not delete bits, removed ids are kept in a tombstone set until next reload.
+type MembershipFilter struct {
+	mu      sync.RWMutex
+	filter  *bloom.BloomFilter
+	removed map[string]struct{}
+	n       uint
+}
 
 func init() {
 	flagFactory := flags.FlagFactory{}
 	flagBloom := flagFactory.CreateFlagBloom()
-	userBloomFilterInstance = bloom.NewWithEstimates(*flagBloom.GetUserCountLevel(), 0.01)
-	itemBloomFilterInstance = bloom.NewWithEstimates(*flagBloom.GetItemCountLevel(), 0.01)
+	userCountLevel = flagBloom.GetUserCountLevel()
+	itemCountLevel = flagBloom.GetItemCountLevel()
+}
+
+// the filters are created on the first use, after the flags are parsed.
+func createBloomFilters() {
+	bloomFilterOnce.Do(func() {
+		userBloomFilterInstance = NewMembershipFilter(*userCountLevel)
+		itemBloomFilterInstance = NewMembershipFilter(*itemCountLevel)
+	})
 }
 
 // INFO: singleton instance
-func GetUserBloomFilterInstance() *bloom.BloomFilter {
+func GetUserBloomFilterInstance() *MembershipFilter {
+	createBloomFilters()
 	return userBloomFilterInstance
 }
 
 // INFO: singleton instance
-func GetItemBloomFilterInstance() *bloom.BloomFilter {
+func GetItemBloomFilterInstance() *MembershipFilter {
+	createBloomFilters()
 	return itemBloomFilterInstance
 }
 
-func BloomPush(filter *bloom.BloomFilter, id string) {
-	filter.Add([]byte(id))
+func NewMembershipFilter(n uint) *MembershipFilter {
+	return &MembershipFilter{
+		filter:  bloom.NewWithEstimates(n, bloomFalsePositiveRate),
+		removed: make(map[string]struct{}, 0),
+		n:       n,
+	}
+}
+
+// NewEmptyFilter create an empty filter with the same estimates, used to rebuild the filter.
+func (m *MembershipFilter) NewEmptyFilter() *MembershipFilter {
+	return NewMembershipFilter(m.n)
+}
+
+func (m *MembershipFilter) Add(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.filter.Add([]byte(id))
+	delete(m.removed, id)
+}
+
+func (m *MembershipFilter) Remove(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.removed[id] = struct{}{}
+}
+
+func (m *MembershipFilter) Test(data []byte) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, ok := m.removed[string(data)]; ok {
+		return false
+	}
+
+	return m.filter.Test(data)
+}
+
+func (m *MembershipFilter) Contains(id string) bool {
+	return m.Test([]byte(id))
+}
+
+// Replace the whole filter by a rebuilt one, such as full reload from file.
+func (m *MembershipFilter) Replace(other *MembershipFilter) {
+	other.mu.RLock()
+	filter := other.filter
+	removed := other.removed
+	other.mu.RUnlock()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.filter = filter
+	m.removed = removed
+}
+
+func (m *MembershipFilter) ClearAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.filter.ClearAll()
+	m.removed = make(map[string]struct{}, 0)
+}
+
+// RemovedCount tombstone count since last reload.
+func (m *MembershipFilter) RemovedCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.removed)
+}
+
+// ApproximatedSize approximated count of ids in the filter.
+func (m *MembershipFilter) ApproximatedSize() uint32 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.filter.ApproximatedSize()
+}
+
+func BloomPush(filter *MembershipFilter, id string) {
+	filter.Add(id)
 }
 
-func CleanBloom(filter *bloom.BloomFilter) {
+func CleanBloom(filter *MembershipFilter) {
 	filter.ClearAll()
 }
@@ -3,23 +3,37 @@ package redis
 import (
 	"context"
 	"infer-microservices/internal/flags"
+	"sync/atomic"
 	"time"
 
 	redis "github.com/go-redis/redis/v8"
 )
 
 var ctx = context.Background()
-var password string
+var password *string
 var flagRedis flags.FlagRedis
 
 type InferRedisClient struct {
-	cli *redis.ClusterClient
+	cli      *redis.ClusterClient
+	inFlight int64 //requests not finished yet.
 }
 
 func init() {
 	flagFactory := flags.FlagFactory{}
 	flagRedis := flagFactory.CreateFlagRedis()
-	password = *flagRedis.GetRedisPassword()
+	password = flagRedis.GetRedisPassword()
+}
+
+// ClusterConf redis cluster conf, the password flag is used if Password is empty.
+type ClusterConf struct {
+	Addrs        []string
+	Password     string
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	MaxRetries   int
+	MinIdleConns int
 }
 
 func NewRedisClusterClient(data map[string]interface{}) *InferRedisClient {
@@ -35,16 +49,34 @@ func NewRedisClusterClient(data map[string]interface{}) *InferRedisClient {
 	for _, addr := range addrs_raw {
 		addrs = append(addrs, addr.(string))
 	}
+
+	return NewRedisClusterClientFromConf(ClusterConf{
+		Addrs:        addrs,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+		DialTimeout:  dialTimeout,
+		IdleTimeout:  idleTimeout,
+		MaxRetries:   maxRetries,
+		MinIdleConns: minIdleConns,
+	})
+}
+
+func NewRedisClusterClientFromConf(conf ClusterConf) *InferRedisClient {
+	clusterPassword := conf.Password
+	if clusterPassword == "" {
+		clusterPassword = *password
+	}
+
 	cli := redis.NewClusterClient(&redis.ClusterOptions{
-		Addrs:         addrs,
-		Password:      password,
-		ReadTimeout:   readTimeout,
-		WriteTimeout:  writeTimeout,
-		DialTimeout:   dialTimeout,
-		IdleTimeout:   idleTimeout,
+		Addrs:         conf.Addrs,
+		Password:      clusterPassword,
+		ReadTimeout:   conf.ReadTimeout,
+		WriteTimeout:  conf.WriteTimeout,
+		DialTimeout:   conf.DialTimeout,
+		IdleTimeout:   conf.IdleTimeout,
 		PoolTimeout:   4 * time.Second,
-		MaxRetries:    maxRetries,
-		MinIdleConns:  minIdleConns,
+		MaxRetries:    conf.MaxRetries,
+		MinIdleConns:  conf.MinIdleConns,
 		ReadOnly:      true,
 		RouteRandomly: true,
 	})
@@ -53,7 +85,15 @@ func NewRedisClusterClient(data map[string]interface{}) *InferRedisClient {
 }
 
 func (m *InferRedisClient) Get(key string) (string, error) {
-	cmd := m.cli.Get(ctx, key)
+	return m.GetContext(ctx, key)
+}
+
+// GetContext get the key in the deadline of ctx_.
+func (m *InferRedisClient) GetContext(ctx_ context.Context, key string) (string, error) {
+	atomic.AddInt64(&m.inFlight, 1)
+	defer atomic.AddInt64(&m.inFlight, -1)
+
+	cmd := m.cli.Get(ctx_, key)
 	value, err := cmd.Result()
 	if err != nil {
 		return "", err
@@ -62,11 +102,22 @@ func (m *InferRedisClient) Get(key string) (string, error) {
 	return value, nil
 }
 
+// IsNil the key does not exist.
+func IsNil(err error) bool {
+	return err == redis.Nil
+}
+
 func (m *InferRedisClient) Set(key string, value string, expire time.Duration) error {
+	atomic.AddInt64(&m.inFlight, 1)
+	defer atomic.AddInt64(&m.inFlight, -1)
+
 	return m.cli.Set(ctx, key, value, expire).Err()
 }
 
 func (m *InferRedisClient) HGet(key string, field string) (string, error) {
+	atomic.AddInt64(&m.inFlight, 1)
+	defer atomic.AddInt64(&m.inFlight, -1)
+
 	value, err := m.cli.HGet(ctx, key, field).Result()
 	if err != nil {
 		return "", err
@@ -75,13 +126,35 @@ func (m *InferRedisClient) HGet(key string, field string) (string, error) {
 }
 
 func (m *InferRedisClient) HSet(key string, field string, value string) error {
+	atomic.AddInt64(&m.inFlight, 1)
+	defer atomic.AddInt64(&m.inFlight, -1)
+
 	return m.cli.HSet(ctx, key, field, value).Err()
 }
 
 func (m *InferRedisClient) HGetAll(key string) (map[string]string, error) {
+	atomic.AddInt64(&m.inFlight, 1)
+	defer atomic.AddInt64(&m.inFlight, -1)
+
 	value, err := m.cli.HGetAll(ctx, key).Result()
 	if err != nil {
 		return map[string]string{}, err
 	}
 	return value, nil
 }
+
+func (m *InferRedisClient) Ping(timeout time.Duration) error {
+	ctx_, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return m.cli.Ping(ctx_).Err()
+}
+
+// InFlightCount requests not finished yet
+func (m *InferRedisClient) InFlightCount() int {
+	return int(atomic.LoadInt64(&m.inFlight))
+}
+
+func (m *InferRedisClient) Close() error {
+	return m.cli.Close()
+}
@@ -0,0 +1,101 @@
+package redis
+
+import (
+	"encoding/json"
+	"infer-microservices/internal/logs"
+	"sync"
+	"time"
+)
+
+// the redis clusters with the same conf share one client, across dataIds and config versions.
+type sharedRedisClient struct {
+	key  string
+	refs int
+}
+
+var sharedRedisClientsMu sync.Mutex
+var sharedRedisClients = make(map[string]*InferRedisClient, 0)                //redis conf -> client
+var sharedRedisClientRefs = make(map[*InferRedisClient]*sharedRedisClient, 0) //client -> refs
+
+// the replaced client is closed when its requests finish, or after the timeout.
+var ClientDrainTimeout = 30 * time.Second
+
+// no request is in flight for the grace before the client is closed, as the grpc pools.
+var ClientDrainGrace = time.Second
+
+// AcquireRedisClusterClient get the shared client of the redis conf, create it if not exists. call ReleaseRedisClient when it is not used.
+func AcquireRedisClusterClient(conf ClusterConf) (*InferRedisClient, error) {
+	keyBytes, err := json.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+	key := string(keyBytes)
+
+	sharedRedisClientsMu.Lock()
+	defer sharedRedisClientsMu.Unlock()
+
+	if client, ok := sharedRedisClients[key]; ok {
+		sharedRedisClientRefs[client].refs += 1
+		return client, nil
+	}
+
+	client := NewRedisClusterClientFromConf(conf)
+	sharedRedisClients[key] = client
+	sharedRedisClientRefs[client] = &sharedRedisClient{key: key, refs: 1}
+
+	return client, nil
+}
+
+// RetainRedisClient one more user of an acquired client.
+func RetainRedisClient(client *InferRedisClient) {
+	sharedRedisClientsMu.Lock()
+	defer sharedRedisClientsMu.Unlock()
+
+	if shared, ok := sharedRedisClientRefs[client]; ok {
+		shared.refs += 1
+	}
+}
+
+// ReleaseRedisClient the last release drains and closes the client.
+func ReleaseRedisClient(client *InferRedisClient) {
+	if client == nil {
+		return
+	}
+
+	sharedRedisClientsMu.Lock()
+	shared, ok := sharedRedisClientRefs[client]
+	if ok {
+		shared.refs -= 1
+		if shared.refs > 0 {
+			sharedRedisClientsMu.Unlock()
+			return
+		}
+		delete(sharedRedisClientRefs, client)
+		delete(sharedRedisClients, shared.key)
+	}
+	sharedRedisClientsMu.Unlock()
+
+	go drainRedisClient(client, ClientDrainTimeout)
+}
+
+// wait for the in-flight requests and the ClientDrainGrace after them, then close the client.
+func drainRedisClient(client *InferRedisClient, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	idleSince := time.Now()
+	for time.Now().Before(deadline) {
+		if client.InFlightCount() > 0 {
+			idleSince = time.Now()
+		} else if time.Since(idleSince) >= ClientDrainGrace {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if inFlight := client.InFlightCount(); inFlight > 0 {
+		logs.Warn("redis client closed with", inFlight, "requests in flight.")
+	}
+
+	err := client.Close()
+	if err != nil {
+		logs.Error(err)
+	}
+}
@@ -28,9 +28,10 @@ var _ = math.Inf
 const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 
 type ItemInfo struct {
-	ItemId    string  `protobuf:"bytes,1,opt,name=ItemId,proto3" json:"ItemId,omitempty"`
-	Score     float32 `protobuf:"fixed32,2,opt,name=Score,proto3" json:"Score,omitempty"`
-	Embedding float32 `protobuf:"fixed32,3,opt,name=Embedding,proto3" json:"Embedding,omitempty"`
+	ItemId     string            `protobuf:"bytes,1,opt,name=ItemId,proto3" json:"ItemId,omitempty"`
+	Score      float32           `protobuf:"fixed32,2,opt,name=Score,proto3" json:"Score,omitempty"`
+	Embedding  float32           `protobuf:"fixed32,3,opt,name=Embedding,proto3" json:"Embedding,omitempty"`
+	Attributes map[string]string `protobuf:"bytes,4,rep,name=Attributes,proto3" json:"Attributes,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 }
 
 func (m *ItemInfo) Reset()         { *m = ItemInfo{} }
@@ -87,6 +88,13 @@ func (m *ItemInfo) GetEmbedding() float32 {
 	return 0
 }
 
+func (m *ItemInfo) GetAttributes() map[string]string {
+	if m != nil {
+		return m.Attributes
+	}
+	return nil
+}
+
 type UserVectorInfo struct {
 	UserId     string    `protobuf:"bytes,1,opt,name=UserId,proto3" json:"UserId,omitempty"`
 	UserVector []float32 `protobuf:"fixed32,2,rep,packed,name=UserVector,proto3" json:"UserVector,omitempty"`
@@ -139,17 +147,80 @@ func (m *UserVectorInfo) GetUserVector() []float32 {
 	return nil
 }
 
+type AttributeFilter struct {
+	Attribute string   `protobuf:"bytes,1,opt,name=Attribute,proto3" json:"Attribute,omitempty"`
+	Op        string   `protobuf:"bytes,2,opt,name=Op,proto3" json:"Op,omitempty"`
+	Values    []string `protobuf:"bytes,3,rep,name=Values,proto3" json:"Values,omitempty"`
+}
+
+func (m *AttributeFilter) Reset()         { *m = AttributeFilter{} }
+func (m *AttributeFilter) String() string { return proto.CompactTextString(m) }
+func (*AttributeFilter) ProtoMessage()    {}
+func (*AttributeFilter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_29f09d8b963a7b04, []int{2}
+}
+func (m *AttributeFilter) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AttributeFilter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AttributeFilter.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *AttributeFilter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AttributeFilter.Merge(m, src)
+}
+func (m *AttributeFilter) XXX_Size() int {
+	return m.Size()
+}
+func (m *AttributeFilter) XXX_DiscardUnknown() {
+	xxx_messageInfo_AttributeFilter.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AttributeFilter proto.InternalMessageInfo
+
+func (m *AttributeFilter) GetAttribute() string {
+	if m != nil {
+		return m.Attribute
+	}
+	return ""
+}
+
+func (m *AttributeFilter) GetOp() string {
+	if m != nil {
+		return m.Op
+	}
+	return ""
+}
+
+func (m *AttributeFilter) GetValues() []string {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
 type RecallRequest struct {
-	IndexName       string          `protobuf:"bytes,1,opt,name=IndexName,proto3" json:"IndexName,omitempty"`
-	UserVectorInfo_ *UserVectorInfo `protobuf:"bytes,2,opt,name=UserVectorInfo_,json=UserVectorInfo,proto3" json:"UserVectorInfo_,omitempty"`
-	RecallNum       int32           `protobuf:"varint,3,opt,name=RecallNum,proto3" json:"RecallNum,omitempty"`
+	IndexName        string             `protobuf:"bytes,1,opt,name=IndexName,proto3" json:"IndexName,omitempty"`
+	UserVectorInfo_  *UserVectorInfo    `protobuf:"bytes,2,opt,name=UserVectorInfo_,json=UserVectorInfo,proto3" json:"UserVectorInfo_,omitempty"`
+	RecallNum        int32              `protobuf:"varint,3,opt,name=RecallNum,proto3" json:"RecallNum,omitempty"`
+	IncludeIds       []string           `protobuf:"bytes,4,rep,name=IncludeIds,proto3" json:"IncludeIds,omitempty"`
+	ExcludeIds       []string           `protobuf:"bytes,5,rep,name=ExcludeIds,proto3" json:"ExcludeIds,omitempty"`
+	AttributeFilters []*AttributeFilter `protobuf:"bytes,6,rep,name=AttributeFilters,proto3" json:"AttributeFilters,omitempty"`
 }
 
 func (m *RecallRequest) Reset()         { *m = RecallRequest{} }
 func (m *RecallRequest) String() string { return proto.CompactTextString(m) }
 func (*RecallRequest) ProtoMessage()    {}
 func (*RecallRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_29f09d8b963a7b04, []int{2}
+	return fileDescriptor_29f09d8b963a7b04, []int{3}
 }
 func (m *RecallRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -199,16 +270,39 @@ func (m *RecallRequest) GetRecallNum() int32 {
 	return 0
 }
 
+func (m *RecallRequest) GetIncludeIds() []string {
+	if m != nil {
+		return m.IncludeIds
+	}
+	return nil
+}
+
+func (m *RecallRequest) GetExcludeIds() []string {
+	if m != nil {
+		return m.ExcludeIds
+	}
+	return nil
+}
+
+func (m *RecallRequest) GetAttributeFilters() []*AttributeFilter {
+	if m != nil {
+		return m.AttributeFilters
+	}
+	return nil
+}
+
 type RecallResponse struct {
 	UserId    string      `protobuf:"bytes,1,opt,name=UserId,proto3" json:"UserId,omitempty"`
 	ItemInfo_ []*ItemInfo `protobuf:"bytes,2,rep,name=ItemInfo_,json=ItemInfo,proto3" json:"ItemInfo_,omitempty"`
+	IndexName string      `protobuf:"bytes,3,opt,name=IndexName,proto3" json:"IndexName,omitempty"`
+	Filtered  bool        `protobuf:"varint,4,opt,name=Filtered,proto3" json:"Filtered,omitempty"`
 }
 
 func (m *RecallResponse) Reset()         { *m = RecallResponse{} }
 func (m *RecallResponse) String() string { return proto.CompactTextString(m) }
 func (*RecallResponse) ProtoMessage()    {}
 func (*RecallResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_29f09d8b963a7b04, []int{3}
+	return fileDescriptor_29f09d8b963a7b04, []int{4}
 }
 func (m *RecallResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -251,6 +345,108 @@ func (m *RecallResponse) GetItemInfo_() []*ItemInfo {
 	return nil
 }
 
+func (m *RecallResponse) GetIndexName() string {
+	if m != nil {
+		return m.IndexName
+	}
+	return ""
+}
+
+func (m *RecallResponse) GetFiltered() bool {
+	if m != nil {
+		return m.Filtered
+	}
+	return false
+}
+
+type BatchRecallRequest struct {
+	RecallRequest_ []*RecallRequest `protobuf:"bytes,1,rep,name=RecallRequest_,json=RecallRequest,proto3" json:"RecallRequest_,omitempty"`
+}
+
+func (m *BatchRecallRequest) Reset()         { *m = BatchRecallRequest{} }
+func (m *BatchRecallRequest) String() string { return proto.CompactTextString(m) }
+func (*BatchRecallRequest) ProtoMessage()    {}
+func (*BatchRecallRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_29f09d8b963a7b04, []int{5}
+}
+func (m *BatchRecallRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *BatchRecallRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_BatchRecallRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *BatchRecallRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchRecallRequest.Merge(m, src)
+}
+func (m *BatchRecallRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *BatchRecallRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchRecallRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchRecallRequest proto.InternalMessageInfo
+
+func (m *BatchRecallRequest) GetRecallRequest_() []*RecallRequest {
+	if m != nil {
+		return m.RecallRequest_
+	}
+	return nil
+}
+
+type BatchRecallResponse struct {
+	RecallResponse_ []*RecallResponse `protobuf:"bytes,1,rep,name=RecallResponse_,json=RecallResponse,proto3" json:"RecallResponse_,omitempty"`
+}
+
+func (m *BatchRecallResponse) Reset()         { *m = BatchRecallResponse{} }
+func (m *BatchRecallResponse) String() string { return proto.CompactTextString(m) }
+func (*BatchRecallResponse) ProtoMessage()    {}
+func (*BatchRecallResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_29f09d8b963a7b04, []int{6}
+}
+func (m *BatchRecallResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *BatchRecallResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_BatchRecallResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *BatchRecallResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchRecallResponse.Merge(m, src)
+}
+func (m *BatchRecallResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *BatchRecallResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchRecallResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchRecallResponse proto.InternalMessageInfo
+
+func (m *BatchRecallResponse) GetRecallResponse_() []*RecallResponse {
+	if m != nil {
+		return m.RecallResponse_
+	}
+	return nil
+}
+
 type IndexInfo struct {
 	IndexName        string `protobuf:"bytes,1,opt,name=IndexName,proto3" json:"IndexName,omitempty"`
 	IndexMd5         string `protobuf:"bytes,2,opt,name=IndexMd5,proto3" json:"IndexMd5,omitempty"`
@@ -264,7 +460,7 @@ func (m *IndexInfo) Reset()         { *m = IndexInfo{} }
 func (m *IndexInfo) String() string { return proto.CompactTextString(m) }
 func (*IndexInfo) ProtoMessage()    {}
 func (*IndexInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_29f09d8b963a7b04, []int{4}
+	return fileDescriptor_29f09d8b963a7b04, []int{7}
 }
 func (m *IndexInfo) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -342,7 +538,7 @@ func (m *GetIndexInfoRequest) Reset()         { *m = GetIndexInfoRequest{} }
 func (m *GetIndexInfoRequest) String() string { return proto.CompactTextString(m) }
 func (*GetIndexInfoRequest) ProtoMessage()    {}
 func (*GetIndexInfoRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_29f09d8b963a7b04, []int{5}
+	return fileDescriptor_29f09d8b963a7b04, []int{8}
 }
 func (m *GetIndexInfoRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -379,7 +575,7 @@ func (m *GetIndexInfoResponse) Reset()         { *m = GetIndexInfoResponse{} }
 func (m *GetIndexInfoResponse) String() string { return proto.CompactTextString(m) }
 func (*GetIndexInfoResponse) ProtoMessage()    {}
 func (*GetIndexInfoResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_29f09d8b963a7b04, []int{6}
+	return fileDescriptor_29f09d8b963a7b04, []int{9}
 }
 func (m *GetIndexInfoResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -417,9 +613,13 @@ func (m *GetIndexInfoResponse) GetIndexInfo_() []*IndexInfo {
 
 func init() {
 	proto.RegisterType((*ItemInfo)(nil), "ItemInfo")
+	proto.RegisterMapType((map[string]string)(nil), "ItemInfo.AttributesEntry")
 	proto.RegisterType((*UserVectorInfo)(nil), "UserVectorInfo")
+	proto.RegisterType((*AttributeFilter)(nil), "AttributeFilter")
 	proto.RegisterType((*RecallRequest)(nil), "RecallRequest")
 	proto.RegisterType((*RecallResponse)(nil), "RecallResponse")
+	proto.RegisterType((*BatchRecallRequest)(nil), "BatchRecallRequest")
+	proto.RegisterType((*BatchRecallResponse)(nil), "BatchRecallResponse")
 	proto.RegisterType((*IndexInfo)(nil), "IndexInfo")
 	proto.RegisterType((*GetIndexInfoRequest)(nil), "GetIndexInfoRequest")
 	proto.RegisterType((*GetIndexInfoResponse)(nil), "GetIndexInfoResponse")
@@ -428,36 +628,49 @@ func init() {
 func init() { proto.RegisterFile("faiss_index.proto", fileDescriptor_29f09d8b963a7b04) }
 
 var fileDescriptor_29f09d8b963a7b04 = []byte{
-	// 453 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x53, 0xcf, 0x6b, 0x13, 0x41,
-	0x14, 0xce, 0xa4, 0x26, 0x74, 0x5f, 0x6d, 0x56, 0xc7, 0x54, 0x87, 0x50, 0x96, 0xb0, 0x88, 0xa4,
-	0x82, 0x23, 0x44, 0x04, 0xa1, 0x27, 0x45, 0xa9, 0x01, 0x2d, 0x32, 0xa9, 0x3d, 0x78, 0x59, 0xb6,
-	0xbb, 0x2f, 0x61, 0xa1, 0xbb, 0xb3, 0xee, 0x6c, 0x45, 0xbd, 0xf7, 0xee, 0x9f, 0xe5, 0xb1, 0xde,
-	0x3c, 0x4a, 0xf2, 0x8f, 0xc8, 0xce, 0xfe, 0x98, 0x6e, 0xad, 0xbd, 0x84, 0xf9, 0xbe, 0x79, 0xf3,
-	0xde, 0xf7, 0x7d, 0x2f, 0x0b, 0x77, 0x17, 0x7e, 0xa4, 0x94, 0x17, 0x25, 0x21, 0x7e, 0xe5, 0x69,
-	0x26, 0x73, 0xe9, 0x1e, 0xc3, 0xe6, 0x2c, 0xc7, 0x78, 0x96, 0x2c, 0x24, 0xbd, 0x0f, 0x7d, 0x7d,
-	0x0e, 0x19, 0x19, 0x93, 0x89, 0x25, 0x2a, 0x44, 0x87, 0xd0, 0x9b, 0x07, 0x32, 0x43, 0xd6, 0x1d,
-	0x93, 0x49, 0x57, 0x94, 0x80, 0xee, 0x82, 0xf5, 0x26, 0x3e, 0xc1, 0x30, 0x8c, 0x92, 0x25, 0xdb,
-	0xd0, 0x37, 0x86, 0x70, 0xdf, 0xc2, 0xe0, 0xa3, 0xc2, 0xec, 0x18, 0x83, 0x5c, 0x66, 0x75, 0xf7,
-	0x82, 0x31, 0xdd, 0x4b, 0x44, 0x1d, 0x00, 0x53, 0xc9, 0xba, 0xe3, 0x8d, 0x49, 0x57, 0x5c, 0x62,
-	0xdc, 0x73, 0x02, 0xdb, 0x02, 0x03, 0xff, 0xf4, 0x54, 0xe0, 0xe7, 0x33, 0x54, 0x79, 0x31, 0x79,
-	0x56, 0x58, 0x38, 0xf4, 0x63, 0xac, 0x9a, 0x19, 0x82, 0xbe, 0x00, 0xbb, 0x3d, 0xd9, 0xd3, 0xba,
-	0xb7, 0xa6, 0x36, 0x6f, 0xf3, 0xe2, 0xaa, 0xc2, 0x5d, 0xb0, 0xca, 0x41, 0x87, 0x67, 0xb1, 0x76,
-	0xd4, 0x13, 0x86, 0x70, 0x3f, 0xc0, 0xa0, 0x96, 0xa1, 0x52, 0x99, 0x28, 0xfc, 0xaf, 0xa3, 0x47,
-	0x60, 0xd5, 0x99, 0x7a, 0xda, 0xd0, 0xd6, 0xd4, 0xe2, 0x35, 0x23, 0x9a, 0xbc, 0xdd, 0x5f, 0xa4,
-	0x32, 0x52, 0x4f, 0xbf, 0xc1, 0xd5, 0x08, 0x36, 0x35, 0x78, 0x1f, 0x3e, 0xd7, 0x76, 0x2c, 0xd1,
-	0xe0, 0xe6, 0xe5, 0xd1, 0xb7, 0x14, 0xb5, 0xee, 0xfa, 0x65, 0x41, 0xd0, 0x87, 0xb0, 0xad, 0xc1,
-	0x3b, 0xe9, 0x87, 0x47, 0x51, 0x8c, 0xec, 0x96, 0xae, 0x68, 0x93, 0x4d, 0xff, 0xd7, 0x51, 0xcc,
-	0x7a, 0xda, 0x7a, 0x83, 0xe9, 0x63, 0xb8, 0xa3, 0xcf, 0x65, 0x54, 0x6a, 0x1e, 0x7d, 0x47, 0xd6,
-	0xd7, 0x35, 0xff, 0xf0, 0xee, 0x0e, 0xdc, 0x3b, 0xc0, 0xbc, 0x71, 0x55, 0xad, 0xcc, 0x7d, 0x09,
-	0xc3, 0x36, 0x5d, 0x45, 0xb8, 0x07, 0xd0, 0x90, 0x1e, 0x23, 0x3a, 0x2b, 0xe0, 0xa6, 0xce, 0xe4,
-	0x33, 0x3d, 0x27, 0xf0, 0xe0, 0x20, 0x4b, 0x83, 0x72, 0x09, 0x73, 0xcc, 0xbe, 0x60, 0x56, 0xfc,
-	0x46, 0x01, 0xd2, 0x27, 0x00, 0xe6, 0x8a, 0x0e, 0x78, 0xeb, 0xff, 0x32, 0xb2, 0xf9, 0x95, 0xc5,
-	0xed, 0xc3, 0xed, 0xcb, 0x6a, 0xe8, 0x90, 0x5f, 0xa3, 0x79, 0xb4, 0xc3, 0xaf, 0x93, 0xfc, 0x6a,
-	0xef, 0xe7, 0xca, 0x21, 0x17, 0x2b, 0x87, 0xfc, 0x59, 0x39, 0xe4, 0xc7, 0xda, 0xe9, 0x5c, 0xac,
-	0x9d, 0xce, 0xef, 0xb5, 0xd3, 0xf9, 0x64, 0xf3, 0xa7, 0xfb, 0xf9, 0x42, 0x15, 0x9a, 0x92, 0xa5,
-	0xb7, 0x94, 0x27, 0x7d, 0xfd, 0x8d, 0x3d, 0xfb, 0x1b, 0x00, 0x00, 0xff, 0xff, 0x54, 0x5b, 0x3c,
-	0xf4, 0x78, 0x03, 0x00, 0x00,
+	// 662 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x54, 0x41, 0x6f, 0xd3, 0x4c,
+	0x10, 0xed, 0xda, 0x4d, 0x54, 0x4f, 0xbf, 0x26, 0xf9, 0x36, 0x29, 0x98, 0xa8, 0xb2, 0x22, 0x0b,
+	0xa1, 0x80, 0xd4, 0x45, 0x0a, 0xaa, 0x54, 0x68, 0x39, 0xb4, 0xa2, 0x94, 0x08, 0x68, 0xa5, 0x6d,
+	0x29, 0x12, 0x97, 0xc8, 0xb5, 0xb7, 0xc5, 0x22, 0x89, 0x83, 0xed, 0x54, 0x2d, 0x57, 0x6e, 0x9c,
+	0xf8, 0x4f, 0x5c, 0x38, 0x96, 0x1b, 0x47, 0xd4, 0xfe, 0x11, 0xb4, 0x6b, 0x7b, 0xed, 0x75, 0xc2,
+	0x25, 0xda, 0x79, 0x33, 0x99, 0x99, 0xf7, 0xde, 0xc8, 0xf0, 0xff, 0x99, 0xe3, 0x47, 0xd1, 0xc0,
+	0x1f, 0x7b, 0xec, 0x92, 0x4c, 0xc2, 0x20, 0x0e, 0xec, 0x6b, 0x04, 0x4b, 0xfd, 0x98, 0x8d, 0xfa,
+	0xe3, 0xb3, 0x00, 0xdf, 0x81, 0xaa, 0x78, 0x7b, 0x26, 0xea, 0xa0, 0xae, 0x41, 0xd3, 0x08, 0xb7,
+	0xa0, 0x72, 0xe4, 0x06, 0x21, 0x33, 0xb5, 0x0e, 0xea, 0x6a, 0x34, 0x09, 0xf0, 0x1a, 0x18, 0x7b,
+	0xa3, 0x53, 0xe6, 0x79, 0xfe, 0xf8, 0xdc, 0xd4, 0x45, 0x26, 0x07, 0xf0, 0x53, 0x80, 0x9d, 0x38,
+	0x0e, 0xfd, 0xd3, 0x69, 0xcc, 0x22, 0x73, 0xb1, 0xa3, 0x77, 0x97, 0x7b, 0xf7, 0x48, 0x36, 0x8a,
+	0xe4, 0xb9, 0xbd, 0x71, 0x1c, 0x5e, 0xd1, 0x42, 0x71, 0xfb, 0x39, 0xd4, 0x4b, 0x69, 0xdc, 0x00,
+	0xfd, 0x13, 0xbb, 0x4a, 0xd7, 0xe2, 0x4f, 0xbe, 0xd3, 0x85, 0x33, 0x9c, 0x26, 0x3b, 0x19, 0x34,
+	0x09, 0x9e, 0x69, 0x9b, 0xc8, 0x7e, 0x05, 0xb5, 0x77, 0x11, 0x0b, 0x4f, 0x98, 0x1b, 0x07, 0x61,
+	0xc6, 0x8b, 0x23, 0x39, 0xaf, 0x24, 0xc2, 0x16, 0x40, 0x5e, 0x69, 0x6a, 0x1d, 0xbd, 0xab, 0xd1,
+	0x02, 0x62, 0xbf, 0x2f, 0x2c, 0xf2, 0xd2, 0x1f, 0xc6, 0x2c, 0xe4, 0xa4, 0x25, 0x94, 0x76, 0xcb,
+	0x01, 0x5c, 0x03, 0xed, 0x70, 0x92, 0x6e, 0xa4, 0x1d, 0x4e, 0xf8, 0xe0, 0x13, 0xbe, 0x57, 0x64,
+	0xea, 0x1d, 0x9d, 0x0f, 0x4e, 0x22, 0xfb, 0xab, 0x06, 0x2b, 0x94, 0xb9, 0xce, 0x70, 0x48, 0xd9,
+	0xe7, 0x29, 0x8b, 0x62, 0xde, 0xb7, 0xcf, 0x6d, 0x39, 0x70, 0x46, 0xb2, 0xaf, 0x04, 0xf0, 0x26,
+	0xd4, 0x55, 0x4a, 0x03, 0x31, 0x64, 0xb9, 0x57, 0x27, 0x2a, 0x4e, 0xcb, 0xd4, 0xd7, 0xc0, 0x48,
+	0x06, 0x1d, 0x4c, 0x47, 0xc2, 0xa4, 0x0a, 0xcd, 0x01, 0x2e, 0x40, 0x7f, 0xec, 0x0e, 0xa7, 0x1e,
+	0xeb, 0x7b, 0x89, 0x49, 0x06, 0x2d, 0x20, 0x3c, 0xbf, 0x77, 0x29, 0xf3, 0x95, 0x24, 0x9f, 0x23,
+	0x78, 0x1b, 0x1a, 0x25, 0x81, 0x22, 0xb3, 0x2a, 0xac, 0x6e, 0x90, 0x52, 0x82, 0xce, 0x54, 0xda,
+	0xdf, 0x10, 0xd4, 0x32, 0x15, 0xa2, 0x49, 0x30, 0x8e, 0xd8, 0x3f, 0x9d, 0x7a, 0x00, 0x46, 0x76,
+	0x3a, 0x03, 0x61, 0xd4, 0x72, 0xcf, 0x90, 0xc7, 0x44, 0xf3, 0x0b, 0x56, 0x64, 0xd4, 0xcb, 0x32,
+	0xb6, 0x61, 0x29, 0x99, 0xcd, 0x3c, 0x73, 0xb1, 0x83, 0xba, 0x4b, 0x54, 0xc6, 0xf6, 0x6b, 0xc0,
+	0xbb, 0x4e, 0xec, 0x7e, 0x54, 0x6d, 0xd9, 0x80, 0x9a, 0x02, 0x0c, 0x4c, 0x24, 0x86, 0xd7, 0x88,
+	0x02, 0x53, 0xd5, 0x4d, 0xfb, 0x10, 0x9a, 0x4a, 0xb3, 0x94, 0xdd, 0x26, 0xd4, 0x55, 0x24, 0x6b,
+	0x57, 0x27, 0x2a, 0x4e, 0x4b, 0xba, 0xd8, 0xbf, 0x50, 0x4a, 0x6c, 0x96, 0x25, 0x9a, 0xc3, 0x52,
+	0x04, 0x6f, 0xbd, 0x8d, 0xf4, 0x14, 0x65, 0x2c, 0xff, 0x79, 0x7c, 0x35, 0x51, 0xf5, 0xe1, 0x00,
+	0xbe, 0x0f, 0x2b, 0x22, 0x78, 0x13, 0x38, 0xde, 0xb1, 0x3f, 0x62, 0x42, 0x24, 0x83, 0xaa, 0xa0,
+	0xec, 0xff, 0xc2, 0x1f, 0x99, 0x15, 0x71, 0x51, 0x32, 0xc6, 0x8f, 0xa0, 0x21, 0xde, 0xc9, 0x05,
+	0x46, 0x47, 0xfe, 0x17, 0x66, 0x56, 0x45, 0xcd, 0x0c, 0x6e, 0xaf, 0x42, 0x73, 0x9f, 0xc5, 0x92,
+	0x55, 0xa6, 0xdd, 0x0e, 0xb4, 0x54, 0x38, 0x15, 0xef, 0x21, 0x80, 0x04, 0x33, 0xdd, 0x80, 0xe4,
+	0x75, 0xb9, 0x3e, 0xbd, 0x1f, 0x08, 0xee, 0xee, 0x87, 0x13, 0x37, 0x11, 0xf1, 0x88, 0x85, 0x17,
+	0x2c, 0xe4, 0xbf, 0xbe, 0xcb, 0xf0, 0x3a, 0x40, 0x9e, 0xc2, 0x25, 0x1f, 0xdb, 0x65, 0x23, 0xf0,
+	0x16, 0xfc, 0x57, 0xdc, 0x06, 0xb7, 0xc8, 0x9c, 0x9d, 0xdb, 0xab, 0x64, 0xee, 0xca, 0xdb, 0x50,
+	0xe7, 0xb3, 0x0a, 0xa7, 0x80, 0x9b, 0x64, 0xf6, 0xca, 0xda, 0x2d, 0x32, 0xe7, 0x5a, 0x76, 0xd7,
+	0x7f, 0xde, 0x58, 0xe8, 0xfa, 0xc6, 0x42, 0x7f, 0x6e, 0x2c, 0xf4, 0xfd, 0xd6, 0x5a, 0xb8, 0xbe,
+	0xb5, 0x16, 0x7e, 0xdf, 0x5a, 0x0b, 0x1f, 0x9a, 0xe4, 0xf1, 0x56, 0xf2, 0x29, 0x3f, 0x0f, 0xce,
+	0x83, 0x33, 0x27, 0x8a, 0x59, 0x78, 0x5a, 0x15, 0x1f, 0xf4, 0x27, 0x7f, 0x07, 0x00, 0xf6, 0x01,
+	0x56, 0xeb, 0xe5, 0x05, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -474,6 +687,7 @@ const _ = grpc.SupportPackageIsVersion4
 type GrpcRecallServerServiceClient interface {
 	GrpcRecall(ctx context.Context, in *RecallRequest, opts ...grpc.CallOption) (*RecallResponse, error)
 	GetIndexInfo(ctx context.Context, in *GetIndexInfoRequest, opts ...grpc.CallOption) (*GetIndexInfoResponse, error)
+	GrpcBatchRecall(ctx context.Context, in *BatchRecallRequest, opts ...grpc.CallOption) (*BatchRecallResponse, error)
 }
 
 type grpcRecallServerServiceClient struct {
@@ -502,10 +716,20 @@ func (c *grpcRecallServerServiceClient) GetIndexInfo(ctx context.Context, in *Ge
 	return out, nil
 }
 
+func (c *grpcRecallServerServiceClient) GrpcBatchRecall(ctx context.Context, in *BatchRecallRequest, opts ...grpc.CallOption) (*BatchRecallResponse, error) {
+	out := new(BatchRecallResponse)
+	err := c.cc.Invoke(ctx, "/GrpcRecallServerService/GrpcBatchRecall", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // GrpcRecallServerServiceServer is the server API for GrpcRecallServerService service.
 type GrpcRecallServerServiceServer interface {
 	GrpcRecall(context.Context, *RecallRequest) (*RecallResponse, error)
 	GetIndexInfo(context.Context, *GetIndexInfoRequest) (*GetIndexInfoResponse, error)
+	GrpcBatchRecall(context.Context, *BatchRecallRequest) (*BatchRecallResponse, error)
 }
 
 // UnimplementedGrpcRecallServerServiceServer can be embedded to have forward compatible implementations.
@@ -518,6 +742,9 @@ func (*UnimplementedGrpcRecallServerServiceServer) GrpcRecall(ctx context.Contex
 func (*UnimplementedGrpcRecallServerServiceServer) GetIndexInfo(ctx context.Context, req *GetIndexInfoRequest) (*GetIndexInfoResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetIndexInfo not implemented")
 }
+func (*UnimplementedGrpcRecallServerServiceServer) GrpcBatchRecall(ctx context.Context, req *BatchRecallRequest) (*BatchRecallResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GrpcBatchRecall not implemented")
+}
 
 func RegisterGrpcRecallServerServiceServer(s *grpc.Server, srv GrpcRecallServerServiceServer) {
 	s.RegisterService(&_GrpcRecallServerService_serviceDesc, srv)
@@ -559,6 +786,24 @@ func _GrpcRecallServerService_GetIndexInfo_Handler(srv interface{}, ctx context.
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GrpcRecallServerService_GrpcBatchRecall_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchRecallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrpcRecallServerServiceServer).GrpcBatchRecall(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/GrpcRecallServerService/GrpcBatchRecall",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrpcRecallServerServiceServer).GrpcBatchRecall(ctx, req.(*BatchRecallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _GrpcRecallServerService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "GrpcRecallServerService",
 	HandlerType: (*GrpcRecallServerServiceServer)(nil),
@@ -571,6 +816,10 @@ var _GrpcRecallServerService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetIndexInfo",
 			Handler:    _GrpcRecallServerService_GetIndexInfo_Handler,
 		},
+		{
+			MethodName: "GrpcBatchRecall",
+			Handler:    _GrpcRecallServerService_GrpcBatchRecall_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "faiss_index.proto",
@@ -596,6 +845,25 @@ func (m *ItemInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Attributes) > 0 {
+		for k := range m.Attributes {
+			v := m.Attributes[k]
+			baseI := i
+			i -= len(v)
+			copy(dAtA[i:], v)
+			i = encodeVarintFaissIndex(dAtA, i, uint64(len(v)))
+			i--
+			dAtA[i] = 0x12
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintFaissIndex(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintFaissIndex(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
 	if m.Embedding != 0 {
 		i -= 4
 		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.Embedding))))
@@ -658,6 +926,52 @@ func (m *UserVectorInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *AttributeFilter) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AttributeFilter) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *AttributeFilter) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Values) > 0 {
+		for iNdEx := len(m.Values) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Values[iNdEx])
+			copy(dAtA[i:], m.Values[iNdEx])
+			i = encodeVarintFaissIndex(dAtA, i, uint64(len(m.Values[iNdEx])))
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if len(m.Op) > 0 {
+		i -= len(m.Op)
+		copy(dAtA[i:], m.Op)
+		i = encodeVarintFaissIndex(dAtA, i, uint64(len(m.Op)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Attribute) > 0 {
+		i -= len(m.Attribute)
+		copy(dAtA[i:], m.Attribute)
+		i = encodeVarintFaissIndex(dAtA, i, uint64(len(m.Attribute)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *RecallRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -678,6 +992,38 @@ func (m *RecallRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.AttributeFilters) > 0 {
+		for iNdEx := len(m.AttributeFilters) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.AttributeFilters[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintFaissIndex(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x32
+		}
+	}
+	if len(m.ExcludeIds) > 0 {
+		for iNdEx := len(m.ExcludeIds) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.ExcludeIds[iNdEx])
+			copy(dAtA[i:], m.ExcludeIds[iNdEx])
+			i = encodeVarintFaissIndex(dAtA, i, uint64(len(m.ExcludeIds[iNdEx])))
+			i--
+			dAtA[i] = 0x2a
+		}
+	}
+	if len(m.IncludeIds) > 0 {
+		for iNdEx := len(m.IncludeIds) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.IncludeIds[iNdEx])
+			copy(dAtA[i:], m.IncludeIds[iNdEx])
+			i = encodeVarintFaissIndex(dAtA, i, uint64(len(m.IncludeIds[iNdEx])))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
 	if m.RecallNum != 0 {
 		i = encodeVarintFaissIndex(dAtA, i, uint64(m.RecallNum))
 		i--
@@ -725,6 +1071,23 @@ func (m *RecallResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Filtered {
+		i--
+		if m.Filtered {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
+	if len(m.IndexName) > 0 {
+		i -= len(m.IndexName)
+		copy(dAtA[i:], m.IndexName)
+		i = encodeVarintFaissIndex(dAtA, i, uint64(len(m.IndexName)))
+		i--
+		dAtA[i] = 0x1a
+	}
 	if len(m.ItemInfo_) > 0 {
 		for iNdEx := len(m.ItemInfo_) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -749,7 +1112,7 @@ func (m *RecallResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func (m *IndexInfo) Marshal() (dAtA []byte, err error) {
+func (m *BatchRecallRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -759,39 +1122,113 @@ func (m *IndexInfo) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *IndexInfo) MarshalTo(dAtA []byte) (int, error) {
+func (m *BatchRecallRequest) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *IndexInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *BatchRecallRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if m.IndexVectorsSize != 0 {
-		i = encodeVarintFaissIndex(dAtA, i, uint64(m.IndexVectorsSize))
-		i--
-		dAtA[i] = 0x30
-	}
-	if m.IndexDim != 0 {
-		i = encodeVarintFaissIndex(dAtA, i, uint64(m.IndexDim))
-		i--
-		dAtA[i] = 0x28
-	}
-	if len(m.IndexLoadTime) > 0 {
-		i -= len(m.IndexLoadTime)
-		copy(dAtA[i:], m.IndexLoadTime)
-		i = encodeVarintFaissIndex(dAtA, i, uint64(len(m.IndexLoadTime)))
-		i--
-		dAtA[i] = 0x22
+	if len(m.RecallRequest_) > 0 {
+		for iNdEx := len(m.RecallRequest_) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.RecallRequest_[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintFaissIndex(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
 	}
-	if len(m.IndexType) > 0 {
-		i -= len(m.IndexType)
-		copy(dAtA[i:], m.IndexType)
-		i = encodeVarintFaissIndex(dAtA, i, uint64(len(m.IndexType)))
-		i--
-		dAtA[i] = 0x1a
+	return len(dAtA) - i, nil
+}
+
+func (m *BatchRecallResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *BatchRecallResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *BatchRecallResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.RecallResponse_) > 0 {
+		for iNdEx := len(m.RecallResponse_) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.RecallResponse_[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintFaissIndex(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *IndexInfo) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *IndexInfo) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *IndexInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.IndexVectorsSize != 0 {
+		i = encodeVarintFaissIndex(dAtA, i, uint64(m.IndexVectorsSize))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.IndexDim != 0 {
+		i = encodeVarintFaissIndex(dAtA, i, uint64(m.IndexDim))
+		i--
+		dAtA[i] = 0x28
+	}
+	if len(m.IndexLoadTime) > 0 {
+		i -= len(m.IndexLoadTime)
+		copy(dAtA[i:], m.IndexLoadTime)
+		i = encodeVarintFaissIndex(dAtA, i, uint64(len(m.IndexLoadTime)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.IndexType) > 0 {
+		i -= len(m.IndexType)
+		copy(dAtA[i:], m.IndexType)
+		i = encodeVarintFaissIndex(dAtA, i, uint64(len(m.IndexType)))
+		i--
+		dAtA[i] = 0x1a
 	}
 	if len(m.IndexMd5) > 0 {
 		i -= len(m.IndexMd5)
@@ -897,6 +1334,14 @@ func (m *ItemInfo) Size() (n int) {
 	if m.Embedding != 0 {
 		n += 5
 	}
+	if len(m.Attributes) > 0 {
+		for k, v := range m.Attributes {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovFaissIndex(uint64(len(k))) + 1 + len(v) + sovFaissIndex(uint64(len(v)))
+			n += mapEntrySize + 1 + sovFaissIndex(uint64(mapEntrySize))
+		}
+	}
 	return n
 }
 
@@ -916,6 +1361,29 @@ func (m *UserVectorInfo) Size() (n int) {
 	return n
 }
 
+func (m *AttributeFilter) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Attribute)
+	if l > 0 {
+		n += 1 + l + sovFaissIndex(uint64(l))
+	}
+	l = len(m.Op)
+	if l > 0 {
+		n += 1 + l + sovFaissIndex(uint64(l))
+	}
+	if len(m.Values) > 0 {
+		for _, s := range m.Values {
+			l = len(s)
+			n += 1 + l + sovFaissIndex(uint64(l))
+		}
+	}
+	return n
+}
+
 func (m *RecallRequest) Size() (n int) {
 	if m == nil {
 		return 0
@@ -933,6 +1401,24 @@ func (m *RecallRequest) Size() (n int) {
 	if m.RecallNum != 0 {
 		n += 1 + sovFaissIndex(uint64(m.RecallNum))
 	}
+	if len(m.IncludeIds) > 0 {
+		for _, s := range m.IncludeIds {
+			l = len(s)
+			n += 1 + l + sovFaissIndex(uint64(l))
+		}
+	}
+	if len(m.ExcludeIds) > 0 {
+		for _, s := range m.ExcludeIds {
+			l = len(s)
+			n += 1 + l + sovFaissIndex(uint64(l))
+		}
+	}
+	if len(m.AttributeFilters) > 0 {
+		for _, e := range m.AttributeFilters {
+			l = e.Size()
+			n += 1 + l + sovFaissIndex(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -952,6 +1438,43 @@ func (m *RecallResponse) Size() (n int) {
 			n += 1 + l + sovFaissIndex(uint64(l))
 		}
 	}
+	l = len(m.IndexName)
+	if l > 0 {
+		n += 1 + l + sovFaissIndex(uint64(l))
+	}
+	if m.Filtered {
+		n += 2
+	}
+	return n
+}
+
+func (m *BatchRecallRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.RecallRequest_) > 0 {
+		for _, e := range m.RecallRequest_ {
+			l = e.Size()
+			n += 1 + l + sovFaissIndex(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *BatchRecallResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.RecallResponse_) > 0 {
+		for _, e := range m.RecallResponse_ {
+			l = e.Size()
+			n += 1 + l + sovFaissIndex(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -1099,6 +1622,133 @@ func (m *ItemInfo) Unmarshal(dAtA []byte) error {
 			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
 			iNdEx += 4
 			m.Embedding = float32(math.Float32frombits(v))
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Attributes", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFaissIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Attributes == nil {
+				m.Attributes = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowFaissIndex
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowFaissIndex
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthFaissIndex
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthFaissIndex
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowFaissIndex
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthFaissIndex
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue < 0 {
+						return ErrInvalidLengthFaissIndex
+					}
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipFaissIndex(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if (skippy < 0) || (iNdEx+skippy) < 0 {
+						return ErrInvalidLengthFaissIndex
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Attributes[mapkey] = mapvalue
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipFaissIndex(dAtA[iNdEx:])
@@ -1256,7 +1906,7 @@ func (m *UserVectorInfo) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *RecallRequest) Unmarshal(dAtA []byte) error {
+func (m *AttributeFilter) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1279,15 +1929,15 @@ func (m *RecallRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: RecallRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: AttributeFilter: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: RecallRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: AttributeFilter: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field IndexName", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Attribute", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -1315,13 +1965,13 @@ func (m *RecallRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.IndexName = string(dAtA[iNdEx:postIndex])
+			m.Attribute = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field UserVectorInfo_", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Op", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowFaissIndex
@@ -1331,33 +1981,29 @@ func (m *RecallRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthFaissIndex
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthFaissIndex
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.UserVectorInfo_ == nil {
-				m.UserVectorInfo_ = &UserVectorInfo{}
-			}
-			if err := m.UserVectorInfo_.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Op = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 3:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field RecallNum", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Values", wireType)
 			}
-			m.RecallNum = 0
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowFaissIndex
@@ -1367,11 +2013,24 @@ func (m *RecallRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.RecallNum |= int32(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Values = append(m.Values, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipFaissIndex(dAtA[iNdEx:])
@@ -1393,7 +2052,7 @@ func (m *RecallRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *RecallResponse) Unmarshal(dAtA []byte) error {
+func (m *RecallRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1416,15 +2075,15 @@ func (m *RecallResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: RecallResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: RecallRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: RecallResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: RecallRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field UserId", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field IndexName", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -1452,11 +2111,11 @@ func (m *RecallResponse) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.UserId = string(dAtA[iNdEx:postIndex])
+			m.IndexName = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ItemInfo_", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field UserVectorInfo_", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -1483,8 +2142,463 @@ func (m *RecallResponse) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.ItemInfo_ = append(m.ItemInfo_, &ItemInfo{})
-			if err := m.ItemInfo_[len(m.ItemInfo_)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if m.UserVectorInfo_ == nil {
+				m.UserVectorInfo_ = &UserVectorInfo{}
+			}
+			if err := m.UserVectorInfo_.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RecallNum", wireType)
+			}
+			m.RecallNum = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFaissIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.RecallNum |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IncludeIds", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFaissIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.IncludeIds = append(m.IncludeIds, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExcludeIds", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFaissIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ExcludeIds = append(m.ExcludeIds, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AttributeFilters", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFaissIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AttributeFilters = append(m.AttributeFilters, &AttributeFilter{})
+			if err := m.AttributeFilters[len(m.AttributeFilters)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipFaissIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RecallResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowFaissIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RecallResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RecallResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field UserId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFaissIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.UserId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ItemInfo_", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFaissIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ItemInfo_ = append(m.ItemInfo_, &ItemInfo{})
+			if err := m.ItemInfo_[len(m.ItemInfo_)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IndexName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFaissIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.IndexName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Filtered", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFaissIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Filtered = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipFaissIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *BatchRecallRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowFaissIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: BatchRecallRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: BatchRecallRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RecallRequest_", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFaissIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RecallRequest_ = append(m.RecallRequest_, &RecallRequest{})
+			if err := m.RecallRequest_[len(m.RecallRequest_)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipFaissIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *BatchRecallResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowFaissIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: BatchRecallResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: BatchRecallResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RecallResponse_", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFaissIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthFaissIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RecallResponse_ = append(m.RecallResponse_, &RecallResponse{})
+			if err := m.RecallResponse_[len(m.RecallResponse_)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -4,6 +4,8 @@ type FlagBloom struct {
 	//redis
 	userCountLevel *uint
 	itemCountLevel *uint
+	//membership
+	membershipReloadDir *string
 }
 
 var flagBloomInstance *FlagBloom
@@ -34,3 +36,12 @@ func (s *FlagBloom) setItemCountLevel(itemCountLevel *uint) {
 func (s *FlagBloom) GetItemCountLevel() *uint {
 	return s.itemCountLevel
 }
+
+// membershipReloadDir
+func (s *FlagBloom) setMembershipReloadDir(membershipReloadDir *string) {
+	s.membershipReloadDir = membershipReloadDir
+}
+
+func (s *FlagBloom) GetMembershipReloadDir() *string {
+	return s.membershipReloadDir
+}
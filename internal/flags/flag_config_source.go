@@ -0,0 +1,35 @@
+package flags
+
+var flagConfigSourceInstance *flagConfigSource
+
+type flagConfigSource struct {
+	configSource   *string
+	configLocalDir *string
+}
+
+// singleton instance
+func init() {
+	flagConfigSourceInstance = new(flagConfigSource)
+}
+
+func getFlagConfigSourceInstance() *flagConfigSource {
+	return flagConfigSourceInstance
+}
+
+// config_source
+func (s *flagConfigSource) setConfigSource(configSource *string) {
+	s.configSource = configSource
+}
+
+func (s *flagConfigSource) GetConfigSource() *string {
+	return s.configSource
+}
+
+// config_local_dir
+func (s *flagConfigSource) setConfigLocalDir(configLocalDir *string) {
+	s.configLocalDir = configLocalDir
+}
+
+func (s *flagConfigSource) GetConfigLocalDir() *string {
+	return s.configLocalDir
+}
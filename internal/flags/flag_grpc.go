@@ -0,0 +1,25 @@
+package flags
+
+var flagGrpcInstance *flagGrpc
+
+type flagGrpc struct {
+	hedgeBudgetPercent *float64
+}
+
+// singleton instance
+func init() {
+	flagGrpcInstance = new(flagGrpc)
+}
+
+func getFlagGrpcInstance() *flagGrpc {
+	return flagGrpcInstance
+}
+
+// grpc_hedge_budget_percent
+func (s *flagGrpc) setHedgeBudgetPercent(hedgeBudgetPercent *float64) {
+	s.hedgeBudgetPercent = hedgeBudgetPercent
+}
+
+func (s *flagGrpc) GetHedgeBudgetPercent() *float64 {
+	return s.hedgeBudgetPercent
+}
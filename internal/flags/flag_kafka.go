@@ -4,9 +4,11 @@ var flagKafkaInstance *flagKafka
 
 type flagKafka struct {
 	// tensorflow
-	kafkaUrl   string
-	kafkaTopic string
-	kafkaGroup string
+	kafkaUrl   *string
+	kafkaTopic *string
+	kafkaGroup *string
+	//membership events
+	kafkaMembershipTopic *string
 }
 
 // singleton instance
@@ -19,28 +21,37 @@ func getFlagKafkaInstance() *flagKafka {
 }
 
 // kafkaUrl
-func (s *flagKafka) setKafkaUrl(kafkaUrl string) {
+func (s *flagKafka) setKafkaUrl(kafkaUrl *string) {
 	s.kafkaUrl = kafkaUrl
 }
 
-func (s *flagKafka) GetKafkaUrl() string {
+func (s *flagKafka) GetKafkaUrl() *string {
 	return s.kafkaUrl
 }
 
 // kafkaTopic
-func (s *flagKafka) setKafkaTopic(kafkaTopic string) {
+func (s *flagKafka) setKafkaTopic(kafkaTopic *string) {
 	s.kafkaTopic = kafkaTopic
 }
 
-func (s *flagKafka) GetKafkaTopic() string {
+func (s *flagKafka) GetKafkaTopic() *string {
 	return s.kafkaTopic
 }
 
 // kafkaGroup
-func (s *flagKafka) setKafkaGroup(kafkaGroup string) {
+func (s *flagKafka) setKafkaGroup(kafkaGroup *string) {
 	s.kafkaGroup = kafkaGroup
 }
 
-func (s *flagKafka) GetKafkaGroup() string {
+func (s *flagKafka) GetKafkaGroup() *string {
 	return s.kafkaGroup
 }
+
+// kafkaMembershipTopic
+func (s *flagKafka) setKafkaMembershipTopic(kafkaMembershipTopic *string) {
+	s.kafkaMembershipTopic = kafkaMembershipTopic
+}
+
+func (s *flagKafka) GetKafkaMembershipTopic() *string {
+	return s.kafkaMembershipTopic
+}
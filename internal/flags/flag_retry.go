@@ -0,0 +1,65 @@
+package flags
+
+var flagRetryInstance *flagRetry
+
+type flagRetry struct {
+	retryMaxAttempts   *int
+	retryBaseBackoffMs *int
+	retryMaxBackoffMs  *int
+	retryBudgetPercent *float64
+	retryMinPerSecond  *int
+}
+
+// singleton instance
+func init() {
+	flagRetryInstance = new(flagRetry)
+}
+
+func getFlagRetryInstance() *flagRetry {
+	return flagRetryInstance
+}
+
+// retry_max_attempts
+func (s *flagRetry) setRetryMaxAttempts(retryMaxAttempts *int) {
+	s.retryMaxAttempts = retryMaxAttempts
+}
+
+func (s *flagRetry) GetRetryMaxAttempts() *int {
+	return s.retryMaxAttempts
+}
+
+// retry_base_backoff_ms
+func (s *flagRetry) setRetryBaseBackoffMs(retryBaseBackoffMs *int) {
+	s.retryBaseBackoffMs = retryBaseBackoffMs
+}
+
+func (s *flagRetry) GetRetryBaseBackoffMs() *int {
+	return s.retryBaseBackoffMs
+}
+
+// retry_max_backoff_ms
+func (s *flagRetry) setRetryMaxBackoffMs(retryMaxBackoffMs *int) {
+	s.retryMaxBackoffMs = retryMaxBackoffMs
+}
+
+func (s *flagRetry) GetRetryMaxBackoffMs() *int {
+	return s.retryMaxBackoffMs
+}
+
+// retry_budget_percent
+func (s *flagRetry) setRetryBudgetPercent(retryBudgetPercent *float64) {
+	s.retryBudgetPercent = retryBudgetPercent
+}
+
+func (s *flagRetry) GetRetryBudgetPercent() *float64 {
+	return s.retryBudgetPercent
+}
+
+// retry_min_per_second
+func (s *flagRetry) setRetryMinPerSecond(retryMinPerSecond *int) {
+	s.retryMinPerSecond = retryMinPerSecond
+}
+
+func (s *flagRetry) GetRetryMinPerSecond() *int {
+	return s.retryMinPerSecond
+}
@@ -1,48 +1,61 @@
 package flags
 
-import "flag"
+import (
+	"flag"
+	"sync"
+)
 
 type FlagFactory struct {
 }
 
-func init() {
-	flag.Parse()
+var flagGroupOnce sync.Map //flag group name -> *sync.Once
+
+//INFO: flags are parsed by the main packages, the parse in init ran before any flag was defined.
+
+// a flag group may be created by many packages, define its flags only once.
+func defineOnce(group string, define func()) {
+	once, _ := flagGroupOnce.LoadOrStore(group, new(sync.Once))
+	once.(*sync.Once).Do(define)
 }
 
 //start file factory
 func (f *FlagFactory) CreateFlagServiceConfig() *FlagServiceStartInfo {
-	serviceStartFile := flag.String("service_start_file", "./conf/service_conf_file.json", "")
-	restServerPort := flag.Uint("rest_server_port", 8888, "")
-	grpcServerPort := flag.Uint("grpc_server_port", 8889, "")
-	maxCpuNum := flag.Int("max_cpu_num", 16, "")
-
 	fs := getFlagServiceStartInfoInstance()
-	fs.setServiceConfigFile(serviceStartFile)
-	fs.setServiceRestPort(restServerPort)
-	fs.setServiceGrpcPort(grpcServerPort)
-	fs.setServiceMaxCpuNum(maxCpuNum)
+	defineOnce("FlagServiceConfig", func() {
+		serviceStartFile := flag.String("service_start_file", "./configs/service_manifest.json", "dataId / group / namespace of all services, loaded at startup")
+		restServerPort := flag.Uint("rest_server_port", 8888, "")
+		grpcServerPort := flag.Uint("grpc_server_port", 8889, "")
+		maxCpuNum := flag.Int("max_cpu_num", 16, "")
+
+		fs.setServiceConfigFile(serviceStartFile)
+		fs.setServiceRestPort(restServerPort)
+		fs.setServiceGrpcPort(grpcServerPort)
+		fs.setServiceMaxCpuNum(maxCpuNum)
+	})
 
 	return fs
 }
 
 //cache factory
 func (f *FlagFactory) CreateFlagCache() *flagCache {
-	bigcaheShards := flag.Int("bigcahe_shards", 1024, "")
-	bigcaheLifeWindowS := flag.Int("bigcahe_lifeWindowS", 300, "")
-	bigcacheCleanWindowS := flag.Int("bigcache_cleanWindowS", 120, "")
-	bigcacheHardMaxCacheSize := flag.Int("bigcache_hardMaxCacheSize", 409600, "MB")
-	bigcacheMaxEntrySize := flag.Int("bigcache_maxEntrySize", 1024, "byte")
-	bigcacheMaxEntriesInWindow := flag.Int("bigcache_maxEntriesInWindow", 2000000, "depends on tps")
-	bigcacheVerbose := flag.Bool("bigcache_verbose", false, "")
-
 	fc := getFlagCacheInstance()
-	fc.setBigcacheShards(bigcaheShards)
-	fc.setBigcacheLifeWindowS(bigcaheLifeWindowS)
-	fc.setBigcacheCleanWindowS(bigcacheCleanWindowS)
-	fc.setBigcacheHardMaxCacheSize(bigcacheHardMaxCacheSize)
-	fc.setBigcacheMaxEntrySize(bigcacheMaxEntrySize)
-	fc.setBigcacheMaxEntriesInWindow(bigcacheMaxEntriesInWindow)
-	fc.setBigcacheVerbose(bigcacheVerbose)
+	defineOnce("FlagCache", func() {
+		bigcaheShards := flag.Int("bigcahe_shards", 1024, "")
+		bigcaheLifeWindowS := flag.Int("bigcahe_lifeWindowS", 300, "")
+		bigcacheCleanWindowS := flag.Int("bigcache_cleanWindowS", 120, "")
+		bigcacheHardMaxCacheSize := flag.Int("bigcache_hardMaxCacheSize", 409600, "MB")
+		bigcacheMaxEntrySize := flag.Int("bigcache_maxEntrySize", 1024, "byte")
+		bigcacheMaxEntriesInWindow := flag.Int("bigcache_maxEntriesInWindow", 2000000, "depends on tps")
+		bigcacheVerbose := flag.Bool("bigcache_verbose", false, "")
+
+		fc.setBigcacheShards(bigcaheShards)
+		fc.setBigcacheLifeWindowS(bigcaheLifeWindowS)
+		fc.setBigcacheCleanWindowS(bigcacheCleanWindowS)
+		fc.setBigcacheHardMaxCacheSize(bigcacheHardMaxCacheSize)
+		fc.setBigcacheMaxEntrySize(bigcacheMaxEntrySize)
+		fc.setBigcacheMaxEntriesInWindow(bigcacheMaxEntriesInWindow)
+		fc.setBigcacheVerbose(bigcacheVerbose)
+	})
 
 	return fc
 }
@@ -50,158 +63,230 @@ func (f *FlagFactory) CreateFlagCache() *flagCache {
 //dubbo factory
 func (f *FlagFactory) CreateFlagDubbo() *flagDubbo {
 	fd := getFlagDubboInstance()
-	dubboServerconf := flag.String("dubbo_serverconf", "conf/dubbogo_server.yml", "")
-	fd.setDubboServiceFile(dubboServerconf)
+	defineOnce("FlagDubbo", func() {
+		dubboServerconf := flag.String("dubbo_serverconf", "conf/dubbogo_server.yml", "")
+		fd.setDubboServiceFile(dubboServerconf)
+	})
 
 	return fd
 }
 
 //dystrix factory
 func (f *FlagFactory) CreateFlagHystrix() *flagsHystrix {
-	hystrixTimeoutMS := flag.Int("hystrix_timeoutMS", 100, "")
-	hystrixRequestVolumeThreshold := flag.Int("hystrix_RequestVolumeThreshold", 50000, "")
-	hystrixSleepWindow := flag.Int("hystrix_SleepWindow", 10000, "")
-	hystrixErrorPercentThreshold := flag.Int("hystrix_ErrorPercentThreshold", 1, "")
-	hystrixLowerRecallNum := flag.Int("hystrix_lowerRecallNum", 100, "")
-	hystrixLowerRankNum := flag.Int("hystrix_lowerRankNum", 100, "")
-	hystrixMaxConcurrentRequests := flag.Int("hystrix_MaxConcurrentRequests", 10000, "")
-
 	fh := getFlagsHystrixInstance()
-	fh.setHystrixErrorPercentThreshold(hystrixErrorPercentThreshold)
-	fh.setHystrixLowerRankNum(hystrixLowerRankNum)
-	fh.setHystrixLowerRecallNum(hystrixLowerRecallNum)
-	fh.setHystrixMaxConcurrentRequests(hystrixMaxConcurrentRequests)
-	fh.setHystrixRequestVolumeThreshold(hystrixRequestVolumeThreshold)
-	fh.setHystrixSleepWindow(hystrixSleepWindow)
-	fh.setHystrixTimeoutMs(hystrixTimeoutMS)
+	defineOnce("FlagHystrix", func() {
+		hystrixTimeoutMS := flag.Int("hystrix_timeoutMS", 100, "")
+		hystrixRequestVolumeThreshold := flag.Int("hystrix_RequestVolumeThreshold", 50000, "")
+		hystrixSleepWindow := flag.Int("hystrix_SleepWindow", 10000, "")
+		hystrixErrorPercentThreshold := flag.Int("hystrix_ErrorPercentThreshold", 1, "")
+		hystrixLowerRecallNum := flag.Int("hystrix_lowerRecallNum", 100, "")
+		hystrixLowerRankNum := flag.Int("hystrix_lowerRankNum", 100, "")
+		hystrixMaxConcurrentRequests := flag.Int("hystrix_MaxConcurrentRequests", 10000, "")
+
+		fh.setHystrixErrorPercentThreshold(hystrixErrorPercentThreshold)
+		fh.setHystrixLowerRankNum(hystrixLowerRankNum)
+		fh.setHystrixLowerRecallNum(hystrixLowerRecallNum)
+		fh.setHystrixMaxConcurrentRequests(hystrixMaxConcurrentRequests)
+		fh.setHystrixRequestVolumeThreshold(hystrixRequestVolumeThreshold)
+		fh.setHystrixSleepWindow(hystrixSleepWindow)
+		fh.setHystrixTimeoutMs(hystrixTimeoutMS)
+	})
 
 	return fh
 }
 
 //logs factory
 func (f *FlagFactory) CreateFlagLog() *flagsLog {
-	logMaxSize := flag.Int("log_max_size", 200000000, "the max size of the log file (in Byte)")
-	logSaveDays := flag.Int("log_save_days", 7, "")
-	logFileName := flag.String("log_file_name", "infer.log", "")
-	logLevel := flag.String("log_level", "error", "the log level, (debug, info, error, fatal)")
-
 	fl := getFlagLogInstance()
-	fl.setLogFileName(logFileName)
-	fl.setLogLevel(logLevel)
-	fl.setLogMaxSize(logMaxSize)
-	fl.setLogSaveDays(logSaveDays)
+	defineOnce("FlagLog", func() {
+		logMaxSize := flag.Int("log_max_size", 200000000, "the max size of the log file (in Byte)")
+		logSaveDays := flag.Int("log_save_days", 7, "")
+		logFileName := flag.String("log_file_name", "infer.log", "")
+		logLevel := flag.String("log_level", "error", "the log level, (debug, info, error, fatal)")
+
+		fl.setLogFileName(logFileName)
+		fl.setLogLevel(logLevel)
+		fl.setLogMaxSize(logMaxSize)
+		fl.setLogSaveDays(logSaveDays)
+	})
 
 	return fl
 }
 
 //nacos factory
 func (f *FlagFactory) CreateFlagNacos() *flagsNacos {
-	nacosIp := flag.String("nacos_ip", "10.10.10.10", "")
-	nacosPort := flag.Int("nacos_port", 8888, "")
-	nacosUsername := flag.String("nacos_username", "nacos", "")
-	nacosPassword := flag.String("nacos_password", "nacos", "")
-	nacosLogdir := flag.String("nacos_logdir", "nacos-logs", "")
-	nacosCachedir := flag.String("nacos_cachedir", "nacos-cache", "")
-	nacosLoglevel := flag.String("nacos_loglevel", "error", "")
-	nacosTimeoutMS := flag.Int("nacos_timeoutMS", 5000, "")
-
 	fn := getFlagsNacosInstance()
-	fn.setNacosIp(nacosIp)
-	fn.setNacosPort(nacosPort)
-	fn.setNacosUsername(nacosUsername)
-	fn.setNacosPassword(nacosPassword)
-	fn.setNacosLogdir(nacosLogdir)
-	fn.setNacosLoglevel(nacosLoglevel)
-	fn.setNacosCachedir(nacosCachedir)
-	fn.setNacosTimeoutMs(nacosTimeoutMS)
+	defineOnce("FlagNacos", func() {
+		nacosIp := flag.String("nacos_ip", "10.10.10.10", "")
+		nacosPort := flag.Int("nacos_port", 8888, "")
+		nacosUsername := flag.String("nacos_username", "nacos", "")
+		nacosPassword := flag.String("nacos_password", "nacos", "")
+		nacosLogdir := flag.String("nacos_logdir", "nacos-logs", "")
+		nacosCachedir := flag.String("nacos_cachedir", "nacos-cache", "")
+		nacosLoglevel := flag.String("nacos_loglevel", "error", "")
+		nacosTimeoutMS := flag.Int("nacos_timeoutMS", 5000, "")
+
+		fn.setNacosIp(nacosIp)
+		fn.setNacosPort(nacosPort)
+		fn.setNacosUsername(nacosUsername)
+		fn.setNacosPassword(nacosPassword)
+		fn.setNacosLogdir(nacosLogdir)
+		fn.setNacosLoglevel(nacosLoglevel)
+		fn.setNacosCachedir(nacosCachedir)
+		fn.setNacosTimeoutMs(nacosTimeoutMS)
+	})
 
 	return fn
 }
 
 //redis factory
 func (f *FlagFactory) CreateFlagRedis() *FlagRedis {
-	redisPassword := flag.String("redis_password", "", "")
-
 	fd := getFlagRedisInstance()
-	fd.setRedisPassword(redisPassword)
+	defineOnce("FlagRedis", func() {
+		redisPassword := flag.String("redis_password", "", "")
+
+		fd.setRedisPassword(redisPassword)
+	})
 
 	return fd
 }
 
 //skywalking factory
 func (f *FlagFactory) CreateFlagSkywalking() *flagsSkywalking {
-	skywalkingWhetheropen := flag.Bool("skywalking_whetheropen", false, "")
-	skywalkingServername := flag.String("skywalking_servername", "infer", "")
-	skywalkingIp := flag.String("skywalking_ip", "10.10.10.10", "")
-	skywalkingPort := flag.Int("skywalking_port", 8080, "")
-
 	fs := getFlagsSkywalkingInstance()
-	fs.setSkywalkingWhetheropen(skywalkingWhetheropen)
-	fs.setSkywalkingIp(skywalkingIp)
-	fs.setSkywalkingPort(skywalkingPort)
-	fs.setSkywalkingServername(skywalkingServername)
+	defineOnce("FlagSkywalking", func() {
+		skywalkingWhetheropen := flag.Bool("skywalking_whetheropen", false, "")
+		skywalkingServername := flag.String("skywalking_servername", "infer", "")
+		skywalkingIp := flag.String("skywalking_ip", "10.10.10.10", "")
+		skywalkingPort := flag.Int("skywalking_port", 8080, "")
+
+		fs.setSkywalkingWhetheropen(skywalkingWhetheropen)
+		fs.setSkywalkingIp(skywalkingIp)
+		fs.setSkywalkingPort(skywalkingPort)
+		fs.setSkywalkingServername(skywalkingServername)
+	})
 
 	return fs
 }
 
 //tensorflow factory
 func (f *FlagFactory) CreateFlagTensorflow() *flagTensorflow {
-	tfservingModeVersion := flag.Int64("tfserving_model_version", 0, "")
-	tfservingTimeoutms := flag.Int64("tfserving_timeoutms", 100, "")
-
 	ft := getFlagTensorflowInstance()
-	ft.setTfservingModelVersion(tfservingModeVersion)
-	ft.setTfservingTimeoutMs(tfservingTimeoutms)
+	defineOnce("FlagTensorflow", func() {
+		tfservingModeVersion := flag.Int64("tfserving_model_version", 0, "")
+		tfservingTimeoutms := flag.Int64("tfserving_timeoutms", 100, "")
+
+		ft.setTfservingModelVersion(tfservingModeVersion)
+		ft.setTfservingTimeoutMs(tfservingTimeoutms)
+	})
 
 	return ft
 }
 
 //bloom factory
 func (f *FlagFactory) CreateFlagBloom() *FlagBloom {
-	userCountLevel := flag.Uint("user_count_level", 100000000, "")
-	itemCountLevel := flag.Uint("item_count_level", 10000000, "")
-
 	ft := getFlagBloomInstance()
-	ft.setUserCountLevel(userCountLevel)
-	ft.setItemCountLevel(itemCountLevel)
+	defineOnce("FlagBloom", func() {
+		userCountLevel := flag.Uint("user_count_level", 100000000, "")
+		itemCountLevel := flag.Uint("item_count_level", 10000000, "")
+		membershipReloadDir := flag.String("membership_reload_dir", "./membership", "the dir of the membership reload files, a reload path is relative to it")
+
+		ft.setUserCountLevel(userCountLevel)
+		ft.setItemCountLevel(itemCountLevel)
+		ft.setMembershipReloadDir(membershipReloadDir)
+	})
 
 	return ft
 }
 
 //viper factory
 func (f *FlagFactory) CreateFlagViper() *FlagViper {
-	configName := flag.String("config_name", "bloom_filter", "")
-	configType := flag.String("config_type", "json", "")
-	configPath := flag.String("configName", "./conf/", "")
-
 	ft := getFlagViperInstance()
-	ft.setConfigName(configName)
-	ft.setConfigType(configType)
-	ft.setConfigPath(configPath)
+	defineOnce("FlagViper", func() {
+		configName := flag.String("config_name", "bloom_filter", "")
+		configType := flag.String("config_type", "json", "")
+		configPath := flag.String("configName", "./conf/", "")
+
+		ft.setConfigName(configName)
+		ft.setConfigType(configType)
+		ft.setConfigPath(configPath)
+	})
 
 	return ft
 }
 
 //jwt factory
 func (f *FlagFactory) CreateFlagJwt() *FlagJwt {
-	jwtKey := flag.String("jwt_key", "im your dad", "")
-
 	ft := getFlagJwtInstance()
-	ft.setJwtKey(jwtKey)
+	defineOnce("FlagJwt", func() {
+		jwtKey := flag.String("jwt_key", "im your dad", "")
+
+		ft.setJwtKey(jwtKey)
+	})
 
 	return ft
 }
 
 //kafka factory
 func (f *FlagFactory) CreateFlagKafka() *flagKafka {
-	kafkaUrl := flag.String("kafka_url", "l27.0.0.1:9092", "")
-	kafkaTopic := flag.String("kafka_topic", "kafka_topic_001", "")
-	kafkaGroup := flag.String("kafka_group", "kafka_group_001", "")
-
 	ft := getFlagKafkaInstance()
-	ft.setKafkaUrl(*kafkaUrl)
-	ft.setKafkaTopic(*kafkaTopic)
-	ft.setKafkaGroup(*kafkaGroup)
+	defineOnce("FlagKafka", func() {
+		kafkaUrl := flag.String("kafka_url", "l27.0.0.1:9092", "")
+		kafkaTopic := flag.String("kafka_topic", "kafka_topic_001", "")
+		kafkaGroup := flag.String("kafka_group", "kafka_group_001", "")
+		kafkaMembershipTopic := flag.String("kafka_membership_topic", "kafka_membership_topic_001", "user / item filter membership events")
+
+		ft.setKafkaUrl(kafkaUrl)
+		ft.setKafkaTopic(kafkaTopic)
+		ft.setKafkaGroup(kafkaGroup)
+		ft.setKafkaMembershipTopic(kafkaMembershipTopic)
+	})
 
 	return ft
 }
+
+//config source factory
+func (f *FlagFactory) CreateFlagConfigSource() *flagConfigSource {
+	fc := getFlagConfigSourceInstance()
+	defineOnce("FlagConfigSource", func() {
+		configSource := flag.String("config_source", "nacos", "nacos or local")
+		configLocalDir := flag.String("config_local_dir", "./configs/nacos", "json / yaml service config dir, only for local config source")
+
+		fc.setConfigSource(configSource)
+		fc.setConfigLocalDir(configLocalDir)
+	})
+
+	return fc
+}
+
+//grpc client factory
+func (f *FlagFactory) CreateFlagGrpc() *flagGrpc {
+	fg := getFlagGrpcInstance()
+	defineOnce("FlagGrpc", func() {
+		hedgeBudgetPercent := flag.Float64("grpc_hedge_budget_percent", 5, "hedged requests of all grpc pools are at most the percent of the requests")
+
+		fg.setHedgeBudgetPercent(hedgeBudgetPercent)
+	})
+
+	return fg
+}
+
+//retry factory
+func (f *FlagFactory) CreateFlagRetry() *flagRetry {
+	fr := getFlagRetryInstance()
+	defineOnce("FlagRetry", func() {
+		retryMaxAttempts := flag.Int("retry_max_attempts", 3, "attempts of a redis / tfserving / faiss call, 1 means no retry")
+		retryBaseBackoffMs := flag.Int("retry_base_backoff_ms", 5, "")
+		retryMaxBackoffMs := flag.Int("retry_max_backoff_ms", 50, "")
+		retryBudgetPercent := flag.Float64("retry_budget_percent", 10, "retries of a downstream are at most the percent of its calls")
+		retryMinPerSecond := flag.Int("retry_min_per_second", 10, "retries allowed per second of a downstream regardless of the budget percent")
+
+		fr.setRetryMaxAttempts(retryMaxAttempts)
+		fr.setRetryBaseBackoffMs(retryBaseBackoffMs)
+		fr.setRetryMaxBackoffMs(retryMaxBackoffMs)
+		fr.setRetryBudgetPercent(retryBudgetPercent)
+		fr.setRetryMinPerSecond(retryMinPerSecond)
+	})
+
+	return fr
+}
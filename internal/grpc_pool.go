@@ -3,8 +3,12 @@ package internal
 import (
 	"context"
 	"errors"
+	"math"
 	"math/rand"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/grpc"
@@ -18,13 +22,34 @@ type GRPCInterface interface {
 	CreateGrpcConn(data map[string]interface{})
 }
 
-// GRPCPool pool info
+// GRPCPool pool info, the idle conns are kept per target, the balancer picks the target of every Get.
 type GRPCPool struct {
 	Mu          sync.Mutex
 	IdleTimeout time.Duration
-	conns       chan *grpcIdleConn
-	factory     func() (*grpc.ClientConn, error)
-	close       func(*grpc.ClientConn) error
+	conns       map[string]*targetConns //addr -> conns of the target, nil when the pool is closed.
+	factory     func(addr string) (*grpc.ClientConn, error)
+	inUse       int64         //conns taken by Get and not put back yet.
+	slots       chan struct{} //one slot for a conn in use, at most MaxCap.
+	waiting     int64         //Get waiting for a slot.
+	created     int64
+	closed      int64
+	options     *Options
+	done        chan struct{} //closed by Close, stop watching the targets.
+	health      *poolHealth
+	balancer    balancer
+	latencies   *latencyWindow //latencies of the successful calls, for the hedge delay.
+}
+
+// targetConns the idle conns and the load of a target.
+type targetConns struct {
+	idle      chan *grpcIdleConn
+	inFlight  int64
+	latencyMs uint64 //float64 bits of the latency ewma.
+}
+
+type targetStats struct {
+	inFlight  int64
+	latencyMs float64
 }
 
 type grpcIdleConn struct {
@@ -38,32 +63,75 @@ func init() {
 
 // Get get from pool
 func (c *GRPCPool) Get() (*grpc.ClientConn, error) {
-	c.Mu.Lock()
-	conns := c.conns
-	c.Mu.Unlock()
+	return c.GetByKey("")
+}
 
-	if conns == nil {
+// GetByKey get a conn of the target picked by the key, such as userId for the consistent hash balancer.
+// at most MaxCap conns are in use, Get waits WaitTimeout for a conn put back, then fails.
+func (c *GRPCPool) GetByKey(key string) (*grpc.ClientConn, error) {
+	return c.getExcept(key, "")
+}
+
+// a conn of another target than exclude, such as the hedged request.
+func (c *GRPCPool) getExcept(key string, exclude string) (*grpc.ClientConn, error) {
+	err := c.acquire()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := c.get(key, exclude)
+	if err != nil {
+		c.release()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (c *GRPCPool) get(key string, exclude string) (*grpc.ClientConn, error) {
+	addr := c.balancer.pick(c, key, exclude)
+	if addr == "" {
+		return nil, errTargets
+	}
+
+	c.Mu.Lock()
+	if c.conns == nil {
+		c.Mu.Unlock()
 		return nil, errClosed
 	}
+	target := c.targetConns(addr)
+	c.Mu.Unlock()
+
 	for {
 		select {
-		case wrapConn := <-conns:
-			if wrapConn == nil {
+		case wrapConn, ok := <-target.idle:
+			if !ok {
 				return nil, errClosed
 			}
 			if timeout := c.IdleTimeout; timeout > 0 {
 				if wrapConn.t.Add(timeout).Before(time.Now()) {
-					c.close(wrapConn.conn)
+					c.closeConn(wrapConn.conn)
 					continue
 				}
 			}
+			atomic.AddInt64(&c.inUse, 1)
+			atomic.AddInt64(&target.inFlight, 1)
 			return wrapConn.conn, nil
 		default:
-			conn, err := c.factory()
+			c.Mu.Lock()
+			factory := c.factory
+			c.Mu.Unlock()
+			if factory == nil {
+				return nil, errClosed
+			}
+
+			conn, err := factory(addr)
 			if err != nil {
 				return nil, err
 			}
+			atomic.AddInt64(&c.created, 1)
 
+			atomic.AddInt64(&c.inUse, 1)
+			atomic.AddInt64(&target.inFlight, 1)
 			return conn, nil
 		}
 	}
@@ -74,20 +142,63 @@ func (c *GRPCPool) Put(conn *grpc.ClientConn) error {
 	if conn == nil {
 		return errRejected
 	}
+	atomic.AddInt64(&c.inUse, -1)
+	defer c.release()
 
 	c.Mu.Lock()
 	defer c.Mu.Unlock()
 
-	if c.conns == nil {
-		return c.close(conn)
+	//pool closed, or the target deregistered while the conn was in use.
+	target, ok := c.conns[conn.Target()]
+	if !ok {
+		return c.closeConn(conn)
+	}
+	atomic.AddInt64(&target.inFlight, -1)
+
+	select {
+	case target.idle <- &grpcIdleConn{conn: conn, t: time.Now()}:
+		return nil
+	default:
+		return c.closeConn(conn)
 	}
+}
 
+// wait for a free slot, at most WaitTimeout.
+func (c *GRPCPool) acquire() error {
 	select {
-	case c.conns <- &grpcIdleConn{conn: conn, t: time.Now()}:
+	case c.slots <- struct{}{}:
 		return nil
 	default:
-		return c.close(conn)
 	}
+	if c.options.WaitTimeout <= 0 {
+		return errExhausted
+	}
+
+	atomic.AddInt64(&c.waiting, 1)
+	defer atomic.AddInt64(&c.waiting, -1)
+	timer := time.NewTimer(c.options.WaitTimeout)
+	defer timer.Stop()
+
+	select {
+	case c.slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return errExhausted
+	case <-c.done:
+		return errClosed
+	}
+}
+
+func (c *GRPCPool) release() {
+	select {
+	case <-c.slots:
+	default:
+	}
+}
+
+func (c *GRPCPool) closeConn(conn *grpc.ClientConn) error {
+	atomic.AddInt64(&c.closed, 1)
+	return conn.Close()
 }
 
 // Close close pool
@@ -96,26 +207,122 @@ func (c *GRPCPool) Close() {
 	conns := c.conns
 	c.conns = nil
 	c.factory = nil
-	closeFun := c.close
-	c.close = nil
 	c.Mu.Unlock()
 
 	if conns == nil {
 		return
 	}
 
-	close(conns)
-	for wrapConn := range conns {
-		closeFun(wrapConn.conn)
+	close(c.done)
+	for _, target := range conns {
+		close(target.idle)
+		for wrapConn := range target.idle {
+			c.closeConn(wrapConn.conn)
+		}
 	}
 }
 
 // IdleCount idle connection count
 func (c *GRPCPool) IdleCount() int {
 	c.Mu.Lock()
-	conns := c.conns
+	defer c.Mu.Unlock()
+
+	count := 0
+	for _, target := range c.conns {
+		count += len(target.idle)
+	}
+	return count
+}
+
+// InUseCount connections in use, taken by Get and not put back yet
+func (c *GRPCPool) InUseCount() int {
+	return int(atomic.LoadInt64(&c.inUse))
+}
+
+// PoolStats the counts of a pool for monitoring.
+type PoolStats struct {
+	Name    string            `json:"name"` //discovery or addrs of the pool.
+	MaxCap  int               `json:"maxCap"`
+	Active  int               `json:"active"` //conns in use.
+	Idle    int               `json:"idle"`
+	Waiting int               `json:"waiting"` //Get waiting for a conn.
+	Created int64             `json:"created"` //conns dialed since the pool was created.
+	Closed  int64             `json:"closed"`
+	Targets []TargetPoolStats `json:"targets"`
+}
+
+// TargetPoolStats the conns of a target.
+type TargetPoolStats struct {
+	Addr      string  `json:"addr"`
+	InFlight  int64   `json:"inFlight"`
+	Idle      int     `json:"idle"`
+	LatencyMs float64 `json:"latencyMs"` //moving average.
+}
+
+// Stats the current counts of the pool.
+func (c *GRPCPool) Stats() PoolStats {
+	stats := PoolStats{
+		Name:    c.options.name(),
+		MaxCap:  c.options.MaxCap,
+		Active:  c.InUseCount(),
+		Waiting: int(atomic.LoadInt64(&c.waiting)),
+		Created: atomic.LoadInt64(&c.created),
+		Closed:  atomic.LoadInt64(&c.closed),
+		Targets: make([]TargetPoolStats, 0),
+	}
+
+	c.Mu.Lock()
+	for addr, target := range c.conns {
+		stats.Idle += len(target.idle)
+		stats.Targets = append(stats.Targets, TargetPoolStats{
+			Addr:      addr,
+			InFlight:  atomic.LoadInt64(&target.inFlight),
+			Idle:      len(target.idle),
+			LatencyMs: math.Float64frombits(atomic.LoadUint64(&target.latencyMs)),
+		})
+	}
+	c.Mu.Unlock()
+	sort.Slice(stats.Targets, func(i, j int) bool { return stats.Targets[i].Addr < stats.Targets[j].Addr })
+
+	return stats
+}
+
+// the conns of addr, created on the first use. c.Mu is held.
+func (c *GRPCPool) targetConns(addr string) *targetConns {
+	target, ok := c.conns[addr]
+	if !ok {
+		target = &targetConns{idle: make(chan *grpcIdleConn, c.options.MaxCap)}
+		c.conns[addr] = target
+	}
+
+	return target
+}
+
+// the targets with weight which are not ejected.
+func (c *GRPCPool) candidates(exclude string) []Target {
+	targets := c.options.Targets()
+	candidates := make([]Target, 0, len(targets))
+	for _, target := range targets {
+		if target.Weight > 0 && target.Addr != exclude && c.health.available(target.Addr) {
+			candidates = append(candidates, target)
+		}
+	}
+
+	return candidates
+}
+
+func (c *GRPCPool) stats(addr string) targetStats {
+	c.Mu.Lock()
+	target, ok := c.conns[addr]
 	c.Mu.Unlock()
-	return len(conns)
+	if !ok {
+		return targetStats{}
+	}
+
+	return targetStats{
+		inFlight:  atomic.LoadInt64(&target.inFlight),
+		latencyMs: math.Float64frombits(atomic.LoadUint64(&target.latencyMs)),
+	}
 }
 
 // NewGRPCPool init grpc pool
@@ -124,51 +331,144 @@ func NewGRPCPool(o *Options, dialOptions ...grpc.DialOption) (*GRPCPool, error)
 		return nil, err
 	}
 
+	//calls without a shorter deadline are bounded by the write and read timeouts.
+	dialOptions = append(dialOptions, grpc.WithChainUnaryInterceptor(o.deadlineInterceptor))
+
 	//init pool
 	pool := &GRPCPool{
-		conns: make(chan *grpcIdleConn, o.MaxCap),
-		factory: func() (*grpc.ClientConn, error) {
-			target := o.nextTarget()
-			if target == "" {
-				return nil, errTargets
-			}
-
+		conns: make(map[string]*targetConns, 0),
+		slots: make(chan struct{}, o.MaxCap),
+		factory: func(addr string) (*grpc.ClientConn, error) {
 			ctx, cancel := context.WithTimeout(context.Background(), o.DialTimeout)
 			defer cancel()
 
-			return grpc.DialContext(ctx, target, dialOptions...)
+			return grpc.DialContext(ctx, addr, dialOptions...)
 		},
-		close:       func(v *grpc.ClientConn) error { return v.Close() },
 		IdleTimeout: o.IdleTimeout,
+		options:     o,
+		done:        make(chan struct{}),
+		balancer:    newBalancer(o.Balancer),
+		latencies:   newLatencyWindow(),
+	}
+	if !o.Health.Disabled {
+		pool.health = newPoolHealth(o.Health, dialOptions)
 	}
 
 	//danamic update targets
-	o.update()
+	if len(o.Targets()) == 0 {
+		o.setTargets(staticTargets(o.InitTargets))
+	}
+	if pool.health != nil {
+		pool.health.setTargets(o.Targets())
+		go pool.health.run(pool.done)
+	}
+	go pool.watchTargets()
 
-	//init make conns
+	//init make conns, spread by the balancer.
 	for i := 0; i < o.InitCap; i++ {
-		conn, err := pool.factory()
+		addr := pool.balancer.pick(pool, "", "")
+		if addr == "" {
+			pool.Close()
+			return nil, errTargets
+		}
+		conn, err := pool.factory(addr)
 		if err != nil {
 			pool.Close()
 			return nil, err
 		}
-		pool.conns <- &grpcIdleConn{conn: conn, t: time.Now()}
+		atomic.AddInt64(&pool.created, 1)
+		pool.Mu.Lock()
+		select {
+		case pool.targetConns(addr).idle <- &grpcIdleConn{conn: conn, t: time.Now()}:
+		default:
+			pool.closeConn(conn)
+		}
+		pool.Mu.Unlock()
 	}
 
 	return pool, nil
 }
 
-func CreateGrpcConn(data map[string]interface{}) (*GRPCPool, error) {
-	if _, ok := data["addrs"]; !ok {
-		return nil, errors.New("addrs no found in grpc config")
+// apply the targets pushed by Options.Input(), until the pool is closed.
+func (c *GRPCPool) watchTargets() {
+	input := c.options.getInput()
+	for {
+		select {
+		case targets := <-input:
+			c.options.setTargets(targets)
+			if c.health != nil {
+				c.health.setTargets(targets)
+			}
+			c.evictIdle()
+		case <-c.done:
+			return
+		}
 	}
+}
 
-	if _, ok := data["pool_size"]; !ok {
-		data["pool_size"] = float64(100)
+// Report the result of a request sent by the conn, consecutive errors or high latency eject its target.
+func (c *GRPCPool) Report(conn *grpc.ClientConn, err error, latency time.Duration) {
+	if conn == nil {
+		return
 	}
 
-	if _, ok := data["timeout"]; !ok {
-		data["timeout"] = float64(1000)
+	c.Mu.Lock()
+	target, ok := c.conns[conn.Target()]
+	c.Mu.Unlock()
+	if ok {
+		latencyMs := float64(latency) / float64(time.Millisecond)
+		if last := math.Float64frombits(atomic.LoadUint64(&target.latencyMs)); last > 0 {
+			latencyMs = latencyEwmaAlpha*latencyMs + (1-latencyEwmaAlpha)*last
+		}
+		atomic.StoreUint64(&target.latencyMs, math.Float64bits(latencyMs))
+	}
+
+	if c.health == nil {
+		return
+	}
+	c.health.report(conn.Target(), err, latency)
+	if !c.health.available(conn.Target()) {
+		c.evictIdle()
+	}
+}
+
+// TargetStatus the health of every target, nil if the health check is disabled.
+func (c *GRPCPool) TargetStatus() []TargetStatus {
+	if c.health == nil {
+		return nil
+	}
+
+	return c.health.statuses()
+}
+
+// close the idle conns of the deregistered and ejected targets.
+func (c *GRPCPool) evictIdle() {
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+
+	for addr, target := range c.conns {
+		registered := c.options.hasTarget(addr)
+		if registered && c.health.available(addr) {
+			continue
+		}
+		if !registered {
+			//the conns in use are closed by Put.
+			delete(c.conns, addr)
+		}
+		for idx := len(target.idle); idx > 0; idx-- {
+			select {
+			case wrapConn := <-target.idle:
+				c.closeConn(wrapConn.conn)
+			default:
+			}
+		}
+	}
+}
+
+func CreateGrpcConn(data map[string]interface{}) (*GRPCPool, error) {
+	err := setGrpcConnDefaults(data)
+	if err != nil {
+		return nil, err
 	}
 
 	addrs_raw := data["addrs"].([]interface{})
@@ -192,29 +492,62 @@ func CreateGrpcConn(data map[string]interface{}) (*GRPCPool, error) {
 		IdleTimeout:  idleTimeout,
 		ReadTimeout:  readTimeout,
 		WriteTimeout: writeTimeout,
+		WaitTimeout:  DefaultWaitTimeout,
 	}
 
 	grpc_pool, err := NewGRPCPool(options, grpc.WithInsecure())
 	return grpc_pool, err
 }
 
+func setGrpcConnDefaults(data map[string]interface{}) error {
+	if _, ok := data["addrs"]; !ok {
+		return errors.New("addrs no found in grpc config")
+	}
+
+	if _, ok := data["pool_size"]; !ok {
+		data["pool_size"] = float64(100)
+	}
+
+	if _, ok := data["timeout"]; !ok {
+		data["timeout"] = float64(1000)
+	}
+
+	return nil
+}
+
 var (
 	errClosed   = errors.New("pool is closed")
 	errInvalid  = errors.New("invalid config")
 	errRejected = errors.New("connection is nil. rejecting")
 	errTargets  = errors.New("targets server is empty")
+	//ErrPoolExhausted all MaxCap conns are in use for WaitTimeout.
+	ErrPoolExhausted = errors.New("pool is exhausted")
+	errExhausted     = ErrPoolExhausted
 )
 
+// DefaultWaitTimeout wait for a conn when the pool is exhausted.
+const DefaultWaitTimeout = 50 * time.Millisecond
+
+// Target a backend of the pool, pushed by a discovery such as nacos naming.
+type Target struct {
+	Addr     string
+	Weight   float64 //0 means no traffic.
+	Metadata map[string]string
+}
+
 // Options pool options
 type Options struct {
 	lock sync.RWMutex
 	//targets node
-	targets *[]string
+	targets []Target
+	version uint64 //increased on every change of the targets.
 	//targets channel
-	input chan *[]string
+	input chan []Target
 
 	//InitTargets init targets
 	InitTargets []string
+	//Discovery the source of the targets, such as nacos://group/service. its targets are pushed by Input().
+	Discovery string
 	// init connection
 	InitCap int
 	// max connections
@@ -223,30 +556,91 @@ type Options struct {
 	IdleTimeout  time.Duration
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
+	//WaitTimeout wait for a conn when MaxCap conns are in use, 0 fails at once.
+	WaitTimeout time.Duration
+	//Health health check and outlier ejection of the targets.
+	Health HealthOptions
+	//Balancer load balance strategy of the targets.
+	Balancer BalancerOptions
+	//TLS tls / mtls of the conns, plaintext by default.
+	TLS TLSOptions
+	//Hedge hedged requests of Invoke, disabled by default.
+	Hedge HedgeOptions
+}
+
+// Input is the input channel, the whole targets are pushed on every change.
+func (o *Options) Input() chan<- []Target {
+	return o.getInput()
 }
 
-// Input is the input channel
-func (o *Options) Input() chan<- *[]string {
+func (o *Options) getInput() chan []Target {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.input == nil {
+		o.input = make(chan []Target, 1)
+	}
+
 	return o.input
 }
 
-// update targets
-func (o *Options) update() {
-	//init targets
-	o.targets = &o.InitTargets
+// Targets the current targets.
+func (o *Options) Targets() []Target {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
 
-	go func() {
-		for targets := range o.input {
-			if targets == nil {
-				continue
-			}
+	return o.targets
+}
+
+// SetTargets replace the targets, such as the first targets of a discovery before the pool is created.
+func (o *Options) SetTargets(targets []Target) {
+	o.setTargets(targets)
+}
+
+func (o *Options) setTargets(targets []Target) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	o.targets = targets
+	o.version += 1
+}
+
+func (o *Options) versionedTargets() ([]Target, uint64) {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+
+	return o.targets, o.version
+}
 
-			o.lock.Lock()
-			o.targets = targets
-			o.lock.Unlock()
+func (o *Options) hasTarget(addr string) bool {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+
+	for _, target := range o.targets {
+		if target.Addr == addr {
+			return true
 		}
-	}()
+	}
 
+	return false
+}
+
+// name the discovery, or the static addrs.
+func (o *Options) name() string {
+	if o.Discovery != "" {
+		return o.Discovery
+	}
+
+	return strings.Join(o.InitTargets, ",")
+}
+
+func staticTargets(addrs []string) []Target {
+	targets := make([]Target, 0, len(addrs))
+	for _, addr := range addrs {
+		targets = append(targets, Target{Addr: addr, Weight: 1})
+	}
+
+	return targets
 }
 
 // NewOptions returns a new newOptions instance with sane defaults.
@@ -258,33 +652,39 @@ func NewOptions() *Options {
 	o.ReadTimeout = 5 * time.Second
 	o.WriteTimeout = 5 * time.Second
 	o.IdleTimeout = 60 * time.Second
+	o.WaitTimeout = DefaultWaitTimeout
+	o.Health = NewHealthOptions()
+	o.Balancer = BalancerOptions{Strategy: BalancerRandom}
+	o.Hedge = NewHedgeOptions()
 	return o
 }
 
 // validate checks a Config instance.
 func (o *Options) validate() error {
-	if o.InitTargets == nil ||
+	if (len(o.InitTargets) == 0 && o.Discovery == "") ||
 		o.InitCap <= 0 ||
 		o.MaxCap <= 0 ||
 		o.InitCap > o.MaxCap ||
 		o.DialTimeout == 0 ||
 		o.ReadTimeout == 0 ||
-		o.WriteTimeout == 0 {
+		o.WriteTimeout == 0 ||
+		o.WaitTimeout < 0 {
 		return errInvalid
 	}
-	return nil
+	if err := o.TLS.validate(); err != nil {
+		return err
+	}
+	return o.Balancer.validate()
 }
 
-// nextTarget next target implement load balance
-func (o *Options) nextTarget() string {
-	o.lock.RLock()
-	defer o.lock.RUnlock()
-
-	tlen := len(*o.targets)
-	if tlen <= 0 {
-		return ""
+// bound the unary call by WriteTimeout + ReadTimeout, unless the context has a shorter deadline.
+func (o *Options) deadlineInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	timeout := o.WriteTimeout + o.ReadTimeout
+	if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > timeout {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
 
-	//rand server
-	return (*o.targets)[rand.Int()%tlen]
+	return invoker(ctx, method, req, reply, cc, opts...)
 }
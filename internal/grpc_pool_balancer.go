@@ -0,0 +1,241 @@
+package internal
+
+import (
+	"hash/crc32"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// load balance strategies of the pool targets.
+const (
+	BalancerRandom         = "random"          //random by weight, the default.
+	BalancerRoundRobin     = "round_robin"     //in turn, ignore the weights.
+	BalancerLeastInFlight  = "least_inflight"  //the target with the least requests in flight.
+	BalancerP2C            = "p2c"             //power of two choices, the lower of latency ewma * (in flight + 1).
+	BalancerWeighted       = "weighted"        //random by the weight in the discovery metadata WeightKey.
+	BalancerConsistentHash = "consistent_hash" //hash ring on the key of GetByKey, such as userId.
+)
+
+// Balancers all strategies.
+var Balancers = []string{BalancerRandom, BalancerRoundRobin, BalancerLeastInFlight, BalancerP2C, BalancerWeighted, BalancerConsistentHash}
+
+const defaultHashReplicas = 160
+
+// BalancerOptions the load balance strategy of a pool.
+type BalancerOptions struct {
+	Strategy  string
+	WeightKey string //metadata key of the weighted strategy.
+	Replicas  int    //virtual nodes of a target with weight 1 in the hash ring.
+}
+
+func (b BalancerOptions) validate() error {
+	if b.Strategy == "" {
+		return nil
+	}
+	for _, strategy := range Balancers {
+		if b.Strategy == strategy {
+			return nil
+		}
+	}
+
+	return errInvalid
+}
+
+// balancer picks a target of the pool except exclude, "" if no target is available.
+type balancer interface {
+	pick(c *GRPCPool, key string, exclude string) string
+}
+
+func newBalancer(options BalancerOptions) balancer {
+	switch options.Strategy {
+	case BalancerRoundRobin:
+		return &roundRobinBalancer{}
+	case BalancerLeastInFlight:
+		return &leastInFlightBalancer{}
+	case BalancerP2C:
+		return &p2cBalancer{}
+	case BalancerWeighted:
+		return &randomBalancer{weightKey: options.WeightKey}
+	case BalancerConsistentHash:
+		replicas := options.Replicas
+		if replicas <= 0 {
+			replicas = defaultHashReplicas
+		}
+		return &consistentHashBalancer{replicas: replicas}
+	default:
+		return &randomBalancer{}
+	}
+}
+
+type randomBalancer struct {
+	weightKey string
+}
+
+func (b *randomBalancer) pick(c *GRPCPool, key string, exclude string) string {
+	candidates := c.candidates(exclude)
+	totalWeight := float64(0)
+	for _, target := range candidates {
+		totalWeight += b.weight(target)
+	}
+	if totalWeight <= 0 {
+		return ""
+	}
+
+	r := rand.Float64() * totalWeight
+	for _, target := range candidates {
+		r -= b.weight(target)
+		if r < 0 {
+			return target.Addr
+		}
+	}
+
+	return candidates[len(candidates)-1].Addr
+}
+
+// the metadata weight, the discovery weight if it is missing or malformed.
+func (b *randomBalancer) weight(target Target) float64 {
+	if b.weightKey != "" {
+		if weight, err := strconv.ParseFloat(target.Metadata[b.weightKey], 64); err == nil && weight >= 0 {
+			return weight
+		}
+	}
+
+	return target.Weight
+}
+
+type roundRobinBalancer struct {
+	next uint64
+}
+
+func (b *roundRobinBalancer) pick(c *GRPCPool, key string, exclude string) string {
+	candidates := c.candidates(exclude)
+	if len(candidates) == 0 {
+		return ""
+	}
+	idx := atomic.AddUint64(&b.next, 1) % uint64(len(candidates))
+
+	return candidates[idx].Addr
+}
+
+type leastInFlightBalancer struct{}
+
+func (b *leastInFlightBalancer) pick(c *GRPCPool, key string, exclude string) string {
+	candidates := c.candidates(exclude)
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	//start at a random target, the ties are spread.
+	start := rand.Intn(len(candidates))
+	best, bestInFlight := "", int64(-1)
+	for idx := range candidates {
+		target := candidates[(start+idx)%len(candidates)]
+		inFlight := c.stats(target.Addr).inFlight
+		if bestInFlight < 0 || inFlight < bestInFlight {
+			best, bestInFlight = target.Addr, inFlight
+		}
+	}
+
+	return best
+}
+
+type p2cBalancer struct{}
+
+func (b *p2cBalancer) pick(c *GRPCPool, key string, exclude string) string {
+	candidates := c.candidates(exclude)
+	switch len(candidates) {
+	case 0:
+		return ""
+	case 1:
+		return candidates[0].Addr
+	}
+
+	i := rand.Intn(len(candidates))
+	j := rand.Intn(len(candidates) - 1)
+	if j >= i {
+		j += 1
+	}
+	if b.score(c, candidates[j].Addr) < b.score(c, candidates[i].Addr) {
+		return candidates[j].Addr
+	}
+
+	return candidates[i].Addr
+}
+
+// a target without latency yet scores 0, it is tried first.
+func (b *p2cBalancer) score(c *GRPCPool, addr string) float64 {
+	stats := c.stats(addr)
+	return stats.latencyMs * float64(stats.inFlight+1)
+}
+
+type consistentHashBalancer struct {
+	replicas int
+	mu       sync.Mutex
+	version  uint64 //targets version of the ring.
+	ring     []uint32
+	nodes    map[uint32]string
+}
+
+func (b *consistentHashBalancer) pick(c *GRPCPool, key string, exclude string) string {
+	if key == "" {
+		return (&randomBalancer{}).pick(c, key, exclude)
+	}
+
+	ring, nodes := b.build(c.options)
+	if len(ring) == 0 {
+		return ""
+	}
+
+	//clockwise from the key, skip the ejected targets.
+	hash := crc32.ChecksumIEEE([]byte(key))
+	start := sort.Search(len(ring), func(i int) bool { return ring[i] >= hash })
+	for idx := 0; idx < len(ring); idx++ {
+		addr := nodes[ring[(start+idx)%len(ring)]]
+		if addr != exclude && c.health.available(addr) {
+			return addr
+		}
+	}
+
+	if addr := nodes[ring[start%len(ring)]]; addr != exclude {
+		return addr
+	}
+	return ""
+}
+
+// rebuild the ring when the targets change, the replicas of a target are in proportion to its weight.
+func (b *consistentHashBalancer) build(o *Options) ([]uint32, map[uint32]string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	targets, version := o.versionedTargets()
+	if b.nodes != nil && b.version == version {
+		return b.ring, b.nodes
+	}
+
+	ring := make([]uint32, 0, len(targets)*b.replicas)
+	nodes := make(map[uint32]string, len(targets)*b.replicas)
+	for _, target := range targets {
+		if target.Weight <= 0 {
+			continue
+		}
+		replicas := int(float64(b.replicas) * target.Weight)
+		if replicas < 1 {
+			replicas = 1
+		}
+		for i := 0; i < replicas; i++ {
+			hash := crc32.ChecksumIEEE([]byte(target.Addr + "#" + strconv.Itoa(i)))
+			if _, ok := nodes[hash]; ok {
+				continue
+			}
+			nodes[hash] = target.Addr
+			ring = append(ring, hash)
+		}
+	}
+	sort.Slice(ring, func(i, j int) bool { return ring[i] < ring[j] })
+	b.ring, b.nodes, b.version = ring, nodes, version
+
+	return ring, nodes
+}
@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func testBalancerPool(strategy string, addrs ...string) *GRPCPool {
+	o := NewOptions()
+	o.Balancer = BalancerOptions{Strategy: strategy}
+	o.setTargets(staticTargets(addrs))
+	options := NewHealthOptions()
+	options.ConsecutiveErrors = 1
+	health := newPoolHealth(options, nil)
+	health.setTargets(o.Targets())
+
+	return &GRPCPool{options: o, health: health, conns: make(map[string]*targetConns, 0), balancer: newBalancer(o.Balancer)}
+}
+
+func TestRoundRobinBalancer(t *testing.T) {
+	pool := testBalancerPool(BalancerRoundRobin, "127.0.0.1:19021", "127.0.0.1:19022")
+	counts := make(map[string]int, 0)
+	for i := 0; i < 10; i++ {
+		counts[pool.balancer.pick(pool, "", "")] += 1
+	}
+	if counts["127.0.0.1:19021"] != 5 || counts["127.0.0.1:19022"] != 5 {
+		t.Errorf("round robin should pick in turn, got %v", counts)
+	}
+}
+
+func TestLeastInFlightBalancer(t *testing.T) {
+	pool := testBalancerPool(BalancerLeastInFlight, "127.0.0.1:19021", "127.0.0.1:19022")
+	pool.targetConns("127.0.0.1:19021").inFlight = 3
+	pool.targetConns("127.0.0.1:19022").inFlight = 1
+	for i := 0; i < 10; i++ {
+		if addr := pool.balancer.pick(pool, "", ""); addr != "127.0.0.1:19022" {
+			t.Errorf("least in flight target should be picked, got %s", addr)
+		}
+	}
+}
+
+func TestConsistentHashBalancer(t *testing.T) {
+	pool := testBalancerPool(BalancerConsistentHash, "127.0.0.1:19021", "127.0.0.1:19022", "127.0.0.1:19023")
+	picked := make(map[string]string, 0)
+	for i := 0; i < 100; i++ {
+		userId := "user" + time.Duration(i).String()
+		picked[userId] = pool.balancer.pick(pool, userId, "")
+		if again := pool.balancer.pick(pool, userId, ""); again != picked[userId] {
+			t.Errorf("same key should pick the same target, %s != %s", again, picked[userId])
+		}
+	}
+
+	//the keys of the ejected target move, the others stay.
+	pool.health.report("127.0.0.1:19021", errors.New("request failed"), time.Millisecond)
+	for userId, addr := range picked {
+		again := pool.balancer.pick(pool, userId, "")
+		if addr == "127.0.0.1:19021" && again == addr {
+			t.Errorf("ejected target is picked for %s", userId)
+		}
+		if addr != "127.0.0.1:19021" && again != addr {
+			t.Errorf("key %s moved from %s to %s", userId, addr, again)
+		}
+	}
+}
+
+func TestBalancerOptionsValidate(t *testing.T) {
+	if err := (BalancerOptions{Strategy: "unknown"}).validate(); err == nil {
+		t.Errorf("unknown strategy should be invalid")
+	}
+}
@@ -0,0 +1,308 @@
+package internal
+
+import (
+	"context"
+	"infer-microservices/internal/logs"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// HealthOptions health check and outlier ejection of the pool targets.
+type HealthOptions struct {
+	Disabled bool
+	//standard grpc health protocol, the targets which do not implement it are checked by the connectivity state.
+	Interval time.Duration
+	Timeout  time.Duration
+	Service  string //service name of the health check request, empty means the whole server.
+	//outlier ejection
+	ConsecutiveErrors  int           //eject after the consecutive errors.
+	LatencyThreshold   time.Duration //eject when the average latency exceeds it, 0 means no latency ejection.
+	BaseEjection       time.Duration //the n-th ejection lasts n * BaseEjection, at most MaxEjection.
+	MaxEjection        time.Duration
+	MaxEjectionPercent int //never eject more targets than it, at least one target is kept.
+}
+
+// NewHealthOptions returns the default health options.
+func NewHealthOptions() HealthOptions {
+	return HealthOptions{
+		Interval:           5 * time.Second,
+		Timeout:            time.Second,
+		ConsecutiveErrors:  5,
+		BaseEjection:       30 * time.Second,
+		MaxEjection:        5 * time.Minute,
+		MaxEjectionPercent: 50,
+	}
+}
+
+const latencyEwmaAlpha = 0.3
+
+// TargetStatus the health of a pool target.
+type TargetStatus struct {
+	Addr              string    `json:"addr"`
+	Weight            float64   `json:"weight"`
+	State             string    `json:"state"`   //connectivity state of the health check conn.
+	Serving           string    `json:"serving"` //health protocol status, UNIMPLEMENTED if the target does not support it.
+	ConsecutiveErrors int       `json:"consecutiveErrors"`
+	LatencyMs         float64   `json:"latencyMs"` //moving average.
+	Ejected           bool      `json:"ejected"`
+	EjectedUntil      time.Time `json:"ejectedUntil,omitempty"`
+	Ejections         int       `json:"ejections"`
+	LastError         string    `json:"lastError,omitempty"`
+}
+
+type targetHealth struct {
+	status     TargetStatus
+	healthConn *grpc.ClientConn //dedicated conn for the health checks.
+}
+
+// poolHealth tracks the targets of a pool, the ejected ones are skipped by Get.
+type poolHealth struct {
+	mu          sync.Mutex
+	options     HealthOptions
+	targets     map[string]*targetHealth
+	dialOptions []grpc.DialOption
+}
+
+func newPoolHealth(options HealthOptions, dialOptions []grpc.DialOption) *poolHealth {
+	return &poolHealth{
+		options:     options,
+		targets:     make(map[string]*targetHealth, 0),
+		dialOptions: dialOptions,
+	}
+}
+
+// available the target is not ejected, an expired ejection is lifted here.
+func (h *poolHealth) available(addr string) bool {
+	if h == nil {
+		return true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	target, ok := h.targets[addr]
+	if !ok || !target.status.Ejected {
+		return true
+	}
+	if time.Now().After(target.status.EjectedUntil) {
+		target.status.Ejected = false
+		target.status.ConsecutiveErrors = 0
+		target.status.LatencyMs = 0
+		logs.Info(addr, time.Now(), "ejection lifted.")
+		return true
+	}
+
+	return false
+}
+
+// report the result of a request or a health check.
+func (h *poolHealth) report(addr string, err error, latency time.Duration) {
+	if h == nil || h.options.Disabled {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	target, ok := h.targets[addr]
+	if !ok {
+		return //deregistered.
+	}
+	latencyMs := float64(latency) / float64(time.Millisecond)
+	if target.status.LatencyMs == 0 {
+		target.status.LatencyMs = latencyMs
+	} else {
+		target.status.LatencyMs = latencyEwmaAlpha*latencyMs + (1-latencyEwmaAlpha)*target.status.LatencyMs
+	}
+
+	if err != nil {
+		target.status.ConsecutiveErrors += 1
+		target.status.LastError = err.Error()
+	} else {
+		target.status.ConsecutiveErrors = 0
+	}
+
+	if target.status.Ejected {
+		return
+	}
+	tooManyErrors := h.options.ConsecutiveErrors > 0 && target.status.ConsecutiveErrors >= h.options.ConsecutiveErrors
+	tooSlow := h.options.LatencyThreshold > 0 && target.status.LatencyMs > float64(h.options.LatencyThreshold)/float64(time.Millisecond)
+	if (tooManyErrors || tooSlow) && h.canEject() {
+		target.status.Ejections += 1
+		ejection := time.Duration(target.status.Ejections) * h.options.BaseEjection
+		if h.options.MaxEjection > 0 && ejection > h.options.MaxEjection {
+			ejection = h.options.MaxEjection
+		}
+		target.status.Ejected = true
+		target.status.EjectedUntil = time.Now().Add(ejection)
+		logs.Warn(addr, time.Now(), "ejected for", ejection, "consecutive errors:", target.status.ConsecutiveErrors, "latency ms:", target.status.LatencyMs)
+	}
+}
+
+// keep MaxEjectionPercent, and at least one target.
+func (h *poolHealth) canEject() bool {
+	ejected := 0
+	for _, target := range h.targets {
+		if target.status.Ejected {
+			ejected += 1
+		}
+	}
+	total := len(h.targets)
+
+	return ejected+1 < total && (ejected+1)*100 <= total*h.options.MaxEjectionPercent
+}
+
+func (h *poolHealth) target(addr string) *targetHealth {
+	target, ok := h.targets[addr]
+	if !ok {
+		target = &targetHealth{status: TargetStatus{Addr: addr, Serving: "UNKNOWN"}}
+		h.targets[addr] = target
+	}
+
+	return target
+}
+
+// keep the tracked targets the same as the pool targets.
+func (h *poolHealth) setTargets(targets []Target) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	current := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		current[t.Addr] = true
+		h.target(t.Addr).status.Weight = t.Weight
+	}
+	for addr, target := range h.targets {
+		if current[addr] {
+			continue
+		}
+		if target.healthConn != nil {
+			target.healthConn.Close()
+		}
+		delete(h.targets, addr)
+	}
+}
+
+// check all targets every interval, until done.
+func (h *poolHealth) run(done <-chan struct{}) {
+	if h.options.Disabled || h.options.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(h.options.Interval)
+	defer ticker.Stop()
+	defer h.close()
+
+	for {
+		select {
+		case <-ticker.C:
+			h.checkAll()
+		case <-done:
+			return
+		}
+	}
+}
+
+func (h *poolHealth) checkAll() {
+	h.mu.Lock()
+	addrs := make([]string, 0, len(h.targets))
+	for addr := range h.targets {
+		addrs = append(addrs, addr)
+	}
+	h.mu.Unlock()
+
+	for _, addr := range addrs {
+		go h.check(addr)
+	}
+}
+
+func (h *poolHealth) check(addr string) {
+	conn, err := h.healthConn(addr)
+	if err != nil {
+		h.report(addr, err, 0)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.options.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	response, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: h.options.Service})
+	latency := time.Since(start)
+	serving := ""
+	if status.Code(err) == codes.Unimplemented {
+		//no health service, the connectivity state decides.
+		serving = "UNIMPLEMENTED"
+		err = nil
+		if state := conn.GetState(); state == connectivity.TransientFailure || state == connectivity.Shutdown {
+			err = status.Errorf(codes.Unavailable, "connectivity state %s", state)
+		}
+	} else if err == nil {
+		serving = response.GetStatus().String()
+		if response.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			err = status.Errorf(codes.Unavailable, "health status %s", serving)
+		}
+	}
+
+	h.mu.Lock()
+	if target, ok := h.targets[addr]; ok {
+		target.status.State = conn.GetState().String()
+		if serving != "" {
+			target.status.Serving = serving
+		}
+	}
+	h.mu.Unlock()
+
+	h.report(addr, err, latency)
+}
+
+func (h *poolHealth) healthConn(addr string) (*grpc.ClientConn, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	target, ok := h.targets[addr]
+	if !ok {
+		return nil, errTargets
+	}
+	if target.healthConn == nil {
+		conn, err := grpc.Dial(addr, h.dialOptions...)
+		if err != nil {
+			return nil, err
+		}
+		target.healthConn = conn
+	}
+
+	return target.healthConn, nil
+}
+
+func (h *poolHealth) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, target := range h.targets {
+		if target.healthConn != nil {
+			target.healthConn.Close()
+			target.healthConn = nil
+		}
+	}
+}
+
+func (h *poolHealth) statuses() []TargetStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	statuses := make([]TargetStatus, 0, len(h.targets))
+	for _, target := range h.targets {
+		statuses = append(statuses, target.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Addr < statuses[j].Addr })
+
+	return statuses
+}
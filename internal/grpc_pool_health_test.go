@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPoolHealthEjection(t *testing.T) {
+	options := NewHealthOptions()
+	options.ConsecutiveErrors = 2
+	options.BaseEjection = 50 * time.Millisecond
+	h := newPoolHealth(options, nil)
+	h.setTargets(staticTargets([]string{"127.0.0.1:19011", "127.0.0.1:19012"}))
+
+	errRequest := errors.New("request failed")
+	h.report("127.0.0.1:19011", errRequest, time.Millisecond)
+	if !h.available("127.0.0.1:19011") {
+		t.Errorf("one error should not eject")
+	}
+	h.report("127.0.0.1:19011", errRequest, time.Millisecond)
+	if h.available("127.0.0.1:19011") {
+		t.Errorf("consecutive errors should eject")
+	}
+
+	//the last target is never ejected.
+	h.report("127.0.0.1:19012", errRequest, time.Millisecond)
+	h.report("127.0.0.1:19012", errRequest, time.Millisecond)
+	if !h.available("127.0.0.1:19012") {
+		t.Errorf("max ejection percent should keep one target")
+	}
+
+	o := NewOptions()
+	o.setTargets(staticTargets([]string{"127.0.0.1:19011", "127.0.0.1:19012"}))
+	pool := &GRPCPool{options: o, health: h, conns: make(map[string]*targetConns, 0), balancer: newBalancer(o.Balancer)}
+	for i := 0; i < 10; i++ {
+		if target := pool.balancer.pick(pool, "", ""); target != "127.0.0.1:19012" {
+			t.Errorf("ejected target is chosen: %s", target)
+		}
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if !h.available("127.0.0.1:19011") {
+		t.Errorf("ejection should be lifted")
+	}
+	t.Log(h.statuses())
+}
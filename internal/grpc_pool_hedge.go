@@ -0,0 +1,224 @@
+package internal
+
+import (
+	"context"
+	"infer-microservices/internal/flags"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// HedgeOptions hedged requests of a pool, a duplicate is sent to another target when the request is slow.
+type HedgeOptions struct {
+	Enabled    bool
+	Percentile float64       //hedge after the percentile latency of the recent requests, such as 95.
+	MinDelay   time.Duration //bounds of the hedge delay, MaxDelay before there are enough samples.
+	MaxDelay   time.Duration
+}
+
+// NewHedgeOptions returns the default hedge options, disabled.
+func NewHedgeOptions() HedgeOptions {
+	return HedgeOptions{
+		Percentile: 95,
+		MinDelay:   time.Millisecond,
+		MaxDelay:   50 * time.Millisecond,
+	}
+}
+
+const hedgeSamples = 1024   //recent latencies of a pool.
+const hedgeMinSamples = 100 //the percentile is used after it.
+const hedgeBudgetBurst = 100
+
+var hedgeBudgetPercent *float64
+
+// all pools share the budget, so the hedges never exceed the percent of the requests.
+var globalHedgeBudget = &hedgeBudget{}
+
+func init() {
+	flagFactory := flags.FlagFactory{}
+	flagGrpc := flagFactory.CreateFlagGrpc()
+	hedgeBudgetPercent = flagGrpc.GetHedgeBudgetPercent()
+}
+
+// GrpcCall a unary call by a conn of the pool.
+type GrpcCall func(ctx context.Context, conn *grpc.ClientConn) (interface{}, error)
+
+type grpcCallResult struct {
+	reply interface{}
+	err   error
+}
+
+// Invoke call by a conn of the target picked by key, the result is reported to the health check and the balancer.
+// with hedging, the call is sent to another target too if there is no response in the hedge delay, the first success wins.
+func (c *GRPCPool) Invoke(ctx context.Context, key string, call GrpcCall) (interface{}, error) {
+	conn, err := c.GetByKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if !c.options.Hedge.Enabled {
+		result := c.call(ctx, conn, call)
+		return result.reply, result.err
+	}
+
+	globalHedgeBudget.deposit(*hedgeBudgetPercent)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() //the slower call is canceled.
+
+	results := make(chan grpcCallResult, 2)
+	go func() { results <- c.call(ctx, conn, call) }()
+	pending := 1
+
+	timer := time.NewTimer(c.hedgeDelay())
+	defer timer.Stop()
+
+	var lastErr error
+	for pending > 0 {
+		select {
+		case result := <-results:
+			pending -= 1
+			if result.err == nil {
+				return result.reply, nil
+			}
+			lastErr = result.err
+		case <-timer.C:
+			if !globalHedgeBudget.withdraw() {
+				continue
+			}
+			hedgeConn, err := c.getExcept(key, conn.Target())
+			if err != nil {
+				globalHedgeBudget.refund()
+				continue //no other target, or the pool is exhausted.
+			}
+			pending += 1
+			go func() { results <- c.call(ctx, hedgeConn, call) }()
+		}
+	}
+
+	return nil, lastErr
+}
+
+// call and put back the conn.
+func (c *GRPCPool) call(ctx context.Context, conn *grpc.ClientConn, call GrpcCall) grpcCallResult {
+	defer c.Put(conn)
+
+	start := time.Now()
+	reply, err := call(ctx, conn)
+	latency := time.Since(start)
+
+	//the loser of a hedge is canceled, it is not the fault of the target.
+	if status.Code(err) != codes.Canceled {
+		c.Report(conn, err, latency)
+	}
+	if err == nil && c.latencies != nil {
+		c.latencies.add(latency)
+	}
+
+	return grpcCallResult{reply: reply, err: err}
+}
+
+// the percentile latency of the recent calls, bounded by MinDelay and MaxDelay.
+func (c *GRPCPool) hedgeDelay() time.Duration {
+	hedge := c.options.Hedge
+	delay, ok := c.latencies.percentile(hedge.Percentile)
+	if !ok {
+		return hedge.MaxDelay
+	}
+	if delay < hedge.MinDelay {
+		delay = hedge.MinDelay
+	}
+	if hedge.MaxDelay > 0 && delay > hedge.MaxDelay {
+		delay = hedge.MaxDelay
+	}
+
+	return delay
+}
+
+// latencyWindow the recent latencies of the successful calls.
+type latencyWindow struct {
+	mu       sync.Mutex
+	samples  []time.Duration
+	next     int
+	added    int //since the last percentile.
+	cachedP  float64
+	cachedAt time.Duration
+}
+
+func newLatencyWindow() *latencyWindow {
+	return &latencyWindow{samples: make([]time.Duration, 0, hedgeSamples)}
+}
+
+func (w *latencyWindow) add(latency time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.samples) < hedgeSamples {
+		w.samples = append(w.samples, latency)
+	} else {
+		w.samples[w.next] = latency
+		w.next = (w.next + 1) % hedgeSamples
+	}
+	w.added += 1
+}
+
+// percentile is sorted again after every 32 samples.
+func (w *latencyWindow) percentile(p float64) (time.Duration, bool) {
+	if w == nil {
+		return 0, false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.samples) < hedgeMinSamples {
+		return 0, false
+	}
+	if w.cachedAt > 0 && w.cachedP == p && w.added < 32 {
+		return w.cachedAt, true
+	}
+
+	sorted := make([]time.Duration, len(w.samples))
+	copy(sorted, w.samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(float64(len(sorted)-1) * p / 100)
+	w.cachedP, w.cachedAt, w.added = p, sorted[idx], 0
+
+	return w.cachedAt, true
+}
+
+// hedgeBudget token bucket, every request deposits percent / 100 token, a hedge withdraws one.
+type hedgeBudget struct {
+	mu     sync.Mutex
+	tokens float64
+}
+
+func (b *hedgeBudget) deposit(percent float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens += percent / 100
+	if b.tokens > hedgeBudgetBurst {
+		b.tokens = hedgeBudgetBurst
+	}
+}
+
+func (b *hedgeBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens -= 1
+	return true
+}
+
+func (b *hedgeBudget) refund() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens += 1
+}
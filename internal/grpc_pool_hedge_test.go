@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type delayedHealthServer struct {
+	healthpb.UnimplementedHealthServer
+	delay time.Duration
+}
+
+func (s *delayedHealthServer) Check(ctx context.Context, in *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func startHealthServer(t *testing.T, delay time.Duration) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, &delayedHealthServer{delay: delay})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String()
+}
+
+func TestGrpcPoolInvokeHedge(t *testing.T) {
+	slow := startHealthServer(t, time.Second)
+	fast := startHealthServer(t, 0)
+
+	o := NewOptions()
+	o.InitTargets = []string{slow, fast}
+	o.InitCap = 2
+	o.Balancer = BalancerOptions{Strategy: BalancerRoundRobin}
+	o.Hedge = HedgeOptions{Enabled: true, Percentile: 95, MaxDelay: 10 * time.Millisecond}
+	pool, err := NewGRPCPool(o, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	globalHedgeBudget.deposit(400)
+	check := func(ctx context.Context, conn *grpc.ClientConn) (interface{}, error) {
+		return healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	}
+	for i := 0; i < 4; i++ {
+		start := time.Now()
+		_, err := pool.Invoke(context.Background(), "", check)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if latency := time.Since(start); latency > 500*time.Millisecond {
+			t.Errorf("slow request should be hedged, latency %v", latency)
+		}
+	}
+}
+
+func TestHedgeBudget(t *testing.T) {
+	budget := &hedgeBudget{}
+	hedges := 0
+	for i := 0; i < 1000; i++ {
+		budget.deposit(5)
+		if budget.withdraw() {
+			hedges += 1
+		}
+	}
+	if hedges > 50 {
+		t.Errorf("hedges %d exceed 5%% of the requests", hedges)
+	}
+}
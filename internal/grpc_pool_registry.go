@@ -0,0 +1,173 @@
+package internal
+
+import (
+	"encoding/json"
+	"infer-microservices/internal/logs"
+	"sort"
+	"sync"
+	"time"
+)
+
+// the downstreams with the same grpc conf share one pool, across dataIds and config versions.
+type sharedGrpcPool struct {
+	key  string
+	refs int
+	stop func() //stop watching the targets of the discovery.
+}
+
+// TargetWatch watch the targets of a discovery, return the current targets, and push the changes into input until stop.
+type TargetWatch func(input chan<- []Target) (targets []Target, stop func(), err error)
+
+var sharedGrpcPoolsMu sync.Mutex
+var sharedGrpcPools = make(map[string]*GRPCPool, 0)             //grpc conf -> pool
+var sharedGrpcPoolRefs = make(map[*GRPCPool]*sharedGrpcPool, 0) //pool -> refs
+
+// the replaced pool is closed when its conns are all put back, or after the timeout.
+var PoolDrainTimeout = 30 * time.Second
+
+// no conn is in use for the grace before the pool is closed, a request may have got the pool from the replaced
+// service config just before the release, and not taken its conn yet. the grace is at least the longest request
+// deadline, see ObserveRequestDeadline.
+var PoolDrainGrace = time.Second
+
+var maxRequestDeadlineMu sync.Mutex
+var maxRequestDeadline time.Duration
+
+// AcquireGrpcPool get the shared pool of the options, create it if not exists. call ReleaseGrpcPool when it is not used.
+// watch is nil for the static InitTargets, else it is started once for the new pool.
+func AcquireGrpcPool(o *Options, watch TargetWatch) (*GRPCPool, error) {
+	keyBytes, err := json.Marshal(o) //the exported fields only.
+	if err != nil {
+		return nil, err
+	}
+	key := string(keyBytes)
+
+	sharedGrpcPoolsMu.Lock()
+	defer sharedGrpcPoolsMu.Unlock()
+
+	if pool, ok := sharedGrpcPools[key]; ok {
+		sharedGrpcPoolRefs[pool].refs += 1
+		return pool, nil
+	}
+
+	stop := func() {}
+	if watch != nil {
+		targets, stop_, err := watch(o.Input())
+		if err != nil {
+			return nil, err
+		}
+		o.SetTargets(targets)
+		stop = stop_
+	}
+
+	transport, err := o.TLS.dialOption()
+	if err != nil {
+		stop()
+		return nil, err
+	}
+	pool, err := NewGRPCPool(o, transport)
+	if err != nil {
+		stop()
+		return nil, err
+	}
+	sharedGrpcPools[key] = pool
+	sharedGrpcPoolRefs[pool] = &sharedGrpcPool{key: key, refs: 1, stop: stop}
+
+	return pool, nil
+}
+
+// RetainGrpcPool one more user of an acquired pool.
+func RetainGrpcPool(pool *GRPCPool) {
+	sharedGrpcPoolsMu.Lock()
+	defer sharedGrpcPoolsMu.Unlock()
+
+	if shared, ok := sharedGrpcPoolRefs[pool]; ok {
+		shared.refs += 1
+	}
+}
+
+// ReleaseGrpcPool the last release drains and closes the pool.
+func ReleaseGrpcPool(pool *GRPCPool) {
+	if pool == nil {
+		return
+	}
+
+	sharedGrpcPoolsMu.Lock()
+	shared, ok := sharedGrpcPoolRefs[pool]
+	if ok {
+		shared.refs -= 1
+		if shared.refs > 0 {
+			sharedGrpcPoolsMu.Unlock()
+			return
+		}
+		delete(sharedGrpcPoolRefs, pool)
+		delete(sharedGrpcPools, shared.key)
+	}
+	sharedGrpcPoolsMu.Unlock()
+
+	if ok {
+		shared.stop()
+	}
+	go DrainGrpcPool(pool, PoolDrainTimeout)
+}
+
+// ObserveRequestDeadline a configured end-to-end deadline, such as the deadline of a dataId. a request holds the pools
+// of its service config at most for its deadline, a replaced pool is drained for the longest one.
+func ObserveRequestDeadline(deadline time.Duration) {
+	maxRequestDeadlineMu.Lock()
+	defer maxRequestDeadlineMu.Unlock()
+
+	if deadline > maxRequestDeadline {
+		maxRequestDeadline = deadline
+	}
+}
+
+// the grace of a drain, PoolDrainGrace or the longest request deadline.
+func poolDrainGrace() time.Duration {
+	maxRequestDeadlineMu.Lock()
+	defer maxRequestDeadlineMu.Unlock()
+
+	if maxRequestDeadline > PoolDrainGrace {
+		return maxRequestDeadline
+	}
+
+	return PoolDrainGrace
+}
+
+// DrainGrpcPool wait for the in-flight requests and the drain grace after them, then close the pool.
+func DrainGrpcPool(pool *GRPCPool, timeout time.Duration) {
+	grace := poolDrainGrace()
+	deadline := time.Now().Add(timeout)
+	idleSince := time.Now()
+	for time.Now().Before(deadline) {
+		if pool.InUseCount() > 0 {
+			idleSince = time.Now()
+		} else if time.Since(idleSince) >= grace {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if inUse := pool.InUseCount(); inUse > 0 {
+		logs.Warn("grpc pool closed with", inUse, "conns in use.")
+	}
+
+	pool.Close()
+}
+
+// GrpcPoolStats the stats of all shared pools.
+func GrpcPoolStats() []PoolStats {
+	sharedGrpcPoolsMu.Lock()
+	pools := make([]*GRPCPool, 0, len(sharedGrpcPools))
+	for _, pool := range sharedGrpcPools {
+		pools = append(pools, pool)
+	}
+	sharedGrpcPoolsMu.Unlock()
+
+	stats := make([]PoolStats, 0, len(pools))
+	for _, pool := range pools {
+		stats = append(stats, pool.Stats())
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+
+	return stats
+}
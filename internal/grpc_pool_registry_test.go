@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func testGrpcOptions(addr string) *Options {
+	o := NewOptions()
+	o.InitTargets = []string{addr}
+	o.InitCap = 1
+	o.MaxCap = 2
+
+	return o
+}
+
+func TestAcquireGrpcPoolShared(t *testing.T) {
+	pool1, err := AcquireGrpcPool(testGrpcOptions("127.0.0.1:19001"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool2, err := AcquireGrpcPool(testGrpcOptions("127.0.0.1:19001"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool3, err := AcquireGrpcPool(testGrpcOptions("127.0.0.1:19002"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pool1 != pool2 {
+		t.Errorf("same conf should share one pool")
+	}
+	if pool1 == pool3 {
+		t.Errorf("different conf should not share the pool")
+	}
+
+	PoolDrainTimeout = time.Second
+	PoolDrainGrace = 100 * time.Millisecond
+	ReleaseGrpcPool(pool1)
+	if pool1.IdleCount() == 0 {
+		t.Errorf("pool closed while still used")
+	}
+
+	ReleaseGrpcPool(pool2)
+	ReleaseGrpcPool(pool3)
+	time.Sleep(300 * time.Millisecond)
+	if _, err := pool1.Get(); err != errClosed {
+		t.Errorf("released pool should be closed, got %v", err)
+	}
+}
+
+func TestDrainGrpcPoolWaitInUse(t *testing.T) {
+	pool, err := NewGRPCPool(testGrpcOptions("127.0.0.1:19003"), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	PoolDrainGrace = 100 * time.Millisecond
+	done := make(chan struct{})
+	go func() {
+		DrainGrpcPool(pool, 5*time.Second)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Errorf("pool closed with a conn in use")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	pool.Put(conn)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Errorf("pool not closed after the conn was put back")
+	}
+}
+
+func TestDrainGrpcPoolGrace(t *testing.T) {
+	pool, err := NewGRPCPool(testGrpcOptions("127.0.0.1:19006"), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//a conn taken in the grace keeps the pool open.
+	PoolDrainGrace = 500 * time.Millisecond
+	done := make(chan struct{})
+	go func() {
+		DrainGrpcPool(pool, 5*time.Second)
+		close(done)
+	}()
+	time.Sleep(200 * time.Millisecond)
+	conn, err := pool.Get()
+	if err != nil {
+		t.Fatalf("pool closed in the grace: %v", err)
+	}
+
+	select {
+	case <-done:
+		t.Errorf("pool closed with a conn in use")
+	case <-time.After(800 * time.Millisecond):
+	}
+
+	pool.Put(conn)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Errorf("pool not closed after the grace")
+	}
+}
+
+func TestPoolDrainGraceDeadline(t *testing.T) {
+	PoolDrainGrace = 100 * time.Millisecond
+	defer func() { maxRequestDeadline = 0 }()
+
+	ObserveRequestDeadline(50 * time.Millisecond)
+	if grace := poolDrainGrace(); grace != 100*time.Millisecond {
+		t.Errorf("grace %v, expect PoolDrainGrace", grace)
+	}
+
+	//the grace covers the longest deadline, a shorter one does not lower it.
+	ObserveRequestDeadline(3 * time.Second)
+	ObserveRequestDeadline(time.Second)
+	if grace := poolDrainGrace(); grace != 3*time.Second {
+		t.Errorf("grace %v, expect the longest deadline 3s", grace)
+	}
+}
+
+func TestGrpcPoolTargetsInput(t *testing.T) {
+	o := testGrpcOptions("127.0.0.1:19004")
+	pool, err := NewGRPCPool(o, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	o.Input() <- []Target{{Addr: "127.0.0.1:19005", Weight: 1}}
+	time.Sleep(100 * time.Millisecond)
+	if pool.IdleCount() != 0 {
+		t.Errorf("idle conn of the deregistered target should be evicted")
+	}
+
+	conn, err := pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.Target() != "127.0.0.1:19005" {
+		t.Errorf("expected the new target, got %s", conn.Target())
+	}
+	pool.Put(conn)
+}
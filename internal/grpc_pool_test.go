@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func TestGrpcPoolExhausted(t *testing.T) {
+	o := testGrpcOptions("127.0.0.1:19031")
+	o.MaxCap = 1
+	o.WaitTimeout = 10 * time.Millisecond
+	pool, err := NewGRPCPool(o, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	conn, err := pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Get(); err != ErrPoolExhausted {
+		t.Errorf("Get should fail when MaxCap conns are in use, got %v", err)
+	}
+
+	//a conn put back while waiting is taken.
+	go func() {
+		time.Sleep(2 * time.Millisecond)
+		pool.Put(conn)
+	}()
+	o.WaitTimeout = time.Second
+	conn, err = pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.Put(conn)
+
+	stats := pool.Stats()
+	t.Log(stats)
+	if stats.Active != 0 || stats.Idle != 1 || stats.Created != 1 || stats.Closed != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
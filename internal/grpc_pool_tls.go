@@ -0,0 +1,180 @@
+package internal
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"infer-microservices/internal/logs"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// TLSReloadInterval the cert files are checked at most once in it, the rotated ones are loaded by the next handshake.
+var TLSReloadInterval = 10 * time.Second
+
+// TLSVersions the min tls versions of TLSOptions.
+var TLSVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSOptions tls of the conns, the zero value is plaintext.
+type TLSOptions struct {
+	Enabled    bool
+	CAFile     string //pem ca bundle of the servers, empty for the system roots.
+	CertFile   string //pem client cert and key for mtls, both or none.
+	KeyFile    string
+	ServerName string //override the server name of the targets, such as the name in the server cert.
+	MinVersion string //1.0, 1.1, 1.2 or 1.3, default 1.2.
+}
+
+func (t TLSOptions) validate() error {
+	if !t.Enabled {
+		return nil
+	}
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return errors.New("tls certFile and keyFile should be set together")
+	}
+	if _, ok := TLSVersions[t.MinVersion]; t.MinVersion != "" && !ok {
+		return errors.New("unknown tls minVersion " + t.MinVersion)
+	}
+
+	return nil
+}
+
+// dialOption plaintext, or tls with the cert files reloaded on rotation.
+func (t TLSOptions) dialOption() (grpc.DialOption, error) {
+	if !t.Enabled {
+		return grpc.WithInsecure(), nil
+	}
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+
+	files := &tlsFiles{options: t}
+	if err := files.reload(); err != nil {
+		return nil, err
+	}
+	files.checked = time.Now()
+
+	minVersion := uint16(tls.VersionTLS12)
+	if t.MinVersion != "" {
+		minVersion = TLSVersions[t.MinVersion]
+	}
+	config := &tls.Config{
+		MinVersion: minVersion,
+		ServerName: t.ServerName,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := files.current()
+			if cert == nil {
+				return &tls.Certificate{}, nil
+			}
+			return cert, nil
+		},
+	}
+	if t.CAFile != "" {
+		//the server cert is verified by the reloaded ca bundle instead of a fixed RootCAs.
+		config.InsecureSkipVerify = true
+		config.VerifyConnection = files.verify
+	}
+
+	return grpc.WithTransportCredentials(credentials.NewTLS(config)), nil
+}
+
+// tlsFiles the cert files of TLSOptions, reloaded when they are modified.
+type tlsFiles struct {
+	options TLSOptions
+	mu      sync.Mutex
+	checked time.Time
+	modTime time.Time //the latest modification of the files.
+	cert    *tls.Certificate
+	roots   *x509.CertPool
+}
+
+func (f *tlsFiles) current() (*tls.Certificate, *x509.CertPool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if time.Since(f.checked) >= TLSReloadInterval {
+		f.checked = time.Now()
+		//a half written rotation fails, the last certs are kept until the next check.
+		if err := f.reload(); err != nil {
+			logs.Error(f.options.CertFile, time.Now(), err)
+		}
+	}
+
+	return f.cert, f.roots
+}
+
+// reload the files if any of them is modified.
+func (f *tlsFiles) reload() error {
+	modTime := time.Time{}
+	for _, file := range []string{f.options.CAFile, f.options.CertFile, f.options.KeyFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+	if !f.modTime.IsZero() && modTime.Equal(f.modTime) {
+		return nil
+	}
+
+	var cert *tls.Certificate
+	if f.options.CertFile != "" {
+		pair, err := tls.LoadX509KeyPair(f.options.CertFile, f.options.KeyFile)
+		if err != nil {
+			return err
+		}
+		cert = &pair
+	}
+	var roots *x509.CertPool
+	if f.options.CAFile != "" {
+		pem, err := os.ReadFile(f.options.CAFile)
+		if err != nil {
+			return err
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return errors.New("no cert found in tls caFile " + f.options.CAFile)
+		}
+	}
+
+	if !f.modTime.IsZero() {
+		logs.Info(f.options.CAFile, f.options.CertFile, time.Now(), "tls certs reloaded.")
+	}
+	f.cert, f.roots, f.modTime = cert, roots, modTime
+
+	return nil
+}
+
+// verify the server cert chain and name by the current ca bundle.
+func (f *tlsFiles) verify(state tls.ConnectionState) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("tls server cert not found")
+	}
+
+	_, roots := f.current()
+	options := x509.VerifyOptions{
+		Roots:         roots,
+		DNSName:       state.ServerName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range state.PeerCertificates[1:] {
+		options.Intermediates.AddCert(cert)
+	}
+	_, err := state.PeerCertificates[0].Verify(options)
+
+	return err
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// write a self signed cert with the serial number, and its key.
+func writeTestCert(t *testing.T, certFile string, keyFile string, serial int64, modTime time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "faiss-server"},
+		DNSNames:     []string{"faiss-server"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+		KeyUsage:     x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	os.Chtimes(certFile, modTime, modTime)
+	os.Chtimes(keyFile, modTime, modTime)
+}
+
+func TestTLSFilesReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client.key")
+	writeTestCert(t, certFile, keyFile, 1, time.Now().Add(-time.Minute))
+
+	options := TLSOptions{Enabled: true, CAFile: certFile, CertFile: certFile, KeyFile: keyFile}
+	if _, err := options.dialOption(); err != nil {
+		t.Fatal(err)
+	}
+
+	files := &tlsFiles{options: options}
+	if err := files.reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	//rotated.
+	writeTestCert(t, certFile, keyFile, 2, time.Now())
+	TLSReloadInterval = 0
+	defer func() { TLSReloadInterval = 10 * time.Second }()
+	cert, roots := files.current()
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaf.SerialNumber.Int64() != 2 || roots == nil {
+		t.Errorf("rotated cert should be loaded, got serial %v", leaf.SerialNumber)
+	}
+
+	//a broken rotation keeps the last cert.
+	os.WriteFile(keyFile, []byte("broken"), 0600)
+	if cert, _ := files.current(); cert == nil {
+		t.Errorf("last cert should be kept")
+	}
+}
+
+func TestTLSOptionsValidate(t *testing.T) {
+	if err := (TLSOptions{Enabled: true, CertFile: "client.pem"}).validate(); err == nil {
+		t.Errorf("certFile without keyFile should be invalid")
+	}
+	if err := (TLSOptions{Enabled: true, MinVersion: "1.4"}).validate(); err == nil {
+		t.Errorf("unknown min version should be invalid")
+	}
+}
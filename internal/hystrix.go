@@ -1,50 +1,56 @@
 package internal
 
 import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
 	"infer-microservices/internal/flags"
+	"sync"
 
 	"github.com/afex/hystrix-go/hystrix"
 )
 
-var hystrixTimeout int
-var hystrixMaxConcurrentRequests int
-var hystrixRequestVolumeThreshold int
-var hystrixSleepWindow int
-var hystrixErrorPercentThreshold int
+var hystrixTimeout *int
+var hystrixMaxConcurrentRequests *int
+var hystrixRequestVolumeThreshold *int
+var hystrixSleepWindow *int
+var hystrixErrorPercentThreshold *int
+
+var hystrixCommands sync.Map //command name -> true, configured commands.
 
 func init() {
 	flagFactory := flags.FlagFactory{}
 	flagHystrix := flagFactory.CreateFlagHystrix()
 
-	hystrixTimeout = *flagHystrix.GetHystrixTimeoutMs()
-	hystrixMaxConcurrentRequests = *flagHystrix.GetHystrixMaxConcurrentRequests()
-	hystrixRequestVolumeThreshold = *flagHystrix.GetHystrixErrorPercentThreshold()
-	hystrixSleepWindow = *flagHystrix.GetHystrixSleepWindow()
-	hystrixErrorPercentThreshold = *flagHystrix.GetHystrixErrorPercentThreshold()
-
-	//all ConfigureCommands use the same conf, we can use different conf if we need.
-	hystrix.ConfigureCommand("dubboServer", hystrix.CommandConfig{
-		Timeout:                hystrixTimeout,
-		MaxConcurrentRequests:  hystrixMaxConcurrentRequests,
-		RequestVolumeThreshold: hystrixRequestVolumeThreshold,
-		SleepWindow:            hystrixSleepWindow,
-		ErrorPercentThreshold:  hystrixErrorPercentThreshold,
-	})
-
-	hystrix.ConfigureCommand("restServer", hystrix.CommandConfig{
-		Timeout:                hystrixTimeout,
-		MaxConcurrentRequests:  hystrixMaxConcurrentRequests,
-		RequestVolumeThreshold: hystrixRequestVolumeThreshold,
-		SleepWindow:            hystrixSleepWindow,
-		ErrorPercentThreshold:  hystrixErrorPercentThreshold,
-	})
-
-	hystrix.ConfigureCommand("grpcServer", hystrix.CommandConfig{
-		Timeout:                hystrixTimeout,
-		MaxConcurrentRequests:  hystrixMaxConcurrentRequests,
-		RequestVolumeThreshold: hystrixRequestVolumeThreshold,
-		SleepWindow:            hystrixSleepWindow,
-		ErrorPercentThreshold:  hystrixErrorPercentThreshold,
-	})
+	hystrixTimeout = flagHystrix.GetHystrixTimeoutMs()
+	hystrixMaxConcurrentRequests = flagHystrix.GetHystrixMaxConcurrentRequests()
+	hystrixRequestVolumeThreshold = flagHystrix.GetHystrixRequestVolumeThreshold()
+	hystrixSleepWindow = flagHystrix.GetHystrixSleepWindow()
+	hystrixErrorPercentThreshold = flagHystrix.GetHystrixErrorPercentThreshold()
+}
+
+// DefaultHystrixCommandConfig the hystrix flags, read when a command is configured.
+func DefaultHystrixCommandConfig() hystrix.CommandConfig {
+	return hystrix.CommandConfig{
+		Timeout:                *hystrixTimeout,
+		MaxConcurrentRequests:  *hystrixMaxConcurrentRequests,
+		RequestVolumeThreshold: *hystrixRequestVolumeThreshold,
+		SleepWindow:            *hystrixSleepWindow,
+		ErrorPercentThreshold:  *hystrixErrorPercentThreshold,
+	}
+}
+
+// ConfigureHystrixCommand configure a command named by prefix and the settings hash, and return the name.
+// INFO: hystrix-go keeps the circuit of a name once it is used, new settings get a new name instead of reconfiguring.
+func ConfigureHystrixCommand(prefix string, config hystrix.CommandConfig) string {
+	settings := fmt.Sprintf("%d_%d_%d_%d_%d", config.Timeout, config.MaxConcurrentRequests,
+		config.RequestVolumeThreshold, config.SleepWindow, config.ErrorPercentThreshold)
+	sum := md5.Sum([]byte(settings))
+	name := prefix + "_" + hex.EncodeToString(sum[:4])
+
+	if _, loaded := hystrixCommands.LoadOrStore(name, true); !loaded {
+		hystrix.ConfigureCommand(name, config)
+	}
 
+	return name
 }
@@ -9,7 +9,7 @@ import (
 	jwt "github.com/golang-jwt/jwt/v4"
 )
 
-var jwtKey []byte
+var jwtKey *string
 
 type Claims struct {
 	Username string `json:"username"`
@@ -19,7 +19,7 @@ type Claims struct {
 func init() {
 	flagFactory := flags.FlagFactory{}
 	flagJwt := flagFactory.CreateFlagJwt()
-	jwtKey = []byte(*flagJwt.GetJwtKey())
+	jwtKey = flagJwt.GetJwtKey()
 }
 
 func JwtAuthMiddleware(hd http.Handler) http.Handler {
@@ -36,7 +36,7 @@ func JwtAuthMiddleware(hd http.Handler) http.Handler {
 				err := errors.New("unexpected signing method")
 				return nil, err
 			}
-			return jwtKey, nil
+			return []byte(*jwtKey), nil
 		})
 
 		if err != nil {
@@ -73,7 +73,7 @@ func AuthHandler(w http.ResponseWriter, r *http.Request) {
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
-	tokenString, err := token.SignedString(jwtKey)
+	tokenString, err := token.SignedString([]byte(*jwtKey))
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
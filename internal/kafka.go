@@ -11,10 +11,11 @@ import (
 )
 
 var (
-	kafkaWriter *kafka.Writer
-	kafkaURL    string
-	kafkaTopic  string
-	kafkaGroup  string
+	kafkaWriter          *kafka.Writer
+	kafkaURL             *string
+	kafkaTopic           *string
+	kafkaGroup           *string
+	kafkaMembershipTopic *string
 )
 
 type CallbackFunc func(string, string)
@@ -25,7 +26,7 @@ func init() {
 	kafkaURL = flagKafka.GetKafkaUrl()
 	kafkaTopic = flagKafka.GetKafkaTopic()
 	kafkaGroup = flagKafka.GetKafkaGroup()
-
+	kafkaMembershipTopic = flagKafka.GetKafkaMembershipTopic()
 }
 
 func getKafkaWriter(kafkaURL, topic string) *kafka.Writer {
@@ -51,7 +52,16 @@ func getKafkaReader(kafkaURL, topic, groupID string) *kafka.Reader {
 
 // 从消息队列里监听来自用户管理后台传来的信息
 func KafkaConsumer(callback CallbackFunc) {
-	reader := getKafkaReader(kafkaURL, kafkaTopic, kafkaGroup)
+	kafkaTopicConsumer(*kafkaTopic, callback)
+}
+
+// listen user / item membership events from the offline pipeline.
+func KafkaMembershipConsumer(callback CallbackFunc) {
+	kafkaTopicConsumer(*kafkaMembershipTopic, callback)
+}
+
+func kafkaTopicConsumer(topic string, callback CallbackFunc) {
+	reader := getKafkaReader(*kafkaURL, topic, *kafkaGroup)
 	defer reader.Close()
 
 	index0 := 0
@@ -74,7 +84,7 @@ func KafkaConsumer(callback CallbackFunc) {
 }
 
 func KafkaProducer(msgKey string, msgValue string) {
-	kafkaWriter = getKafkaWriter(kafkaURL, kafkaTopic)
+	kafkaWriter = getKafkaWriter(*kafkaURL, *kafkaTopic)
 	defer kafkaWriter.Close()
 
 	msg := kafka.Message{
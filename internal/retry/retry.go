@@ -0,0 +1,199 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"infer-microservices/internal/flags"
+	"io"
+	"math/rand"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// the downstreams, each has its own retry budget.
+const (
+	Redis     = "redis"
+	Tfserving = "tfserving"
+	Faiss     = "faiss"
+)
+
+const budgetBurst = 100 //at most tokens of a budget.
+
+var retryMaxAttempts *int
+var retryBaseBackoffMs *int
+var retryMaxBackoffMs *int
+var retryBudgetPercent *float64
+var retryMinPerSecond *int
+
+var policies sync.Map //downstream -> *Policy
+
+func init() {
+	flagFactory := flags.FlagFactory{}
+	flagRetry := flagFactory.CreateFlagRetry()
+
+	retryMaxAttempts = flagRetry.GetRetryMaxAttempts()
+	retryBaseBackoffMs = flagRetry.GetRetryBaseBackoffMs()
+	retryMaxBackoffMs = flagRetry.GetRetryMaxBackoffMs()
+	retryBudgetPercent = flagRetry.GetRetryBudgetPercent()
+	retryMinPerSecond = flagRetry.GetRetryMinPerSecond()
+}
+
+// Policy retry the idempotent calls of a downstream on the transient errors.
+type Policy struct {
+	maxAttempts int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	budget      *Budget
+}
+
+// Downstream the shared policy of a downstream, from the retry flags parsed before the first call.
+func Downstream(name string) *Policy {
+	if policy, ok := policies.Load(name); ok {
+		return policy.(*Policy)
+	}
+
+	policy, _ := policies.LoadOrStore(name, NewPolicy(
+		*retryMaxAttempts,
+		time.Duration(*retryBaseBackoffMs)*time.Millisecond,
+		time.Duration(*retryMaxBackoffMs)*time.Millisecond,
+		NewBudget(*retryBudgetPercent, float64(*retryMinPerSecond)),
+	))
+
+	return policy.(*Policy)
+}
+
+// NewPolicy maxAttempts includes the first call, budget nil means no limit.
+func NewPolicy(maxAttempts int, baseBackoff time.Duration, maxBackoff time.Duration, budget *Budget) *Policy {
+	return &Policy{
+		maxAttempts: maxAttempts,
+		baseBackoff: baseBackoff,
+		maxBackoff:  maxBackoff,
+		budget:      budget,
+	}
+}
+
+// Do call op until it succeeds, fails with an error which is not retryable, or the attempts, the budget or the deadline of ctx run out.
+// op must be idempotent, such as a redis get, a tfserving predict or a faiss recall.
+func (p *Policy) Do(ctx context.Context, op func(ctx context.Context) error) error {
+	p.budget.deposit()
+
+	for attempt := 1; ; attempt++ {
+		err := op(ctx)
+		if err == nil || attempt >= p.maxAttempts || !Retryable(err) {
+			return err
+		}
+
+		//no time left for the backoff and another call.
+		backoff := p.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= backoff {
+			return err
+		}
+		if !p.budget.withdraw() {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+// full jitter, random in [0, min(maxBackoff, baseBackoff * 2^(attempt-1))).
+func (p *Policy) backoff(attempt int) time.Duration {
+	backoff := p.baseBackoff << (attempt - 1)
+	if backoff <= 0 || (p.maxBackoff > 0 && backoff > p.maxBackoff) {
+		backoff = p.maxBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(backoff)))
+}
+
+// Retryable the transient errors: grpc UNAVAILABLE, connection reset / refused, and the redis cluster errors in a failover.
+func Retryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if s, ok := status.FromError(err); ok {
+		return s.Code() == codes.Unavailable
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	message := err.Error()
+	for _, prefix := range []string{"LOADING ", "TRYAGAIN ", "CLUSTERDOWN ", "READONLY "} {
+		if strings.HasPrefix(message, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Budget token bucket of the retries, every call deposits percent / 100 token, and minPerSecond tokens are added every second.
+// a retry withdraws one token, so the retries can not amplify an outage.
+type Budget struct {
+	mu           sync.Mutex
+	ratio        float64
+	minPerSecond float64
+	tokens       float64
+	last         time.Time
+}
+
+func NewBudget(percent float64, minPerSecond float64) *Budget {
+	return &Budget{
+		ratio:        percent / 100,
+		minPerSecond: minPerSecond,
+		last:         time.Now(),
+	}
+}
+
+func (b *Budget) deposit() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.add(b.ratio)
+}
+
+func (b *Budget) withdraw() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.add(now.Sub(b.last).Seconds() * b.minPerSecond)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens -= 1
+
+	return true
+}
+
+func (b *Budget) add(tokens float64) {
+	b.tokens += tokens
+	if b.tokens > budgetBurst {
+		b.tokens = budgetBurst
+	}
+}
@@ -0,0 +1,93 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPolicyDo(t *testing.T) {
+	policy := NewPolicy(3, time.Millisecond, 2*time.Millisecond, nil)
+
+	calls := 0
+	err := policy.Do(context.Background(), func(ctx context.Context) error {
+		calls += 1
+		if calls < 3 {
+			return status.Error(codes.Unavailable, "connection reset")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("unavailable should be retried, calls %d err %v", calls, err)
+	}
+
+	calls = 0
+	err = policy.Do(context.Background(), func(ctx context.Context) error {
+		calls += 1
+		return status.Error(codes.InvalidArgument, "bad tensor")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("invalid argument should not be retried, calls %d", calls)
+	}
+}
+
+func TestPolicyDeadline(t *testing.T) {
+	policy := NewPolicy(5, 50*time.Millisecond, 50*time.Millisecond, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	policy.Do(ctx, func(ctx context.Context) error {
+		return errors.New("CLUSTERDOWN The cluster is down")
+	})
+	if latency := time.Since(start); latency > 40*time.Millisecond {
+		t.Errorf("backoff should be bounded by the deadline, latency %v", latency)
+	}
+}
+
+// the stage ctx of a downstream call is derived from the request ctx, the retries stop at the request deadline.
+func TestPolicyParentDeadline(t *testing.T) {
+	policy := NewPolicy(1000, 5*time.Millisecond, 5*time.Millisecond, nil)
+	parent, cancelParent := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancelParent()
+	ctx, cancel := context.WithTimeout(parent, time.Second)
+	defer cancel()
+
+	start := time.Now()
+	attempts := 0
+	err := policy.Do(ctx, func(ctx context.Context) error {
+		attempts++
+		return status.Error(codes.Unavailable, "unavailable")
+	})
+	if err == nil {
+		t.Fatalf("want the last error")
+	}
+	if latency := time.Since(start); latency > 200*time.Millisecond {
+		t.Errorf("retries should stop at the parent deadline, latency %v", latency)
+	}
+	if attempts >= 1000 {
+		t.Errorf("retries should stop before the attempts run out, attempts %d", attempts)
+	}
+	//the last backoff is skipped when it would pass the deadline, at most 5ms before it.
+	if latency := time.Since(start); latency < 20*time.Millisecond {
+		t.Errorf("retries should go on until the parent deadline, latency %v", latency)
+	}
+}
+
+func TestBudget(t *testing.T) {
+	budget := NewBudget(10, 0)
+	retries := 0
+	for i := 0; i < 100; i++ {
+		budget.deposit()
+		if budget.withdraw() {
+			retries += 1
+		}
+	}
+	if retries > 10 {
+		t.Errorf("retries %d exceed 10%% of the calls", retries)
+	}
+}
@@ -0,0 +1,141 @@
+package ann
+
+import (
+	"fmt"
+	"strings"
+)
+
+// index types.
+const (
+	IndexFlat = "flat" //brute force, exact.
+	IndexHNSW = "hnsw" //hierarchical navigable small world graph, approximate.
+)
+
+var IndexTypes = []string{IndexFlat, IndexHNSW}
+
+// metrics, the score of a result is the larger the closer.
+const (
+	MetricInnerProduct = "ip" //score is the inner product.
+	MetricL2           = "l2" //score is the negative squared l2 distance.
+)
+
+var Metrics = []string{MetricInnerProduct, MetricL2}
+
+// Item an item embedding of the index.
+type Item struct {
+	Id         string            `json:"id"`
+	Vector     []float32         `json:"vector"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Result an item and its score.
+type Result struct {
+	Item  *Item
+	Score float32
+}
+
+// Filter the items passed are searched, nil passes all.
+type Filter func(item *Item) bool
+
+// Index a vector index built from the items, immutable after built, safe for concurrent searches.
+type Index interface {
+	// Search the top k items of the vector, by score desc.
+	Search(vector []float32, k int, filter Filter) []Result
+	Dim() int
+	Size() int
+}
+
+// Options of an index.
+type Options struct {
+	Type           string
+	Metric         string
+	M              int //hnsw, max neighbors of a node per layer, 2M on layer 0.
+	EfConstruction int //hnsw, candidates of the neighbor search while building.
+	EfSearch       int //hnsw, candidates of a search, at least k.
+}
+
+func NewOptions() Options {
+	return Options{
+		Type:           IndexFlat,
+		Metric:         MetricInnerProduct,
+		M:              16,
+		EfConstruction: 200,
+		EfSearch:       64,
+	}
+}
+
+func (o Options) validate() error {
+	if !contains(IndexTypes, o.Type) {
+		return fmt.Errorf("index type %q, should be one of %s", o.Type, strings.Join(IndexTypes, ", "))
+	}
+	if !contains(Metrics, o.Metric) {
+		return fmt.Errorf("metric %q, should be one of %s", o.Metric, strings.Join(Metrics, ", "))
+	}
+	if o.Type == IndexHNSW && (o.M < 2 || o.EfConstruction < 1 || o.EfSearch < 1) {
+		return fmt.Errorf("hnsw m should be at least 2, efConstruction and efSearch at least 1")
+	}
+
+	return nil
+}
+
+// Build an index of the items, the vectors should be of the same dim.
+func Build(items []*Item, o Options) (Index, error) {
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	dim := 0
+	for _, item := range items {
+		if dim == 0 {
+			dim = len(item.Vector)
+		}
+		if len(item.Vector) == 0 || len(item.Vector) != dim {
+			return nil, fmt.Errorf("item %s dim %d, the index dim is %d", item.Id, len(item.Vector), dim)
+		}
+	}
+
+	score := scoreFunc(o.Metric)
+	if o.Type == IndexHNSW {
+		return newHnswIndex(items, dim, score, o), nil
+	}
+
+	return newFlatIndex(items, dim, score), nil
+}
+
+type scorer func(a []float32, b []float32) float32
+
+func scoreFunc(metric string) scorer {
+	if metric == MetricL2 {
+		return negativeL2
+	}
+
+	return innerProduct
+}
+
+func innerProduct(a []float32, b []float32) float32 {
+	var score float32
+	for i := range a {
+		score += a[i] * b[i]
+	}
+
+	return score
+}
+
+func negativeL2(a []float32, b []float32) float32 {
+	var distance float32
+	for i := range a {
+		diff := a[i] - b[i]
+		distance += diff * diff
+	}
+
+	return -distance
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
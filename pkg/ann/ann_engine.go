@@ -0,0 +1,148 @@
+package ann
+
+import (
+	"fmt"
+	"infer-microservices/internal/logs"
+	"sync/atomic"
+	"time"
+)
+
+const DefaultReloadInterval = time.Minute
+
+// loadedIndex an index and the version of its items.
+type loadedIndex struct {
+	index    Index
+	version  string
+	loadedAt time.Time
+}
+
+// Engine an in-process index of a source, a new version of the source is built in the background and swapped in,
+// the searches in flight finish on the old index.
+type Engine struct {
+	name    string
+	options Options
+	source  Source
+	current atomic.Pointer[loadedIndex]
+	refs    int32
+	done    chan struct{}
+}
+
+// EngineInfo the metadata of the index in use.
+type EngineInfo struct {
+	Type     string    `json:"type"`
+	Metric   string    `json:"metric"`
+	Source   string    `json:"source"`
+	Version  string    `json:"version"`
+	LoadedAt time.Time `json:"loadedAt"`
+	Dim      int       `json:"dim"`
+	Size     int       `json:"size"`
+}
+
+// NewEngine load the source and build the index, then check the version of the source every reloadInterval.
+// the source is closed with the engine, or if the first load fails.
+func NewEngine(name string, o Options, source Source, reloadInterval time.Duration) (*Engine, error) {
+	if err := o.validate(); err != nil {
+		source.Close()
+		return nil, err
+	}
+
+	e := &Engine{name: name, options: o, source: source, refs: 1, done: make(chan struct{})}
+	if _, err := e.reload(); err != nil {
+		source.Close()
+		return nil, fmt.Errorf("index %s load %s failed: %s", name, source, err)
+	}
+	if reloadInterval <= 0 {
+		reloadInterval = DefaultReloadInterval
+	}
+	go e.run(reloadInterval)
+
+	return e, nil
+}
+
+func (e *Engine) run(reloadInterval time.Duration) {
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.done:
+			return
+		case <-ticker.C:
+			swapped, err := e.reload()
+			if err != nil {
+				//the old index is kept.
+				logs.Error(e.name, time.Now(), err)
+			} else if swapped {
+				info := e.Info()
+				logs.Info(e.name, time.Now(), "index swapped, version:", info.Version, "size:", info.Size)
+			}
+		}
+	}
+}
+
+// reload build the index if the version of the source changed, true if a new index is swapped in.
+func (e *Engine) reload() (bool, error) {
+	version, err := e.source.Version()
+	if err != nil {
+		return false, err
+	}
+	if current := e.current.Load(); current != nil && (current.version == version || version == "") {
+		return false, nil
+	}
+
+	items, err := e.source.Load()
+	if err != nil {
+		return false, err
+	}
+	index, err := Build(items, e.options)
+	if err != nil {
+		return false, err
+	}
+	if current := e.current.Load(); current != nil && current.index.Size() > 0 && index.Dim() != current.index.Dim() {
+		return false, fmt.Errorf("index %s version %s dim %d, the dim in use is %d", e.name, version, index.Dim(), current.index.Dim())
+	}
+	e.current.Store(&loadedIndex{index: index, version: version, loadedAt: time.Now()})
+
+	return true, nil
+}
+
+// Search the top k items of the vector on the index in use.
+func (e *Engine) Search(vector []float32, k int, filter Filter) ([]Result, error) {
+	current := e.current.Load()
+	if dim := current.index.Dim(); current.index.Size() > 0 && dim != len(vector) {
+		return nil, fmt.Errorf("index %s dim %d, the user embedding dim is %d", e.name, dim, len(vector))
+	}
+
+	return current.index.Search(vector, k, filter), nil
+}
+
+// Dim of the index in use, 0 if it has no item.
+func (e *Engine) Dim() int {
+	return e.current.Load().index.Dim()
+}
+
+func (e *Engine) Info() EngineInfo {
+	current := e.current.Load()
+	return EngineInfo{
+		Type:     e.options.Type,
+		Metric:   e.options.Metric,
+		Source:   e.source.String(),
+		Version:  current.version,
+		LoadedAt: current.loadedAt,
+		Dim:      current.index.Dim(),
+		Size:     current.index.Size(),
+	}
+}
+
+// Retain one more service config uses the engine.
+func (e *Engine) Retain() {
+	atomic.AddInt32(&e.refs, 1)
+}
+
+// Release the engine stops reloading and closes its source when no service config uses it.
+func (e *Engine) Release() {
+	if atomic.AddInt32(&e.refs, -1) == 0 {
+		close(e.done)
+		e.source.Close()
+	}
+}
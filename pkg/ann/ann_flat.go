@@ -0,0 +1,41 @@
+package ann
+
+// flatIndex brute force search of all items.
+type flatIndex struct {
+	items []*Item
+	dim   int
+	score scorer
+}
+
+func newFlatIndex(items []*Item, dim int, score scorer) *flatIndex {
+	return &flatIndex{items: items, dim: dim, score: score}
+}
+
+func (f *flatIndex) Search(vector []float32, k int, filter Filter) []Result {
+	if k <= 0 || len(vector) != f.dim {
+		return nil
+	}
+
+	top := newTopK(k)
+	for i, item := range f.items {
+		if filter != nil && !filter(item) {
+			continue
+		}
+		top.push(candidate{node: int32(i), score: f.score(vector, item.Vector)})
+	}
+
+	results := make([]Result, 0, k)
+	for _, c := range top.sorted() {
+		results = append(results, Result{Item: f.items[c.node], Score: c.score})
+	}
+
+	return results
+}
+
+func (f *flatIndex) Dim() int {
+	return f.dim
+}
+
+func (f *flatIndex) Size() int {
+	return len(f.items)
+}
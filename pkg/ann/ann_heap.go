@@ -0,0 +1,79 @@
+package ann
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// candidate a node of the index and its score to the query.
+type candidate struct {
+	node  int32
+	score float32
+}
+
+// minHeap the worst candidate on top, keeps the best ones.
+type minHeap []candidate
+
+func (h minHeap) Len() int            { return len(h) }
+func (h minHeap) Less(i, j int) bool  { return h[i].score < h[j].score }
+func (h minHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *minHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// maxHeap the best candidate on top, the ones to expand.
+type maxHeap []candidate
+
+func (h maxHeap) Len() int            { return len(h) }
+func (h maxHeap) Less(i, j int) bool  { return h[i].score > h[j].score }
+func (h maxHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *maxHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// topK keeps the k best candidates.
+type topK struct {
+	k    int
+	heap minHeap
+}
+
+func newTopK(k int) *topK {
+	return &topK{k: k, heap: make(minHeap, 0, k+1)}
+}
+
+func (t *topK) full() bool {
+	return len(t.heap) >= t.k
+}
+
+// worst score of the kept candidates.
+func (t *topK) worst() float32 {
+	return t.heap[0].score
+}
+
+func (t *topK) push(c candidate) {
+	if !t.full() {
+		heap.Push(&t.heap, c)
+		return
+	}
+	if c.score > t.heap[0].score {
+		t.heap[0] = c
+		heap.Fix(&t.heap, 0)
+	}
+}
+
+// sorted the candidates by score desc.
+func (t *topK) sorted() []candidate {
+	candidates := make([]candidate, len(t.heap))
+	copy(candidates, t.heap)
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+
+	return candidates
+}
@@ -0,0 +1,230 @@
+package ann
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// hnswIndex hierarchical navigable small world graph, see https://arxiv.org/abs/1603.09320.
+// the upper layers are sparse long links to the region of the query, the layer 0 links all items.
+type hnswIndex struct {
+	items          []*Item
+	dim            int
+	score          scorer
+	m              int //max neighbors per upper layer.
+	m0             int //max neighbors on layer 0.
+	efConstruction int
+	efSearch       int
+	levelMult      float64
+	neighbors      [][][]int32 //node -> layer -> neighbors.
+	entry          int32
+	maxLevel       int
+	visited        sync.Pool //*visitedList
+}
+
+func newHnswIndex(items []*Item, dim int, score scorer, o Options) *hnswIndex {
+	h := &hnswIndex{
+		items:          items,
+		dim:            dim,
+		score:          score,
+		m:              o.M,
+		m0:             2 * o.M,
+		efConstruction: o.EfConstruction,
+		efSearch:       o.EfSearch,
+		levelMult:      1 / math.Log(float64(o.M)),
+		neighbors:      make([][][]int32, len(items)),
+	}
+	h.visited.New = func() interface{} {
+		return &visitedList{marks: make([]uint32, len(items))}
+	}
+
+	//a fixed seed, the same items build the same graph.
+	random := rand.New(rand.NewSource(1))
+	for node := range items {
+		h.insert(int32(node), int(math.Floor(-math.Log(1-random.Float64())*h.levelMult)))
+	}
+
+	return h
+}
+
+func (h *hnswIndex) insert(node int32, level int) {
+	h.neighbors[node] = make([][]int32, level+1)
+	if node == 0 {
+		h.entry = 0
+		h.maxLevel = level
+		return
+	}
+
+	vector := h.items[node].Vector
+	cur := candidate{node: h.entry, score: h.score(vector, h.items[h.entry].Vector)}
+	for l := h.maxLevel; l > level; l-- {
+		cur = h.greedy(vector, cur, l)
+	}
+
+	top := level
+	if h.maxLevel < top {
+		top = h.maxLevel
+	}
+	for l := top; l >= 0; l-- {
+		found := h.searchLayer(vector, cur, h.efConstruction, l, nil)
+		selected := found
+		if len(selected) > h.m {
+			selected = selected[:h.m]
+		}
+
+		links := make([]int32, 0, len(selected))
+		for _, c := range selected {
+			links = append(links, c.node)
+			h.neighbors[c.node][l] = append(h.neighbors[c.node][l], node)
+			if len(h.neighbors[c.node][l]) > h.maxNeighbors(l) {
+				h.prune(c.node, l)
+			}
+		}
+		h.neighbors[node][l] = links
+		cur = found[0]
+	}
+
+	if level > h.maxLevel {
+		h.entry = node
+		h.maxLevel = level
+	}
+}
+
+func (h *hnswIndex) maxNeighbors(level int) int {
+	if level == 0 {
+		return h.m0
+	}
+
+	return h.m
+}
+
+// prune keeps the closest neighbors of the node on the layer.
+func (h *hnswIndex) prune(node int32, level int) {
+	vector := h.items[node].Vector
+	top := newTopK(h.maxNeighbors(level))
+	for _, neighbor := range h.neighbors[node][level] {
+		top.push(candidate{node: neighbor, score: h.score(vector, h.items[neighbor].Vector)})
+	}
+
+	links := h.neighbors[node][level][:0]
+	for _, c := range top.sorted() {
+		links = append(links, c.node)
+	}
+	h.neighbors[node][level] = links
+}
+
+// greedy move to the closest neighbor on the layer until no neighbor is closer.
+func (h *hnswIndex) greedy(vector []float32, cur candidate, level int) candidate {
+	for changed := true; changed; {
+		changed = false
+		for _, neighbor := range h.neighbors[cur.node][level] {
+			if score := h.score(vector, h.items[neighbor].Vector); score > cur.score {
+				cur = candidate{node: neighbor, score: score}
+				changed = true
+			}
+		}
+	}
+
+	return cur
+}
+
+// searchLayer the ef closest nodes passing the filter on the layer from the entry, by score desc.
+// the nodes not passing the filter are still expanded, the graph keeps connected.
+func (h *hnswIndex) searchLayer(vector []float32, entry candidate, ef int, level int, filter Filter) []candidate {
+	visited := h.visited.Get().(*visitedList)
+	defer h.visited.Put(visited)
+	visited.reset()
+
+	visited.visit(entry.node)
+	candidates := &maxHeap{entry}
+	results := newTopK(ef)
+	if filter == nil || filter(h.items[entry.node]) {
+		results.push(entry)
+	}
+
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(candidate)
+		if results.full() && c.score < results.worst() {
+			break
+		}
+
+		for _, neighbor := range h.neighbors[c.node][level] {
+			if visited.visit(neighbor) {
+				continue
+			}
+			score := h.score(vector, h.items[neighbor].Vector)
+			if results.full() && score <= results.worst() {
+				continue
+			}
+			heap.Push(candidates, candidate{node: neighbor, score: score})
+			if filter == nil || filter(h.items[neighbor]) {
+				results.push(candidate{node: neighbor, score: score})
+			}
+		}
+	}
+
+	return results.sorted()
+}
+
+func (h *hnswIndex) Search(vector []float32, k int, filter Filter) []Result {
+	if k <= 0 || len(h.items) == 0 || len(vector) != h.dim {
+		return nil
+	}
+
+	cur := candidate{node: h.entry, score: h.score(vector, h.items[h.entry].Vector)}
+	for l := h.maxLevel; l > 0; l-- {
+		cur = h.greedy(vector, cur, l)
+	}
+
+	ef := h.efSearch
+	if ef < k {
+		ef = k
+	}
+	found := h.searchLayer(vector, cur, ef, 0, filter)
+	if len(found) > k {
+		found = found[:k]
+	}
+
+	results := make([]Result, 0, len(found))
+	for _, c := range found {
+		results = append(results, Result{Item: h.items[c.node], Score: c.score})
+	}
+
+	return results
+}
+
+func (h *hnswIndex) Dim() int {
+	return h.dim
+}
+
+func (h *hnswIndex) Size() int {
+	return len(h.items)
+}
+
+// visitedList the nodes visited by a search, reset by a new epoch instead of clearing.
+type visitedList struct {
+	marks []uint32
+	epoch uint32
+}
+
+func (v *visitedList) reset() {
+	v.epoch++
+	if v.epoch == 0 {
+		for i := range v.marks {
+			v.marks[i] = 0
+		}
+		v.epoch = 1
+	}
+}
+
+// visit the node, true if it was visited.
+func (v *visitedList) visit(node int32) bool {
+	if v.marks[node] == v.epoch {
+		return true
+	}
+	v.marks[node] = v.epoch
+
+	return false
+}
@@ -0,0 +1,132 @@
+package ann
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	redis_v8 "infer-microservices/internal/db/redis"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// Source the item embeddings of an index, and their version. a new version is loaded and swapped in.
+type Source interface {
+	// Version of the items, the same version is not loaded again.
+	Version() (string, error)
+	Load() ([]*Item, error)
+	// Close the source when the index is not used.
+	Close()
+	String() string
+}
+
+// FileSource items of a local file, one json item per line, such as
+// {"id": "item-001", "vector": [0.1, 0.2], "attributes": {"region": "us"}}.
+// the version is the modify time and the size of the file.
+type FileSource struct {
+	path string
+}
+
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path}
+}
+
+func (f *FileSource) Version() (string, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.FormatInt(info.ModTime().UnixNano(), 10) + "-" + strconv.FormatInt(info.Size(), 10), nil
+}
+
+func (f *FileSource) Load() ([]*Item, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	items := make([]*Item, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		item := &Item{}
+		if err := json.Unmarshal(scanner.Bytes(), item); err != nil {
+			return nil, fmt.Errorf("%s line %d: %s", f.path, line, err)
+		}
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (f *FileSource) Close() {
+}
+
+func (f *FileSource) String() string {
+	return "file " + f.path
+}
+
+// RedisSource items of a redis hash snapshot, the fields are the item ids, the values are
+// {"vector": [0.1, 0.2], "attributes": {"region": "us"}}. the version is the value of key:version,
+// which the snapshot writer sets after a new snapshot, without it the snapshot is loaded once.
+// the client is released by Close.
+type RedisSource struct {
+	client *redis_v8.InferRedisClient
+	key    string
+}
+
+func NewRedisSource(client *redis_v8.InferRedisClient, key string) *RedisSource {
+	return &RedisSource{client: client, key: key}
+}
+
+func (r *RedisSource) Version() (string, error) {
+	version, err := r.client.Get(r.key + ":version")
+	if err != nil && !redis_v8.IsNil(err) {
+		return "", err
+	}
+
+	return version, nil
+}
+
+func (r *RedisSource) Load() ([]*Item, error) {
+	values, err := r.client.HGetAll(r.key)
+	if err != nil {
+		return nil, err
+	}
+
+	//sorted by id, the same snapshot builds the same index.
+	ids := make([]string, 0, len(values))
+	for id := range values {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	items := make([]*Item, 0, len(values))
+	for _, id := range ids {
+		item := &Item{}
+		if err := json.Unmarshal([]byte(values[id]), item); err != nil {
+			return nil, fmt.Errorf("%s item %s: %s", r.key, id, err)
+		}
+		item.Id = id
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// Close release the redis client of the source.
+func (r *RedisSource) Close() {
+	redis_v8.ReleaseRedisClient(r.client)
+}
+
+func (r *RedisSource) String() string {
+	return "redis " + r.key
+}
@@ -0,0 +1,136 @@
+package ann
+
+import (
+	"encoding/json"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func randomItems(n int, dim int, seed int64) []*Item {
+	random := rand.New(rand.NewSource(seed))
+	items := make([]*Item, 0, n)
+	for i := 0; i < n; i++ {
+		vector := make([]float32, dim)
+		for j := range vector {
+			vector[j] = random.Float32()*2 - 1
+		}
+		region := "us"
+		if i%2 == 1 {
+			region = "ca"
+		}
+		items = append(items, &Item{Id: strconv.Itoa(i), Vector: vector, Attributes: map[string]string{"region": region}})
+	}
+
+	return items
+}
+
+func TestHnswRecall(t *testing.T) {
+	items := randomItems(2000, 16, 1)
+	queries := randomItems(50, 16, 2)
+	for _, metric := range Metrics {
+		o := NewOptions()
+		o.Metric = metric
+		flat, err := Build(items, o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		o.Type = IndexHNSW
+		hnsw, err := Build(items, o)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		hits, total := 0, 0
+		for _, query := range queries {
+			exact := make(map[string]bool, 0)
+			for _, result := range flat.Search(query.Vector, 10, nil) {
+				exact[result.Item.Id] = true
+			}
+			for _, result := range hnsw.Search(query.Vector, 10, nil) {
+				if exact[result.Item.Id] {
+					hits += 1
+				}
+			}
+			total += 10
+		}
+		if recall := float64(hits) / float64(total); recall < 0.9 {
+			t.Errorf("%s hnsw recall@10 %.2f, expect at least 0.9", metric, recall)
+		}
+	}
+}
+
+func TestSearchFilter(t *testing.T) {
+	items := randomItems(500, 8, 1)
+	filter := func(item *Item) bool { return item.Attributes["region"] == "ca" }
+	for _, indexType := range IndexTypes {
+		o := NewOptions()
+		o.Type = indexType
+		index, err := Build(items, o)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		results := index.Search(items[0].Vector, 20, filter)
+		if len(results) != 20 {
+			t.Errorf("%s recall %d items, expect 20", indexType, len(results))
+		}
+		for i, result := range results {
+			if result.Item.Attributes["region"] != "ca" {
+				t.Errorf("%s recall item %s of region %s", indexType, result.Item.Id, result.Item.Attributes["region"])
+			}
+			if i > 0 && result.Score > results[i-1].Score {
+				t.Errorf("%s results not sorted by score", indexType)
+			}
+		}
+	}
+}
+
+func writeItems(t *testing.T, path string, items []*Item) {
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	encoder := json.NewEncoder(file)
+	for _, item := range items {
+		if err := encoder.Encode(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestEngineHotSwap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.jsonl")
+	writeItems(t, path, randomItems(100, 8, 1))
+
+	engine, err := NewEngine("index-001", NewOptions(), NewFileSource(path), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer engine.Release()
+	if info := engine.Info(); info.Size != 100 || info.Dim != 8 {
+		t.Errorf("engine info %+v, expect 100 items of dim 8", info)
+	}
+
+	//a new version is swapped in.
+	writeItems(t, path, randomItems(200, 8, 2))
+	os.Chtimes(path, time.Now().Add(time.Second), time.Now().Add(time.Second))
+	swapped, err := engine.reload()
+	if err != nil || !swapped || engine.Info().Size != 200 {
+		t.Errorf("new version not swapped in, swapped %v, err %v, info %+v", swapped, err, engine.Info())
+	}
+
+	//a version of another dim is rejected, the index in use is kept.
+	writeItems(t, path, randomItems(10, 4, 3))
+	os.Chtimes(path, time.Now().Add(2*time.Second), time.Now().Add(2*time.Second))
+	if _, err := engine.reload(); err == nil || engine.Info().Size != 200 {
+		t.Errorf("index of another dim should be rejected")
+	}
+	if _, err := engine.Search(make([]float32, 4), 10, nil); err == nil {
+		t.Errorf("search of another dim should fail")
+	}
+}
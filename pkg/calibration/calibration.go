@@ -0,0 +1,184 @@
+package calibration
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Calibrator map raw model scores to calibrated probabilities, so scores of different models are comparable.
+type Calibrator interface {
+	Calibrate(score float32, context map[string]string) float32
+}
+
+// NewCalibrator build calibrator from the model_conf calibration spec.
+//
+//	{"type": "platt", "a": -1.2, "b": 0.3}
+//	{"type": "isotonic", "x": [0.0, 0.5, 1.0], "y": [0.0, 0.3, 1.0]}
+//	{"type": "slice", "feature": "country", "slices": {"us": {...}, "cn": {...}}, "default": {...}}
+func NewCalibrator(spec map[string]interface{}) (Calibrator, error) {
+	calibrationType, _ := spec["type"].(string)
+	switch strings.ToLower(calibrationType) {
+	case "platt":
+		return newPlattCalibrator(spec)
+	case "isotonic":
+		return newIsotonicCalibrator(spec)
+	case "slice":
+		return newSliceCalibrator(spec)
+	default:
+		return nil, fmt.Errorf("unknown calibration type %q, should be platt, isotonic or slice", calibrationType)
+	}
+}
+
+// PlattCalibrator p = 1 / (1 + exp(a * logit(score) + b)).
+type PlattCalibrator struct {
+	a float64
+	b float64
+}
+
+func newPlattCalibrator(spec map[string]interface{}) (*PlattCalibrator, error) {
+	a, okA := spec["a"].(float64)
+	b, okB := spec["b"].(float64)
+	if !okA || !okB {
+		return nil, errors.New("platt calibration need number params a and b")
+	}
+
+	return &PlattCalibrator{a: a, b: b}, nil
+}
+
+func (p *PlattCalibrator) Calibrate(score float32, context map[string]string) float32 {
+	//INFO: raw scores are sigmoid outputs, convert back to logit first.
+	s := math.Min(math.Max(float64(score), 1e-7), 1-1e-7)
+	logit := math.Log(s / (1 - s))
+
+	return float32(1 / (1 + math.Exp(p.a*logit+p.b)))
+}
+
+// IsotonicCalibrator piecewise linear interpolation between increasing breakpoints.
+type IsotonicCalibrator struct {
+	x []float64
+	y []float64
+}
+
+func newIsotonicCalibrator(spec map[string]interface{}) (*IsotonicCalibrator, error) {
+	x, err := floatList(spec["x"])
+	if err != nil {
+		return nil, fmt.Errorf("isotonic calibration x: %v", err)
+	}
+	y, err := floatList(spec["y"])
+	if err != nil {
+		return nil, fmt.Errorf("isotonic calibration y: %v", err)
+	}
+
+	if len(x) == 0 || len(x) != len(y) {
+		return nil, errors.New("isotonic calibration x and y should have the same non-zero length")
+	}
+	for i := 1; i < len(x); i++ {
+		if x[i] <= x[i-1] {
+			return nil, errors.New("isotonic calibration x should be strictly increasing")
+		}
+		if y[i] < y[i-1] {
+			return nil, errors.New("isotonic calibration y should be non-decreasing")
+		}
+	}
+
+	return &IsotonicCalibrator{x: x, y: y}, nil
+}
+
+func (i *IsotonicCalibrator) Calibrate(score float32, context map[string]string) float32 {
+	s := float64(score)
+	n := len(i.x)
+	if s <= i.x[0] {
+		return float32(i.y[0])
+	}
+	if s >= i.x[n-1] {
+		return float32(i.y[n-1])
+	}
+
+	// first breakpoint greater than score.
+	idx := sort.SearchFloat64s(i.x, s)
+	if i.x[idx] == s {
+		return float32(i.y[idx])
+	}
+	x0, x1 := i.x[idx-1], i.x[idx]
+	y0, y1 := i.y[idx-1], i.y[idx]
+
+	return float32(y0 + (y1-y0)*(s-x0)/(x1-x0))
+}
+
+// SliceCalibrator choose calibrator by a request context feature, such as country or platform.
+type SliceCalibrator struct {
+	feature    string
+	slices     map[string]Calibrator
+	defaultCal Calibrator
+}
+
+func newSliceCalibrator(spec map[string]interface{}) (*SliceCalibrator, error) {
+	feature, _ := spec["feature"].(string)
+	if feature == "" {
+		return nil, errors.New("slice calibration need context feature")
+	}
+
+	slicesSpec, ok := spec["slices"].(map[string]interface{})
+	if !ok || len(slicesSpec) == 0 {
+		return nil, errors.New("slice calibration need slices")
+	}
+
+	s := &SliceCalibrator{
+		feature: feature,
+		slices:  make(map[string]Calibrator, len(slicesSpec)),
+	}
+	for sliceValue, sliceSpec := range slicesSpec {
+		sliceSpecMap, ok := sliceSpec.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("slice %s calibration should be an object", sliceValue)
+		}
+		calibrator, err := NewCalibrator(sliceSpecMap)
+		if err != nil {
+			return nil, fmt.Errorf("slice %s: %v", sliceValue, err)
+		}
+		s.slices[sliceValue] = calibrator
+	}
+
+	if defaultSpec, ok := spec["default"].(map[string]interface{}); ok {
+		calibrator, err := NewCalibrator(defaultSpec)
+		if err != nil {
+			return nil, fmt.Errorf("default slice: %v", err)
+		}
+		s.defaultCal = calibrator
+	}
+
+	return s, nil
+}
+
+func (s *SliceCalibrator) Calibrate(score float32, context map[string]string) float32 {
+	if calibrator, ok := s.slices[context[s.feature]]; ok {
+		return calibrator.Calibrate(score, context)
+	}
+	if s.defaultCal != nil {
+		return s.defaultCal.Calibrate(score, context)
+	}
+
+	//unknown slice and no default, keep raw score.
+	return score
+}
+
+func floatList(raw interface{}) ([]float64, error) {
+	rawList, ok := raw.([]interface{})
+	if !ok {
+		return nil, errors.New("should be a number list")
+	}
+
+	values := make([]float64, 0, len(rawList))
+	for _, v := range rawList {
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a number", v)
+		}
+		values = append(values, f)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,90 @@
+package calibration
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func newTestCalibrator(t *testing.T, specStr string) Calibrator {
+	spec := make(map[string]interface{}, 0)
+	if err := json.Unmarshal([]byte(specStr), &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	calibrator, err := NewCalibrator(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return calibrator
+}
+
+func TestPlattCalibration(t *testing.T) {
+	// a = -1, b = 0 is the identity on sigmoid outputs.
+	calibrator := newTestCalibrator(t, `{"type": "platt", "a": -1, "b": 0}`)
+	for _, score := range []float32{0.1, 0.5, 0.9} {
+		if got := calibrator.Calibrate(score, nil); math.Abs(float64(got-score)) > 1e-5 {
+			t.Errorf("identity platt calibrate %v, got %v", score, got)
+		}
+	}
+
+	calibrator = newTestCalibrator(t, `{"type": "platt", "a": -1, "b": 1}`)
+	if got := calibrator.Calibrate(0.5, nil); got >= 0.5 {
+		t.Errorf("positive b should lower the score, got %v", got)
+	}
+}
+
+func TestIsotonicCalibration(t *testing.T) {
+	calibrator := newTestCalibrator(t, `{"type": "isotonic", "x": [0.1, 0.5, 0.9], "y": [0.0, 0.2, 1.0]}`)
+
+	cases := map[float32]float32{
+		0.05: 0.0,
+		0.1:  0.0,
+		0.3:  0.1,
+		0.5:  0.2,
+		0.7:  0.6,
+		0.95: 1.0,
+	}
+	for score, expected := range cases {
+		if got := calibrator.Calibrate(score, nil); math.Abs(float64(got-expected)) > 1e-5 {
+			t.Errorf("isotonic calibrate %v, expected %v, got %v", score, expected, got)
+		}
+	}
+}
+
+func TestSliceCalibration(t *testing.T) {
+	calibrator := newTestCalibrator(t, `{
+		"type": "slice",
+		"feature": "country",
+		"slices": {
+			"us": {"type": "isotonic", "x": [0, 1], "y": [0, 0.5]}
+		},
+		"default": {"type": "isotonic", "x": [0, 1], "y": [0.5, 1]}
+	}`)
+
+	if got := calibrator.Calibrate(1, map[string]string{"country": "us"}); got != 0.5 {
+		t.Errorf("us slice expected 0.5, got %v", got)
+	}
+	if got := calibrator.Calibrate(0, map[string]string{"country": "cn"}); got != 0.5 {
+		t.Errorf("default slice expected 0.5, got %v", got)
+	}
+}
+
+func TestInvalidCalibrationSpec(t *testing.T) {
+	specs := []string{
+		`{"type": "unknown"}`,
+		`{"type": "platt", "a": 1}`,
+		`{"type": "isotonic", "x": [0.5, 0.1], "y": [0, 1]}`,
+		`{"type": "isotonic", "x": [0.1, 0.5], "y": [1, 0]}`,
+		`{"type": "slice", "feature": "country", "slices": {}}`,
+	}
+
+	for _, specStr := range specs {
+		spec := make(map[string]interface{}, 0)
+		json.Unmarshal([]byte(specStr), &spec)
+		if _, err := NewCalibrator(spec); err == nil {
+			t.Errorf("expected error for spec %s", specStr)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package config_check
+
+import (
+	"encoding/json"
+	"errors"
+	"infer-microservices/pkg/calibration"
+	"infer-microservices/pkg/config_loader/config_schema"
+	"infer-microservices/pkg/services/io"
+	"os"
+	"strconv"
+	"time"
+)
+
+const suspiciousTimeout = 10 * time.Second
+
+// CheckServiceContent check a service content, such as the nacos config of a dataId:
+// {"config": {"redis_conf": {...}, "model_conf": {...}, "index_conf": {...}}}
+func CheckServiceContent(dataId string, content []byte) *Report {
+	report := NewReport(dataId)
+
+	doc := make(map[string]interface{}, 0)
+	err := json.Unmarshal(content, &doc)
+	if err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			report.Errorf("$", "invalid json at offset %d: %v", syntaxErr.Offset, err)
+		} else {
+			report.Errorf("$", "%v", err)
+		}
+		return report
+	}
+
+	CheckServiceDocument(report, doc)
+
+	return report
+}
+
+// CheckServiceDocument check a parsed service content.
+func CheckServiceDocument(report *Report, doc map[string]interface{}) {
+	c := checker{report: report}
+
+	config, ok := c.object(doc, "$", "config", true)
+	if !ok {
+		return
+	}
+
+	if redisConf, ok := c.object(config, "$.config", "redis_conf", true); ok {
+		c.checkRedisConf(redisConf, "$.config.redis_conf")
+	}
+	if modelConf, ok := c.object(config, "$.config", "model_conf", true); ok {
+		c.checkModelConf(modelConf, "$.config.model_conf")
+	}
+	if indexConf, ok := c.object(config, "$.config", "index_conf", false); ok && len(indexConf) > 0 {
+		c.checkIndexConf(indexConf, "$.config.index_conf")
+	}
+	if resilienceConf, ok := c.object(config, "$.config", "resilience_conf", false); ok {
+		c.checkResilienceConf(resilienceConf, "$.config.resilience_conf")
+	}
+}
+
+type checker struct {
+	report *Report
+}
+
+// the schema issues, which are load-time errors and ignored keys.
+func (c *checker) addIssues(issues config_schema.Issues) {
+	for _, issue := range issues {
+		if issue.Warning {
+			c.report.Warnf(issue.Path, "%s", issue.Message)
+		} else {
+			c.report.Errorf(issue.Path, "%s", issue.Message)
+		}
+	}
+}
+
+func (c *checker) checkRedisConf(redisConf map[string]interface{}, path string) {
+	conf, issues := config_schema.ParseRedisConf(redisConf, path)
+	c.addIssues(issues)
+	path += ".redisCluster"
+
+	//the fields with errors keep their defaults.
+	if conf.Addrs != nil && len(conf.Addrs) == 0 {
+		c.report.Warnf(path+".addrs", "no redis address")
+	}
+	c.timeout(path+".readTimeout", conf.ReadTimeout)
+	c.timeout(path+".writeTimeout", conf.WriteTimeout)
+	c.timeout(path+".dialTimeout", conf.DialTimeout)
+}
+
+func (c *checker) checkModelConf(modelConf map[string]interface{}, path string) {
+	conf, issues := config_schema.ParseModelConf(modelConf, path)
+	c.addIssues(issues)
+	if conf.ModelName == "" {
+		return
+	}
+	path += "." + conf.ModelName
+
+	c.checkGrpcConf(conf.TfservingGrpc, path+".tfservingGrpcAddr")
+	keyPres := map[string]string{
+		"userRedisKeyPreOffline":  conf.UserRedisKeyPreOffline,
+		"userRedisKeyPreRealtime": conf.UserRedisKeyPreRealtime,
+		"itemRedisKeyPre":         conf.ItemRedisKeyPre,
+	}
+	for _, key := range []string{"userRedisKeyPreOffline", "userRedisKeyPreRealtime", "itemRedisKeyPre"} {
+		if keyPres[key] == "" && !issues.Has(path+"."+key) {
+			c.report.Warnf(path+"."+key, "empty redis key prefix")
+		}
+	}
+	if conf.Calibration != nil {
+		if _, err := calibration.NewCalibrator(conf.Calibration); err != nil {
+			c.report.Errorf(path+".calibration", "%v", err)
+		}
+	}
+}
+
+func (c *checker) checkIndexConf(indexConf map[string]interface{}, path string) {
+	conf, issues := config_schema.ParseIndexConf(indexConf, path)
+	c.addIssues(issues)
+
+	if conf.HasFaissIndex() {
+		c.checkGrpcConf(conf.FaissGrpc, path+".faissGrpcAddr")
+	}
+	indexNames := make(map[string]bool, len(conf.Indexes))
+	for idx, index := range conf.Indexes {
+		indexPath := path + ".indexInfo[" + strconv.Itoa(idx) + "]"
+		if indexNames[index.IndexName] {
+			c.report.Warnf(indexPath+".indexName", "index %s is duplicated, it will be searched twice", index.IndexName)
+		}
+		indexNames[index.IndexName] = true
+
+		if index.RecallNum > io.MaxRecallNum {
+			c.report.Warnf(indexPath+".recallNum", "%v exceeds the request recallNum limit %d", index.RecallNum, io.MaxRecallNum)
+		}
+		if index.Local != nil {
+			c.file(indexPath+".local.file", index.Local.File)
+		}
+	}
+}
+
+func (c *checker) checkResilienceConf(resilienceConf map[string]interface{}, path string) {
+	conf, issues := config_schema.ParseResilienceConf(resilienceConf, path)
+	c.addIssues(issues)
+
+	if conf.Hystrix.Timeout > conf.Deadline {
+		c.report.Warnf(path+".hystrix.timeout", "%v exceeds the deadline %v, the degraded request never runs", conf.Hystrix.Timeout, conf.Deadline)
+	}
+	for _, stage := range config_schema.Stages {
+		if conf.StageTimeouts[stage] > conf.Deadline {
+			c.report.Warnf(path+".stageTimeouts."+stage, "%v exceeds the deadline %v", conf.StageTimeouts[stage], conf.Deadline)
+		}
+	}
+}
+
+func (c *checker) checkGrpcConf(conf config_schema.GrpcConf, path string) {
+	if conf.Addrs != nil && len(conf.Addrs) == 0 {
+		c.report.Warnf(path+".addrs", "no grpc address")
+	}
+	if conf.PoolSize > 1000 {
+		c.report.Warnf(path+".pool_size", "%v connections is suspicious", conf.PoolSize)
+	}
+	c.timeout(path+".readTimeout", conf.ReadTimeout)
+	c.timeout(path+".writeTimeout", conf.WriteTimeout)
+	c.timeout(path+".dialTimeout", conf.DialTimeout)
+	c.file(path+".tls.caFile", conf.TLS.CAFile)
+	c.file(path+".tls.certFile", conf.TLS.CertFile)
+	c.file(path+".tls.keyFile", conf.TLS.KeyFile)
+}
+
+// warn when a file, such as a tls file, is not readable here, it may exist on the serving hosts only.
+func (c *checker) file(path string, file string) {
+	if file == "" {
+		return
+	}
+	if _, err := os.Stat(file); err != nil {
+		c.report.Warnf(path, "%v", err)
+	}
+}
+
+// warn when a timeout is suspicious long.
+func (c *checker) timeout(path string, timeout time.Duration) {
+	if timeout > suspiciousTimeout {
+		c.report.Warnf(path, "%v is suspicious long", timeout)
+	}
+}
+
+func (c *checker) object(parent map[string]interface{}, path string, key string, required bool) (map[string]interface{}, bool) {
+	v, ok := parent[key].(map[string]interface{})
+	if !ok && (required || parent[key] != nil) {
+		if _, exist := parent[key]; exist {
+			c.report.Errorf(path+"."+key, "should be object")
+		} else {
+			c.report.Errorf(path+"."+key, "missing")
+		}
+	}
+
+	return v, ok
+}
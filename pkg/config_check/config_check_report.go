@@ -0,0 +1,58 @@
+package config_check
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+)
+
+// Issue a schema violation or a suspicious value, path is the json path in the service content.
+type Issue struct {
+	Path    string `json:"path"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+type Report struct {
+	DataId string  `json:"dataId"`
+	Issues []Issue `json:"issues"`
+}
+
+func NewReport(dataId string) *Report {
+	return &Report{
+		DataId: dataId,
+		Issues: make([]Issue, 0),
+	}
+}
+
+func (r *Report) Errorf(path string, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, Issue{Path: path, Level: LevelError, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *Report) Warnf(path string, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, Issue{Path: path, Level: LevelWarning, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *Report) HasError() bool {
+	for _, issue := range r.Issues {
+		if issue.Level == LevelError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// one issue per line, for cli output.
+func (r *Report) String() string {
+	var sb strings.Builder
+	for _, issue := range r.Issues {
+		sb.WriteString(fmt.Sprintf("%-7s %s: %s\n", issue.Level, issue.Path, issue.Message))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,163 @@
+package config_check
+
+import (
+	"testing"
+)
+
+const validContent = `
+{
+	"config": {
+		"redis_conf": {
+			"redisCluster": {
+				"addrs": ["127.0.0.1:6379"],
+				"password": "",
+				"readTimeoutMs": 100,
+				"writeTimeoutMs": 100,
+				"dialTimeoutMs": 600,
+				"idleTimeoutS": 100,
+				"maxRetries": 2,
+				"minIdleConns": 50
+			}
+		},
+		"model_conf": {
+			"model-001": {
+				"tfservingGrpcAddr": {
+					"tfservingModelName": "models",
+					"addrs": ["127.0.0.1:8500"],
+					"pool_size": 50,
+					"initCap": 10,
+					"readTimeoutMs": 100,
+					"writeTimeoutMs": 100,
+					"dialTimeoutMs": 600,
+					"idleTimeoutS": 100
+				},
+				"userRedisKeyPreOffline": "u_off_",
+				"userRedisKeyPreRealtime": "u_rt_",
+				"itemRedisKeyPre": "i_"
+			}
+		},
+		"index_conf": {
+			"faissGrpcAddr": {
+				"addrs": ["127.0.0.1:9000"],
+				"initCap": 10,
+				"readTimeoutMs": 100,
+				"writeTimeoutMs": 100,
+				"dialTimeoutMs": 600,
+				"idleTimeoutS": 100
+			},
+			"indexInfo": [{"indexName": "index-001", "recallNum": 100}]
+		},
+		"resilience_conf": {
+			"deadline": "100ms",
+			"stageTimeouts": {"feature": "50ms", "recall": "50ms", "rankMs": 60, "format": "20ms"},
+			"hystrix": {"timeout": "80ms", "maxConcurrentRequests": 1000, "sleepWindow": "10s", "errorPercentThreshold": 20},
+			"lowerRecallNum": 50,
+			"lowerRankNum": 50
+		}
+	}
+}
+`
+
+func TestCheckValidServiceContent(t *testing.T) {
+	report := CheckServiceContent("inferid-001", []byte(validContent))
+	if len(report.Issues) != 0 {
+		t.Errorf("expected no issues, got:\n%s", report)
+	}
+}
+
+func TestCheckInvalidServiceContent(t *testing.T) {
+	content := `
+	{
+		"config": {
+			"redis_conf": {
+				"redisCluster": {
+					"addrs": "127.0.0.1:6379",
+					"readTimeoutMs": 100,
+					"writeTimeoutMs": 100,
+					"dialTimeoutS": 600,
+					"idleTimeoutS": 100,
+					"maxRetries": 20,
+					"minIdleConns": 50
+				}
+			},
+			"model_conf": {
+				"model-001": {
+					"tfservingGrpcAddr": {
+						"tfservingModelName": "models",
+						"addrs": [],
+						"pool_size": 5,
+						"initCap": 10,
+						"readTimeoutMs": 100,
+						"writeTimeoutMs": 100,
+						"dialTimeoutMs": 600,
+						"idleTimeoutS": 100
+					},
+					"userRedisKeyPreOffline": "u_off_",
+					"userRedisKeyPreRealtime": "u_rt_",
+					"itemRedisKeyPre": "i_",
+					"calibration": {"type": "platt"}
+				}
+			},
+			"index_conf": {
+				"faissGrpcAddr": {
+					"addrs": ["127.0.0.1:9000"],
+					"initCap": 10,
+					"readTimeoutMs": 100,
+					"writeTimeoutMs": 100,
+					"dialTimeoutMs": 600,
+					"idleTimeoutS": 100
+				},
+				"indexInfo": [{"indexName": "index-001", "recallNum": 5000}]
+			},
+			"resilience_conf": {
+				"deadline": "100ms",
+				"hystrix": {"timeout": "200ms"},
+				"lowerRankNum": 0
+			}
+		}
+	}
+	`
+
+	expected := map[string]string{
+		"$.config.redis_conf.redisCluster.addrs":                  LevelError,
+		"$.config.redis_conf.redisCluster.dialTimeoutS":           LevelError,
+		"$.config.redis_conf.redisCluster.maxRetries":             LevelError,
+		"$.config.model_conf.model-001.tfservingGrpcAddr.addrs":   LevelWarning,
+		"$.config.model_conf.model-001.tfservingGrpcAddr.initCap": LevelError,
+		"$.config.model_conf.model-001.calibration":               LevelError,
+		"$.config.index_conf.indexInfo[0].recallNum":              LevelWarning,
+		"$.config.resilience_conf.hystrix.timeout":                LevelWarning,
+		"$.config.resilience_conf.lowerRankNum":                   LevelError,
+	}
+
+	report := CheckServiceContent("inferid-001", []byte(content))
+	t.Log(report)
+	if !report.HasError() {
+		t.Errorf("expected errors")
+	}
+
+	issues := make(map[string]string, len(report.Issues))
+	for _, issue := range report.Issues {
+		issues[issue.Path] = issue.Level
+	}
+	for path, level := range expected {
+		if issues[path] != level {
+			t.Errorf("expected %s at %s, got %q", level, path, issues[path])
+		}
+	}
+	if len(report.Issues) != len(expected) {
+		t.Errorf("expected %d issues, got %d", len(expected), len(report.Issues))
+	}
+}
+
+func TestCheckMalformedServiceContent(t *testing.T) {
+	report := CheckServiceContent("inferid-001", []byte(`{"config": {`))
+	if !report.HasError() || report.Issues[0].Path != "$" {
+		t.Errorf("malformed json should be reported at $, got:\n%s", report)
+	}
+
+	report = CheckServiceContent("inferid-001", []byte(`{}`))
+	if !report.HasError() || report.Issues[0].Path != "$.config" {
+		t.Errorf("missing config should be reported at $.config, got:\n%s", report)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"infer-microservices/pkg/config_loader/faiss_config"
 	"infer-microservices/pkg/config_loader/model_config"
 	"infer-microservices/pkg/config_loader/redis_config"
+	"infer-microservices/pkg/config_loader/resilience_config"
 )
 
 // config interface
@@ -37,3 +38,11 @@ func (r *ConfigFactory) createRedisConfig(dataId string, redisConfStr string) *r
 
 	return redisConfig
 }
+
+// resilience config factory
+func (r *ConfigFactory) createResilienceConfig(dataId string, resilienceConfStr string) *resilience_config.ResilienceConfig {
+	resilienceConfig := new(resilience_config.ResilienceConfig)
+	resilienceConfig.ConfigLoad(dataId, resilienceConfStr)
+
+	return resilienceConfig
+}
@@ -0,0 +1,485 @@
+package config_schema
+
+import (
+	"infer-microservices/internal"
+	redis_v8 "infer-microservices/internal/db/redis"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// defaults of the optional fields.
+const (
+	DefaultGrpcPoolSize     = 100
+	DefaultGrpcInitCap      = 5
+	DefaultGrpcDialTimeout  = 5 * time.Second
+	DefaultGrpcReadTimeout  = 5 * time.Second
+	DefaultGrpcWriteTimeout = 5 * time.Second
+	DefaultGrpcIdleTimeout  = 60 * time.Second
+	DefaultGrpcWaitTimeout  = internal.DefaultWaitTimeout
+
+	DefaultRedisDialTimeout  = 5 * time.Second
+	DefaultRedisReadTimeout  = 3 * time.Second
+	DefaultRedisWriteTimeout = 3 * time.Second
+	DefaultRedisIdleTimeout  = 5 * time.Minute
+	DefaultRedisMaxRetries   = 0 //the lookups are retried by internal/retry with a budget.
+	DefaultRedisMinIdleConns = 0
+
+	DefaultRecallNum = 100
+)
+
+const maxTimeout = time.Minute
+const maxIdleTimeout = 24 * time.Hour
+
+// NacosServiceConf a nacos naming service instead of addrs, its healthy instances are the targets.
+type NacosServiceConf struct {
+	ServiceName string
+	GroupName   string
+	Namespace   string
+	Clusters    []string
+}
+
+// GrpcConf tfservingGrpcAddr of model_conf, faissGrpcAddr of index_conf.
+type GrpcConf struct {
+	Addrs        []string
+	NacosService *NacosServiceConf //nil for the static addrs.
+	PoolSize     int
+	InitCap      int
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	WaitTimeout  time.Duration          //wait for a conn when pool_size conns are in use.
+	HealthCheck  internal.HealthOptions //health check and outlier ejection of the targets.
+	Balancer     internal.BalancerOptions
+	TLS          internal.TLSOptions //plaintext when tls is not set.
+	Hedging      internal.HedgeOptions
+}
+
+// RedisConf redis_conf.
+type RedisConf struct {
+	Addrs        []string
+	Password     string
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	MaxRetries   int
+	MinIdleConns int
+}
+
+// ModelConf the model of model_conf, one model per dataId.
+type ModelConf struct {
+	ModelName               string
+	TfservingModelName      string
+	TfservingGrpc           GrpcConf
+	UserRedisKeyPreOffline  string
+	UserRedisKeyPreRealtime string
+	ItemRedisKeyPre         string
+	Calibration             map[string]interface{} //nil if not calibrated, see calibration.NewCalibrator.
+	EmbeddingDim            int                    //user tower embedding dim, checked with the faiss index dim. 0 is not checked.
+	ItemEmbedding           *ItemEmbeddingConf     //the item embeddings of the similar items api, nil if not served.
+}
+
+type IndexInfo struct {
+	IndexName    string
+	RecallNum    int
+	Proportion   int               //the share of the request recallNum, relative to the other indexes. default is RecallNum.
+	Filters      []AttributeFilter //filters of the recall items, with the filters of the request.
+	MaxOverFetch int               //max recall num / recallNum, if the faiss server does not filter.
+	Engine       string            //faiss, or local for an in-process index.
+	Local        *LocalIndexConf   //the in-process index if the engine is local.
+}
+
+// IndexConf index_conf.
+type IndexConf struct {
+	FaissGrpc GrpcConf
+	Indexes   []IndexInfo
+}
+
+// HasFaissIndex some index is on the faiss server, faissGrpcAddr is needed.
+func (c *IndexConf) HasFaissIndex() bool {
+	for _, index := range c.Indexes {
+		if index.Engine != IndexEngineLocal {
+			return true
+		}
+	}
+
+	return len(c.Indexes) == 0
+}
+
+// ParseRedisConf redis_conf at path, such as $.config.redis_conf.
+func ParseRedisConf(redisConf map[string]interface{}, path string) (*RedisConf, Issues) {
+	d := newDecoder()
+	conf := &RedisConf{}
+
+	cluster, ok := d.object(redisConf, path, "redisCluster", true)
+	d.unknownKeys(redisConf, path)
+	if !ok {
+		return conf, d.issues
+	}
+	d.redisCluster(cluster, path+".redisCluster", conf)
+
+	return conf, d.issues
+}
+
+func (d *decoder) redisCluster(cluster map[string]interface{}, path string, conf *RedisConf) {
+	conf.Addrs = d.stringList(cluster, path, "addrs", true)
+	conf.Password = d.str(cluster, path, "password", false, "")
+	conf.DialTimeout = d.duration(cluster, path, "dialTimeout", DefaultRedisDialTimeout, time.Millisecond, maxTimeout)
+	conf.ReadTimeout = d.duration(cluster, path, "readTimeout", DefaultRedisReadTimeout, time.Millisecond, maxTimeout)
+	conf.WriteTimeout = d.duration(cluster, path, "writeTimeout", DefaultRedisWriteTimeout, time.Millisecond, maxTimeout)
+	conf.IdleTimeout = d.duration(cluster, path, "idleTimeout", DefaultRedisIdleTimeout, 0, maxIdleTimeout)
+	conf.MaxRetries = d.integer(cluster, path, "maxRetries", false, DefaultRedisMaxRetries, 0, 10)
+	conf.MinIdleConns = d.integer(cluster, path, "minIdleConns", false, DefaultRedisMinIdleConns, 0, 10000)
+	d.unknownKeys(cluster, path)
+}
+
+// ParseModelConf model_conf at path, such as $.config.model_conf. with many models, the first by name is used.
+func ParseModelConf(modelConf map[string]interface{}, path string) (*ModelConf, Issues) {
+	d := newDecoder()
+	conf := &ModelConf{}
+
+	if len(modelConf) == 0 {
+		d.errorf(path, "no model")
+		return conf, d.issues
+	}
+	modelNames := make([]string, 0, len(modelConf))
+	for modelName := range modelConf {
+		modelNames = append(modelNames, modelName)
+	}
+	sort.Strings(modelNames)
+	if len(modelNames) > 1 {
+		d.warnf(path, "%d models, only one model per dataId is supported, %s is used", len(modelNames), modelNames[0])
+	}
+
+	conf.ModelName = modelNames[0]
+	model, ok := d.object(modelConf, path, conf.ModelName, true)
+	if !ok {
+		return conf, d.issues
+	}
+	path += "." + conf.ModelName
+
+	if grpcConf, ok := d.object(model, path, "tfservingGrpcAddr", true); ok {
+		grpcPath := path + ".tfservingGrpcAddr"
+		conf.TfservingModelName = d.str(grpcConf, grpcPath, "tfservingModelName", true, "")
+		conf.TfservingGrpc = d.grpcConf(grpcConf, grpcPath)
+	}
+	conf.UserRedisKeyPreOffline = d.str(model, path, "userRedisKeyPreOffline", true, "")
+	conf.UserRedisKeyPreRealtime = d.str(model, path, "userRedisKeyPreRealtime", true, "")
+	conf.ItemRedisKeyPre = d.str(model, path, "itemRedisKeyPre", true, "")
+	conf.Calibration, _ = d.object(model, path, "calibration", false)
+	conf.EmbeddingDim = d.integer(model, path, "embeddingDim", false, 0, 1, 65536)
+	if embeddingConf, ok := d.object(model, path, "itemEmbedding", false); ok {
+		conf.ItemEmbedding = d.itemEmbedding(embeddingConf, path+".itemEmbedding")
+	}
+	d.ignore(path, "fieldsSpec")
+	d.unknownKeys(model, path)
+
+	return conf, d.issues
+}
+
+// ParseIndexConf index_conf at path, such as $.config.index_conf.
+func ParseIndexConf(indexConf map[string]interface{}, path string) (*IndexConf, Issues) {
+	d := newDecoder()
+	conf := &IndexConf{}
+
+	d.use(path, "indexInfo")
+	indexInfo, ok := indexConf["indexInfo"].([]interface{})
+	if !ok {
+		if _, exist := indexConf["indexInfo"]; exist {
+			d.errorf(path+".indexInfo", "should be an array")
+		} else {
+			d.errorf(path+".indexInfo", "missing")
+		}
+	} else if len(indexInfo) == 0 {
+		d.errorf(path+".indexInfo", "no index")
+	}
+
+	conf.Indexes = make([]IndexInfo, 0, len(indexInfo))
+	for idx, rawIndex := range indexInfo {
+		indexPath := path + ".indexInfo[" + strconv.Itoa(idx) + "]"
+		index, ok := rawIndex.(map[string]interface{})
+		if !ok {
+			d.errorf(indexPath, "should be an object")
+			continue
+		}
+
+		indexInfo := IndexInfo{
+			IndexName:    d.str(index, indexPath, "indexName", true, ""),
+			RecallNum:    d.integer(index, indexPath, "recallNum", false, DefaultRecallNum, 1, math.MaxInt32),
+			Proportion:   d.integer(index, indexPath, "proportion", false, 0, 1, math.MaxInt32),
+			Filters:      d.attributeFilters(index, indexPath, "filters"),
+			MaxOverFetch: d.integer(index, indexPath, "maxOverFetch", false, DefaultMaxOverFetch, 1, 20),
+			Engine:       d.str(index, indexPath, "engine", false, IndexEngineFaiss),
+		}
+		if indexInfo.Proportion == 0 {
+			indexInfo.Proportion = indexInfo.RecallNum
+		}
+		switch indexInfo.Engine {
+		case IndexEngineFaiss:
+			if _, ok := index["local"]; ok {
+				d.warnf(indexPath+".local", "ignored, the engine is faiss")
+			}
+			d.use(indexPath, "local")
+		case IndexEngineLocal:
+			if localConf, ok := d.object(index, indexPath, "local", true); ok {
+				indexInfo.Local = d.localIndex(localConf, indexPath+".local")
+			}
+		default:
+			d.errorf(indexPath+".engine", "%q, should be %s or %s", indexInfo.Engine, IndexEngineFaiss, IndexEngineLocal)
+		}
+		conf.Indexes = append(conf.Indexes, indexInfo)
+		d.unknownKeys(index, indexPath)
+	}
+
+	//the local indexes need no faiss server.
+	if grpcConf, ok := d.object(indexConf, path, "faissGrpcAddr", conf.HasFaissIndex()); ok {
+		conf.FaissGrpc = d.grpcConf(grpcConf, path+".faissGrpcAddr")
+	}
+	d.unknownKeys(indexConf, path)
+
+	return conf, d.issues
+}
+
+func (d *decoder) grpcConf(grpcConf map[string]interface{}, path string) GrpcConf {
+	conf := GrpcConf{}
+	if nacosService, ok := d.object(grpcConf, path, "nacosService", false); ok {
+		nacosPath := path + ".nacosService"
+		conf.NacosService = &NacosServiceConf{
+			ServiceName: d.str(nacosService, nacosPath, "serviceName", true, ""),
+			GroupName:   d.str(nacosService, nacosPath, "groupName", false, "DEFAULT_GROUP"),
+			Namespace:   d.str(nacosService, nacosPath, "namespace", false, ""),
+			Clusters:    d.stringList(nacosService, nacosPath, "clusters", false),
+		}
+		d.unknownKeys(nacosService, nacosPath)
+		if _, ok := grpcConf["addrs"]; ok {
+			d.warnf(path+".addrs", "ignored, the targets are from nacosService")
+		}
+		d.use(path, "addrs")
+	} else {
+		conf.Addrs = d.stringList(grpcConf, path, "addrs", true)
+	}
+	conf.PoolSize = d.integer(grpcConf, path, "pool_size", false, DefaultGrpcPoolSize, 1, 10000)
+	defaultInitCap := DefaultGrpcInitCap
+	if defaultInitCap > conf.PoolSize {
+		defaultInitCap = conf.PoolSize
+	}
+	conf.InitCap = d.integer(grpcConf, path, "initCap", false, defaultInitCap, 1, conf.PoolSize)
+	conf.DialTimeout = d.duration(grpcConf, path, "dialTimeout", DefaultGrpcDialTimeout, time.Millisecond, maxTimeout)
+	conf.ReadTimeout = d.duration(grpcConf, path, "readTimeout", DefaultGrpcReadTimeout, time.Millisecond, maxTimeout)
+	conf.WriteTimeout = d.duration(grpcConf, path, "writeTimeout", DefaultGrpcWriteTimeout, time.Millisecond, maxTimeout)
+	conf.IdleTimeout = d.duration(grpcConf, path, "idleTimeout", DefaultGrpcIdleTimeout, 0, maxIdleTimeout)
+	conf.WaitTimeout = d.duration(grpcConf, path, "waitTimeout", DefaultGrpcWaitTimeout, 0, maxTimeout)
+	conf.HealthCheck = d.healthCheck(grpcConf, path)
+	conf.Balancer = d.balancer(grpcConf, path)
+	conf.TLS = d.tls(grpcConf, path)
+	conf.Hedging = d.hedging(grpcConf, path)
+	if _, ok := grpcConf["timeout"]; ok {
+		d.use(path, "timeout")
+		d.warnf(path+".timeout", "not used, set readTimeout / writeTimeout instead")
+	}
+	d.unknownKeys(grpcConf, path)
+
+	return conf
+}
+
+// healthCheck of a grpc conf, optional. health check is enabled by default.
+func (d *decoder) healthCheck(grpcConf map[string]interface{}, path string) internal.HealthOptions {
+	conf := internal.NewHealthOptions()
+	healthCheck, ok := d.object(grpcConf, path, "healthCheck", false)
+	if !ok {
+		return conf
+	}
+
+	path = path + ".healthCheck"
+	conf.Disabled = !d.boolean(healthCheck, path, "enabled", true)
+	conf.Interval = d.duration(healthCheck, path, "interval", conf.Interval, 100*time.Millisecond, time.Hour)
+	conf.Timeout = d.duration(healthCheck, path, "timeout", conf.Timeout, time.Millisecond, maxTimeout)
+	conf.Service = d.str(healthCheck, path, "service", false, "")
+	conf.ConsecutiveErrors = d.integer(healthCheck, path, "consecutiveErrors", false, conf.ConsecutiveErrors, 0, 10000)
+	conf.LatencyThreshold = d.duration(healthCheck, path, "latencyThreshold", 0, 0, maxTimeout)
+	conf.BaseEjection = d.duration(healthCheck, path, "baseEjection", conf.BaseEjection, time.Millisecond, time.Hour)
+	conf.MaxEjection = d.duration(healthCheck, path, "maxEjection", conf.MaxEjection, conf.BaseEjection, 24*time.Hour)
+	conf.MaxEjectionPercent = d.integer(healthCheck, path, "maxEjectionPercent", false, conf.MaxEjectionPercent, 0, 100)
+	if conf.Timeout > conf.Interval {
+		d.warnf(path+".timeout", "%v is longer than the interval %v", conf.Timeout, conf.Interval)
+	}
+	d.unknownKeys(healthCheck, path)
+
+	return conf
+}
+
+// balancer of a grpc conf, optional. "round_robin" or {"strategy": "weighted", "weightKey": "weight"}.
+func (d *decoder) balancer(grpcConf map[string]interface{}, path string) internal.BalancerOptions {
+	conf := internal.BalancerOptions{Strategy: internal.BalancerRandom}
+	if strategy, ok := grpcConf["balancer"].(string); ok {
+		d.use(path, "balancer")
+		conf.Strategy = strategy
+	} else if balancer, ok := d.object(grpcConf, path, "balancer", false); ok {
+		balancerPath := path + ".balancer"
+		conf.Strategy = d.str(balancer, balancerPath, "strategy", true, internal.BalancerRandom)
+		conf.WeightKey = d.str(balancer, balancerPath, "weightKey", conf.Strategy == internal.BalancerWeighted, "")
+		conf.Replicas = d.integer(balancer, balancerPath, "replicas", false, 0, 1, 10000)
+		d.unknownKeys(balancer, balancerPath)
+	}
+
+	for _, strategy := range internal.Balancers {
+		if conf.Strategy == strategy {
+			return conf
+		}
+	}
+	d.errorf(path+".balancer", "unknown strategy %q, should be one of %v", conf.Strategy, internal.Balancers)
+
+	return internal.BalancerOptions{Strategy: internal.BalancerRandom}
+}
+
+// tls of a grpc conf, optional. {"caFile", "certFile", "keyFile", "serverName", "minVersion"}, {} uses the system roots.
+func (d *decoder) tls(grpcConf map[string]interface{}, path string) internal.TLSOptions {
+	tlsConf, ok := d.object(grpcConf, path, "tls", false)
+	if !ok {
+		return internal.TLSOptions{}
+	}
+
+	path = path + ".tls"
+	conf := internal.TLSOptions{
+		Enabled:    true,
+		CAFile:     d.str(tlsConf, path, "caFile", false, ""),
+		CertFile:   d.str(tlsConf, path, "certFile", false, ""),
+		KeyFile:    d.str(tlsConf, path, "keyFile", false, ""),
+		ServerName: d.str(tlsConf, path, "serverName", false, ""),
+		MinVersion: d.str(tlsConf, path, "minVersion", false, "1.2"),
+	}
+	if (conf.CertFile == "") != (conf.KeyFile == "") {
+		d.errorf(path, "certFile and keyFile should be set together")
+	}
+	if _, ok := internal.TLSVersions[conf.MinVersion]; !ok {
+		d.errorf(path+".minVersion", "unknown version %q, should be 1.0, 1.1, 1.2 or 1.3", conf.MinVersion)
+	} else if conf.MinVersion < "1.2" {
+		d.warnf(path+".minVersion", "tls %s is deprecated", conf.MinVersion)
+	}
+	d.unknownKeys(tlsConf, path)
+
+	return conf
+}
+
+// hedging of a grpc conf, optional. {"percentile": 95, "minDelay": "1ms", "maxDelay": "50ms"} enables it.
+func (d *decoder) hedging(grpcConf map[string]interface{}, path string) internal.HedgeOptions {
+	conf := internal.NewHedgeOptions()
+	hedging, ok := d.object(grpcConf, path, "hedging", false)
+	if !ok {
+		return conf
+	}
+
+	path = path + ".hedging"
+	conf.Enabled = d.boolean(hedging, path, "enabled", true)
+	conf.Percentile = float64(d.integer(hedging, path, "percentile", false, int(conf.Percentile), 50, 99))
+	conf.MinDelay = d.duration(hedging, path, "minDelay", conf.MinDelay, 0, maxTimeout)
+	conf.MaxDelay = d.duration(hedging, path, "maxDelay", conf.MaxDelay, conf.MinDelay, maxTimeout)
+	d.unknownKeys(hedging, path)
+
+	return conf
+}
+
+// Options grpc pool options of the conf, Discovery is set by the discovery of NacosService.
+func (g GrpcConf) Options() *internal.Options {
+	return &internal.Options{
+		InitTargets:  g.Addrs,
+		InitCap:      g.InitCap,
+		MaxCap:       g.PoolSize,
+		DialTimeout:  g.DialTimeout,
+		IdleTimeout:  g.IdleTimeout,
+		ReadTimeout:  g.ReadTimeout,
+		WriteTimeout: g.WriteTimeout,
+		WaitTimeout:  g.WaitTimeout,
+		Health:       g.HealthCheck,
+		Balancer:     g.Balancer,
+		TLS:          g.TLS,
+		Hedge:        g.Hedging,
+	}
+}
+
+// ClusterConf redis cluster client conf of the conf.
+func (r RedisConf) ClusterConf() redis_v8.ClusterConf {
+	return redis_v8.ClusterConf{
+		Addrs:        r.Addrs,
+		Password:     r.Password,
+		DialTimeout:  r.DialTimeout,
+		ReadTimeout:  r.ReadTimeout,
+		WriteTimeout: r.WriteTimeout,
+		IdleTimeout:  r.IdleTimeout,
+		MaxRetries:   r.MaxRetries,
+		MinIdleConns: r.MinIdleConns,
+	}
+}
+
+// stages of a request, each waits its downstreams at most the stage timeout.
+const (
+	StageFeature = "feature" //user / item features from redis.
+	StageRecall  = "recall"  //faiss recall.
+	StageRank    = "rank"    //tfserving predict.
+	StageFormat  = "format"  //collecting the result items.
+)
+
+var Stages = []string{StageFeature, StageRecall, StageRank, StageFormat}
+
+const DefaultDeadline = 100 * time.Millisecond
+const DefaultStageTimeout = 100 * time.Millisecond
+
+// HystrixConf the circuit breaker of a dataId, zero fields use the hystrix flags.
+type HystrixConf struct {
+	Timeout                time.Duration
+	MaxConcurrentRequests  int
+	RequestVolumeThreshold int
+	SleepWindow            time.Duration
+	ErrorPercentThreshold  int
+}
+
+// ResilienceConf resilience_conf, optional. zero fields of the rank stage, hystrix and lower nums use the flags.
+type ResilienceConf struct {
+	Deadline       time.Duration            //end-to-end deadline of a request.
+	StageTimeouts  map[string]time.Duration //stage -> timeout.
+	Hystrix        HystrixConf
+	LowerRecallNum int //recall num of the degraded request.
+	LowerRankNum   int //rank item num of the degraded request.
+}
+
+// ParseResilienceConf resilience_conf at path, such as $.config.resilience_conf. nil resilienceConf gives the defaults.
+func ParseResilienceConf(resilienceConf map[string]interface{}, path string) (*ResilienceConf, Issues) {
+	d := newDecoder()
+	conf := &ResilienceConf{
+		Deadline:      DefaultDeadline,
+		StageTimeouts: make(map[string]time.Duration, len(Stages)),
+	}
+	for _, stage := range Stages {
+		conf.StageTimeouts[stage] = DefaultStageTimeout
+	}
+	conf.StageTimeouts[StageRank] = 0
+	if resilienceConf == nil {
+		return conf, d.issues
+	}
+
+	conf.Deadline = d.duration(resilienceConf, path, "deadline", DefaultDeadline, time.Millisecond, maxTimeout)
+	if stageTimeouts, ok := d.object(resilienceConf, path, "stageTimeouts", false); ok {
+		stagePath := path + ".stageTimeouts"
+		for _, stage := range Stages {
+			conf.StageTimeouts[stage] = d.duration(stageTimeouts, stagePath, stage, conf.StageTimeouts[stage], time.Millisecond, maxTimeout)
+		}
+		d.unknownKeys(stageTimeouts, stagePath)
+	}
+	if hystrixConf, ok := d.object(resilienceConf, path, "hystrix", false); ok {
+		hystrixPath := path + ".hystrix"
+		conf.Hystrix.Timeout = d.duration(hystrixConf, hystrixPath, "timeout", 0, time.Millisecond, maxTimeout)
+		conf.Hystrix.MaxConcurrentRequests = d.integer(hystrixConf, hystrixPath, "maxConcurrentRequests", false, 0, 1, 1000000)
+		conf.Hystrix.RequestVolumeThreshold = d.integer(hystrixConf, hystrixPath, "requestVolumeThreshold", false, 0, 1, 10000000)
+		conf.Hystrix.SleepWindow = d.duration(hystrixConf, hystrixPath, "sleepWindow", 0, time.Millisecond, time.Hour)
+		conf.Hystrix.ErrorPercentThreshold = d.integer(hystrixConf, hystrixPath, "errorPercentThreshold", false, 0, 1, 100)
+		d.unknownKeys(hystrixConf, hystrixPath)
+	}
+	conf.LowerRecallNum = d.integer(resilienceConf, path, "lowerRecallNum", false, 0, 1, math.MaxInt32)
+	conf.LowerRankNum = d.integer(resilienceConf, path, "lowerRankNum", false, 0, 1, math.MaxInt32)
+	d.unknownKeys(resilienceConf, path)
+
+	return conf, d.issues
+}
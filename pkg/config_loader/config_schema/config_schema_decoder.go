@@ -0,0 +1,304 @@
+package config_schema
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Issue a field which can not be loaded (error) or is ignored (warning), path is the json path in the conf.
+type Issue struct {
+	Path    string
+	Warning bool
+	Message string
+}
+
+type Issues []Issue
+
+// Err all errors in one, nil if there is only warnings.
+func (s Issues) Err() error {
+	messages := make([]string, 0)
+	for _, issue := range s {
+		if !issue.Warning {
+			messages = append(messages, issue.Path+": "+issue.Message)
+		}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	return errors.New(strings.Join(messages, "; "))
+}
+
+func (s Issues) Warnings() []string {
+	warnings := make([]string, 0)
+	for _, issue := range s {
+		if issue.Warning {
+			warnings = append(warnings, issue.Path+": "+issue.Message)
+		}
+	}
+
+	return warnings
+}
+
+// Has an issue at path.
+func (s Issues) Has(path string) bool {
+	for _, issue := range s {
+		if issue.Path == path {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decoder read typed fields from a json object, and records the keys read, the others are unknown keys.
+type decoder struct {
+	issues Issues
+	used   map[string]map[string]bool //path -> keys read.
+}
+
+func newDecoder() *decoder {
+	return &decoder{
+		issues: make(Issues, 0),
+		used:   make(map[string]map[string]bool, 0),
+	}
+}
+
+func (d *decoder) errorf(path string, format string, args ...interface{}) {
+	d.issues = append(d.issues, Issue{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (d *decoder) warnf(path string, format string, args ...interface{}) {
+	d.issues = append(d.issues, Issue{Path: path, Warning: true, Message: fmt.Sprintf(format, args...)})
+}
+
+func (d *decoder) use(path string, key string) {
+	if _, ok := d.used[path]; !ok {
+		d.used[path] = make(map[string]bool, 0)
+	}
+	d.used[path][key] = true
+}
+
+// mark keys which are known but not loaded, such as comments.
+func (d *decoder) ignore(path string, keys ...string) {
+	for _, key := range keys {
+		d.use(path, key)
+	}
+}
+
+// warn the keys of obj which were not read, a key which looks like a known one is pointed out.
+func (d *decoder) unknownKeys(obj map[string]interface{}, path string) {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if d.used[path][key] {
+			continue
+		}
+		if similar := d.similarKey(path, key); similar != "" {
+			d.warnf(path+"."+key, "unknown key, ignored, did you mean %s", similar)
+			continue
+		}
+		d.warnf(path+"."+key, "unknown key, ignored")
+	}
+}
+
+func (d *decoder) similarKey(path string, key string) string {
+	for known := range d.used[path] {
+		if normalizeKey(known) == normalizeKey(key) {
+			return known
+		}
+	}
+
+	return ""
+}
+
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.ReplaceAll(key, "_", ""), "-", ""))
+}
+
+func (d *decoder) object(parent map[string]interface{}, path string, key string, required bool) (map[string]interface{}, bool) {
+	d.use(path, key)
+	raw, exist := parent[key]
+	if !exist {
+		if required {
+			d.errorf(path+"."+key, "missing")
+		}
+		return nil, false
+	}
+
+	v, ok := raw.(map[string]interface{})
+	if !ok {
+		d.errorf(path+"."+key, "should be an object")
+	}
+
+	return v, ok
+}
+
+func (d *decoder) str(parent map[string]interface{}, path string, key string, required bool, def string) string {
+	d.use(path, key)
+	raw, exist := parent[key]
+	if !exist {
+		if required {
+			d.errorf(path+"."+key, "missing")
+		}
+		return def
+	}
+
+	v, ok := raw.(string)
+	if !ok {
+		d.errorf(path+"."+key, "should be a string")
+		return def
+	}
+
+	return v
+}
+
+func (d *decoder) boolean(parent map[string]interface{}, path string, key string, def bool) bool {
+	d.use(path, key)
+	raw, exist := parent[key]
+	if !exist {
+		return def
+	}
+
+	v, ok := raw.(bool)
+	if !ok {
+		d.errorf(path+"."+key, "should be true or false")
+		return def
+	}
+
+	return v
+}
+
+func (d *decoder) stringList(parent map[string]interface{}, path string, key string, required bool) []string {
+	d.use(path, key)
+	raw, exist := parent[key]
+	if !exist {
+		if required {
+			d.errorf(path+"."+key, "missing")
+		}
+		return nil
+	}
+
+	rawList, ok := raw.([]interface{})
+	if !ok {
+		d.errorf(path+"."+key, "should be a string array")
+		return nil
+	}
+
+	values := make([]string, 0, len(rawList))
+	for idx, raw := range rawList {
+		v, ok := raw.(string)
+		if !ok {
+			d.errorf(path+"."+key+"["+strconv.Itoa(idx)+"]", "should be a string")
+			return nil
+		}
+		values = append(values, v)
+	}
+
+	return values
+}
+
+// integer may be a number or a number string, which is warned.
+func (d *decoder) integer(parent map[string]interface{}, path string, key string, required bool, def int, min int, max int) int {
+	d.use(path, key)
+	raw, exist := parent[key]
+	if !exist {
+		if required {
+			d.errorf(path+"."+key, "missing")
+		}
+		return def
+	}
+
+	var v float64
+	switch raw_ := raw.(type) {
+	case float64:
+		v = raw_
+	case string:
+		parsed, err := strconv.ParseFloat(raw_, 64)
+		if err != nil {
+			d.errorf(path+"."+key, "%q is not a number", raw_)
+			return def
+		}
+		d.warnf(path+"."+key, "should be a number, not a string")
+		v = parsed
+	default:
+		d.errorf(path+"."+key, "should be a number")
+		return def
+	}
+
+	if v != float64(int64(v)) {
+		d.errorf(path+"."+key, "should be an integer, got %v", v)
+		return def
+	}
+	if v < float64(min) || v > float64(max) {
+		d.errorf(path+"."+key, "%v out of range [%v, %v]", v, min, max)
+		return def
+	}
+
+	return int(v)
+}
+
+// duration of the field base, it may be set by one of the keys:
+// base: "100ms" / "5s", or a number in ms.
+// baseMs / baseS: a number in the unit of the suffix, or a duration string.
+func (d *decoder) duration(parent map[string]interface{}, path string, base string, def time.Duration, min time.Duration, max time.Duration) time.Duration {
+	units := []struct {
+		key  string
+		unit time.Duration
+	}{
+		{base, time.Millisecond},
+		{base + "Ms", time.Millisecond},
+		{base + "S", time.Second},
+	}
+
+	found := ""
+	v := def
+	for _, u := range units {
+		d.use(path, u.key)
+		raw, exist := parent[u.key]
+		if !exist {
+			continue
+		}
+		if found != "" {
+			d.errorf(path+"."+u.key, "conflicts with %s, set only one of them", found)
+			continue
+		}
+		found = u.key
+
+		parsed, err := parseDuration(raw, u.unit)
+		if err != nil {
+			d.errorf(path+"."+u.key, "%v", err)
+			continue
+		}
+		if parsed < min || parsed > max {
+			d.errorf(path+"."+u.key, "%v out of range [%v, %v]", parsed, min, max)
+			continue
+		}
+		v = parsed
+	}
+
+	return v
+}
+
+func parseDuration(raw interface{}, unit time.Duration) (time.Duration, error) {
+	switch v := raw.(type) {
+	case float64:
+		return time.Duration(v * float64(unit)), nil
+	case string:
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a duration, such as \"100ms\" or \"5s\"", v)
+		}
+		return parsed, nil
+	default:
+		return 0, errors.New("should be a number or a duration string")
+	}
+}
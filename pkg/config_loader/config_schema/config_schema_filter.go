@@ -0,0 +1,102 @@
+package config_schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ops of the attribute filters, lt / le / gt / ge compare the values as numbers.
+const (
+	FilterEq    = "eq"
+	FilterNe    = "ne"
+	FilterIn    = "in"
+	FilterNotIn = "not_in"
+	FilterLt    = "lt"
+	FilterLe    = "le"
+	FilterGt    = "gt"
+	FilterGe    = "ge"
+)
+
+var FilterOps = []string{FilterEq, FilterNe, FilterIn, FilterNotIn, FilterLt, FilterLe, FilterGt, FilterGe}
+
+const DefaultMaxOverFetch = 4 //recall at most 4x recallNum to make up the post-filtered items.
+
+// AttributeFilter an attribute predicate of the recall items, such as {"attribute": "region", "op": "in", "values": ["us", "ca"]}.
+// a value "${key}" of an index rule is the key of the request context, the rule is skipped if the request has no such key.
+type AttributeFilter struct {
+	Attribute string   `json:"attribute"`
+	Op        string   `json:"op"`
+	Values    []string `json:"values"`
+}
+
+// Check the op is known, and the values fit it.
+func (f AttributeFilter) Check() error {
+	if f.Attribute == "" {
+		return fmt.Errorf("filter attribute can not be empty")
+	}
+
+	switch f.Op {
+	case FilterIn, FilterNotIn:
+		if len(f.Values) == 0 {
+			return fmt.Errorf("filter %s %s needs values", f.Attribute, f.Op)
+		}
+	case FilterEq, FilterNe:
+		if len(f.Values) != 1 {
+			return fmt.Errorf("filter %s %s needs one value", f.Attribute, f.Op)
+		}
+	case FilterLt, FilterLe, FilterGt, FilterGe:
+		if len(f.Values) != 1 {
+			return fmt.Errorf("filter %s %s needs one value", f.Attribute, f.Op)
+		}
+		if _, err := strconv.ParseFloat(f.Values[0], 64); err != nil && !strings.HasPrefix(f.Values[0], "${") {
+			return fmt.Errorf("filter %s %s needs a number, got %q", f.Attribute, f.Op, f.Values[0])
+		}
+	default:
+		return fmt.Errorf("filter %s op %q, should be one of %s", f.Attribute, f.Op, strings.Join(FilterOps, ", "))
+	}
+
+	return nil
+}
+
+// filters of an index, the rules of the business such as in stock or the region of the request.
+func (d *decoder) attributeFilters(parent map[string]interface{}, path string, key string) []AttributeFilter {
+	d.use(path, key)
+	raw, exist := parent[key]
+	if !exist {
+		return nil
+	}
+
+	rawList, ok := raw.([]interface{})
+	if !ok {
+		d.errorf(path+"."+key, "should be an array")
+		return nil
+	}
+
+	filters := make([]AttributeFilter, 0, len(rawList))
+	for idx, rawFilter := range rawList {
+		filterPath := path + "." + key + "[" + strconv.Itoa(idx) + "]"
+		filterConf, ok := rawFilter.(map[string]interface{})
+		if !ok {
+			d.errorf(filterPath, "should be an object")
+			continue
+		}
+
+		filter := AttributeFilter{
+			Attribute: d.str(filterConf, filterPath, "attribute", true, ""),
+			Op:        d.str(filterConf, filterPath, "op", true, ""),
+			Values:    d.stringList(filterConf, filterPath, "values", true),
+		}
+		d.unknownKeys(filterConf, filterPath)
+		if filter.Attribute == "" || filter.Op == "" {
+			continue
+		}
+		if err := filter.Check(); err != nil {
+			d.errorf(filterPath, "%s", err)
+			continue
+		}
+		filters = append(filters, filter)
+	}
+
+	return filters
+}
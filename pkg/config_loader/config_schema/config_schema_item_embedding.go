@@ -0,0 +1,39 @@
+package config_schema
+
+import "strings"
+
+// sources of the item embeddings of the similar items api.
+const (
+	ItemEmbeddingRedis     = "redis"     //a json array at redisKeyPre + itemId of the service redis.
+	ItemEmbeddingTfserving = "tfserving" //the item tower of the tfserving model, fed by the item features.
+)
+
+var ItemEmbeddingSources = []string{ItemEmbeddingRedis, ItemEmbeddingTfserving}
+
+const DefaultItemEmbeddingTensorName = "item_embedding"
+
+// ItemEmbeddingConf where the similar items api gets the embedding of an item, such as
+// {"source": "redis", "redisKeyPre": "item_em_"} or {"source": "tfserving", "tensorName": "item_embedding"}.
+type ItemEmbeddingConf struct {
+	Source      string
+	RedisKeyPre string
+	TensorName  string
+}
+
+func (d *decoder) itemEmbedding(embeddingConf map[string]interface{}, path string) *ItemEmbeddingConf {
+	conf := &ItemEmbeddingConf{
+		Source: d.str(embeddingConf, path, "source", true, ""),
+	}
+	switch conf.Source {
+	case ItemEmbeddingRedis:
+		conf.RedisKeyPre = d.str(embeddingConf, path, "redisKeyPre", true, "")
+	case ItemEmbeddingTfserving:
+		conf.TensorName = d.str(embeddingConf, path, "tensorName", false, DefaultItemEmbeddingTensorName)
+	case "":
+	default:
+		d.errorf(path+".source", "%q, should be one of %s", conf.Source, strings.Join(ItemEmbeddingSources, ", "))
+	}
+	d.unknownKeys(embeddingConf, path)
+
+	return conf
+}
@@ -0,0 +1,71 @@
+package config_schema
+
+import (
+	"infer-microservices/pkg/ann"
+	"strings"
+	"time"
+)
+
+// engines of an index.
+const (
+	IndexEngineFaiss = "faiss" //the remote faiss server, the default.
+	IndexEngineLocal = "local" //an in-process index of pkg/ann.
+)
+
+// LocalIndexConf the in-process index of an index, its items are loaded from a file or a redis hash snapshot.
+type LocalIndexConf struct {
+	Options        ann.Options
+	File           string
+	Redis          *RedisConf //the redis cluster of the snapshot, nil if loaded from the file.
+	RedisKey       string
+	ReloadInterval time.Duration //the version of the source is checked every interval, a new version is swapped in.
+}
+
+func (d *decoder) localIndex(localConf map[string]interface{}, path string) *LocalIndexConf {
+	defaults := ann.NewOptions()
+	conf := &LocalIndexConf{
+		Options: ann.Options{
+			Type:           d.str(localConf, path, "type", false, defaults.Type),
+			Metric:         d.str(localConf, path, "metric", false, defaults.Metric),
+			M:              d.integer(localConf, path, "m", false, defaults.M, 2, 128),
+			EfConstruction: d.integer(localConf, path, "efConstruction", false, defaults.EfConstruction, 1, 4096),
+			EfSearch:       d.integer(localConf, path, "efSearch", false, defaults.EfSearch, 1, 4096),
+		},
+		File:           d.str(localConf, path, "file", false, ""),
+		ReloadInterval: d.duration(localConf, path, "reloadInterval", ann.DefaultReloadInterval, time.Second, 24*time.Hour),
+	}
+	if !contains(ann.IndexTypes, conf.Options.Type) {
+		d.errorf(path+".type", "%q, should be one of %s", conf.Options.Type, strings.Join(ann.IndexTypes, ", "))
+	}
+	if !contains(ann.Metrics, conf.Options.Metric) {
+		d.errorf(path+".metric", "%q, should be one of %s", conf.Options.Metric, strings.Join(ann.Metrics, ", "))
+	}
+
+	if redisConf, ok := d.object(localConf, path, "redis", false); ok {
+		redisPath := path + ".redis"
+		conf.RedisKey = d.str(redisConf, redisPath, "key", true, "")
+		if cluster, ok := d.object(redisConf, redisPath, "redisCluster", true); ok {
+			conf.Redis = &RedisConf{}
+			d.redisCluster(cluster, redisPath+".redisCluster", conf.Redis)
+		}
+		d.unknownKeys(redisConf, redisPath)
+	}
+	if conf.File == "" && conf.Redis == nil {
+		d.errorf(path, "file or redis is needed")
+	} else if conf.File != "" && conf.Redis != nil {
+		d.errorf(path, "file and redis are both set, one of them is needed")
+	}
+	d.unknownKeys(localConf, path)
+
+	return conf
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
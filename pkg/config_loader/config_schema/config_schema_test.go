@@ -0,0 +1,231 @@
+package config_schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func parseJson(t *testing.T, content string) map[string]interface{} {
+	conf := make(map[string]interface{}, 0)
+	err := json.Unmarshal([]byte(content), &conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conf
+}
+
+func TestParseGrpcConfDurations(t *testing.T) {
+	conf := parseJson(t, `{
+		"faissGrpcAddr": {
+			"addrs": ["127.0.0.1:9000"],
+			"idleTimeoutMs": 100,
+			"dialTimeoutS": 2,
+			"readTimeout": "150ms",
+			"writeTimeoutMs": "1s"
+		},
+		"indexInfo": [{"indexName": "index-001", "recallNum": 300}, {"indexName": "index-002"}]
+	}`)
+
+	indexConf, issues := ParseIndexConf(conf, "$.index_conf")
+	if issues.Err() != nil {
+		t.Fatal(issues.Err())
+	}
+
+	grpcConf := indexConf.FaissGrpc
+	if grpcConf.IdleTimeout != 100*time.Millisecond || grpcConf.DialTimeout != 2*time.Second ||
+		grpcConf.ReadTimeout != 150*time.Millisecond || grpcConf.WriteTimeout != time.Second {
+		t.Errorf("durations not coerced: %+v", grpcConf)
+	}
+	if grpcConf.PoolSize != DefaultGrpcPoolSize || grpcConf.InitCap != DefaultGrpcInitCap {
+		t.Errorf("defaults not set: %+v", grpcConf)
+	}
+	if indexConf.Indexes[0].RecallNum != 300 || indexConf.Indexes[1].RecallNum != DefaultRecallNum {
+		t.Errorf("recallNum not loaded: %+v", indexConf.Indexes)
+	}
+}
+
+func TestParseRedisConfIssues(t *testing.T) {
+	conf := parseJson(t, `{
+		"redisCluster": {
+			"addrs": ["127.0.0.1:6379"],
+			"readTimeoutMs": 100,
+			"readTimeout": "100ms",
+			"dialTimeout": "5 seconds",
+			"max_retries": 2
+		}
+	}`)
+
+	_, issues := ParseRedisConf(conf, "$.redis_conf")
+	t.Log(issues)
+
+	expected := map[string]bool{
+		"$.redis_conf.redisCluster.readTimeoutMs": false,
+		"$.redis_conf.redisCluster.dialTimeout":   false,
+		"$.redis_conf.redisCluster.max_retries":   true,
+	}
+	for path, warning := range expected {
+		found := false
+		for _, issue := range issues {
+			if issue.Path == path && issue.Warning == warning {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected issue at %s, warning %v", path, warning)
+		}
+	}
+	if len(issues) != len(expected) {
+		t.Errorf("expected %d issues, got %d", len(expected), len(issues))
+	}
+}
+
+func TestParseModelConfMissing(t *testing.T) {
+	conf := parseJson(t, `{"model-001": {"tfservingGrpcAddr": {"addrs": []}}}`)
+
+	_, issues := ParseModelConf(conf, "$.model_conf")
+	if issues.Err() == nil {
+		t.Errorf("missing tfservingModelName and redis key prefixes should be errors")
+	}
+	t.Log(issues.Err())
+}
+
+func TestParseModelConfItemEmbedding(t *testing.T) {
+	model := `{"model-001": {
+		"tfservingGrpcAddr": {"tfservingModelName": "models", "addrs": ["127.0.0.1:8500"]},
+		"userRedisKeyPreOffline": "u_off_", "userRedisKeyPreRealtime": "u_rt_", "itemRedisKeyPre": "i_",
+		"itemEmbedding": %s
+	}}`
+
+	modelConf, issues := ParseModelConf(parseJson(t, fmt.Sprintf(model, `{"source": "tfserving"}`)), "$.model_conf")
+	if issues.Err() != nil {
+		t.Fatal(issues.Err())
+	}
+	if modelConf.ItemEmbedding.TensorName != DefaultItemEmbeddingTensorName {
+		t.Errorf("default tensor name not set: %+v", modelConf.ItemEmbedding)
+	}
+
+	_, issues = ParseModelConf(parseJson(t, fmt.Sprintf(model, `{"source": "redis"}`)), "$.model_conf")
+	if !issues.Has("$.model_conf.model-001.itemEmbedding.redisKeyPre") {
+		t.Errorf("redis source without redisKeyPre should be an error")
+	}
+	_, issues = ParseModelConf(parseJson(t, fmt.Sprintf(model, `{"source": "hbase"}`)), "$.model_conf")
+	if !issues.Has("$.model_conf.model-001.itemEmbedding.source") {
+		t.Errorf("unknown source should be an error")
+	}
+}
+
+func TestParseGrpcConfNacosService(t *testing.T) {
+	conf := parseJson(t, `{
+		"faissGrpcAddr": {
+			"nacosService": {"serviceName": "faiss-server", "clusters": ["bj"]},
+			"addrs": ["127.0.0.1:9000"]
+		},
+		"indexInfo": [{"indexName": "index-001"}]
+	}`)
+
+	indexConf, issues := ParseIndexConf(conf, "$.index_conf")
+	if issues.Err() != nil {
+		t.Fatal(issues.Err())
+	}
+
+	nacosService := indexConf.FaissGrpc.NacosService
+	if nacosService == nil || nacosService.ServiceName != "faiss-server" || nacosService.GroupName != "DEFAULT_GROUP" {
+		t.Errorf("nacosService not loaded: %+v", nacosService)
+	}
+	if indexConf.FaissGrpc.Addrs != nil || !issues.Has("$.index_conf.faissGrpcAddr.addrs") {
+		t.Errorf("addrs should be ignored with a warning when nacosService is set")
+	}
+}
+
+func TestParseGrpcConfBalancer(t *testing.T) {
+	conf := parseJson(t, `{
+		"faissGrpcAddr": {
+			"addrs": ["127.0.0.1:9000"],
+			"balancer": {"strategy": "weighted", "weightKey": "cpu"}
+		},
+		"indexInfo": [{"indexName": "index-001"}]
+	}`)
+
+	indexConf, issues := ParseIndexConf(conf, "$.index_conf")
+	if issues.Err() != nil {
+		t.Fatal(issues.Err())
+	}
+	if indexConf.FaissGrpc.Balancer.Strategy != "weighted" || indexConf.FaissGrpc.Balancer.WeightKey != "cpu" {
+		t.Errorf("balancer not loaded: %+v", indexConf.FaissGrpc.Balancer)
+	}
+
+	conf = parseJson(t, `{
+		"faissGrpcAddr": {"addrs": ["127.0.0.1:9000"], "balancer": "fastest"},
+		"indexInfo": [{"indexName": "index-001"}]
+	}`)
+	_, issues = ParseIndexConf(conf, "$.index_conf")
+	if !issues.Has("$.index_conf.faissGrpcAddr.balancer") || issues.Err() == nil {
+		t.Errorf("unknown strategy should be an error")
+	}
+}
+
+func TestParseIndexConfFilters(t *testing.T) {
+	conf := parseJson(t, `{
+		"faissGrpcAddr": {"addrs": ["127.0.0.1:9000"]},
+		"indexInfo": [{
+			"indexName": "index-001",
+			"filters": [
+				{"attribute": "stock", "op": "gt", "values": ["0"]},
+				{"attribute": "region", "op": "eq", "values": ["${region}"]}
+			],
+			"maxOverFetch": 8
+		}]
+	}`)
+
+	indexConf, issues := ParseIndexConf(conf, "$.index_conf")
+	if issues.Err() != nil {
+		t.Fatal(issues.Err())
+	}
+	index := indexConf.Indexes[0]
+	if len(index.Filters) != 2 || index.Filters[1].Values[0] != "${region}" || index.MaxOverFetch != 8 {
+		t.Errorf("filters not loaded: %+v", index)
+	}
+	if index.Proportion != index.RecallNum {
+		t.Errorf("proportion should default to recallNum: %+v", index)
+	}
+
+	conf = parseJson(t, `{
+		"faissGrpcAddr": {"addrs": ["127.0.0.1:9000"]},
+		"indexInfo": [{"indexName": "index-001", "filters": [{"attribute": "stock", "op": "between", "values": ["0"]}]}]
+	}`)
+	_, issues = ParseIndexConf(conf, "$.index_conf")
+	if !issues.Has("$.index_conf.indexInfo[0].filters[0]") || issues.Err() == nil {
+		t.Errorf("unknown op should be an error")
+	}
+}
+
+func TestParseIndexConfLocal(t *testing.T) {
+	conf := parseJson(t, `{
+		"indexInfo": [{
+			"indexName": "index-001",
+			"engine": "local",
+			"local": {"type": "hnsw", "metric": "l2", "file": "/data/items.jsonl", "reloadInterval": "5m"}
+		}]
+	}`)
+
+	indexConf, issues := ParseIndexConf(conf, "$.index_conf")
+	if issues.Err() != nil {
+		t.Fatal(issues.Err())
+	}
+	local := indexConf.Indexes[0].Local
+	if local == nil || local.Options.Type != "hnsw" || local.Options.Metric != "l2" || local.ReloadInterval != 5*time.Minute {
+		t.Errorf("local index not loaded: %+v", local)
+	}
+
+	//a faiss index needs faissGrpcAddr, a local index needs file or redis.
+	conf = parseJson(t, `{
+		"indexInfo": [{"indexName": "index-001"}, {"indexName": "index-002", "engine": "local", "local": {"type": "hnsw"}}]
+	}`)
+	_, issues = ParseIndexConf(conf, "$.index_conf")
+	if !issues.Has("$.index_conf.faissGrpcAddr") || !issues.Has("$.index_conf.indexInfo[1].local") {
+		t.Errorf("missing faissGrpcAddr and local source should be errors: %v", issues.Err())
+	}
+}
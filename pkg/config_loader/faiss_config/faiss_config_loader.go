@@ -2,15 +2,19 @@ package faiss_config
 
 import (
 	"infer-microservices/internal"
+	redis_v8 "infer-microservices/internal/db/redis"
 	faiss_index "infer-microservices/internal/faiss_gogofaster"
 	"infer-microservices/internal/logs"
 	"infer-microservices/internal/utils"
-	"reflect"
-	"strconv"
+	"infer-microservices/pkg/ann"
+	"infer-microservices/pkg/config_loader/config_schema"
+	"infer-microservices/pkg/discovery"
+	"time"
 )
 
 type FaissIndexConfigs struct {
 	faissIndexConfigs []FaissIndexConfig
+	indexInfo         *indexInfo //metadata from the faiss server, shared by the copies.
 }
 
 func (f *FaissIndexConfigs) SetFaissIndexConfig(faissIndexConfigs []FaissIndexConfig) {
@@ -22,10 +26,16 @@ func (f *FaissIndexConfigs) GetFaissIndexConfig() []FaissIndexConfig {
 }
 
 type FaissIndexConfig struct {
-	indexName     string                     `validate:"required,unique,min=4,max=10"` //index name.
-	faissGrpcPool *internal.GRPCPool         `validate:"required"`                     //faiss  grpc pool.
-	faissIndexs   *faiss_index.RecallRequest `validate:"required"`                     // faiss index.
-	recallNum     int                        `validate:"required"`                     // faiss recall num.
+	indexName     string                          `validate:"required,unique,min=4,max=10"` //index name.
+	faissGrpcPool *internal.GRPCPool              `validate:"required"`                     //faiss  grpc pool.
+	faissIndexs   *faiss_index.RecallRequest      `validate:"required"`                     // faiss index.
+	recallNum     int                             `validate:"required"`                     // faiss recall num.
+	proportion    int                             //the share of the request recall num.
+	indexInfo     *indexInfo                      //metadata of all indexes, shared with FaissIndexConfigs.
+	filters       []config_schema.AttributeFilter //filters of the recall items.
+	maxOverFetch  int                             //max recall num / recallNum to make up the post-filtered items.
+	overFetch     *overFetch                      //pass ratio of the post-filter, shared by the copies.
+	localIndex    *ann.Engine                     //the in-process index if the engine is local, nil for faiss.
 }
 
 // index name
@@ -64,60 +74,147 @@ func (f *FaissIndexConfig) GetRecallNum() int {
 	return f.recallNum
 }
 
+// proportion
+func (f *FaissIndexConfig) setProportion(proportion int) {
+	f.proportion = proportion
+}
+
+func (f *FaissIndexConfig) GetProportion() int {
+	return f.proportion
+}
+
+// filters
+func (f *FaissIndexConfig) setFilters(filters []config_schema.AttributeFilter) {
+	f.filters = filters
+}
+
+func (f *FaissIndexConfig) GetFilters() []config_schema.AttributeFilter {
+	return f.filters
+}
+
+// max over fetch
+func (f *FaissIndexConfig) setMaxOverFetch(maxOverFetch int) {
+	f.maxOverFetch = maxOverFetch
+}
+
+func (f *FaissIndexConfig) GetMaxOverFetch() int {
+	return f.maxOverFetch
+}
+
+// local index
+func (f *FaissIndexConfig) setLocalIndex(localIndex *ann.Engine) {
+	f.localIndex = localIndex
+}
+
+func (f *FaissIndexConfig) GetLocalIndex() *ann.Engine {
+	return f.localIndex
+}
+
 // @implement ConfigLoadInterface
 func (f *FaissIndexConfigs) ConfigLoad(dataId string, indexConfStr string) error {
 	dataConf := utils.ConvertJsonToStruct(indexConfStr)
-
-	faissIndexConfigs := make([]FaissIndexConfig, 0)
-	// create faiss grpc pool
-	faissGrpcConf := dataConf["faissGrpcAddr"].(map[string]interface{})
-	faissGrpcPool, err := internal.CreateGrpcConn(faissGrpcConf)
-	if err != nil {
+	indexConf, issues := config_schema.ParseIndexConf(dataConf, "index_conf")
+	for _, warning := range issues.Warnings() {
+		logs.Warn(dataId, time.Now(), warning)
+	}
+	if err := issues.Err(); err != nil {
 		return err
 	}
 
-	//INFO:the recallNum param from http request,maybe int/ float /string。 user reflect to convert to int32.
+	// create faiss grpc pool, not needed if all indexes are local.
+	var faissGrpcPool *internal.GRPCPool
+	var err error
+	if indexConf.HasFaissIndex() {
+		faissGrpcPool, err = discovery.AcquireGrpcPool(indexConf.FaissGrpc)
+		if err != nil {
+			return err
+		}
+	}
+
 	//INFO:Processing multiple recalls simultaneously to save network overhead
-	recallNum := int32(100)
-	indexInfo := dataConf["indexInfo"].([]interface{})
-	for _, tmpIndexConf := range indexInfo {
-		tmpIndexConfMap := tmpIndexConf.(map[string]interface{})
+	indexInfo := newIndexInfo()
+	faissIndexConfigs := make([]FaissIndexConfig, 0, len(indexConf.Indexes))
+	for _, index := range indexConf.Indexes {
 		faissIndexConfig := FaissIndexConfig{}
-
-		//recallNum := int32(tmpIndexConfMap["recall_num"].(float64))
-		recallNumType := reflect.TypeOf(tmpIndexConfMap["recallNum"])
-		recallNumTypeKind := recallNumType.Kind()
-		switch recallNumTypeKind {
-		case reflect.String:
-			recallNumStr, ok := tmpIndexConfMap["recallNum"].(string)
-			if ok {
-				recallNum64, err := strconv.ParseInt(recallNumStr, 10, 64)
-				if err != nil {
-					logs.Warn(err)
-				} else {
-					recallNum = int32(recallNum64)
+		if index.Engine == config_schema.IndexEngineLocal {
+			localIndex, err := newLocalIndex(index.IndexName, index.Local)
+			if err != nil {
+				for _, loaded := range faissIndexConfigs {
+					if loaded.GetLocalIndex() != nil {
+						loaded.GetLocalIndex().Release()
+					}
 				}
+				internal.ReleaseGrpcPool(faissGrpcPool)
+				return err
 			}
-		case reflect.Float32, reflect.Float64, reflect.Int16, reflect.Int, reflect.Int64, reflect.Int8,
-			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			recallNum, _ = tmpIndexConfMap["recallNum"].(int32)
-		default:
-			logs.Warn("unkown type, set recallnum to 100")
+			faissIndexConfig.setLocalIndex(localIndex)
+		} else {
+			faissIndexConfig.setFaissGrpcPool(faissGrpcPool)
 		}
-
-		indexName_ := tmpIndexConfMap["indexName"].(string)
 		indexInfoStruct := &faiss_index.RecallRequest{
-			IndexName: indexName_,
-			RecallNum: recallNum,
+			IndexName: index.IndexName,
+			RecallNum: int32(index.RecallNum),
 		}
 
-		faissIndexConfig.setIndexName(indexName_)
-		faissIndexConfig.setFaissGrpcPool(faissGrpcPool)
+		faissIndexConfig.setIndexName(index.IndexName)
 		faissIndexConfig.setFaissIndexs(indexInfoStruct)
+		faissIndexConfig.SetRecallNum(index.RecallNum)
+		faissIndexConfig.setProportion(index.Proportion)
+		faissIndexConfig.setFilters(index.Filters)
+		faissIndexConfig.setMaxOverFetch(index.MaxOverFetch)
+		faissIndexConfig.overFetch = newOverFetch()
+		faissIndexConfig.indexInfo = indexInfo
 		faissIndexConfigs = append(faissIndexConfigs, faissIndexConfig)
+	}
+	f.SetFaissIndexConfig(faissIndexConfigs)
+	f.indexInfo = indexInfo
+
+	return nil
+}
 
-		f.SetFaissIndexConfig(faissIndexConfigs)
+// the in-process index of an index with the local engine.
+func newLocalIndex(indexName string, localConf *config_schema.LocalIndexConf) (*ann.Engine, error) {
+	var source ann.Source = ann.NewFileSource(localConf.File)
+	if localConf.Redis != nil {
+		client, err := redis_v8.AcquireRedisClusterClient(localConf.Redis.ClusterConf())
+		if err != nil {
+			return nil, err
+		}
+		source = ann.NewRedisSource(client, localConf.RedisKey)
+	}
+
+	return ann.NewEngine(indexName, localConf.Options, source, localConf.ReloadInterval)
+}
+
+// GetFaissGrpcPool the faiss pool is acquired once and shared by all faiss indexes, nil if all indexes are local.
+func (f *FaissIndexConfigs) GetFaissGrpcPool() *internal.GRPCPool {
+	for _, indexConfig := range f.faissIndexConfigs {
+		if indexConfig.GetFaissGrpcPool() != nil {
+			return indexConfig.GetFaissGrpcPool()
+		}
 	}
 
 	return nil
 }
+
+// Retain one more service config uses the faiss pool and the local indexes.
+func (f *FaissIndexConfigs) Retain() {
+	if pool := f.GetFaissGrpcPool(); pool != nil {
+		internal.RetainGrpcPool(pool)
+	}
+	for _, indexConfig := range f.faissIndexConfigs {
+		if indexConfig.GetLocalIndex() != nil {
+			indexConfig.GetLocalIndex().Retain()
+		}
+	}
+}
+
+// Release the faiss pool is closed and the local indexes are dropped when no service config uses them.
+func (f *FaissIndexConfigs) Release() {
+	internal.ReleaseGrpcPool(f.GetFaissGrpcPool())
+	for _, indexConfig := range f.faissIndexConfigs {
+		if indexConfig.GetLocalIndex() != nil {
+			indexConfig.GetLocalIndex().Release()
+		}
+	}
+}
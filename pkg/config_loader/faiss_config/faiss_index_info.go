@@ -0,0 +1,218 @@
+package faiss_config
+
+import (
+	"context"
+	"fmt"
+	faiss_index "infer-microservices/internal/faiss_gogofaster"
+	"infer-microservices/internal/logs"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// IndexInfoRefreshInterval the index metadata is refreshed by GetIndexInfo in it, older metadata is stale.
+var IndexInfoRefreshInterval = time.Minute
+
+// IndexStatus metadata of a configured index from the faiss server, and its freshness.
+type IndexStatus struct {
+	IndexName        string    `json:"indexName"`
+	Found            bool      `json:"found"` //the faiss server has the index.
+	IndexMd5         string    `json:"indexMd5"`
+	IndexType        string    `json:"indexType"`
+	IndexLoadTime    string    `json:"indexLoadTime"`
+	IndexDim         int       `json:"indexDim"`
+	IndexVectorsSize int       `json:"indexVectorsSize"`
+	CheckedAt        time.Time `json:"checkedAt"` //the last successful GetIndexInfo.
+	ChangedAt        time.Time `json:"changedAt"` //the last md5 change.
+	Stale            bool      `json:"stale"`
+	Error            string    `json:"error,omitempty"` //the last GetIndexInfo error.
+}
+
+// indexInfo the index statuses of a FaissIndexConfigs.
+type indexInfo struct {
+	mu       sync.Mutex
+	statuses map[string]IndexStatus //index name -> status.
+}
+
+func newIndexInfo() *indexInfo {
+	return &indexInfo{statuses: make(map[string]IndexStatus, 0)}
+}
+
+// the index info created by ConfigLoad, an empty one if the configs are not loaded.
+func (f *FaissIndexConfigs) getIndexInfo() *indexInfo {
+	if f.indexInfo == nil {
+		return newIndexInfo()
+	}
+
+	return f.indexInfo
+}
+
+// RefreshIndexInfo get the metadata of the configured indexes from the faiss server, or the local indexes.
+// an md5 change of an index changes IndexVersion, the caches of the recall results keyed by it are invalid.
+func (f *FaissIndexConfigs) RefreshIndexInfo(timeout time.Duration) error {
+	f.refreshLocalIndexInfo()
+	pool := f.GetFaissGrpcPool()
+	if pool == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	reply, err := pool.Invoke(ctx, "", func(ctx context.Context, conn *grpc.ClientConn) (interface{}, error) {
+		return faiss_index.NewGrpcRecallServerServiceClient(conn).GetIndexInfo(ctx, &faiss_index.GetIndexInfoRequest{})
+	})
+
+	info := f.getIndexInfo()
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	now := time.Now()
+	if err != nil {
+		for _, indexConfig := range f.faissIndexConfigs {
+			if indexConfig.GetLocalIndex() != nil {
+				continue
+			}
+			indexStatus := info.statuses[indexConfig.GetIndexName()]
+			indexStatus.IndexName = indexConfig.GetIndexName()
+			indexStatus.Error = err.Error()
+			info.statuses[indexStatus.IndexName] = indexStatus
+		}
+		return err
+	}
+
+	serverIndexes := make(map[string]*faiss_index.IndexInfo, 0)
+	for _, serverIndex := range reply.(*faiss_index.GetIndexInfoResponse).GetIndexInfo_() {
+		serverIndexes[serverIndex.GetIndexName()] = serverIndex
+	}
+	for _, indexConfig := range f.faissIndexConfigs {
+		if indexConfig.GetLocalIndex() != nil {
+			continue
+		}
+		last, ok := info.statuses[indexConfig.GetIndexName()]
+		indexStatus := IndexStatus{IndexName: indexConfig.GetIndexName(), CheckedAt: now, ChangedAt: last.ChangedAt}
+		if serverIndex, found := serverIndexes[indexStatus.IndexName]; found {
+			indexStatus.Found = true
+			indexStatus.IndexMd5 = serverIndex.GetIndexMd5()
+			indexStatus.IndexType = serverIndex.GetIndexType()
+			indexStatus.IndexLoadTime = serverIndex.GetIndexLoadTime()
+			indexStatus.IndexDim = int(serverIndex.GetIndexDim())
+			indexStatus.IndexVectorsSize = int(serverIndex.GetIndexVectorsSize())
+		}
+		if ok && last.IndexMd5 != "" && last.IndexMd5 != indexStatus.IndexMd5 {
+			indexStatus.ChangedAt = now
+			logs.Info(indexStatus.IndexName, now, "faiss index changed, md5:", last.IndexMd5, "->", indexStatus.IndexMd5)
+		}
+		info.statuses[indexStatus.IndexName] = indexStatus
+	}
+
+	return nil
+}
+
+// the metadata of the local indexes, the version of the items is the md5.
+func (f *FaissIndexConfigs) refreshLocalIndexInfo() {
+	info := f.getIndexInfo()
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	now := time.Now()
+	for _, indexConfig := range f.faissIndexConfigs {
+		if indexConfig.GetLocalIndex() == nil {
+			continue
+		}
+		engineInfo := indexConfig.GetLocalIndex().Info()
+		last, ok := info.statuses[indexConfig.GetIndexName()]
+		indexStatus := IndexStatus{
+			IndexName:        indexConfig.GetIndexName(),
+			Found:            true,
+			IndexMd5:         engineInfo.Version,
+			IndexType:        "local " + engineInfo.Type + " " + engineInfo.Metric,
+			IndexLoadTime:    engineInfo.LoadedAt.Format(time.RFC3339),
+			IndexDim:         engineInfo.Dim,
+			IndexVectorsSize: engineInfo.Size,
+			CheckedAt:        now,
+			ChangedAt:        last.ChangedAt,
+		}
+		if ok && last.IndexMd5 != indexStatus.IndexMd5 {
+			indexStatus.ChangedAt = now
+		}
+		info.statuses[indexStatus.IndexName] = indexStatus
+	}
+}
+
+// IndexInfoUnimplemented the faiss server does not implement GetIndexInfo, such as an old version.
+func IndexInfoUnimplemented(err error) bool {
+	return status.Code(err) == codes.Unimplemented
+}
+
+// CheckIndexes the configured indexes are on the faiss server, and their dims are embeddingDim (0 is not checked).
+// the indexes without metadata are not checked.
+func (f *FaissIndexConfigs) CheckIndexes(embeddingDim int) error {
+	info := f.getIndexInfo()
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	for _, indexConfig := range f.faissIndexConfigs {
+		indexStatus, ok := info.statuses[indexConfig.GetIndexName()]
+		if !ok || indexStatus.CheckedAt.IsZero() {
+			continue
+		}
+		if !indexStatus.Found {
+			return fmt.Errorf("index %s not found on the faiss server", indexStatus.IndexName)
+		}
+		if embeddingDim > 0 && indexStatus.IndexDim != embeddingDim {
+			return fmt.Errorf("index %s dim %d, the user embedding dim is %d", indexStatus.IndexName, indexStatus.IndexDim, embeddingDim)
+		}
+	}
+
+	return nil
+}
+
+// IndexStatuses the metadata of the configured indexes, by name.
+func (f *FaissIndexConfigs) IndexStatuses() []IndexStatus {
+	info := f.getIndexInfo()
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	statuses := make([]IndexStatus, 0, len(info.statuses))
+	for _, indexStatus := range info.statuses {
+		indexStatus.Stale = time.Since(indexStatus.CheckedAt) > 2*IndexInfoRefreshInterval
+		statuses = append(statuses, indexStatus)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].IndexName < statuses[j].IndexName })
+
+	return statuses
+}
+
+// GetIndexDim the dim of the index from the faiss server or the local index, 0 if unknown.
+func (f *FaissIndexConfig) GetIndexDim() int {
+	if f.localIndex != nil {
+		return f.localIndex.Dim()
+	}
+	if f.indexInfo == nil {
+		return 0
+	}
+
+	f.indexInfo.mu.Lock()
+	defer f.indexInfo.mu.Unlock()
+
+	return f.indexInfo.statuses[f.indexName].IndexDim
+}
+
+// IndexVersion the md5s of the indexes, it changes when any index is rebuilt, such as a part of the recall cache key.
+func (f *FaissIndexConfigs) IndexVersion() string {
+	info := f.getIndexInfo()
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	md5s := make([]string, 0, len(f.faissIndexConfigs))
+	for _, indexConfig := range f.faissIndexConfigs {
+		md5s = append(md5s, info.statuses[indexConfig.GetIndexName()].IndexMd5)
+	}
+
+	return strings.Join(md5s, ",")
+}
@@ -0,0 +1,53 @@
+package faiss_config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigLoadLocalIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.jsonl")
+	items := `{"id": "item-001", "vector": [1, 0]}
+{"id": "item-002", "vector": [0, 1]}
+`
+	if err := os.WriteFile(path, []byte(items), 0644); err != nil {
+		t.Fatal(err)
+	}
+	indexConf, _ := json.Marshal(map[string]interface{}{
+		"indexInfo": []interface{}{map[string]interface{}{
+			"indexName": "index-001",
+			"recallNum": 1,
+			"engine":    "local",
+			"local":     map[string]interface{}{"type": "flat", "file": path},
+		}},
+	})
+
+	faissConfigs := new(FaissIndexConfigs)
+	err := faissConfigs.ConfigLoad("inferid-001", string(indexConf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer faissConfigs.Release()
+
+	if faissConfigs.GetFaissGrpcPool() != nil {
+		t.Errorf("a local index needs no faiss pool")
+	}
+	indexConfig := faissConfigs.GetFaissIndexConfig()[0]
+	results, err := indexConfig.GetLocalIndex().Search([]float32{0, 1}, indexConfig.GetRecallNum(), nil)
+	if err != nil || len(results) != 1 || results[0].Item.Id != "item-002" {
+		t.Errorf("local index search: %v, %v", results, err)
+	}
+
+	if err := faissConfigs.RefreshIndexInfo(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := faissConfigs.CheckIndexes(2); err != nil {
+		t.Error(err)
+	}
+	if err := faissConfigs.CheckIndexes(8); err == nil {
+		t.Errorf("dim 2 of the local index should not match the embedding dim 8")
+	}
+}
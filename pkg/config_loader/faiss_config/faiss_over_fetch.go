@@ -0,0 +1,81 @@
+package faiss_config
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+const overFetchDecay = 0.8 //weight of the last pass ratio.
+const minPassRatio = 0.01
+
+// overFetch the pass ratio of the post-filter of an index, a faiss server without native filtering is over fetched by it.
+type overFetch struct {
+	passRatio uint64 //float64 bits, ewma of the passed items / the fetched items.
+	native    int32  //1 if the faiss server filtered the last recall.
+	noAttrs   int32  //1 if the items of the last post-filtered recall had no attributes.
+}
+
+func newOverFetch() *overFetch {
+	o := &overFetch{}
+	atomic.StoreUint64(&o.passRatio, math.Float64bits(1))
+	return o
+}
+
+// FetchNum the recall num to fetch recallNum items after the post-filter, at most maxOverFetch times.
+// an index whose faiss server filters natively, or returns no item attributes, is not over fetched.
+func (f *FaissIndexConfig) FetchNum(recallNum int) int {
+	if f.overFetch == nil || atomic.LoadInt32(&f.overFetch.native) == 1 || atomic.LoadInt32(&f.overFetch.noAttrs) == 1 {
+		return recallNum
+	}
+
+	maxFetchNum := recallNum
+	if f.maxOverFetch > 1 {
+		maxFetchNum = recallNum * f.maxOverFetch
+	}
+	passRatio := math.Float64frombits(atomic.LoadUint64(&f.overFetch.passRatio))
+	fetchNum := int(math.Ceil(float64(recallNum) / passRatio))
+	if fetchNum > maxFetchNum {
+		return maxFetchNum
+	}
+
+	return fetchNum
+}
+
+// ObserveFilter record a filtered recall, native if the faiss server filtered it, or fetched items and the passed ones.
+func (f *FaissIndexConfig) ObserveFilter(fetched int, passed int, native bool) {
+	if f.overFetch == nil {
+		return
+	}
+	if native {
+		atomic.StoreInt32(&f.overFetch.native, 1)
+		return
+	}
+	atomic.StoreInt32(&f.overFetch.native, 0)
+	if fetched == 0 {
+		return
+	}
+
+	for {
+		old := atomic.LoadUint64(&f.overFetch.passRatio)
+		passRatio := overFetchDecay*math.Float64frombits(old) + (1-overFetchDecay)*float64(passed)/float64(fetched)
+		if passRatio < minPassRatio {
+			passRatio = minPassRatio
+		}
+		if atomic.CompareAndSwapUint64(&f.overFetch.passRatio, old, math.Float64bits(passRatio)) {
+			return
+		}
+	}
+}
+
+// ObserveAttributes record whether the items of a post-filtered recall have attributes, true if it changed.
+func (f *FaissIndexConfig) ObserveAttributes(available bool) bool {
+	if f.overFetch == nil {
+		return false
+	}
+	noAttrs := int32(0)
+	if !available {
+		noAttrs = 1
+	}
+
+	return atomic.SwapInt32(&f.overFetch.noAttrs, noAttrs) != noAttrs
+}
@@ -0,0 +1,50 @@
+package faiss_config
+
+import "testing"
+
+func TestFetchNum(t *testing.T) {
+	f := &FaissIndexConfig{maxOverFetch: 4, overFetch: newOverFetch()}
+	if fetchNum := f.FetchNum(100); fetchNum != 100 {
+		t.Errorf("fetch num %d before any recall, expect 100", fetchNum)
+	}
+
+	//half of the items are filtered out.
+	for i := 0; i < 50; i++ {
+		f.ObserveFilter(100, 50, false)
+	}
+	if fetchNum := f.FetchNum(100); fetchNum < 190 || fetchNum > 210 {
+		t.Errorf("fetch num %d, expect about 200", fetchNum)
+	}
+
+	//at most maxOverFetch times.
+	for i := 0; i < 50; i++ {
+		f.ObserveFilter(100, 1, false)
+	}
+	if fetchNum := f.FetchNum(100); fetchNum != 400 {
+		t.Errorf("fetch num %d, expect 400", fetchNum)
+	}
+
+	f.ObserveFilter(100, 100, true)
+	if fetchNum := f.FetchNum(100); fetchNum != 100 {
+		t.Errorf("fetch num %d of a native filtering server, expect 100", fetchNum)
+	}
+}
+
+func TestFetchNumNoAttributes(t *testing.T) {
+	f := &FaissIndexConfig{maxOverFetch: 4, overFetch: newOverFetch()}
+	for i := 0; i < 50; i++ {
+		f.ObserveFilter(100, 50, false)
+	}
+
+	if !f.ObserveAttributes(false) || f.ObserveAttributes(false) {
+		t.Errorf("ObserveAttributes should report the change only")
+	}
+	if fetchNum := f.FetchNum(100); fetchNum != 100 {
+		t.Errorf("fetch num %d without item attributes, expect 100", fetchNum)
+	}
+
+	f.ObserveAttributes(true)
+	if fetchNum := f.FetchNum(100); fetchNum < 190 || fetchNum > 210 {
+		t.Errorf("fetch num %d with item attributes again, expect about 200", fetchNum)
+	}
+}
@@ -2,7 +2,12 @@ package model_config
 
 import (
 	"infer-microservices/internal"
+	"infer-microservices/internal/logs"
 	"infer-microservices/internal/utils"
+	"infer-microservices/pkg/calibration"
+	"infer-microservices/pkg/config_loader/config_schema"
+	"infer-microservices/pkg/discovery"
+	"time"
 )
 
 type ModelConfig struct {
@@ -10,9 +15,12 @@ type ModelConfig struct {
 	tfservingModelName string             `validate:"required,min=4,max=10"`        //model name of tfserving config list.
 	tfservingGrpcPool  *internal.GRPCPool `validate:"required"`                     //tfserving grpc pool.
 	//fieldsSpec         map[string]interface{} //feaure engine conf.
-	userRedisKeyPreOffline  string `validate:"required,min=4,max=10"` //user offline feature redis key pre.
-	userRedisKeyPreRealtime string `validate:"required,min=4,max=10"` //user Realtime feature redis key pre.
-	itemRedisKeyPre         string `validate:"required,min=4,max=10"` //item feature redis key pre.
+	userRedisKeyPreOffline  string                           `validate:"required,min=4,max=10"` //user offline feature redis key pre.
+	userRedisKeyPreRealtime string                           `validate:"required,min=4,max=10"` //user Realtime feature redis key pre.
+	itemRedisKeyPre         string                           `validate:"required,min=4,max=10"` //item feature redis key pre.
+	calibrator              calibration.Calibrator           //score calibration, nil means raw scores.
+	embeddingDim            int                              //user tower embedding dim, 0 is unknown.
+	itemEmbedding           *config_schema.ItemEmbeddingConf //item embeddings of the similar items api, nil if not served.
 }
 
 func init() {
@@ -72,35 +80,80 @@ func (f *ModelConfig) GetItemRedisKeyPre() string {
 	return f.itemRedisKeyPre
 }
 
+// calibrator
+func (f *ModelConfig) setCalibrator(calibrator calibration.Calibrator) {
+	f.calibrator = calibrator
+}
+
+func (f *ModelConfig) GetCalibrator() calibration.Calibrator {
+	return f.calibrator
+}
+
+// embeddingDim
+func (f *ModelConfig) setEmbeddingDim(embeddingDim int) {
+	f.embeddingDim = embeddingDim
+}
+
+func (f *ModelConfig) GetEmbeddingDim() int {
+	return f.embeddingDim
+}
+
+// itemEmbedding
+func (f *ModelConfig) setItemEmbedding(itemEmbedding *config_schema.ItemEmbeddingConf) {
+	f.itemEmbedding = itemEmbedding
+}
+
+func (f *ModelConfig) GetItemEmbedding() *config_schema.ItemEmbeddingConf {
+	return f.itemEmbedding
+}
+
 // @implement ConfigLoadInterface
 func (m *ModelConfig) ConfigLoad(dataId string, modelConfStr string) error {
-
 	dataConf := utils.ConvertJsonToStruct(modelConfStr)
-	for _, tmpModelConf_ := range dataConf { // only 1 model
-
-		modelConfTmp := tmpModelConf_.(map[string]interface{})
-		tfservingGrpcConf := modelConfTmp["tfservingGrpcAddr"].(map[string]interface{})
-		modelName := tfservingGrpcConf["tfservingModelName"].(string) //tfserving config list modelname
+	modelConf, issues := config_schema.ParseModelConf(dataConf, "model_conf")
+	for _, warning := range issues.Warnings() {
+		logs.Warn(dataId, time.Now(), warning)
+	}
+	if err := issues.Err(); err != nil {
+		return err
+	}
 
-		// create tfserving grpc pool
-		tfservingGrpcPool, err := internal.CreateGrpcConn(tfservingGrpcConf)
+	//score calibration, optional.
+	var calibrator calibration.Calibrator
+	var err error
+	if modelConf.Calibration != nil {
+		calibrator, err = calibration.NewCalibrator(modelConf.Calibration)
 		if err != nil {
 			return err
 		}
+	}
 
-		//fieldsSpec := modelConfTmp["fieldsSpec"].(map[string]interface{})
-		userRedisKeyPreOffline := modelConfTmp["userRedisKeyPreOffline"].(string)
-		userRedisKeyPreRealtime := modelConfTmp["userRedisKeyPreRealtime"].(string)
-		itemRedisKeyPre := modelConfTmp["itemRedisKeyPre"].(string)
-
-		//set
-		m.setModelName(dataId)
-		m.setTfservingModelName(modelName)
-		m.setTfservingGrpcPool(tfservingGrpcPool)
-		m.setUserRedisKeyPreOffline(userRedisKeyPreOffline)
-		m.setUserRedisKeyPreRealtime(userRedisKeyPreRealtime)
-		m.setItemRedisKeyPre(itemRedisKeyPre)
+	// create tfserving grpc pool
+	tfservingGrpcPool, err := discovery.AcquireGrpcPool(modelConf.TfservingGrpc)
+	if err != nil {
+		return err
 	}
 
+	//set
+	m.setModelName(dataId)
+	m.setTfservingModelName(modelConf.TfservingModelName)
+	m.setTfservingGrpcPool(tfservingGrpcPool)
+	m.setUserRedisKeyPreOffline(modelConf.UserRedisKeyPreOffline)
+	m.setUserRedisKeyPreRealtime(modelConf.UserRedisKeyPreRealtime)
+	m.setItemRedisKeyPre(modelConf.ItemRedisKeyPre)
+	m.setCalibrator(calibrator)
+	m.setEmbeddingDim(modelConf.EmbeddingDim)
+	m.setItemEmbedding(modelConf.ItemEmbedding)
+
 	return nil
 }
+
+// Retain one more service config uses the tfserving pool.
+func (m *ModelConfig) Retain() {
+	internal.RetainGrpcPool(m.tfservingGrpcPool)
+}
+
+// Release the tfserving pool is closed when no service config uses it.
+func (m *ModelConfig) Release() {
+	internal.ReleaseGrpcPool(m.tfservingGrpcPool)
+}
@@ -2,7 +2,10 @@ package redis_config
 
 import (
 	redis_v8 "infer-microservices/internal/db/redis"
+	"infer-microservices/internal/logs"
 	"infer-microservices/internal/utils"
+	"infer-microservices/pkg/config_loader/config_schema"
+	"time"
 )
 
 type RedisConfig struct {
@@ -30,10 +33,30 @@ func (r *RedisConfig) GetRedisPool() *redis_v8.InferRedisClient {
 // @implement ConfigLoadInterface
 func (r *RedisConfig) ConfigLoad(dataId string, redisConfStr string) error {
 	confMap := utils.ConvertJsonToStruct(redisConfStr)
-	redisClusterInfo := confMap["redisCluster"].(map[string]interface{})
-	redisConnPool := redis_v8.NewRedisClusterClient(redisClusterInfo)
+	redisConf, issues := config_schema.ParseRedisConf(confMap, "redis_conf")
+	for _, warning := range issues.Warnings() {
+		logs.Warn(dataId, time.Now(), warning)
+	}
+	if err := issues.Err(); err != nil {
+		return err
+	}
+
+	redisConnPool, err := redis_v8.AcquireRedisClusterClient(redisConf.ClusterConf())
+	if err != nil {
+		return err
+	}
 
 	r.setRedisPool(redisConnPool)
 
 	return nil
 }
+
+// Retain one more service config uses the redis pool.
+func (r *RedisConfig) Retain() {
+	redis_v8.RetainRedisClient(r.redisPool)
+}
+
+// Release the redis pool is closed when no service config uses it.
+func (r *RedisConfig) Release() {
+	redis_v8.ReleaseRedisClient(r.redisPool)
+}
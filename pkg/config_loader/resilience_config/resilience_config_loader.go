@@ -0,0 +1,160 @@
+package resilience_config
+
+import (
+	"infer-microservices/internal"
+	"infer-microservices/internal/flags"
+	"infer-microservices/internal/logs"
+	"infer-microservices/internal/utils"
+	"infer-microservices/pkg/config_loader/config_schema"
+	"time"
+)
+
+var lowerRecallNum *int
+var lowerRankNum *int
+var tfservingTimeoutMs *int64
+
+// deadline, stage timeouts, hystrix command and degradation sizes of a dataId.
+type ResilienceConfig struct {
+	deadline       time.Duration
+	stageTimeouts  map[string]time.Duration
+	hystrixCommand string
+	hystrixTimeout time.Duration
+	lowerRecallNum int
+	lowerRankNum   int
+}
+
+func init() {
+	flagFactory := flags.FlagFactory{}
+	flagHystrix := flagFactory.CreateFlagHystrix()
+	lowerRecallNum = flagHystrix.GetHystrixLowerRecallNum()
+	lowerRankNum = flagHystrix.GetHystrixLowerRankNum()
+
+	flagTensorflow := flagFactory.CreateFlagTensorflow()
+	tfservingTimeoutMs = flagTensorflow.GetTfservingTimeoutMs()
+}
+
+// deadline
+func (r *ResilienceConfig) setDeadline(deadline time.Duration) {
+	r.deadline = deadline
+}
+
+func (r *ResilienceConfig) GetDeadline() time.Duration {
+	if r == nil || r.deadline == 0 {
+		return config_schema.DefaultDeadline
+	}
+
+	return r.deadline
+}
+
+// stageTimeouts
+func (r *ResilienceConfig) setStageTimeouts(stageTimeouts map[string]time.Duration) {
+	r.stageTimeouts = stageTimeouts
+}
+
+// GetStageTimeout timeout of a stage, see config_schema.Stages.
+func (r *ResilienceConfig) GetStageTimeout(stage string) time.Duration {
+	if r != nil {
+		if timeout, ok := r.stageTimeouts[stage]; ok && timeout > 0 {
+			return timeout
+		}
+	}
+	if stage == config_schema.StageRank {
+		return time.Duration(*tfservingTimeoutMs) * time.Millisecond
+	}
+
+	return config_schema.DefaultStageTimeout
+}
+
+// hystrixCommand
+func (r *ResilienceConfig) setHystrixCommand(hystrixCommand string) {
+	r.hystrixCommand = hystrixCommand
+}
+
+func (r *ResilienceConfig) GetHystrixCommand() string {
+	return r.hystrixCommand
+}
+
+// hystrixTimeout
+func (r *ResilienceConfig) setHystrixTimeout(hystrixTimeout time.Duration) {
+	r.hystrixTimeout = hystrixTimeout
+}
+
+func (r *ResilienceConfig) GetHystrixTimeout() time.Duration {
+	return r.hystrixTimeout
+}
+
+// lowerRecallNum
+func (r *ResilienceConfig) setLowerRecallNum(lowerRecallNum int) {
+	r.lowerRecallNum = lowerRecallNum
+}
+
+func (r *ResilienceConfig) GetLowerRecallNum() int {
+	return r.lowerRecallNum
+}
+
+// lowerRankNum
+func (r *ResilienceConfig) setLowerRankNum(lowerRankNum int) {
+	r.lowerRankNum = lowerRankNum
+}
+
+func (r *ResilienceConfig) GetLowerRankNum() int {
+	return r.lowerRankNum
+}
+
+// @implement ConfigLoadInterface
+// resilienceConfStr may be empty, the flags are used then.
+func (r *ResilienceConfig) ConfigLoad(dataId string, resilienceConfStr string) error {
+	var dataConf map[string]interface{}
+	if resilienceConfStr != "" {
+		dataConf = utils.ConvertJsonToStruct(resilienceConfStr)
+	}
+	resilienceConf, issues := config_schema.ParseResilienceConf(dataConf, "resilience_conf")
+	for _, warning := range issues.Warnings() {
+		logs.Warn(dataId, time.Now(), warning)
+	}
+	if err := issues.Err(); err != nil {
+		return err
+	}
+
+	//hystrix, unset settings use the flags.
+	commandConfig := internal.DefaultHystrixCommandConfig()
+	if resilienceConf.Hystrix.Timeout > 0 {
+		commandConfig.Timeout = int(resilienceConf.Hystrix.Timeout / time.Millisecond)
+	}
+	if resilienceConf.Hystrix.MaxConcurrentRequests > 0 {
+		commandConfig.MaxConcurrentRequests = resilienceConf.Hystrix.MaxConcurrentRequests
+	}
+	if resilienceConf.Hystrix.RequestVolumeThreshold > 0 {
+		commandConfig.RequestVolumeThreshold = resilienceConf.Hystrix.RequestVolumeThreshold
+	}
+	if resilienceConf.Hystrix.SleepWindow > 0 {
+		commandConfig.SleepWindow = int(resilienceConf.Hystrix.SleepWindow / time.Millisecond)
+	}
+	if resilienceConf.Hystrix.ErrorPercentThreshold > 0 {
+		commandConfig.ErrorPercentThreshold = resilienceConf.Hystrix.ErrorPercentThreshold
+	}
+	hystrixCommand := internal.ConfigureHystrixCommand("infer_"+dataId, commandConfig)
+
+	//degradation
+	lowerRecallNum_ := *lowerRecallNum
+	if resilienceConf.LowerRecallNum > 0 {
+		lowerRecallNum_ = resilienceConf.LowerRecallNum
+	}
+	lowerRankNum_ := *lowerRankNum
+	if resilienceConf.LowerRankNum > 0 {
+		lowerRankNum_ = resilienceConf.LowerRankNum
+	}
+
+	//set
+	r.setDeadline(resilienceConf.Deadline)
+	r.setStageTimeouts(resilienceConf.StageTimeouts)
+	r.setHystrixCommand(hystrixCommand)
+	r.setHystrixTimeout(time.Duration(commandConfig.Timeout) * time.Millisecond)
+	r.setLowerRecallNum(lowerRecallNum_)
+	r.setLowerRankNum(lowerRankNum_)
+
+	//the replaced pools are drained for the requests in flight.
+	internal.ObserveRequestDeadline(r.GetDeadline())
+
+	return nil
+}
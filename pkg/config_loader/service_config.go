@@ -4,6 +4,7 @@ import (
 	"infer-microservices/pkg/config_loader/faiss_config"
 	"infer-microservices/pkg/config_loader/model_config"
 	"infer-microservices/pkg/config_loader/redis_config"
+	"infer-microservices/pkg/config_loader/resilience_config"
 )
 
 var serviceConfigs = make(map[string]*ServiceConfig, 0) //one server/dataid,one service conn
@@ -17,10 +18,16 @@ func GetServiceConfigs() map[string]*ServiceConfig {
 }
 
 type ServiceConfig struct {
-	serviceId         string                         `validate:"required,unique,min=4,max=10"` //dataid
-	redisConfig       redis_config.RedisConfig       `validate:"required"`                     //redis conn info
-	faissIndexConfigs faiss_config.FaissIndexConfigs //index conn info
-	modelConfig       model_config.ModelConfig       `validate:"required"` //model conn info
+	serviceId         string                             `validate:"required,unique,min=4,max=10"` //dataid
+	redisConfig       redis_config.RedisConfig           `validate:"required"`                     //redis conn info
+	faissIndexConfigs faiss_config.FaissIndexConfigs     //index conn info
+	modelConfig       model_config.ModelConfig           `validate:"required"` //model conn info
+	resilienceConfig  resilience_config.ResilienceConfig //timeouts, hystrix and degradation
+
+	//the conf each part is built from, an unchanged part is reused on reload.
+	redisConfStr string
+	modelConfStr string
+	indexConfStr string
 }
 
 func init() {
@@ -61,3 +68,19 @@ func (s *ServiceConfig) setModelConfig(modelConfig model_config.ModelConfig) {
 func (s *ServiceConfig) GetModelConfig() *model_config.ModelConfig {
 	return &s.modelConfig
 }
+
+// resilienceConfig
+func (s *ServiceConfig) setResilienceConfig(resilienceConfig resilience_config.ResilienceConfig) {
+	s.resilienceConfig = resilienceConfig
+}
+
+func (s *ServiceConfig) GetResilienceConfig() *resilience_config.ResilienceConfig {
+	return &s.resilienceConfig
+}
+
+// Release the pools of the service config, they are closed when no service config uses them.
+func (s *ServiceConfig) Release() {
+	s.redisConfig.Release()
+	s.modelConfig.Release()
+	s.faissIndexConfigs.Release()
+}
@@ -18,6 +18,7 @@ func (b *ServiceConfigBuilder) RedisConfigBuilder(dataId string, redisConfStr st
 	configFactory := &ConfigFactory{}
 	redisConfig := configFactory.createRedisConfig(dataId, redisConfStr)
 	b.serviceConfig.setRedisConfig(*redisConfig)
+	b.serviceConfig.redisConfStr = redisConfStr
 
 	return b
 }
@@ -27,6 +28,7 @@ func (b *ServiceConfigBuilder) FaissConfigBuilder(dataId string, indexConfStr st
 	configFactory := &ConfigFactory{}
 	faissConfigs := configFactory.createFaissConfig(dataId, indexConfStr)
 	b.serviceConfig.SetFaissIndexConfigs(*faissConfigs)
+	b.serviceConfig.indexConfStr = indexConfStr
 
 	return b
 }
@@ -36,6 +38,55 @@ func (b *ServiceConfigBuilder) ModelConfigBuilder(dataId string, modelConfStr st
 	configFactory := &ConfigFactory{}
 	modelConfig := configFactory.createModelConfig(dataId, modelConfStr)
 	b.serviceConfig.setModelConfig(*modelConfig)
+	b.serviceConfig.modelConfStr = modelConfStr
+
+	return b
+}
+
+// resilience builder, cheap to build, it is always rebuilt.
+func (b *ServiceConfigBuilder) ResilienceConfigBuilder(dataId string, resilienceConfStr string) *ServiceConfigBuilder {
+	configFactory := &ConfigFactory{}
+	resilienceConfig := configFactory.createResilienceConfig(dataId, resilienceConfStr)
+	b.serviceConfig.setResilienceConfig(*resilienceConfig)
+
+	return b
+}
+
+// redis builder, reuse the redis config of old if the conf is not changed.
+func (b *ServiceConfigBuilder) RedisConfigReuseBuilder(dataId string, redisConfStr string, old *ServiceConfig) *ServiceConfigBuilder {
+	if old == nil || old.redisConfStr != redisConfStr {
+		return b.RedisConfigBuilder(dataId, redisConfStr)
+	}
+
+	old.redisConfig.Retain()
+	b.serviceConfig.setRedisConfig(old.redisConfig)
+	b.serviceConfig.redisConfStr = redisConfStr
+
+	return b
+}
+
+// faiss builder, reuse the faiss configs of old if the conf is not changed.
+func (b *ServiceConfigBuilder) FaissConfigReuseBuilder(dataId string, indexConfStr string, old *ServiceConfig) *ServiceConfigBuilder {
+	if old == nil || old.indexConfStr != indexConfStr {
+		return b.FaissConfigBuilder(dataId, indexConfStr)
+	}
+
+	old.faissIndexConfigs.Retain()
+	b.serviceConfig.SetFaissIndexConfigs(old.faissIndexConfigs)
+	b.serviceConfig.indexConfStr = indexConfStr
+
+	return b
+}
+
+// model builder, reuse the model config of old if the conf is not changed.
+func (b *ServiceConfigBuilder) ModelConfigReuseBuilder(dataId string, modelConfStr string, old *ServiceConfig) *ServiceConfigBuilder {
+	if old == nil || old.modelConfStr != modelConfStr {
+		return b.ModelConfigBuilder(dataId, modelConfStr)
+	}
+
+	old.modelConfig.Retain()
+	b.serviceConfig.setModelConfig(old.modelConfig)
+	b.serviceConfig.modelConfStr = modelConfStr
 
 	return b
 }
@@ -28,6 +28,7 @@ func (s *ServiceConfigDirector) ServiceConfigUpdateContainIndexDirector(dataId s
 	err := validate.Struct(serviceConfig)
 	if err != nil {
 		logs.Error(dataId, time.Now(), err)
+		serviceConfig.Release()
 		return ServiceConfig{}
 	}
 
@@ -46,8 +47,47 @@ func (s *ServiceConfigDirector) ServiceConfigUpdaterNotContainIndexDirector(data
 	err := validate.Struct(serviceConfig)
 	if err != nil {
 		logs.Error(dataId, time.Now(), err)
+		serviceConfig.Release()
 		return ServiceConfig{}
 	}
 
 	return serviceConfig
 }
+
+// build from the active service config old, only the changed parts are rebuilt. old may be nil.
+func (s *ServiceConfigDirector) ServiceConfigReloadDirector(dataId string, old *ServiceConfig,
+	redisConfStr string, modelConfStr string, indexConfStr string, resilienceConfStr string) ServiceConfig {
+	builder := s.configBuilder.RedisConfigReuseBuilder(dataId, redisConfStr, old).ModelConfigReuseBuilder(dataId, modelConfStr, old).ResilienceConfigBuilder(dataId, resilienceConfStr)
+	if indexConfStr != "" {
+		builder = builder.FaissConfigReuseBuilder(dataId, indexConfStr, old)
+	}
+	serviceConfig := builder.GetServiceConfig()
+	serviceConfig.setServiceId(dataId)
+
+	//validete serviceConfig
+	validate := validator.New()
+	err := validate.Struct(serviceConfig)
+	if err != nil {
+		logs.Error(dataId, time.Now(), err)
+		serviceConfig.Release()
+		return ServiceConfig{}
+	}
+
+	return serviceConfig
+}
+
+// the parts rebuilt from old, for logs.
+func ChangedServiceConfigParts(old *ServiceConfig, redisConfStr string, modelConfStr string, indexConfStr string) []string {
+	changed := make([]string, 0)
+	if old == nil || old.redisConfStr != redisConfStr {
+		changed = append(changed, "redis")
+	}
+	if old == nil || old.modelConfStr != modelConfStr {
+		changed = append(changed, "model")
+	}
+	if indexConfStr != "" && (old == nil || old.indexConfStr != indexConfStr) {
+		changed = append(changed, "index")
+	}
+
+	return changed
+}
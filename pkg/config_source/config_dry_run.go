@@ -0,0 +1,142 @@
+package config_source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"infer-microservices/internal"
+	"infer-microservices/pkg/config_check"
+	"infer-microservices/pkg/config_loader/faiss_config"
+	"infer-microservices/pkg/config_loader/model_config"
+	"infer-microservices/pkg/config_loader/redis_config"
+	"infer-microservices/pkg/config_loader/resilience_config"
+	"infer-microservices/pkg/nacos"
+	"time"
+
+	"google.golang.org/grpc/connectivity"
+)
+
+var dryRunProbeTimeout = 2 * time.Second
+
+// DryRun check a service content, the running service config is never replaced.
+// with connect, the content also goes through the loaders, and the redis / grpc pools are probed then released.
+func DryRun(dataId string, content []byte, connect bool) *config_check.Report {
+	report := config_check.CheckServiceContent(dataId, content)
+	if !connect || report.HasError() {
+		return report
+	}
+
+	nacosContent := nacos.NacosContent{}
+	redisConfStr, modelConfStr, indexConfStr, err := nacosContent.InputServiceConfigParse(string(content))
+	if err != nil {
+		report.Errorf("$", "%v", err)
+		return report
+	}
+
+	dryRunLoad(report, "$.config.redis_conf", func() error {
+		redisConfig := new(redis_config.RedisConfig)
+		err := redisConfig.ConfigLoad(dataId, redisConfStr)
+		if err != nil {
+			return err
+		}
+		defer redisConfig.Release()
+
+		return redisConfig.GetRedisPool().Ping(dryRunProbeTimeout)
+	})
+
+	dryRunLoad(report, "$.config.model_conf", func() error {
+		modelConfig := new(model_config.ModelConfig)
+		err := modelConfig.ConfigLoad(dataId, modelConfStr)
+		if err != nil {
+			return err
+		}
+		defer modelConfig.Release()
+
+		return probeGrpcPool(modelConfig.GetTfservingGrpcPool())
+	})
+
+	if indexConfStr != "" {
+		dryRunLoad(report, "$.config.index_conf", func() error {
+			faissConfigs := new(faiss_config.FaissIndexConfigs)
+			err := faissConfigs.ConfigLoad(dataId, indexConfStr)
+			if err != nil {
+				return err
+			}
+			defer faissConfigs.Release()
+			//all faiss indexes share one pool, the local indexes are loaded by ConfigLoad.
+			if faissGrpcPool := faissConfigs.GetFaissGrpcPool(); faissGrpcPool != nil {
+				return probeGrpcPool(faissGrpcPool)
+			}
+
+			return nil
+		})
+	}
+
+	dryRunLoad(report, "$.config.resilience_conf", func() error {
+		resilienceConfStr, err := nacosContent.InputResilienceConfigParse(string(content))
+		if err != nil {
+			return err
+		}
+		resilienceConfig := new(resilience_config.ResilienceConfig)
+
+		return resilienceConfig.ConfigLoad(dataId, resilienceConfStr)
+	})
+
+	return report
+}
+
+// DryRunFile dry run a local json / yaml config file, see LocalConfigSource for the file format.
+func DryRunFile(path string, connect bool) *config_check.Report {
+	dataId, contentMap, err := readLocalConfigDocument(path)
+	if err != nil {
+		report := config_check.NewReport(path)
+		report.Errorf("$", "%v", err)
+		return report
+	}
+
+	content, err := json.Marshal(contentMap)
+	if err != nil {
+		report := config_check.NewReport(dataId)
+		report.Errorf("$", "%v", err)
+		return report
+	}
+
+	return DryRun(dataId, content, connect)
+}
+
+// INFO: the loaders use type assertions, a panic is reported as an issue.
+func dryRunLoad(report *config_check.Report, path string, load func() error) {
+	defer func() {
+		if info := recover(); info != nil {
+			report.Errorf(path, "loader panic: %v", info)
+		}
+	}()
+
+	err := load()
+	if err != nil {
+		report.Errorf(path, "%v", err)
+	}
+}
+
+// dial is lazy, wait until the connection is ready.
+func probeGrpcPool(pool *internal.GRPCPool) error {
+	conn, err := pool.Get()
+	if err != nil {
+		return err
+	}
+	defer pool.Put(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), dryRunProbeTimeout)
+	defer cancel()
+
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("%s is not ready in %v, state %s", conn.Target(), dryRunProbeTimeout, state)
+		}
+	}
+}
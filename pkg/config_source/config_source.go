@@ -0,0 +1,145 @@
+package config_source
+
+import (
+	"errors"
+	"fmt"
+	"infer-microservices/internal/logs"
+	"infer-microservices/pkg/config_check"
+	config_loader "infer-microservices/pkg/config_loader"
+	"infer-microservices/pkg/config_loader/faiss_config"
+	"infer-microservices/pkg/nacos"
+	"sync"
+	"time"
+)
+
+var mt sync.Mutex
+
+// ConfigSource feed the service config of a dataId to the ServiceConfigDirector, and keep it updated.
+type ConfigSource interface {
+	// load and watch the service config of dataId, calling it again with the same dataId does nothing.
+	Listen(dataId string, groupId string, namespaceId string) error
+}
+
+// build the service config from the service content and the active one, it is activated only if it builds and probes well.
+func serviceConfigUpdate(dataId string, content string) error {
+	mt.Lock()
+	defer mt.Unlock()
+
+	history, err := getServiceConfigHistory(dataId)
+	if err != nil {
+		logs.Error(dataId, time.Now(), err)
+		return err
+	}
+	if history.unchanged(content) {
+		logs.Info(dataId, time.Now(), "service config not changed.")
+		return nil
+	}
+
+	serviceConf, buildErr := serviceConfigBuilder(dataId, content, history.activeServiceConfig())
+	if buildErr != nil {
+		logs.Error(dataId, time.Now(), buildErr)
+	} else {
+		logs.Info(dataId, "built", time.Now(), serviceConf)
+	}
+
+	return history.add(content, serviceConf, buildErr)
+}
+
+func buildServiceConfig(dataId string, content string, old *config_loader.ServiceConfig) (serviceConf *config_loader.ServiceConfig, err error) {
+	//INFO: the loaders use type assertions, a panic is a failed build.
+	defer func() {
+		if info := recover(); info != nil {
+			serviceConf = nil
+			err = fmt.Errorf("service config loader panic: %v", info)
+		}
+	}()
+
+	report := config_check.CheckServiceContent(dataId, []byte(content))
+	if report.HasError() {
+		return nil, fmt.Errorf("invalid service config: %s", report.String())
+	}
+
+	nacosContent := nacos.NacosContent{}
+	redisConfStr, modelConfStr, indexConfStr, err := nacosContent.InputServiceConfigParse(content)
+	if err != nil {
+		return nil, err
+	}
+	resilienceConfStr, err := nacosContent.InputResilienceConfigParse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := config_loader.ServiceConfigBuilder{}
+	director := config_loader.ServiceConfigDirector{}
+	director.SetConfigBuilder(builder)
+
+	//only the changed parts are rebuilt, the pools of the unchanged parts are reused.
+	logs.Info(dataId, time.Now(), "rebuild service config parts:", config_loader.ChangedServiceConfigParts(old, redisConfStr, modelConfStr, indexConfStr))
+	serviceConfig := director.ServiceConfigReloadDirector(dataId, old, redisConfStr, modelConfStr, indexConfStr, resilienceConfStr)
+	if serviceConfig.GetServiceId() == "" {
+		return nil, errors.New("invalid service config, keep the old one")
+	}
+
+	return &serviceConfig, nil
+}
+
+// probe redis, tfserving and faiss of a built service config.
+func probeServiceConfig(serviceConf *config_loader.ServiceConfig) (err error) {
+	defer func() {
+		if info := recover(); info != nil {
+			err = fmt.Errorf("service config probe panic: %v", info)
+		}
+	}()
+
+	if redisPool := serviceConf.GetRedisConfig().GetRedisPool(); redisPool != nil {
+		err = redisPool.Ping(dryRunProbeTimeout)
+		if err != nil {
+			return fmt.Errorf("redis: %v", err)
+		}
+	}
+
+	if tfservingPool := serviceConf.GetModelConfig().GetTfservingGrpcPool(); tfservingPool != nil {
+		err = probeGrpcPool(tfservingPool)
+		if err != nil {
+			return fmt.Errorf("tfserving: %v", err)
+		}
+	}
+
+	//all faiss indexes share one pool, the local indexes need none.
+	faissIndexConfigs := serviceConf.GetFaissIndexConfigs().GetFaissIndexConfig()
+	if faissGrpcPool := serviceConf.GetFaissIndexConfigs().GetFaissGrpcPool(); faissGrpcPool != nil {
+		err = probeGrpcPool(faissGrpcPool)
+		if err != nil {
+			return fmt.Errorf("faiss: %v", err)
+		}
+	}
+
+	//index metadata, the indexes should be on the faiss server or loaded locally, with the user embedding dim.
+	if len(faissIndexConfigs) > 0 {
+		err = checkFaissIndexes(serviceConf)
+		if err != nil {
+			return fmt.Errorf("faiss: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// refresh the index metadata by GetIndexInfo, then check the indexes. old faiss servers without GetIndexInfo are not checked.
+func checkFaissIndexes(serviceConf *config_loader.ServiceConfig) error {
+	err := serviceConf.GetFaissIndexConfigs().RefreshIndexInfo(dryRunProbeTimeout)
+	if faiss_config.IndexInfoUnimplemented(err) {
+		logs.Warn(serviceConf.GetServiceId(), time.Now(), "faiss GetIndexInfo unimplemented, the indexes are not checked.")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	embeddingDim := 0
+	if modelConfig := serviceConf.GetModelConfig(); modelConfig != nil {
+		embeddingDim = modelConfig.GetEmbeddingDim()
+	}
+
+	return serviceConf.GetFaissIndexConfigs().CheckIndexes(embeddingDim)
+}
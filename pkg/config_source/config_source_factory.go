@@ -0,0 +1,21 @@
+package config_source
+
+import (
+	"strings"
+)
+
+type ConfigSourceFactory struct {
+}
+
+// create config source by type, nacos or local.
+func (f *ConfigSourceFactory) CreateConfigSource(sourceType string, nacosIp string, nacosPort uint64, localDir string) ConfigSource {
+	switch strings.ToLower(sourceType) {
+	case "local":
+		return NewLocalConfigSource(localDir)
+	default:
+		nacosSource := new(NacosConfigSource)
+		nacosSource.SetNacosIp(nacosIp)
+		nacosSource.SetNacosPort(nacosPort)
+		return nacosSource
+	}
+}
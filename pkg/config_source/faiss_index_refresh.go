@@ -0,0 +1,45 @@
+package config_source
+
+import (
+	"infer-microservices/internal/logs"
+	"infer-microservices/pkg/config_loader/faiss_config"
+	"sync"
+	"time"
+)
+
+var indexInfoRefreshOnce sync.Once
+
+// refresh the faiss index metadata of the active service configs every faiss_config.IndexInfoRefreshInterval.
+func startIndexInfoRefresh() {
+	indexInfoRefreshOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(faiss_config.IndexInfoRefreshInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				refreshIndexInfo()
+			}
+		}()
+	})
+}
+
+func refreshIndexInfo() {
+	storeMu.RLock()
+	activeHistories := make([]*serviceConfigHistory, 0, len(histories))
+	for _, history := range histories {
+		activeHistories = append(activeHistories, history)
+	}
+	storeMu.RUnlock()
+
+	for _, history := range activeHistories {
+		serviceConfig := history.activeServiceConfig()
+		if serviceConfig == nil || len(serviceConfig.GetFaissIndexConfigs().GetFaissIndexConfig()) == 0 {
+			continue
+		}
+
+		//a rebuilt index with another dim keeps the config active, recall of the index fails until it is fixed.
+		err := checkFaissIndexes(serviceConfig)
+		if err != nil {
+			logs.Error(history.dataId, time.Now(), err)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package config_source
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"infer-microservices/internal/logs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"gopkg.in/yaml.v3"
+)
+
+// LocalConfigSource service configs from json / yaml files of a dir, for tests, ci and envs without nacos.
+//
+// a file is the service_start_config.json format: {"dataId": "...", "content": {"config": {...}}},
+// or only the content, then the file name is the dataId.
+type LocalConfigSource struct {
+	dir      string
+	mu       sync.Mutex
+	watcher  *fsnotify.Watcher
+	files    map[string]string //dataId -> file path
+	listened map[string]bool
+}
+
+func NewLocalConfigSource(dir string) *LocalConfigSource {
+	return &LocalConfigSource{
+		dir:      dir,
+		files:    make(map[string]string, 0),
+		listened: make(map[string]bool, 0),
+	}
+}
+
+// @implement ConfigSource
+func (l *LocalConfigSource) Listen(dataId string, groupId string, namespaceId string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.listened[dataId] {
+		return nil
+	}
+
+	err := l.startWatch()
+	if err != nil {
+		return err
+	}
+
+	path, ok := l.files[dataId]
+	if !ok {
+		return fmt.Errorf("dataId %s not found in local config dir %s", dataId, l.dir)
+	}
+	_, content, err := readLocalConfigFile(path)
+	if err != nil {
+		return err
+	}
+
+	err = serviceConfigUpdate(dataId, content)
+	if err != nil {
+		return err
+	}
+	l.listened[dataId] = true
+
+	return nil
+}
+
+// watch the dir rather than files, editors usually replace the file when saving.
+func (l *LocalConfigSource) startWatch() error {
+	if l.watcher != nil {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	err = watcher.Add(l.dir)
+	if err != nil {
+		watcher.Close()
+		return err
+	}
+
+	err = l.scan()
+	if err != nil {
+		watcher.Close()
+		return err
+	}
+	l.watcher = watcher
+	go l.watch()
+
+	return nil
+}
+
+func (l *LocalConfigSource) scan() error {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(l.dir, entry.Name())
+		if entry.IsDir() || !isLocalConfigFile(path) {
+			continue
+		}
+
+		dataId, _, err := readLocalConfigFile(path)
+		if err != nil {
+			logs.Error(path, time.Now(), err)
+			continue
+		}
+		l.files[dataId] = path
+	}
+
+	return nil
+}
+
+func (l *LocalConfigSource) watch() {
+	for {
+		select {
+		case event, ok := <-l.watcher.Events:
+			if !ok {
+				return
+			}
+			if !isLocalConfigFile(event.Name) || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			l.onFileChange(event.Name)
+		case err, ok := <-l.watcher.Errors:
+			if !ok {
+				return
+			}
+			logs.Error(l.dir, time.Now(), err)
+		}
+	}
+}
+
+func (l *LocalConfigSource) onFileChange(path string) {
+	//INFO: a half written file fails to parse, keep the old config until the next write event.
+	dataId, content, err := readLocalConfigFile(path)
+	if err != nil {
+		logs.Error(path, time.Now(), err)
+		return
+	}
+
+	l.mu.Lock()
+	l.files[dataId] = path
+	listened := l.listened[dataId]
+	l.mu.Unlock()
+
+	if listened {
+		serviceConfigUpdate(dataId, content)
+	}
+}
+
+func isLocalConfigFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	default:
+		return false
+	}
+}
+
+// read a json / yaml config file, return the dataId and the service content json.
+func readLocalConfigFile(path string) (string, string, error) {
+	dataId, contentMap, err := readLocalConfigDocument(path)
+	if err != nil {
+		return "", "", err
+	}
+	if _, ok := contentMap["config"]; !ok {
+		return "", "", errors.New("config not found in " + path)
+	}
+
+	content, err := json.Marshal(contentMap)
+	if err != nil {
+		return "", "", err
+	}
+
+	return dataId, string(content), nil
+}
+
+// read a json / yaml config file, return the dataId and the service content.
+func readLocalConfigDocument(path string) (string, map[string]interface{}, error) {
+	buff, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	fileMap := make(map[string]interface{}, 0)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(buff, &fileMap)
+	default:
+		err = json.Unmarshal(buff, &fileMap)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	dataId, _ := fileMap["dataId"].(string)
+	contentMap := fileMap
+	if content, ok := fileMap["content"].(map[string]interface{}); ok {
+		contentMap = content
+	}
+	if dataId == "" {
+		dataId = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	return dataId, contentMap, nil
+}
@@ -0,0 +1,77 @@
+package config_source
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeLocalConfig(t *testing.T, path string, content string) {
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func waitActiveVersion(history *serviceConfigHistory, version int64) bool {
+	deadline := time.Now().Add(2 * time.Second)
+	for activeVersion(history) != version && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return activeVersion(history) == version
+}
+
+func TestLocalConfigSourceReload(t *testing.T) {
+	dataId := "test-local-reload"
+	history := useStubHistory(t, dataId)
+	useStubHistory(t, "test-local-bad")
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "service.json")
+	writeLocalConfig(t, path, `{"dataId": "test-local-reload", "content": {"config": {"version": 1}}}`)
+	//the file name is the dataId of a content only file.
+	writeLocalConfig(t, filepath.Join(dir, "test-local-bad.yaml"), "config:\n  version: bad\n")
+
+	source := NewLocalConfigSource(dir)
+	if err := source.Listen(dataId, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if activeVersion(history) != 1 {
+		t.Fatalf("active %d after listen, expect 1", activeVersion(history))
+	}
+	serviceConfig, _ := GetServiceConfig(dataId)
+	if !strings.Contains(stubContentOf(serviceConfig), `"version":1`) {
+		t.Errorf("active content %s", stubContentOf(serviceConfig))
+	}
+
+	//a changed file is a new version.
+	writeLocalConfig(t, path, `{"dataId": "test-local-reload", "content": {"config": {"version": 2}}}`)
+	if !waitActiveVersion(history, 2) {
+		t.Fatalf("active %d after the file changed, expect 2", activeVersion(history))
+	}
+
+	//a half written file is skipped, the active version is kept.
+	writeLocalConfig(t, path, `{"dataId": "test-local-reload", "content": {"con`)
+	time.Sleep(200 * time.Millisecond)
+	versions, _ := ServiceConfigVersions(dataId)
+	if activeVersion(history) != 2 || len(versions) != 2 {
+		t.Errorf("active %d of %d versions after a half written file, expect 2 of 2", activeVersion(history), len(versions))
+	}
+
+	cases := []struct {
+		dataId string
+		err    bool
+	}{
+		{dataId, false}, //listened already.
+		{"test-local-bad", true},
+		{"test-local-not-exists", true},
+	}
+	for _, c := range cases {
+		err := source.Listen(c.dataId, "", "")
+		if (err != nil) != c.err {
+			t.Errorf("listen %s: err %v, expect err %v", c.dataId, err, c.err)
+		}
+	}
+}
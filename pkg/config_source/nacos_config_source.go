@@ -0,0 +1,42 @@
+package config_source
+
+import (
+	"infer-microservices/pkg/nacos"
+)
+
+// NacosConfigSource service configs from nacos, one nacos listener per dataId.
+type NacosConfigSource struct {
+	nacosIp   string
+	nacosPort uint64
+}
+
+// nacosIp
+func (n *NacosConfigSource) SetNacosIp(nacosIp string) {
+	n.nacosIp = nacosIp
+}
+
+func (n *NacosConfigSource) GetNacosIp() string {
+	return n.nacosIp
+}
+
+// nacosPort
+func (n *NacosConfigSource) SetNacosPort(nacosPort uint64) {
+	n.nacosPort = nacosPort
+}
+
+func (n *NacosConfigSource) GetNacosPort() uint64 {
+	return n.nacosPort
+}
+
+// @implement ConfigSource
+func (n *NacosConfigSource) Listen(dataId string, groupId string, namespaceId string) error {
+	nacosConn := nacos.NacosConnConfig{}
+	nacosConn.SetDataId(dataId)
+	nacosConn.SetGroupId(groupId)
+	nacosConn.SetNamespaceId(namespaceId)
+	nacosConn.SetIp(n.nacosIp)
+	nacosConn.SetPort(n.nacosPort)
+
+	//INFO: the error of the current content fails the load, the later contents are still listened and may fix it.
+	return nacosConn.StartListenNacos(serviceConfigUpdate)
+}
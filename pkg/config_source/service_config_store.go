@@ -0,0 +1,341 @@
+package config_source
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"infer-microservices/internal/logs"
+	config_loader "infer-microservices/pkg/config_loader"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const maxServiceConfigVersions = 10
+
+// version status
+const (
+	VersionActive     = "active"
+	VersionInactive   = "inactive"   //was active, its content is kept for rollback.
+	VersionFailed     = "failed"     //build or probe failed, never active.
+	VersionRolledBack = "rolledback" //was active, rolled back.
+)
+
+var errServiceConfigLoading = errors.New("service config is loading")
+
+// wait before the second probe of a new active version, roll back if it fails.
+var rollbackCheckDelay = 30 * time.Second
+
+// build and probe a version, the tests stub them.
+var serviceConfigBuilder = buildServiceConfig
+var serviceConfigProber = probeServiceConfig
+
+var storeMu sync.RWMutex
+var histories = make(map[string]*serviceConfigHistory, 0) //dataId of manifest -> versions.
+
+// ServiceConfigVersion a built service config of a dataId.
+type ServiceConfigVersion struct {
+	Version     int64     `json:"version"`
+	Md5         string    `json:"md5"` //md5 of the service content.
+	Status      string    `json:"status"`
+	Message     string    `json:"message"`
+	CreatedAt   time.Time `json:"createdAt"`
+	ActivatedAt time.Time `json:"activatedAt"`
+
+	content       string
+	serviceConfig *config_loader.ServiceConfig //only the active version holds its pools.
+}
+
+type serviceConfigHistory struct {
+	dataId      string
+	mu          sync.Mutex
+	active      atomic.Pointer[ServiceConfigVersion]
+	config      atomic.Pointer[config_loader.ServiceConfig] //of the active version, read without the lock.
+	versions    []*ServiceConfigVersion //oldest first.
+	nextVersion int64
+	lastErr     error //why there is no active version.
+}
+
+// LoadServiceConfigs load and watch all services of the manifest, before the service ports are opened.
+func LoadServiceConfigs(source ConfigSource, manifest *ServiceManifest) error {
+	storeMu.Lock()
+	for _, entry := range manifest.Services {
+		if _, ok := histories[entry.DataId]; !ok {
+			histories[entry.DataId] = &serviceConfigHistory{dataId: entry.DataId, lastErr: errServiceConfigLoading}
+		}
+	}
+	storeMu.Unlock()
+
+	startIndexInfoRefresh()
+
+	for _, entry := range manifest.Services {
+		err := source.Listen(entry.DataId, entry.Group, entry.Namespace)
+		if err == nil {
+			logs.Info(entry.DataId, time.Now(), "service config loaded.")
+			continue
+		}
+
+		logs.Error(entry.DataId, time.Now(), err)
+		if manifest.FailFast {
+			return fmt.Errorf("load service config %s failed: %v", entry.DataId, err)
+		}
+		history, _ := getServiceConfigHistory(entry.DataId)
+		history.setLastErr(err)
+	}
+
+	return nil
+}
+
+// GetServiceConfig the active service config of dataId, unknown or unavailable dataIds return error.
+func GetServiceConfig(dataId string) (*config_loader.ServiceConfig, error) {
+	history, err := getServiceConfigHistory(dataId)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceConfig := history.config.Load()
+	if serviceConfig == nil {
+		history.mu.Lock()
+		defer history.mu.Unlock()
+		return nil, fmt.Errorf("dataId %s is unavailable: %v", dataId, history.lastErr)
+	}
+
+	return serviceConfig, nil
+}
+
+// ServiceConfigVersions versions of dataId, newest first.
+func ServiceConfigVersions(dataId string) ([]ServiceConfigVersion, error) {
+	history, err := getServiceConfigHistory(dataId)
+	if err != nil {
+		return nil, err
+	}
+
+	history.mu.Lock()
+	defer history.mu.Unlock()
+
+	versions := make([]ServiceConfigVersion, 0, len(history.versions))
+	for idx := len(history.versions) - 1; idx >= 0; idx-- {
+		versions = append(versions, *history.versions[idx])
+	}
+
+	return versions, nil
+}
+
+// RollbackServiceConfig activate an old version of dataId, version 0 means the last good one before the active.
+func RollbackServiceConfig(dataId string, version int64) (ServiceConfigVersion, error) {
+	history, err := getServiceConfigHistory(dataId)
+	if err != nil {
+		return ServiceConfigVersion{}, err
+	}
+
+	//the version is rebuilt from its content, as serviceConfigUpdate.
+	mt.Lock()
+	defer mt.Unlock()
+	history.mu.Lock()
+	defer history.mu.Unlock()
+
+	var target *ServiceConfigVersion
+	if version == 0 {
+		target = history.lastGood(history.active.Load())
+	} else {
+		target = history.find(version)
+	}
+	if target == nil || target.Status == VersionFailed {
+		return ServiceConfigVersion{}, fmt.Errorf("no version %d of dataId %s to roll back to", version, dataId)
+	}
+	if target == history.active.Load() {
+		return *target, nil
+	}
+
+	err = history.rollback(target, "rolled back by admin")
+	if err != nil {
+		return *target, fmt.Errorf("version %d: %v", target.Version, err)
+	}
+
+	return *target, nil
+}
+
+func getServiceConfigHistory(dataId string) (*serviceConfigHistory, error) {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+
+	history, ok := histories[dataId]
+	if !ok {
+		return nil, fmt.Errorf("unknown dataId %s, not in the service manifest", dataId)
+	}
+
+	return history, nil
+}
+
+// add a new version, it is activated only if built and probed successfully, else the active one is kept.
+func (h *serviceConfigHistory) add(content string, serviceConfig *config_loader.ServiceConfig, buildErr error) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextVersion += 1
+	version := &ServiceConfigVersion{
+		Version:       h.nextVersion,
+		Md5:           contentMd5(content),
+		CreatedAt:     time.Now(),
+		content:       content,
+		serviceConfig: serviceConfig,
+	}
+	h.versions = append(h.versions, version)
+	h.evict()
+
+	err := buildErr
+	if err == nil {
+		err = serviceConfigProber(serviceConfig)
+	}
+	if err != nil {
+		version.Status = VersionFailed
+		version.Message = err.Error()
+		if serviceConfig != nil {
+			serviceConfig.Release()
+		}
+		version.serviceConfig = nil
+		if h.active.Load() == nil {
+			h.lastErr = err
+		}
+		logs.Error(h.dataId, time.Now(), "service config version", version.Version, "failed, keep the active one.", err)
+		return err
+	}
+
+	h.activate(version, VersionInactive, "")
+	go h.verify(version, rollbackCheckDelay)
+
+	return nil
+}
+
+func (h *serviceConfigHistory) activeServiceConfig() *config_loader.ServiceConfig {
+	return h.config.Load()
+}
+
+// the content is the same as the active version, nothing to rebuild.
+func (h *serviceConfigHistory) unchanged(content string) bool {
+	active := h.active.Load()
+
+	return active != nil && active.Md5 == contentMd5(content)
+}
+
+// swap the active version, the old active gets oldStatus and its pools are released, they are drained and closed
+// when no service config uses them.
+func (h *serviceConfigHistory) activate(version *ServiceConfigVersion, oldStatus string, message string) {
+	old := h.active.Load()
+	version.Status = VersionActive
+	version.ActivatedAt = time.Now()
+	h.lastErr = nil
+	h.config.Store(version.serviceConfig)
+	h.active.Store(version)
+
+	if old != nil {
+		old.Status = oldStatus
+		old.Message = message
+		if old.serviceConfig != nil {
+			old.serviceConfig.Release()
+			old.serviceConfig = nil
+		}
+	}
+
+	logs.Info(h.dataId, time.Now(), "service config version", version.Version, "activated.")
+}
+
+// rebuild an inactive version from its content and the active one, activate it if it probes well.
+func (h *serviceConfigHistory) rollback(version *ServiceConfigVersion, message string) error {
+	serviceConfig, err := serviceConfigBuilder(h.dataId, version.content, h.activeServiceConfig())
+	if err != nil {
+		return fmt.Errorf("rebuild failed: %v", err)
+	}
+	err = serviceConfigProber(serviceConfig)
+	if err != nil {
+		serviceConfig.Release()
+		return fmt.Errorf("probe failed: %v", err)
+	}
+
+	version.serviceConfig = serviceConfig
+	h.activate(version, VersionRolledBack, message)
+
+	return nil
+}
+
+// probe the new active version again after a while, roll back to the last good one if it fails.
+func (h *serviceConfigHistory) verify(version *ServiceConfigVersion, delay time.Duration) {
+	time.Sleep(delay)
+
+	mt.Lock()
+	defer mt.Unlock()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.active.Load() != version {
+		return
+	}
+	err := serviceConfigProber(version.serviceConfig)
+	if err == nil {
+		return
+	}
+
+	lastGood := h.lastGood(version)
+	if lastGood == nil {
+		logs.Error(h.dataId, time.Now(), "service config version", version.Version, "probe failed, no version to roll back to.", err)
+		return
+	}
+	rollbackErr := h.rollback(lastGood, "probe failed after activation: "+err.Error())
+	if rollbackErr != nil {
+		logs.Error(h.dataId, time.Now(), "service config version", version.Version, "probe failed, roll back to", lastGood.Version, "failed.", err, rollbackErr)
+		return
+	}
+	logs.Error(h.dataId, time.Now(), "service config version", version.Version, "rolled back to", lastGood.Version, err)
+}
+
+// the newest version which was active before current.
+func (h *serviceConfigHistory) lastGood(current *ServiceConfigVersion) *ServiceConfigVersion {
+	for idx := len(h.versions) - 1; idx >= 0; idx-- {
+		version := h.versions[idx]
+		if version == current || version.Status == VersionFailed {
+			continue
+		}
+		if current == nil || version.Version < current.Version {
+			return version
+		}
+	}
+
+	return nil
+}
+
+func (h *serviceConfigHistory) find(version int64) *ServiceConfigVersion {
+	for _, v := range h.versions {
+		if v.Version == version {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// INFO: the active version is never evicted, the others hold no pools.
+func (h *serviceConfigHistory) evict() {
+	for len(h.versions) > maxServiceConfigVersions {
+		idx := 0
+		if h.versions[0] == h.active.Load() {
+			idx = 1
+		}
+		h.versions = append(h.versions[:idx], h.versions[idx+1:]...)
+	}
+}
+
+func (h *serviceConfigHistory) setLastErr(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.active.Load() == nil {
+		h.lastErr = err
+	}
+}
+
+func contentMd5(content string) string {
+	sum := md5.Sum([]byte(content))
+
+	return hex.EncodeToString(sum[:])
+}
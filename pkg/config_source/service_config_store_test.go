@@ -0,0 +1,293 @@
+package config_source
+
+import (
+	"errors"
+	"fmt"
+	config_loader "infer-microservices/pkg/config_loader"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// the contents of the stub configs, a content with "bad" fails to build, the contents in failingContents fail to probe.
+var stubContents sync.Map    //*config_loader.ServiceConfig -> content
+var failingContents sync.Map //content -> true
+
+func stubServiceConfigBuilder(dataId string, content string, old *config_loader.ServiceConfig) (*config_loader.ServiceConfig, error) {
+	if strings.Contains(content, "bad") {
+		return nil, errors.New("invalid service config " + content)
+	}
+	serviceConfig := new(config_loader.ServiceConfig)
+	stubContents.Store(serviceConfig, content)
+
+	return serviceConfig, nil
+}
+
+func stubServiceConfigProber(serviceConfig *config_loader.ServiceConfig) error {
+	content, _ := stubContents.Load(serviceConfig)
+	if _, ok := failingContents.Load(content); ok {
+		return errors.New("probe failed " + content.(string))
+	}
+
+	return nil
+}
+
+func stubContentOf(serviceConfig *config_loader.ServiceConfig) string {
+	content, _ := stubContents.Load(serviceConfig)
+	content_, _ := content.(string)
+
+	return content_
+}
+
+// use the stubs in a test, and a new history of dataId.
+func useStubHistory(t *testing.T, dataId string) *serviceConfigHistory {
+	builder, prober := serviceConfigBuilder, serviceConfigProber
+	serviceConfigBuilder, serviceConfigProber = stubServiceConfigBuilder, stubServiceConfigProber
+	t.Cleanup(func() {
+		serviceConfigBuilder, serviceConfigProber = builder, prober
+	})
+
+	history := &serviceConfigHistory{dataId: dataId, lastErr: errServiceConfigLoading}
+	storeMu.Lock()
+	histories[dataId] = history
+	storeMu.Unlock()
+
+	return history
+}
+
+func statusOf(t *testing.T, dataId string, version int64) string {
+	versions, err := ServiceConfigVersions(dataId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range versions {
+		if v.Version == version {
+			return v.Status
+		}
+	}
+
+	return ""
+}
+
+func activeVersion(history *serviceConfigHistory) int64 {
+	if active := history.active.Load(); active != nil {
+		return active.Version
+	}
+
+	return 0
+}
+
+func TestServiceConfigUpdate(t *testing.T) {
+	dataId := "test-update"
+	history := useStubHistory(t, dataId)
+	if _, err := GetServiceConfig(dataId); err == nil {
+		t.Errorf("dataId without version should be unavailable")
+	}
+	failingContents.Store("probefail-3", true)
+
+	steps := []struct {
+		content  string
+		err      bool
+		active   int64
+		versions int
+	}{
+		{"ok-1", false, 1, 1},
+		{"ok-1", false, 1, 1}, //unchanged, no new version.
+		{"bad-2", true, 1, 2},
+		{"probefail-3", true, 1, 3},
+		{"ok-4", false, 4, 4},
+	}
+	for _, step := range steps {
+		err := serviceConfigUpdate(dataId, step.content)
+		if (err != nil) != step.err {
+			t.Errorf("%s: err %v, expect err %v", step.content, err, step.err)
+		}
+		versions, _ := ServiceConfigVersions(dataId)
+		if activeVersion(history) != step.active || len(versions) != step.versions {
+			t.Errorf("%s: active %d of %d versions, expect %d of %d", step.content, activeVersion(history), len(versions), step.active, step.versions)
+		}
+	}
+
+	serviceConfig, err := GetServiceConfig(dataId)
+	if err != nil || stubContentOf(serviceConfig) != "ok-4" {
+		t.Errorf("active config %s, err %v", stubContentOf(serviceConfig), err)
+	}
+	expects := map[int64]string{1: VersionInactive, 2: VersionFailed, 3: VersionFailed, 4: VersionActive}
+	for version, expect := range expects {
+		if status := statusOf(t, dataId, version); status != expect {
+			t.Errorf("version %d: %s, expect %s", version, status, expect)
+		}
+	}
+
+	//only the active version holds its pools.
+	history.mu.Lock()
+	for _, version := range history.versions {
+		if (version.serviceConfig != nil) != (version.Version == 4) {
+			t.Errorf("version %d: serviceConfig %v", version.Version, version.serviceConfig)
+		}
+	}
+	history.mu.Unlock()
+}
+
+func TestServiceConfigEvict(t *testing.T) {
+	dataId := "test-evict"
+	history := useStubHistory(t, dataId)
+
+	//the active version is the oldest one, it is kept.
+	serviceConfigUpdate(dataId, "ok-1")
+	for i := 2; i <= maxServiceConfigVersions+2; i++ {
+		serviceConfigUpdate(dataId, fmt.Sprintf("bad-%d", i))
+	}
+
+	versions, _ := ServiceConfigVersions(dataId)
+	if len(versions) != maxServiceConfigVersions {
+		t.Errorf("%d versions, expect %d", len(versions), maxServiceConfigVersions)
+	}
+	if activeVersion(history) != 1 || versions[len(versions)-1].Version != 1 {
+		t.Errorf("active version %d should be kept", activeVersion(history))
+	}
+	if versions[len(versions)-2].Version != 4 {
+		t.Errorf("oldest inactive version %d, expect 4", versions[len(versions)-2].Version)
+	}
+}
+
+func TestRollbackServiceConfig(t *testing.T) {
+	dataId := "test-rollback"
+	history := useStubHistory(t, dataId)
+	for _, content := range []string{"ok-1", "ok-2", "bad-3", "ok-4"} {
+		serviceConfigUpdate(dataId, content)
+	}
+
+	cases := []struct {
+		name    string
+		version int64
+		failing string //content which fails to probe.
+		err     bool
+		active  int64
+	}{
+		{"last good", 0, "", false, 2},
+		{"by version", 1, "", false, 1},
+		{"active", 1, "", false, 1},
+		{"failed version", 3, "", true, 1},
+		{"unknown version", 99, "", true, 1},
+		{"probe failed", 4, "ok-4", true, 1},
+		{"newest", 4, "", false, 4},
+	}
+	for _, c := range cases {
+		if c.failing != "" {
+			failingContents.Store(c.failing, true)
+		}
+		_, err := RollbackServiceConfig(dataId, c.version)
+		failingContents.Delete(c.failing)
+
+		if (err != nil) != c.err {
+			t.Errorf("%s: err %v, expect err %v", c.name, err, c.err)
+		}
+		if activeVersion(history) != c.active {
+			t.Errorf("%s: active %d, expect %d", c.name, activeVersion(history), c.active)
+		}
+	}
+
+	//the rolled back version is rebuilt from its content.
+	serviceConfig, _ := GetServiceConfig(dataId)
+	if stubContentOf(serviceConfig) != "ok-4" {
+		t.Errorf("active config %s, expect ok-4", stubContentOf(serviceConfig))
+	}
+	if status := statusOf(t, dataId, 1); status != VersionRolledBack {
+		t.Errorf("version 1: %s, expect %s", status, VersionRolledBack)
+	}
+
+	if _, err := RollbackServiceConfig("test-rollback-unknown", 0); err == nil {
+		t.Errorf("unknown dataId should fail")
+	}
+}
+
+func TestServiceConfigAutoRollback(t *testing.T) {
+	dataId := "test-auto-rollback"
+	history := useStubHistory(t, dataId)
+	delay := rollbackCheckDelay
+	rollbackCheckDelay = 50 * time.Millisecond
+	defer func() { rollbackCheckDelay = delay }()
+
+	serviceConfigUpdate(dataId, "ok-1")
+	serviceConfigUpdate(dataId, "auto-2")
+
+	//the probe of version 2 fails after activation.
+	failingContents.Store("auto-2", true)
+	defer failingContents.Delete("auto-2")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for activeVersion(history) != 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if activeVersion(history) != 1 {
+		t.Fatalf("active %d, expect rolled back to 1", activeVersion(history))
+	}
+	serviceConfig, _ := GetServiceConfig(dataId)
+	if stubContentOf(serviceConfig) != "ok-1" {
+		t.Errorf("active config %s, expect ok-1", stubContentOf(serviceConfig))
+	}
+	if status := statusOf(t, dataId, 2); status != VersionRolledBack {
+		t.Errorf("version 2: %s, expect %s", status, VersionRolledBack)
+	}
+}
+
+// a ConfigSource of the stub contents, or the error of the dataId.
+type stubConfigSource struct {
+	errs map[string]error
+}
+
+func (s *stubConfigSource) Listen(dataId string, groupId string, namespaceId string) error {
+	if err, ok := s.errs[dataId]; ok {
+		return err
+	}
+
+	return serviceConfigUpdate(dataId, "ok-"+dataId)
+}
+
+func TestLoadServiceConfigs(t *testing.T) {
+	useStubHistory(t, "test-load-stub")
+
+	cases := []struct {
+		name        string
+		failFast    bool
+		dataIds     []string
+		errs        map[string]error
+		err         bool
+		unavailable []string
+	}{
+		{"all loaded", true, []string{"test-load-1a", "test-load-1b"}, nil, false, nil},
+		{"fail fast", true, []string{"test-load-2a", "test-load-2b"}, map[string]error{"test-load-2a": errors.New("nacos down")}, true, nil},
+		{"mark unavailable", false, []string{"test-load-3a", "test-load-3b"}, map[string]error{"test-load-3a": errors.New("nacos down")}, false, []string{"test-load-3a"}},
+		{"invalid content", false, []string{"test-load-bad-4a"}, nil, false, []string{"test-load-bad-4a"}},
+	}
+	for _, c := range cases {
+		manifest := &ServiceManifest{FailFast: c.failFast}
+		for _, dataId := range c.dataIds {
+			manifest.Services = append(manifest.Services, ServiceManifestEntry{DataId: dataId})
+		}
+
+		err := LoadServiceConfigs(&stubConfigSource{errs: c.errs}, manifest)
+		if (err != nil) != c.err {
+			t.Errorf("%s: err %v, expect err %v", c.name, err, c.err)
+		}
+		if c.err {
+			continue
+		}
+		for _, dataId := range c.dataIds {
+			_, err := GetServiceConfig(dataId)
+			unavailable := false
+			for _, id := range c.unavailable {
+				unavailable = unavailable || id == dataId
+			}
+			if (err != nil) != unavailable {
+				t.Errorf("%s: %s err %v, expect unavailable %v", c.name, dataId, err, unavailable)
+			}
+		}
+	}
+
+	if _, err := GetServiceConfig("test-load-not-in-manifest"); err == nil {
+		t.Errorf("dataId not in the manifest should fail")
+	}
+}
@@ -0,0 +1,59 @@
+package config_source
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ServiceManifest all services to load at startup, the service_start_file.
+type ServiceManifest struct {
+	FailFast bool                   `json:"failFast"` //exit when a service config failed to load, or mark it unavailable.
+	Services []ServiceManifestEntry `json:"services"`
+}
+
+type ServiceManifestEntry struct {
+	DataId    string `json:"dataId"`
+	Group     string `json:"group"`
+	Namespace string `json:"namespace"`
+}
+
+func LoadServiceManifest(path string) (*ServiceManifest, error) {
+	buff, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &ServiceManifest{}
+	err = json.Unmarshal(buff, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	err = manifest.Check()
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+func (m *ServiceManifest) Check() error {
+	if len(m.Services) == 0 {
+		return errors.New("service manifest has no services")
+	}
+
+	dataIds := make(map[string]bool, len(m.Services))
+	for _, entry := range m.Services {
+		if entry.DataId == "" {
+			return errors.New("dataId can not be empty in service manifest")
+		}
+		if dataIds[entry.DataId] {
+			return fmt.Errorf("dataId %s is duplicated in service manifest", entry.DataId)
+		}
+		dataIds[entry.DataId] = true
+	}
+
+	return nil
+}
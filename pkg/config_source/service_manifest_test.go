@@ -0,0 +1,42 @@
+package config_source
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadServiceManifest(t *testing.T) {
+	cases := []struct {
+		name     string
+		content  string
+		err      bool
+		services int
+	}{
+		{"valid", `{"failFast": true, "services": [{"dataId": "inferid-001", "group": "infer", "namespace": "ns"}, {"dataId": "inferid-002"}]}`, false, 2},
+		{"no services", `{"failFast": true, "services": []}`, true, 0},
+		{"empty dataId", `{"services": [{"dataId": "", "group": "infer"}]}`, true, 0},
+		{"duplicated dataId", `{"services": [{"dataId": "inferid-001"}, {"dataId": "inferid-001"}]}`, true, 0},
+		{"invalid json", `{"services": [`, true, 0},
+	}
+	dir := t.TempDir()
+	for _, c := range cases {
+		path := filepath.Join(dir, c.name+".json")
+		if err := os.WriteFile(path, []byte(c.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		manifest, err := LoadServiceManifest(path)
+		if (err != nil) != c.err {
+			t.Errorf("%s: err %v, expect err %v", c.name, err, c.err)
+			continue
+		}
+		if err == nil && len(manifest.Services) != c.services {
+			t.Errorf("%s: %d services, expect %d", c.name, len(manifest.Services), c.services)
+		}
+	}
+
+	if _, err := LoadServiceManifest(filepath.Join(dir, "not-exists.json")); err == nil {
+		t.Errorf("missing manifest should fail")
+	}
+}
@@ -0,0 +1,24 @@
+package discovery
+
+import (
+	"infer-microservices/internal"
+	"infer-microservices/pkg/config_loader/config_schema"
+	"infer-microservices/pkg/nacos"
+)
+
+// AcquireGrpcPool the shared pool of a grpc conf, its targets are the static addrs, or the instances of a nacos naming service.
+func AcquireGrpcPool(conf config_schema.GrpcConf) (*internal.GRPCPool, error) {
+	options := conf.Options()
+	if conf.NacosService == nil {
+		return internal.AcquireGrpcPool(options, nil)
+	}
+
+	namingService := nacos.NacosNamingService{}
+	namingService.SetServiceName(conf.NacosService.ServiceName)
+	namingService.SetGroupName(conf.NacosService.GroupName)
+	namingService.SetNamespaceId(conf.NacosService.Namespace)
+	namingService.SetClusters(conf.NacosService.Clusters)
+	options.Discovery = namingService.Discovery()
+
+	return internal.AcquireGrpcPool(options, namingService.Watch)
+}
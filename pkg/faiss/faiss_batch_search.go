@@ -0,0 +1,293 @@
+package faiss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"infer-microservices/internal"
+	faiss_index "infer-microservices/internal/faiss_gogofaster"
+	"infer-microservices/internal/retry"
+	"infer-microservices/pkg/config_loader/faiss_config"
+	"infer-microservices/pkg/feature"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BatchRecallProbeInterval a faiss pool without GrpcBatchRecall is called per index, and probed again after it.
+var BatchRecallProbeInterval = time.Minute
+
+// faiss pool -> the time GrpcBatchRecall was found unimplemented.
+var batchUnsupported sync.Map
+
+// ErrBatchRecallUnimplemented the faiss server does not implement GrpcBatchRecall.
+var ErrBatchRecallUnimplemented = errors.New("faiss server does not implement GrpcBatchRecall")
+
+// FaissMultiIndexSearch recall one user vector against the indexes, with the filters of each index. recallNum of the request
+// is split across the indexes by their proportions, 0 uses the recall num of each index, see SplitRecallNum.
+// the indexes on the same faiss pool are recalled by one GrpcBatchRecall. the results are in the order of the indexes,
+// the failed ones are empty and their errors are joined. filter may be nil.
+func FaissMultiIndexSearch(ctx context.Context, indexConfigs []faiss_config.FaissIndexConfig, example feature.ExampleFeatures, vector []float32, filter *RecallFilter, recallNum int) ([][]*faiss_index.ItemInfo, error) {
+	if len(*example.UserExampleFeatures.Buff) == 0 || len(*example.UserContextExampleFeatures.Buff) == 0 {
+		return make([][]*faiss_index.ItemInfo, len(indexConfigs)), nil
+	}
+
+	return multiIndexSearch(ctx, indexConfigs, example.UserId(), vector, filter, recallNum)
+}
+
+// FaissSimilarItemsSearch recall the similar items of an item by its embedding, like FaissMultiIndexSearch.
+// the item itself is excluded, filter may be nil.
+func FaissSimilarItemsSearch(ctx context.Context, indexConfigs []faiss_config.FaissIndexConfig, itemId string, vector []float32, filter *RecallFilter, recallNum int) ([][]*faiss_index.ItemInfo, error) {
+	return multiIndexSearch(ctx, indexConfigs, itemId, vector, filter.excluding(itemId), recallNum)
+}
+
+// key is the routing key of the faiss calls, such as the user id. the calls are bounded by the deadline of ctx.
+func multiIndexSearch(ctx context.Context, indexConfigs []faiss_config.FaissIndexConfig, key string, vector []float32, filter *RecallFilter, recallNum int) ([][]*faiss_index.ItemInfo, error) {
+	results := make([][]*faiss_index.ItemInfo, len(indexConfigs))
+	if len(indexConfigs) == 0 {
+		return results, nil
+	}
+	recallNums := SplitRecallNum(indexConfigs, recallNum)
+
+	//group the faiss indexes by pool, the pool is shared by all faiss indexes of a config now.
+	pools := make([]*internal.GRPCPool, 0)
+	positions := make(map[*internal.GRPCPool][]int, 0)
+	requests := make(map[*internal.GRPCPool][]*faiss_index.RecallRequest, 0)
+	indexFilters := make([]*indexFilter, len(indexConfigs))
+	locals := make([]int, 0)
+	errs := make([]error, 0)
+	for i := range indexConfigs {
+		indexConfig := &indexConfigs[i]
+		if indexConfig.GetLocalIndex() != nil {
+			locals = append(locals, i)
+			continue
+		}
+		request, err := recallRequest(indexConfig, vector, recallNums[i])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		indexFilters[i] = filter.forIndex(indexConfig, recallNums[i])
+		if indexFilters[i] != nil {
+			indexFilters[i].apply(indexConfig, request)
+		}
+
+		pool := indexConfig.GetFaissGrpcPool()
+		if _, ok := positions[pool]; !ok {
+			pools = append(pools, pool)
+		}
+		positions[pool] = append(positions[pool], i)
+		requests[pool] = append(requests[pool], request)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(*grpcTimeoutMs)*time.Millisecond)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, pool := range pools {
+		wg.Add(1)
+		go func(pool *internal.GRPCPool) {
+			defer wg.Done()
+			responses, err := batchRecall(ctx, pool, key, requests[pool])
+			mu.Lock()
+			defer mu.Unlock()
+			for j, position := range positions[pool] {
+				results[position] = filterItems(&indexConfigs[position], indexFilters[position], responses[j])
+			}
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}(pool)
+	}
+
+	//the local indexes are searched while waiting for the faiss servers.
+	for _, position := range locals {
+		items, err := localSearch(&indexConfigs[position], vector, filter.forIndex(&indexConfigs[position], recallNums[position]), recallNums[position])
+		mu.Lock()
+		results[position] = items
+		if err != nil {
+			errs = append(errs, err)
+		}
+		mu.Unlock()
+	}
+	wg.Wait()
+
+	return results, errors.Join(errs...)
+}
+
+// FaissMultiVectorSearch recall the vectors against one index by one GrpcBatchRecall, such as the user vectors of a batch.
+// each vector has its filter and recall num, a nil filter does not filter, 0 uses the recall num of the index. key is the
+// routing key of the call. the results are in the order of the vectors. ErrBatchRecallUnimplemented if the faiss server
+// does not implement GrpcBatchRecall, the caller recalls the vectors one by one then.
+func FaissMultiVectorSearch(ctx context.Context, f *faiss_config.FaissIndexConfig, key string, vectors [][]float32, filters []*RecallFilter, recallNums []int) ([][]*faiss_index.ItemInfo, error) {
+	results := make([][]*faiss_index.ItemInfo, len(vectors))
+	if len(vectors) == 0 {
+		return results, nil
+	}
+
+	indexRecallNums := make([]int, len(vectors))
+	indexFilters := make([]*indexFilter, len(vectors))
+	for i := range vectors {
+		indexRecallNums[i] = recallNums[i]
+		if indexRecallNums[i] <= 0 {
+			indexRecallNums[i] = f.GetRecallNum()
+		}
+		indexFilters[i] = filters[i].forIndex(f, indexRecallNums[i])
+	}
+
+	if f.GetLocalIndex() != nil {
+		errs := make([]error, 0)
+		for i, vector := range vectors {
+			items, err := localSearch(f, vector, indexFilters[i], indexRecallNums[i])
+			if err != nil {
+				errs = append(errs, err)
+			}
+			results[i] = items
+		}
+		return results, errors.Join(errs...)
+	}
+
+	requests := make([]*faiss_index.RecallRequest, len(vectors))
+	for i, vector := range vectors {
+		request, err := recallRequest(f, vector, indexRecallNums[i])
+		if err != nil {
+			return results, err
+		}
+		if indexFilters[i] != nil {
+			indexFilters[i].apply(f, request)
+		}
+		requests[i] = request
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(*grpcTimeoutMs)*time.Millisecond)
+	defer cancel()
+
+	responses, err := grpcBatchRecall(ctx, f.GetFaissGrpcPool(), key, requests)
+	for i, response := range responses {
+		results[i] = filterItems(f, indexFilters[i], response)
+	}
+
+	return results, err
+}
+
+// the items of a response, post-filtered if the recall is filtered. a failed recall has no response.
+func filterItems(f *faiss_config.FaissIndexConfig, filter *indexFilter, response *faiss_index.RecallResponse) []*faiss_index.ItemInfo {
+	if response == nil || filter == nil {
+		return response.GetItemInfo_()
+	}
+
+	return filter.filter(f, response)
+}
+
+// the recall request of an index with recallNum, such as the recall num of the index.
+func recallRequest(f *faiss_config.FaissIndexConfig, vector []float32, recallNum int) (*faiss_index.RecallRequest, error) {
+	if indexDim := f.GetIndexDim(); indexDim > 0 && indexDim != len(vector) {
+		return nil, fmt.Errorf("index %s dim %d, the user embedding dim is %d", f.GetIndexName(), indexDim, len(vector))
+	}
+
+	return &faiss_index.RecallRequest{
+		IndexName:       f.GetFaissIndexs().IndexName,
+		UserVectorInfo_: &faiss_index.UserVectorInfo{UserVector: vector},
+		RecallNum:       int32(recallNum),
+	}, nil
+}
+
+// recall the requests by one GrpcBatchRecall, or one GrpcRecall per request if the faiss server does not implement it.
+// the responses are in the order of the requests, nil if failed.
+func batchRecall(ctx context.Context, pool *internal.GRPCPool, key string, requests []*faiss_index.RecallRequest) ([]*faiss_index.RecallResponse, error) {
+	if len(requests) == 1 {
+		return recallEach(ctx, pool, key, requests)
+	}
+
+	responses, err := grpcBatchRecall(ctx, pool, key, requests)
+	if errors.Is(err, ErrBatchRecallUnimplemented) {
+		return recallEach(ctx, pool, key, requests)
+	}
+
+	return responses, err
+}
+
+// recall the requests by one GrpcBatchRecall, ErrBatchRecallUnimplemented if the faiss server does not implement it.
+// the responses are in the order of the requests, nil if failed.
+func grpcBatchRecall(ctx context.Context, pool *internal.GRPCPool, key string, requests []*faiss_index.RecallRequest) ([]*faiss_index.RecallResponse, error) {
+	results := make([]*faiss_index.RecallResponse, len(requests))
+	if !batchSupported(pool) {
+		return results, ErrBatchRecallUnimplemented
+	}
+
+	var reply interface{}
+	err := retry.Downstream(retry.Faiss).Do(ctx, func(ctx context.Context) error {
+		var err error
+		reply, err = pool.Invoke(ctx, key, func(ctx context.Context, conn *grpc.ClientConn) (interface{}, error) {
+			return faiss_index.NewGrpcRecallServerServiceClient(conn).GrpcBatchRecall(ctx, &faiss_index.BatchRecallRequest{RecallRequest_: requests})
+		})
+		return err
+	})
+	if status.Code(err) == codes.Unimplemented {
+		batchUnsupported.Store(pool, time.Now())
+		return results, ErrBatchRecallUnimplemented
+	}
+	if err != nil {
+		return results, err
+	}
+
+	responses := reply.(*faiss_index.BatchRecallResponse).GetRecallResponse_()
+	if len(responses) != len(requests) {
+		return results, fmt.Errorf("batch recall of %d requests, %d responses", len(requests), len(responses))
+	}
+
+	return responses, nil
+}
+
+// GrpcBatchRecall was not found unimplemented on the pool, or it is time to probe again.
+func batchSupported(pool *internal.GRPCPool) bool {
+	unsupportedAt, ok := batchUnsupported.Load(pool)
+	if !ok {
+		return true
+	}
+	if time.Since(unsupportedAt.(time.Time)) < BatchRecallProbeInterval {
+		return false
+	}
+	batchUnsupported.Delete(pool)
+
+	return true
+}
+
+// recall the requests concurrently, one GrpcRecall per request.
+func recallEach(ctx context.Context, pool *internal.GRPCPool, key string, requests []*faiss_index.RecallRequest) ([]*faiss_index.RecallResponse, error) {
+	results := make([]*faiss_index.RecallResponse, len(requests))
+	errs := make([]error, len(requests))
+	var wg sync.WaitGroup
+	for i, request := range requests {
+		wg.Add(1)
+		go func(i int, request *faiss_index.RecallRequest) {
+			defer wg.Done()
+			results[i], errs[i] = recall(ctx, pool, key, request)
+		}(i, request)
+	}
+	wg.Wait()
+
+	return results, errors.Join(errs...)
+}
+
+// recall a request by GrpcRecall, a slow recall is hedged to another faiss server if the faiss pool enables hedging.
+func recall(ctx context.Context, pool *internal.GRPCPool, key string, request *faiss_index.RecallRequest) (*faiss_index.RecallResponse, error) {
+	var reply interface{}
+	err := retry.Downstream(retry.Faiss).Do(ctx, func(ctx context.Context) error {
+		var err error
+		reply, err = pool.Invoke(ctx, key, func(ctx context.Context, conn *grpc.ClientConn) (interface{}, error) {
+			return faiss_index.NewGrpcRecallServerServiceClient(conn).GrpcRecall(ctx, request)
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reply.(*faiss_index.RecallResponse), nil
+}
@@ -0,0 +1,143 @@
+package faiss
+
+import (
+	"context"
+	"errors"
+	"infer-microservices/internal"
+	faiss_index "infer-microservices/internal/faiss_gogofaster"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+type recallServer struct {
+	faiss_index.UnimplementedGrpcRecallServerServiceServer
+	batch        bool //implement GrpcBatchRecall.
+	recalls      int32
+	batchRecalls int32
+}
+
+func (s *recallServer) GrpcRecall(ctx context.Context, in *faiss_index.RecallRequest) (*faiss_index.RecallResponse, error) {
+	atomic.AddInt32(&s.recalls, 1)
+	return recallResponse(in), nil
+}
+
+func (s *recallServer) GrpcBatchRecall(ctx context.Context, in *faiss_index.BatchRecallRequest) (*faiss_index.BatchRecallResponse, error) {
+	if !s.batch {
+		return s.UnimplementedGrpcRecallServerServiceServer.GrpcBatchRecall(ctx, in)
+	}
+	atomic.AddInt32(&s.batchRecalls, 1)
+	response := &faiss_index.BatchRecallResponse{}
+	for _, request := range in.GetRecallRequest_() {
+		response.RecallResponse_ = append(response.RecallResponse_, recallResponse(request))
+	}
+	return response, nil
+}
+
+// recall num items named by the index.
+func recallResponse(in *faiss_index.RecallRequest) *faiss_index.RecallResponse {
+	response := &faiss_index.RecallResponse{IndexName: in.GetIndexName()}
+	for i := int32(0); i < in.GetRecallNum(); i++ {
+		response.ItemInfo_ = append(response.ItemInfo_, &faiss_index.ItemInfo{ItemId: in.GetIndexName()})
+	}
+	return response
+}
+
+func startRecallServer(t *testing.T, server *recallServer) *internal.GRPCPool {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer := grpc.NewServer()
+	faiss_index.RegisterGrpcRecallServerServiceServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	o := internal.NewOptions()
+	o.InitTargets = []string{listener.Addr().String()}
+	o.InitCap = 1
+	pool, err := internal.NewGRPCPool(o, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	return pool
+}
+
+func testRequests() []*faiss_index.RecallRequest {
+	return []*faiss_index.RecallRequest{
+		{IndexName: "index_a", RecallNum: 2},
+		{IndexName: "index_b", RecallNum: 3},
+	}
+}
+
+func checkResults(t *testing.T, results []*faiss_index.RecallResponse) {
+	for i, request := range testRequests() {
+		items := results[i].GetItemInfo_()
+		if len(items) != int(request.RecallNum) {
+			t.Errorf("%s recall %d items, expect %d", request.IndexName, len(items), request.RecallNum)
+		}
+		for _, item := range items {
+			if item.ItemId != request.IndexName {
+				t.Errorf("%s recall item of %s", request.IndexName, item.ItemId)
+			}
+		}
+	}
+}
+
+func TestBatchRecall(t *testing.T) {
+	server := &recallServer{batch: true}
+	pool := startRecallServer(t, server)
+
+	results, err := batchRecall(context.Background(), pool, "", testRequests())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResults(t, results)
+	if server.batchRecalls != 1 || server.recalls != 0 {
+		t.Errorf("batch recalls %d, recalls %d, expect one batch recall", server.batchRecalls, server.recalls)
+	}
+}
+
+func TestBatchRecallFallback(t *testing.T) {
+	server := &recallServer{}
+	pool := startRecallServer(t, server)
+
+	for i := 0; i < 2; i++ {
+		results, err := batchRecall(context.Background(), pool, "", testRequests())
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkResults(t, results)
+	}
+	if server.recalls != 4 {
+		t.Errorf("recalls %d, expect 4 by the fallback", server.recalls)
+	}
+	if batchSupported(pool) {
+		t.Errorf("the pool should be marked without batch recall")
+	}
+
+	batchUnsupported.Store(pool, time.Now().Add(-BatchRecallProbeInterval))
+	if !batchSupported(pool) {
+		t.Errorf("the pool should be probed again after the interval")
+	}
+}
+
+func TestGrpcBatchRecallUnimplemented(t *testing.T) {
+	server := &recallServer{}
+	pool := startRecallServer(t, server)
+
+	//the caller falls back to its own per request recalls, no GrpcRecall is made here.
+	for i := 0; i < 2; i++ {
+		if _, err := grpcBatchRecall(context.Background(), pool, "", testRequests()); !errors.Is(err, ErrBatchRecallUnimplemented) {
+			t.Fatalf("err %v, expect ErrBatchRecallUnimplemented", err)
+		}
+	}
+	if server.recalls != 0 {
+		t.Errorf("recalls %d, expect none", server.recalls)
+	}
+}
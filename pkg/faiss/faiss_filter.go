@@ -0,0 +1,244 @@
+package faiss
+
+import (
+	faiss_index "infer-microservices/internal/faiss_gogofaster"
+	"infer-microservices/internal/logs"
+	"infer-microservices/pkg/config_loader/config_schema"
+	"infer-microservices/pkg/config_loader/faiss_config"
+	"infer-microservices/pkg/services/io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecallFilter the filters of a recall from the request, the filters of the index rules are added per index.
+type RecallFilter struct {
+	IncludeIds []string                        //recall only these items.
+	ExcludeIds []string                        //do not recall these items.
+	Attributes []config_schema.AttributeFilter //attribute predicates of the request.
+	Context    map[string]string               //request context, the ${key} values of the index rules.
+}
+
+// NewRecallFilter the filters of a request.
+func NewRecallFilter(in *io.RecRequest) *RecallFilter {
+	return &RecallFilter{
+		IncludeIds: in.GetIncludeItems(),
+		ExcludeIds: in.GetExcludeItems(),
+		Attributes: in.GetFilters(),
+		Context:    in.GetContext(),
+	}
+}
+
+// indexFilter the filters of a recall of an index.
+type indexFilter struct {
+	include    map[string]bool
+	exclude    map[string]bool
+	attributes []config_schema.AttributeFilter
+	recallNum  int //the items wanted, the fetched ones may be more.
+}
+
+// the filters of an index recalling recallNum items: the filters of the request, and the index rules resolved by the
+// request context. nil if nothing is filtered.
+func (r *RecallFilter) forIndex(f *faiss_config.FaissIndexConfig, recallNum int) *indexFilter {
+	attributes := make([]config_schema.AttributeFilter, 0)
+	for _, rule := range f.GetFilters() {
+		if resolved, ok := resolveRule(rule, r.context()); ok {
+			attributes = append(attributes, resolved)
+		}
+	}
+	if r != nil {
+		attributes = append(attributes, r.Attributes...)
+	}
+	if len(attributes) == 0 && (r == nil || len(r.IncludeIds) == 0 && len(r.ExcludeIds) == 0) {
+		return nil
+	}
+
+	filter := &indexFilter{attributes: attributes, recallNum: recallNum}
+	if r != nil {
+		filter.include = idSet(r.IncludeIds)
+		filter.exclude = idSet(r.ExcludeIds)
+	}
+
+	return filter
+}
+
+// excluding a copy of the filter which also excludes itemId, the filter itself is not changed.
+func (r *RecallFilter) excluding(itemId string) *RecallFilter {
+	excluded := &RecallFilter{}
+	if r != nil {
+		*excluded = *r
+	}
+	excluded.ExcludeIds = append(append(make([]string, 0, len(excluded.ExcludeIds)+1), excluded.ExcludeIds...), itemId)
+
+	return excluded
+}
+
+func (r *RecallFilter) context() map[string]string {
+	if r == nil {
+		return nil
+	}
+
+	return r.Context
+}
+
+// a rule with ${key} values takes them from the request context, it is skipped if the context has no such key.
+func resolveRule(rule config_schema.AttributeFilter, context map[string]string) (config_schema.AttributeFilter, bool) {
+	values := make([]string, 0, len(rule.Values))
+	for _, value := range rule.Values {
+		if strings.HasPrefix(value, "${") && strings.HasSuffix(value, "}") {
+			contextValue, ok := context[value[2:len(value)-1]]
+			if !ok {
+				return rule, false
+			}
+			value = contextValue
+		}
+		values = append(values, value)
+	}
+	resolved := config_schema.AttributeFilter{Attribute: rule.Attribute, Op: rule.Op, Values: values}
+
+	return resolved, resolved.Check() == nil
+}
+
+func idSet(ids []string) map[string]bool {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+
+	return set
+}
+
+// apply the filters to the recall request, the faiss server filters them natively if it can.
+// the recall num is over fetched for the post-filter, unless the server filtered the last recall natively.
+func (i *indexFilter) apply(f *faiss_config.FaissIndexConfig, request *faiss_index.RecallRequest) {
+	for id := range i.include {
+		request.IncludeIds = append(request.IncludeIds, id)
+	}
+	for id := range i.exclude {
+		request.ExcludeIds = append(request.ExcludeIds, id)
+	}
+	for _, attribute := range i.attributes {
+		request.AttributeFilters = append(request.AttributeFilters, &faiss_index.AttributeFilter{
+			Attribute: attribute.Attribute,
+			Op:        attribute.Op,
+			Values:    attribute.Values,
+		})
+	}
+	request.RecallNum = int32(f.FetchNum(i.recallNum))
+}
+
+// filter the items of the response if the faiss server did not, at most recallNum items are kept.
+// if the items have no attributes, the attribute predicates are not matched and the pass ratio is not observed,
+// over fetching does not help.
+func (i *indexFilter) filter(f *faiss_config.FaissIndexConfig, response *faiss_index.RecallResponse) []*faiss_index.ItemInfo {
+	items := response.GetItemInfo_()
+	if response.GetFiltered() {
+		f.ObserveFilter(len(items), len(items), true)
+		return truncate(items, i.recallNum)
+	}
+
+	attributesAvailable := len(i.attributes) == 0 || hasAttributes(items)
+	if f.ObserveAttributes(attributesAvailable) && !attributesAvailable {
+		logs.Warn(f.GetIndexName(), time.Now(), "faiss returns no item attributes, the attribute filters match no item.")
+	}
+
+	passed := make([]*faiss_index.ItemInfo, 0, len(items))
+	for _, item := range items {
+		if i.pass(item) {
+			passed = append(passed, item)
+		}
+	}
+	if attributesAvailable {
+		f.ObserveFilter(len(items), len(passed), false)
+	}
+
+	return truncate(passed, i.recallNum)
+}
+
+// hasAttributes any item has attributes, a faiss server which does not return them returns none.
+func hasAttributes(items []*faiss_index.ItemInfo) bool {
+	for _, item := range items {
+		if len(item.GetAttributes()) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pass the item passes the filters.
+func (i *indexFilter) pass(item *faiss_index.ItemInfo) bool {
+	return i.passItem(item.GetItemId(), item.GetAttributes())
+}
+
+// passItem the item passes the id filters and the attribute predicates, a missing attribute is a non-match, see match.
+func (i *indexFilter) passItem(itemId string, attributes map[string]string) bool {
+	if i.exclude[itemId] {
+		return false
+	}
+	if i.include != nil && !i.include[itemId] {
+		return false
+	}
+	for _, attribute := range i.attributes {
+		if !match(attribute, attributes) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// match an attribute predicate, an item without the attribute only matches ne / not_in.
+func match(filter config_schema.AttributeFilter, attributes map[string]string) bool {
+	value, ok := attributes[filter.Attribute]
+	switch filter.Op {
+	case config_schema.FilterEq:
+		return ok && value == filter.Values[0]
+	case config_schema.FilterNe:
+		return !ok || value != filter.Values[0]
+	case config_schema.FilterIn:
+		return ok && contains(filter.Values, value)
+	case config_schema.FilterNotIn:
+		return !ok || !contains(filter.Values, value)
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if !ok || err != nil {
+		return false
+	}
+	bound, _ := strconv.ParseFloat(filter.Values[0], 64)
+	switch filter.Op {
+	case config_schema.FilterLt:
+		return number < bound
+	case config_schema.FilterLe:
+		return number <= bound
+	case config_schema.FilterGt:
+		return number > bound
+	case config_schema.FilterGe:
+		return number >= bound
+	}
+
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func truncate(items []*faiss_index.ItemInfo, recallNum int) []*faiss_index.ItemInfo {
+	if recallNum > 0 && len(items) > recallNum {
+		return items[:recallNum]
+	}
+
+	return items
+}
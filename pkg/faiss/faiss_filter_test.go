@@ -0,0 +1,85 @@
+package faiss
+
+import (
+	faiss_index "infer-microservices/internal/faiss_gogofaster"
+	"infer-microservices/pkg/config_loader/config_schema"
+	"infer-microservices/pkg/config_loader/faiss_config"
+	"testing"
+)
+
+func TestResolveRule(t *testing.T) {
+	rule := config_schema.AttributeFilter{Attribute: "region", Op: config_schema.FilterEq, Values: []string{"${country}"}}
+
+	resolved, ok := resolveRule(rule, map[string]string{"country": "us"})
+	if !ok || resolved.Values[0] != "us" || rule.Values[0] != "${country}" {
+		t.Errorf("rule not resolved by the context: %+v", resolved)
+	}
+	if _, ok := resolveRule(rule, nil); ok {
+		t.Errorf("rule without the context key should be skipped")
+	}
+}
+
+func TestIndexFilter(t *testing.T) {
+	filter := &indexFilter{
+		exclude: idSet([]string{"seen"}),
+		attributes: []config_schema.AttributeFilter{
+			{Attribute: "stock", Op: config_schema.FilterGt, Values: []string{"0"}},
+			{Attribute: "region", Op: config_schema.FilterIn, Values: []string{"us", "ca"}},
+		},
+		recallNum: 2,
+	}
+	response := &faiss_index.RecallResponse{ItemInfo_: []*faiss_index.ItemInfo{
+		{ItemId: "seen", Attributes: map[string]string{"stock": "1", "region": "us"}},
+		{ItemId: "out_of_stock", Attributes: map[string]string{"stock": "0", "region": "us"}},
+		{ItemId: "other_region", Attributes: map[string]string{"stock": "1", "region": "uk"}},
+		{ItemId: "a", Attributes: map[string]string{"stock": "1", "region": "us"}},
+		{ItemId: "b", Attributes: map[string]string{"stock": "2", "region": "ca"}},
+		{ItemId: "c", Attributes: map[string]string{"stock": "3", "region": "ca"}},
+	}}
+
+	items := filter.filter(&faiss_config.FaissIndexConfig{}, response)
+	if len(items) != 2 || items[0].ItemId != "a" || items[1].ItemId != "b" {
+		t.Errorf("filtered items: %v", items)
+	}
+
+	//filtered by the faiss server, only truncated.
+	response.Filtered = true
+	if items := filter.filter(&faiss_config.FaissIndexConfig{}, response); len(items) != 2 || items[0].ItemId != "seen" {
+		t.Errorf("natively filtered items should not be filtered again: %v", items)
+	}
+}
+
+func TestIndexFilterNoAttributes(t *testing.T) {
+	filter := &indexFilter{
+		attributes: []config_schema.AttributeFilter{
+			{Attribute: "region", Op: config_schema.FilterEq, Values: []string{"us"}},
+		},
+		recallNum: 2,
+	}
+	if filter.passItem("a", nil) {
+		t.Errorf("an item without the attribute should not match")
+	}
+
+	//the faiss server returns no attributes, nothing passes and the index is not over fetched for it.
+	f := &faiss_config.FaissIndexConfig{}
+	response := &faiss_index.RecallResponse{ItemInfo_: []*faiss_index.ItemInfo{{ItemId: "a"}, {ItemId: "b"}}}
+	if items := filter.filter(f, response); len(items) != 0 {
+		t.Errorf("items without attributes should be filtered out: %v", items)
+	}
+	if fetchNum := f.FetchNum(2); fetchNum != 2 {
+		t.Errorf("fetch num %d without attributes, expect 2", fetchNum)
+	}
+}
+
+func TestSimilarItemsFilter(t *testing.T) {
+	filter := &RecallFilter{ExcludeIds: []string{"seen"}}
+	for _, recallFilter := range []*RecallFilter{nil, filter} {
+		excluded := recallFilter.excluding("source").forIndex(&faiss_config.FaissIndexConfig{}, 10)
+		if excluded == nil || excluded.passItem("source", nil) || !excluded.passItem("other", nil) {
+			t.Errorf("the source item should be excluded: %+v", excluded)
+		}
+	}
+	if len(filter.ExcludeIds) != 1 {
+		t.Errorf("the request filter should not be changed: %v", filter.ExcludeIds)
+	}
+}
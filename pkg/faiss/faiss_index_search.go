@@ -10,44 +10,37 @@ import (
 	"time"
 )
 
-var grpcTimeout int64
+var grpcTimeoutMs *int64
 
 func init() {
 	flagFactory := flags.FlagFactory{}
 	flagTensorflow := flagFactory.CreateFlagTensorflow()
-	grpcTimeout = *flagTensorflow.GetTfservingTimeoutMs()
+	grpcTimeoutMs = flagTensorflow.GetTfservingTimeoutMs()
 }
-func FaissVectorSearch(f *faiss_config.FaissIndexConfig, example feature.ExampleFeatures, vector []float32) ([]*faiss_index.ItemInfo, error) {
+func FaissVectorSearch(ctx context.Context, f *faiss_config.FaissIndexConfig, example feature.ExampleFeatures, vector []float32, filter *RecallFilter) ([]*faiss_index.ItemInfo, error) {
+	if f.GetLocalIndex() != nil {
+		return localSearch(f, vector, filter.forIndex(f, f.GetRecallNum()), f.GetRecallNum())
+	}
 
-	faissIndexs := f.GetFaissIndexs()
-	faissGrpcConn, err := f.GetFaissGrpcPool().Get()
+	index_conf_tmp, err := recallRequest(f, vector, f.GetRecallNum())
 	if err != nil {
 		return nil, err
 	}
-
-	defer f.GetFaissGrpcPool().Put(faissGrpcConn)
-
-	faissClient := faiss_index.NewGrpcRecallServerServiceClient(faissGrpcConn)
-	vector_info := faiss_index.UserVectorInfo{
-		UserVector: vector,
-	}
-
-	index_conf_tmp := &faiss_index.RecallRequest{
-		IndexName:       faissIndexs.IndexName,
-		UserVectorInfo_: &vector_info,
-		RecallNum:       faissIndexs.RecallNum,
+	indexFilter := filter.forIndex(f, f.GetRecallNum())
+	if indexFilter != nil {
+		indexFilter.apply(f, index_conf_tmp)
 	}
 
 	if len(*example.UserExampleFeatures.Buff) == 0 || len(*example.UserContextExampleFeatures.Buff) == 0 {
 		return make([]*faiss_index.ItemInfo, 0), nil
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(grpcTimeout)*time.Millisecond)
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(*grpcTimeoutMs)*time.Millisecond)
 	defer cancel()
 
-	rst, err := faissClient.GrpcRecall(ctx, index_conf_tmp)
+	response, err := recall(ctx, f.GetFaissGrpcPool(), example.UserId(), index_conf_tmp)
 	if err != nil {
 		return nil, err
 	}
 
-	return rst.ItemInfo_, nil
+	return filterItems(f, indexFilter, response), nil
 }
@@ -0,0 +1,33 @@
+package faiss
+
+import (
+	faiss_index "infer-microservices/internal/faiss_gogofaster"
+	"infer-microservices/pkg/ann"
+	"infer-microservices/pkg/config_loader/faiss_config"
+)
+
+// localSearch recall recallNum items of an index of the local engine in process, the filters are applied while searching, no over fetch.
+func localSearch(f *faiss_config.FaissIndexConfig, vector []float32, filter *indexFilter, recallNum int) ([]*faiss_index.ItemInfo, error) {
+	var annFilter ann.Filter
+	if filter != nil {
+		annFilter = func(item *ann.Item) bool {
+			return filter.passItem(item.Id, item.Attributes)
+		}
+	}
+
+	results, err := f.GetLocalIndex().Search(vector, recallNum, annFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*faiss_index.ItemInfo, 0, len(results))
+	for _, result := range results {
+		items = append(items, &faiss_index.ItemInfo{
+			ItemId:     result.Item.Id,
+			Score:      result.Score,
+			Attributes: result.Item.Attributes,
+		})
+	}
+
+	return items, nil
+}
@@ -0,0 +1,76 @@
+package faiss
+
+import (
+	faiss_index "infer-microservices/internal/faiss_gogofaster"
+	"infer-microservices/pkg/config_loader/faiss_config"
+	"math"
+	"sort"
+)
+
+// RecallDedupOverFetch the indexes of a split recall fetch 20% more than their shares, the items recalled by many indexes
+// are merged into one.
+const RecallDedupOverFetch = 0.2
+
+// SplitRecallNum the recall num of each index. recallNum of the request is split by the proportions of the indexes, and
+// over fetched for the duplicates if there are many indexes. 0 uses the recall num of each index.
+func SplitRecallNum(indexConfigs []faiss_config.FaissIndexConfig, recallNum int) []int {
+	recallNums := make([]int, len(indexConfigs))
+	totalProportion := 0
+	for i := range indexConfigs {
+		recallNums[i] = indexConfigs[i].GetRecallNum()
+		totalProportion += proportion(&indexConfigs[i])
+	}
+	if recallNum <= 0 || totalProportion == 0 {
+		return recallNums
+	}
+
+	overFetch := 1.0
+	if len(indexConfigs) > 1 {
+		overFetch += RecallDedupOverFetch
+	}
+	for i := range indexConfigs {
+		share := float64(recallNum) * float64(proportion(&indexConfigs[i])) / float64(totalProportion)
+		recallNums[i] = int(math.Ceil(share * overFetch))
+		if recallNums[i] < 1 {
+			recallNums[i] = 1
+		}
+	}
+
+	return recallNums
+}
+
+// the proportion of an index, the recall num of the index if not set.
+func proportion(f *faiss_config.FaissIndexConfig) int {
+	if f.GetProportion() > 0 {
+		return f.GetProportion()
+	}
+
+	return f.GetRecallNum()
+}
+
+// MergeItems merge the recall results of the indexes, an item recalled by many indexes is kept once with its highest score.
+// the items are sorted by score, the first recallNum are kept, 0 keeps all.
+func MergeItems(results [][]*faiss_index.ItemInfo, recallNum int) []*faiss_index.ItemInfo {
+	merged := make([]*faiss_index.ItemInfo, 0)
+	positions := make(map[string]int, 0)
+	for _, items := range results {
+		for _, item := range items {
+			if item == nil {
+				continue
+			}
+			position, ok := positions[item.ItemId]
+			if !ok {
+				positions[item.ItemId] = len(merged)
+				merged = append(merged, item)
+			} else if item.Score > merged[position].Score {
+				merged[position] = item
+			}
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Score > merged[j].Score
+	})
+
+	return truncate(merged, recallNum)
+}
@@ -0,0 +1,51 @@
+package faiss
+
+import (
+	faiss_index "infer-microservices/internal/faiss_gogofaster"
+	"infer-microservices/pkg/config_loader/faiss_config"
+	"testing"
+)
+
+func TestSplitRecallNum(t *testing.T) {
+	indexConfigs := make([]faiss_config.FaissIndexConfig, 2)
+	indexConfigs[0].SetRecallNum(300)
+	indexConfigs[1].SetRecallNum(100)
+
+	//the recall nums of the indexes without the request recall num.
+	if recallNums := SplitRecallNum(indexConfigs, 0); recallNums[0] != 300 || recallNums[1] != 100 {
+		t.Errorf("recall nums %v, expect the ones of the indexes", recallNums)
+	}
+	//split by the proportions, over fetched for the duplicates.
+	if recallNums := SplitRecallNum(indexConfigs, 100); recallNums[0] != 90 || recallNums[1] != 30 {
+		t.Errorf("recall nums %v, expect [90 30]", recallNums)
+	}
+	//one index, no duplicates.
+	if recallNums := SplitRecallNum(indexConfigs[:1], 100); recallNums[0] != 100 {
+		t.Errorf("recall nums %v, expect [100]", recallNums)
+	}
+}
+
+func TestMergeItems(t *testing.T) {
+	results := [][]*faiss_index.ItemInfo{
+		{{ItemId: "a", Score: 0.9}, {ItemId: "b", Score: 0.5}},
+		nil,
+		{{ItemId: "b", Score: 0.8}, {ItemId: "c", Score: 0.7}, {ItemId: "d", Score: 0.1}},
+	}
+
+	merged := MergeItems(results, 3)
+	expects := []string{"a", "b", "c"}
+	if len(merged) != len(expects) {
+		t.Fatalf("merged %d items, expect %d", len(merged), len(expects))
+	}
+	for i, expect := range expects {
+		if merged[i].ItemId != expect {
+			t.Errorf("merged item %d is %s, expect %s", i, merged[i].ItemId, expect)
+		}
+	}
+	if merged[1].Score != 0.8 {
+		t.Errorf("duplicate item should keep the highest score, got %v", merged[1].Score)
+	}
+	if len(MergeItems(results, 0)) != 4 {
+		t.Errorf("recallNum 0 should keep all items")
+	}
+}
@@ -0,0 +1,86 @@
+package faiss
+
+import (
+	"context"
+	faiss_index "infer-microservices/internal/faiss_gogofaster"
+	"infer-microservices/pkg/config_loader/faiss_config"
+	"infer-microservices/pkg/feature"
+	"time"
+)
+
+// IndexResult the recall result of an index of a streaming recall.
+type IndexResult struct {
+	Position  int //the position of the index in the index configs.
+	IndexName string
+	Items     []*faiss_index.ItemInfo
+	Err       error
+}
+
+// FaissMultiIndexSearchStream recall like FaissMultiIndexSearch, the result of each index is sent as soon as the index
+// responds, so the remote indexes are recalled by one GrpcRecall each instead of a GrpcBatchRecall. the channel gets
+// one result per index and is closed after the last one, the recalls not done when ctx is done fail by ctx.Err().
+func FaissMultiIndexSearchStream(ctx context.Context, indexConfigs []faiss_config.FaissIndexConfig, example feature.ExampleFeatures, vector []float32, filter *RecallFilter, recallNum int) <-chan IndexResult {
+	resultCh := make(chan IndexResult, len(indexConfigs))
+	if len(*example.UserExampleFeatures.Buff) == 0 || len(*example.UserContextExampleFeatures.Buff) == 0 {
+		for i := range indexConfigs {
+			resultCh <- IndexResult{Position: i, IndexName: indexConfigs[i].GetIndexName()}
+		}
+		close(resultCh)
+		return resultCh
+	}
+
+	go multiIndexSearchStream(ctx, indexConfigs, example.UserId(), vector, filter, recallNum, resultCh)
+
+	return resultCh
+}
+
+func multiIndexSearchStream(ctx context.Context, indexConfigs []faiss_config.FaissIndexConfig, key string, vector []float32, filter *RecallFilter, recallNum int, resultCh chan<- IndexResult) {
+	defer close(resultCh)
+	recallNums := SplitRecallNum(indexConfigs, recallNum)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(*grpcTimeoutMs)*time.Millisecond)
+	defer cancel()
+
+	doneCh := make(chan IndexResult, len(indexConfigs))
+	for i := range indexConfigs {
+		go func(i int) {
+			indexConfig := &indexConfigs[i]
+			result := IndexResult{Position: i, IndexName: indexConfig.GetIndexName()}
+			indexFilter := filter.forIndex(indexConfig, recallNums[i])
+			if indexConfig.GetLocalIndex() != nil {
+				result.Items, result.Err = localSearch(indexConfig, vector, indexFilter, recallNums[i])
+				doneCh <- result
+				return
+			}
+
+			request, err := recallRequest(indexConfig, vector, recallNums[i])
+			if err != nil {
+				result.Err = err
+				doneCh <- result
+				return
+			}
+			if indexFilter != nil {
+				indexFilter.apply(indexConfig, request)
+			}
+			response, err := recall(ctx, indexConfig.GetFaissGrpcPool(), key, request)
+			result.Items, result.Err = filterItems(indexConfig, indexFilter, response), err
+			doneCh <- result
+		}(i)
+	}
+
+	done := make([]bool, len(indexConfigs))
+	for received := 0; received < len(indexConfigs); received++ {
+		select {
+		case <-ctx.Done():
+			for i := range indexConfigs {
+				if !done[i] {
+					resultCh <- IndexResult{Position: i, IndexName: indexConfigs[i].GetIndexName(), Err: ctx.Err()}
+				}
+			}
+			return
+		case result := <-doneCh:
+			done[result.Position] = true
+			resultCh <- result
+		}
+	}
+}
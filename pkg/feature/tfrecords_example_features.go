@@ -10,3 +10,12 @@ type ExampleFeatures struct {
 	UserContextExampleFeatures *SeqExampleBuff
 	ItemSeqExampleFeatures     *[]SeqExampleBuff
 }
+
+// UserId the key of the user examples, "" if it is not set.
+func (e ExampleFeatures) UserId() string {
+	if e.UserExampleFeatures == nil || e.UserExampleFeatures.Key == nil {
+		return ""
+	}
+
+	return *e.UserExampleFeatures.Key
+}
@@ -0,0 +1,58 @@
+package membership
+
+import (
+	"infer-microservices/internal/logs"
+	"infer-microservices/internal/utils"
+	"sync"
+)
+
+const auditLogSize = 1000
+
+// keep the latest applied events in memory, all events are written to the log as well.
+type membershipAuditLog struct {
+	mu      sync.Mutex
+	records []MembershipEventResult
+	next    int
+	full    bool
+}
+
+func newMembershipAuditLog(size int) *membershipAuditLog {
+	return &membershipAuditLog{
+		records: make([]MembershipEventResult, size),
+	}
+}
+
+func (a *membershipAuditLog) record(result MembershipEventResult) {
+	logs.Info("membership audit:", utils.ConvertStructToJson(result))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.records[a.next] = result
+	a.next = (a.next + 1) % len(a.records)
+	if a.next == 0 {
+		a.full = true
+	}
+}
+
+// recent events, newest first.
+func (a *membershipAuditLog) recent(limit int) []MembershipEventResult {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	size := a.next
+	if a.full {
+		size = len(a.records)
+	}
+	if limit <= 0 || limit > size {
+		limit = size
+	}
+
+	results := make([]MembershipEventResult, 0, limit)
+	for i := 1; i <= limit; i++ {
+		idx := (a.next - i + len(a.records)) % len(a.records)
+		results = append(results, a.records[idx])
+	}
+
+	return results
+}
@@ -0,0 +1,82 @@
+package membership
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	ActionAdd    = "add"
+	ActionRemove = "remove"
+	ActionReload = "reload"
+
+	TargetUser = "user"
+	TargetItem = "item"
+
+	SourceAdmin = "admin"
+	SourceKafka = "kafka"
+	SourceFile  = "file"
+)
+
+var eventSeq uint64
+
+// MembershipEvent is one auditable change of the user / item filter.
+// INFO: admin api and kafka topic share the same json format, offline pipeline publish it to kafka.
+type MembershipEvent struct {
+	EventId   string   `json:"eventId"`
+	Action    string   `json:"action"` //add, remove or reload
+	Target    string   `json:"target"` //user or item
+	Ids       []string `json:"ids"`
+	Path      string   `json:"path"` //reload file path in membership_reload_dir, one id per line.
+	Source    string   `json:"source"`
+	Operator  string   `json:"operator"`
+	Timestamp int64    `json:"timestamp"`
+}
+
+// MembershipEventResult is the audit record of an applied event.
+type MembershipEventResult struct {
+	Event     MembershipEvent `json:"event"`
+	Applied   int             `json:"applied"`
+	Success   bool            `json:"success"`
+	Message   string          `json:"message"`
+	ElapsedMs int64           `json:"elapsedMs"`
+}
+
+// fill the fields which producer not set.
+func (e *MembershipEvent) complete(source string) {
+	e.Action = strings.ToLower(strings.TrimSpace(e.Action))
+	e.Target = strings.ToLower(strings.TrimSpace(e.Target))
+	if e.Source == "" {
+		e.Source = source
+	}
+	if e.Timestamp == 0 {
+		e.Timestamp = time.Now().Unix()
+	}
+	if e.EventId == "" {
+		e.EventId = fmt.Sprintf("%s-%d-%d", e.Source, time.Now().UnixNano(), atomic.AddUint64(&eventSeq, 1))
+	}
+}
+
+func (e *MembershipEvent) Check() error {
+	if e.Target != TargetUser && e.Target != TargetItem {
+		return fmt.Errorf("unknown membership target %q, should be user or item", e.Target)
+	}
+
+	switch e.Action {
+	case ActionAdd, ActionRemove:
+		if len(e.Ids) == 0 {
+			return errors.New("ids can not be empty")
+		}
+	case ActionReload:
+		if e.Path == "" {
+			return errors.New("reload path can not be empty")
+		}
+	default:
+		return fmt.Errorf("unknown membership action %q, should be add, remove or reload", e.Action)
+	}
+
+	return nil
+}
@@ -0,0 +1,270 @@
+package membership
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"infer-microservices/internal"
+	"infer-microservices/internal/flags"
+	"infer-microservices/internal/logs"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var membershipManagerInstance *MembershipManager
+var membershipManagerOnce sync.Once
+var membershipReloadDir *string
+
+func init() {
+	flagFactory := flags.FlagFactory{}
+	membershipReloadDir = flagFactory.CreateFlagBloom().GetMembershipReloadDir()
+}
+
+// MembershipManager apply membership events to the user / item filters.
+type MembershipManager struct {
+	targets   map[string]*targetState
+	auditLog  *membershipAuditLog
+	reloadDir string //the reload files are in it.
+}
+
+// changes which arrive while a full reload is running are replayed on the new filter.
+type targetState struct {
+	mu        sync.Mutex
+	filter    *internal.MembershipFilter
+	reloading bool
+	pending   []MembershipEvent
+}
+
+// singleton instance, created on the first use as the filters of the flags.
+func GetMembershipManagerInstance() *MembershipManager {
+	membershipManagerOnce.Do(func() {
+		membershipManagerInstance = &MembershipManager{
+			targets: map[string]*targetState{
+				TargetUser: {filter: internal.GetUserBloomFilterInstance()},
+				TargetItem: {filter: internal.GetItemBloomFilterInstance()},
+			},
+			auditLog:  newMembershipAuditLog(auditLogSize),
+			reloadDir: *membershipReloadDir,
+		}
+	})
+
+	return membershipManagerInstance
+}
+
+// Apply validate the event, apply it to the filter and write the audit record.
+func (m *MembershipManager) Apply(event MembershipEvent, source string) (MembershipEventResult, error) {
+	start := time.Now()
+	event.complete(source)
+	result := MembershipEventResult{
+		Event: event,
+	}
+
+	err := event.Check()
+	if err == nil {
+		state := m.targets[event.Target]
+		switch event.Action {
+		case ActionAdd, ActionRemove:
+			result.Applied = state.apply(event)
+		case ActionReload:
+			var path string
+			path, err = reloadFilePath(m.reloadDir, event.Path)
+			if err == nil {
+				result.Applied, err = state.reload(path)
+			}
+		}
+	}
+
+	result.ElapsedMs = time.Since(start).Milliseconds()
+	result.Success = err == nil
+	if err != nil {
+		result.Message = err.Error()
+	} else {
+		result.Message = "success"
+	}
+	m.auditLog.record(result)
+
+	return result, err
+}
+
+// Contains query membership of ids.
+func (m *MembershipManager) Contains(target string, ids []string) (map[string]bool, error) {
+	state, ok := m.targets[target]
+	if !ok {
+		return nil, errors.New("unknown membership target " + target)
+	}
+
+	result := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		result[id] = state.filter.Contains(id)
+	}
+
+	return result, nil
+}
+
+// Events recent applied events, newest first.
+func (m *MembershipManager) Events(limit int) []MembershipEventResult {
+	return m.auditLog.recent(limit)
+}
+
+// ApplyKafkaMessage is the kafka callback, message value is a MembershipEvent json.
+func (m *MembershipManager) ApplyKafkaMessage(msgKey string, msgValue string) {
+	event := MembershipEvent{}
+	err := json.Unmarshal([]byte(msgValue), &event)
+	if err != nil {
+		logs.Error("membership event unmarshal failed, key:", msgKey, err)
+		return
+	}
+
+	_, err = m.Apply(event, SourceKafka)
+	if err != nil {
+		logs.Error("membership event apply failed, key:", msgKey, err)
+	}
+}
+
+// StartMembershipConsumer consume membership events from kafka.
+func StartMembershipConsumer() {
+	internal.KafkaMembershipConsumer(GetMembershipManagerInstance().ApplyKafkaMessage)
+}
+
+func (s *targetState) apply(event MembershipEvent) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	applied := applyIds(s.filter, event.Action, event.Ids)
+	if s.reloading {
+		s.pending = append(s.pending, event)
+	}
+
+	return applied
+}
+
+func applyIds(filter *internal.MembershipFilter, action string, ids []string) int {
+	applied := 0
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if action == ActionAdd {
+			filter.Add(id)
+		} else {
+			filter.Remove(id)
+		}
+		applied += 1
+	}
+
+	return applied
+}
+
+// reload rebuild the filter from a file, one id (or comma separated ids) per line.
+func (s *targetState) reload(path string) (int, error) {
+	s.mu.Lock()
+	if s.reloading {
+		s.mu.Unlock()
+		return 0, errors.New("reload is already running")
+	}
+	s.reloading = true
+	s.pending = nil
+	s.mu.Unlock()
+
+	newFilter := s.filter.NewEmptyFilter()
+	count, err := loadIdsFromFile(path, newFilter)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reloading = false
+	pending := s.pending
+	s.pending = nil
+
+	if err != nil {
+		return count, err
+	}
+
+	//replay the changes during reload, then swap.
+	for _, event := range pending {
+		applyIds(newFilter, event.Action, event.Ids)
+	}
+	s.filter.Replace(newFilter)
+
+	return count, nil
+}
+
+// reloadFilePath the file of a reload path in dir, a relative path is relative to dir. a path with "..", or out of dir
+// after the symlinks are resolved, is rejected.
+func reloadFilePath(dir string, path string) (string, error) {
+	if dir == "" {
+		return "", errors.New("membership reload is disabled, membership_reload_dir is empty")
+	}
+	for _, element := range strings.Split(filepath.ToSlash(path), "/") {
+		if element == ".." {
+			return "", fmt.Errorf("reload path %s should not contain ..", path)
+		}
+	}
+
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	file := path
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(root, file)
+	}
+	if !inDir(root, file) {
+		return "", fmt.Errorf("reload path %s is out of the membership reload dir %s", path, dir)
+	}
+
+	//a symlink in the dir may point out of it.
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+	realFile, err := filepath.EvalSymlinks(file)
+	if err != nil {
+		return "", err
+	}
+	if !inDir(realRoot, realFile) {
+		return "", fmt.Errorf("reload path %s is out of the membership reload dir %s", path, dir)
+	}
+
+	return realFile, nil
+}
+
+// file is a file under dir, both are absolute.
+func inDir(dir string, file string) bool {
+	rel, err := filepath.Rel(dir, filepath.Clean(file))
+
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func loadIdsFromFile(path string, filter *internal.MembershipFilter) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	//INFO: ReadString has no line length limit, the file is read as a stream.
+	count := 0
+	reader := bufio.NewReaderSize(file, 1024*1024)
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			count += applyIds(filter, ActionAdd, strings.Split(line, ","))
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+	}
+
+	return count, nil
+}
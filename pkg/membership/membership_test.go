@@ -0,0 +1,208 @@
+package membership
+
+import (
+	"infer-microservices/internal"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// a manager of its own filters, the singleton filters are shared by the models.
+func newTestMembershipManager(reloadDir string) *MembershipManager {
+	return &MembershipManager{
+		targets: map[string]*targetState{
+			TargetUser: {filter: internal.NewMembershipFilter(1000)},
+			TargetItem: {filter: internal.NewMembershipFilter(1000)},
+		},
+		auditLog:  newMembershipAuditLog(auditLogSize),
+		reloadDir: reloadDir,
+	}
+}
+
+func TestMembershipAuditLog(t *testing.T) {
+	cases := []struct {
+		records int
+		limit   int
+		expect  []string //event ids, newest first.
+	}{
+		{0, 0, []string{}},
+		{2, 0, []string{"e2", "e1"}},
+		{2, 5, []string{"e2", "e1"}},
+		{3, 2, []string{"e3", "e2"}},
+		{5, 0, []string{"e5", "e4", "e3"}}, //the ring keeps the latest 3.
+		{7, 2, []string{"e7", "e6"}},
+	}
+	for _, c := range cases {
+		auditLog := newMembershipAuditLog(3)
+		for i := 1; i <= c.records; i++ {
+			auditLog.record(MembershipEventResult{Event: MembershipEvent{EventId: "e" + string(rune('0'+i))}})
+		}
+
+		results := auditLog.recent(c.limit)
+		if len(results) != len(c.expect) {
+			t.Errorf("%d records, limit %d: %d results, expect %d", c.records, c.limit, len(results), len(c.expect))
+			continue
+		}
+		for i, result := range results {
+			if result.Event.EventId != c.expect[i] {
+				t.Errorf("%d records, limit %d: result %d is %s, expect %s", c.records, c.limit, i, result.Event.EventId, c.expect[i])
+			}
+		}
+	}
+}
+
+func TestMembershipManagerApply(t *testing.T) {
+	reloadDir := t.TempDir()
+	m := newTestMembershipManager(reloadDir)
+
+	cases := []struct {
+		name    string
+		event   MembershipEvent
+		err     bool
+		applied int
+	}{
+		{"add users", MembershipEvent{Action: "ADD", Target: "user", Ids: []string{"u1", "u2", " ", "u3"}}, false, 3},
+		{"remove user", MembershipEvent{Action: ActionRemove, Target: TargetUser, Ids: []string{"u2"}}, false, 1},
+		{"add items", MembershipEvent{Action: ActionAdd, Target: TargetItem, Ids: []string{"i1"}}, false, 1},
+		{"unknown target", MembershipEvent{Action: ActionAdd, Target: "shop", Ids: []string{"s1"}}, true, 0},
+		{"unknown action", MembershipEvent{Action: "clear", Target: TargetUser, Ids: []string{"u1"}}, true, 0},
+		{"empty ids", MembershipEvent{Action: ActionAdd, Target: TargetUser}, true, 0},
+		{"empty reload path", MembershipEvent{Action: ActionReload, Target: TargetUser}, true, 0},
+		{"missing reload file", MembershipEvent{Action: ActionReload, Target: TargetUser, Path: filepath.Join(reloadDir, "missing.txt")}, true, 0},
+	}
+	for _, c := range cases {
+		result, err := m.Apply(c.event, SourceAdmin)
+		if (err != nil) != c.err {
+			t.Errorf("%s: err %v, expect err %v", c.name, err, c.err)
+		}
+		if result.Applied != c.applied || result.Success == c.err {
+			t.Errorf("%s: %+v, expect applied %d", c.name, result, c.applied)
+		}
+		if result.Event.Source != SourceAdmin || result.Event.EventId == "" {
+			t.Errorf("%s: event not completed %+v", c.name, result.Event)
+		}
+	}
+
+	contains, _ := m.Contains(TargetUser, []string{"u1", "u2", "u3"})
+	if !contains["u1"] || contains["u2"] || !contains["u3"] {
+		t.Errorf("users %v, expect u1 and u3", contains)
+	}
+	if _, err := m.Contains("shop", []string{"s1"}); err == nil {
+		t.Errorf("unknown target should fail")
+	}
+
+	//every event is audited, the failed ones too.
+	events := m.Events(0)
+	if len(events) != len(cases) || events[0].Event.Action != ActionReload || events[len(events)-1].Event.Action != ActionAdd {
+		t.Errorf("%d audit records, expect %d newest first", len(events), len(cases))
+	}
+}
+
+func TestMembershipManagerReload(t *testing.T) {
+	reloadDir := t.TempDir()
+	m := newTestMembershipManager(reloadDir)
+	m.Apply(MembershipEvent{Action: ActionAdd, Target: TargetItem, Ids: []string{"old"}}, SourceKafka)
+
+	path := filepath.Join(reloadDir, "items.txt")
+	content := "i1\ni2,i3\n# comment\n\n i4 \ni5"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := m.Apply(MembershipEvent{Action: ActionReload, Target: TargetItem, Path: path}, SourceFile)
+	if err != nil || result.Applied != 5 {
+		t.Fatalf("reload: %+v, err %v, expect 5 ids", result, err)
+	}
+
+	//the filter is rebuilt from the file only.
+	contains, _ := m.Contains(TargetItem, []string{"i1", "i2", "i3", "i4", "i5", "old"})
+	for _, id := range []string{"i1", "i2", "i3", "i4", "i5"} {
+		if !contains[id] {
+			t.Errorf("%s should be loaded", id)
+		}
+	}
+	if contains["old"] {
+		t.Errorf("old should be dropped by the reload")
+	}
+
+	//the users are not reloaded.
+	m.Apply(MembershipEvent{Action: ActionAdd, Target: TargetUser, Ids: []string{"u1"}}, SourceAdmin)
+	users, _ := m.Contains(TargetUser, []string{"u1"})
+	if !users["u1"] {
+		t.Errorf("u1 should be kept")
+	}
+
+	//one reload of a target at a time.
+	state := m.targets[TargetItem]
+	state.mu.Lock()
+	state.reloading = true
+	state.mu.Unlock()
+	if _, err := m.Apply(MembershipEvent{Action: ActionReload, Target: TargetItem, Path: path}, SourceAdmin); err == nil {
+		t.Errorf("concurrent reload should fail")
+	}
+}
+
+func TestApplyKafkaMessage(t *testing.T) {
+	m := newTestMembershipManager(t.TempDir())
+
+	m.ApplyKafkaMessage("k1", `{"action": "add", "target": "user", "ids": ["u1"]}`)
+	m.ApplyKafkaMessage("k2", `{"action": "add", "target": `)
+
+	events := m.Events(0)
+	if len(events) != 1 || events[0].Event.Source != SourceKafka || !events[0].Success {
+		t.Errorf("audit records %+v, expect one kafka event", events)
+	}
+}
+
+func TestReloadFilePath(t *testing.T) {
+	root := t.TempDir()
+	reloadDir := filepath.Join(root, "membership")
+	outside := filepath.Join(root, "outside.txt")
+	for _, dir := range []string{reloadDir, filepath.Join(reloadDir, "daily")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, path := range []string{filepath.Join(reloadDir, "items.txt"), filepath.Join(reloadDir, "daily", "users.txt"), outside} {
+		if err := os.WriteFile(path, []byte("id1\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(reloadDir, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		dir  string
+		path string
+		err  bool
+	}{
+		{reloadDir, "items.txt", false},
+		{reloadDir, "daily/users.txt", false},
+		{reloadDir, filepath.Join(reloadDir, "items.txt"), false},
+		{reloadDir, "../outside.txt", true},
+		{reloadDir, "daily/../items.txt", true},
+		{reloadDir, outside, true},
+		{reloadDir, "/etc/passwd", true},
+		{reloadDir, "link.txt", true}, //the symlink points out of the dir.
+		{reloadDir, "", true},
+		{"", "items.txt", true}, //reload is disabled.
+	}
+	for _, c := range cases {
+		_, err := reloadFilePath(c.dir, c.path)
+		if (err != nil) != c.err {
+			t.Errorf("dir %s, path %s: err %v, expect err %v", c.dir, c.path, err, c.err)
+		}
+	}
+
+	//a rejected path is an audited failure, the filter is kept.
+	m := newTestMembershipManager(reloadDir)
+	m.Apply(MembershipEvent{Action: ActionAdd, Target: TargetItem, Ids: []string{"i1"}}, SourceAdmin)
+	result, err := m.Apply(MembershipEvent{Action: ActionReload, Target: TargetItem, Path: "../outside.txt"}, SourceKafka)
+	if err == nil || result.Success || result.Applied != 0 {
+		t.Errorf("reload out of the dir: %+v, err %v, expect failure", result, err)
+	}
+	if contains, _ := m.Contains(TargetItem, []string{"i1", "id1"}); !contains["i1"] || contains["id1"] {
+		t.Errorf("items %v, expect the filter kept", contains)
+	}
+}
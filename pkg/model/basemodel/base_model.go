@@ -3,75 +3,81 @@ package basemodel
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 
 	"infer-microservices/internal"
 
 	faiss_index "infer-microservices/internal/faiss_gogofaster"
 	"infer-microservices/internal/flags"
 	"infer-microservices/internal/logs"
+	"infer-microservices/internal/retry"
 	framework "infer-microservices/internal/tensorflow_gogofaster/core/framework"
 	tfserving "infer-microservices/internal/tfserving_gogofaster"
 	"infer-microservices/internal/utils"
 	config_loader "infer-microservices/pkg/config_loader"
+	"infer-microservices/pkg/config_loader/config_schema"
+	"infer-microservices/pkg/config_loader/resilience_config"
 	"infer-microservices/pkg/feature"
+	"sort"
 	"time"
 
 	"github.com/allegro/bigcache"
-	bloom "github.com/bits-and-blooms/bloom/v3"
 
 	"github.com/gogo/protobuf/types"
+	"google.golang.org/grpc"
 )
 
-var tfservingModelVersion int64
-var tfservingTimeout int64
+var tfservingModelVersion *int64
 var baseModelInstance *BaseModel
-var bigCacheConfBaseModel bigcache.Config
-var lifeWindowS time.Duration
-var cleanWindowS time.Duration
-var hardMaxCacheSize int
-var maxEntrySize int
+var lifeWindowS *int
+var cleanWindowS *int
+var hardMaxCacheSize *int
+var maxEntrySize *int
 var maxEntriesInWindow int
 var verbose bool
 var shards int
 
-type CreateSampleCallBackFunc func(userId string, itemList []string) (feature.ExampleFeatures, error)
+type CreateSampleCallBackFunc func(ctx context.Context, userId string, itemList []string) (feature.ExampleFeatures, error)
 
 var SampleCallBackFuncMap = make(map[string]CreateSampleCallBackFunc, 0)
 
 type BaseModel struct {
 	modelName       string
 	serviceConfig   *config_loader.ServiceConfig
-	userBloomFilter *bloom.BloomFilter
-	itemBloomFilter *bloom.BloomFilter
+	userBloomFilter *internal.MembershipFilter
+	itemBloomFilter *internal.MembershipFilter
 }
 
 func init() {
 	flagFactory := flags.FlagFactory{}
 	flagTensorflow := flagFactory.CreateFlagTensorflow()
-	tfservingModelVersion = *flagTensorflow.GetTfservingModelVersion()
-	tfservingTimeout = *flagTensorflow.GetTfservingTimeoutMs()
+	tfservingModelVersion = flagTensorflow.GetTfservingModelVersion()
 
 	flagCache := flagFactory.CreateFlagCache()
-	lifeWindowS = time.Duration(*flagCache.GetBigcacheLifeWindowS())
-	cleanWindowS = time.Duration(*flagCache.GetBigcacheCleanWindowS())
-	hardMaxCacheSize = *flagCache.GetBigcacheHardMaxCacheSize()
-	maxEntrySize = *flagCache.GetBigcacheMaxEntrySize()
-	bigCacheConfBaseModel = bigcache.Config{
+	lifeWindowS = flagCache.GetBigcacheLifeWindowS()
+	cleanWindowS = flagCache.GetBigcacheCleanWindowS()
+	hardMaxCacheSize = flagCache.GetBigcacheHardMaxCacheSize()
+	maxEntrySize = flagCache.GetBigcacheMaxEntrySize()
+
+	//callback func config
+	basemodel0 := BaseModel{}
+	SampleCallBackFuncMap["recall"] = basemodel0.GetInferExampleFeaturesNotContainItems
+	SampleCallBackFuncMap["rank"] = basemodel0.GetInferExampleFeaturesContainItems
+}
+
+// the bigcache config, the flags are parsed by main after init.
+func bigCacheConfBaseModel() bigcache.Config {
+	return bigcache.Config{
 		Shards:             shards,
-		LifeWindow:         lifeWindowS * time.Minute,
-		CleanWindow:        cleanWindowS * time.Minute,
+		LifeWindow:         time.Duration(*lifeWindowS) * time.Minute,
+		CleanWindow:        time.Duration(*cleanWindowS) * time.Minute,
 		MaxEntriesInWindow: maxEntriesInWindow,
-		MaxEntrySize:       maxEntrySize,
+		MaxEntrySize:       *maxEntrySize,
 		Verbose:            verbose,
-		HardMaxCacheSize:   hardMaxCacheSize,
+		HardMaxCacheSize:   *hardMaxCacheSize,
 		OnRemove:           nil,
 		OnRemoveWithReason: nil,
 	}
-
-	//callback func config
-	basemodel0 := BaseModel{}
-	SampleCallBackFuncMap["recall"] = basemodel0.GetInferExampleFeaturesNotContainItems
-	SampleCallBackFuncMap["rank"] = basemodel0.GetInferExampleFeaturesContainItems
 }
 
 // singleton instance
@@ -106,23 +112,53 @@ func (b *BaseModel) GetServiceConfig() *config_loader.ServiceConfig {
 
 // }
 
-func (b *BaseModel) SetUserBloomFilter(filter *bloom.BloomFilter) {
+func (b *BaseModel) SetUserBloomFilter(filter *internal.MembershipFilter) {
 	b.userBloomFilter = filter
 }
 
-func (b *BaseModel) GetUserBloomFilter() *bloom.BloomFilter {
+func (b *BaseModel) GetUserBloomFilter() *internal.MembershipFilter {
 	return b.userBloomFilter
 }
 
-func (b *BaseModel) SetItemBloomFilter(filter *bloom.BloomFilter) {
+func (b *BaseModel) SetItemBloomFilter(filter *internal.MembershipFilter) {
 	b.itemBloomFilter = filter
 }
 
-func (b *BaseModel) GetItemBloomFilter() *bloom.BloomFilter {
+func (b *BaseModel) GetItemBloomFilter() *internal.MembershipFilter {
 	return b.itemBloomFilter
 }
 
 // observer nontify
+// timeout of a stage of the service config, see config_schema.Stages.
+func (b *BaseModel) stageTimeout(stage string) time.Duration {
+	var resilienceConfig *resilience_config.ResilienceConfig
+	if b.serviceConfig != nil {
+		resilienceConfig = b.serviceConfig.GetResilienceConfig()
+	}
+
+	return resilienceConfig.GetStageTimeout(stage)
+}
+
+// the context of a stage of the request ctx, its deadline is the stage timeout or the request deadline if it is earlier.
+func (b *BaseModel) stageContext(ctx context.Context, stage string) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, b.stageTimeout(stage))
+}
+
+// get the features of redisKey, the transient errors are retried in the feature stage timeout.
+func (b *BaseModel) redisGet(ctx context.Context, redisKey string) (string, error) {
+	ctx, cancel := b.stageContext(ctx, config_schema.StageFeature)
+	defer cancel()
+
+	value := ""
+	err := retry.Downstream(retry.Redis).Do(ctx, func(ctx context.Context) error {
+		var err error
+		value, err = b.serviceConfig.GetRedisConfig().GetRedisPool().GetContext(ctx, redisKey)
+		return err
+	})
+
+	return value, err
+}
+
 func (b *BaseModel) notify(sub Subject) {
 	//reload baseModel
 	b.SetUserBloomFilter(internal.GetUserBloomFilterInstance())
@@ -130,7 +166,7 @@ func (b *BaseModel) notify(sub Subject) {
 }
 
 // Each model may have multiple ways to create samples, using callback functions to determine which method to call
-func (d *BaseModel) GetInferExampleFeaturesNotContainItems(userId string, itemList []string) (feature.ExampleFeatures, error) {
+func (d *BaseModel) GetInferExampleFeaturesNotContainItems(ctx context.Context, userId string, itemList []string) (feature.ExampleFeatures, error) {
 	cacheKeyPrefix := userId + d.serviceConfig.GetServiceId() + d.modelName + "_samples"
 
 	//init examples
@@ -142,13 +178,13 @@ func (d *BaseModel) GetInferExampleFeaturesNotContainItems(userId string, itemLi
 	}
 
 	//set cache
-	bigCache, err := bigcache.NewBigCache(bigCacheConfBaseModel)
+	bigCache, err := bigcache.NewBigCache(bigCacheConfBaseModel())
 	if err != nil {
 		logs.Error(err)
 	}
 
 	// if hit cacha.
-	if lifeWindowS > 0 {
+	if *lifeWindowS > 0 {
 
 		//INFO:MMO, go-cache can't set MaxCacheSize. change to use bigcache.
 
@@ -169,11 +205,13 @@ func (d *BaseModel) GetInferExampleFeaturesNotContainItems(userId string, itemLi
 	//INFO:The process of constructing samples is independent
 	userOfflineExampleCh := make(chan *feature.SeqExampleBuff, 1)
 	userOnlineExampleCh := make(chan *feature.SeqExampleBuff, 1)
+	ctx, cancel := d.stageContext(ctx, config_schema.StageFeature)
+	defer cancel()
 
 	//get user offline example
-	go d.getUserExampleFeaturesOffline(userId, userOfflineExampleCh)
+	go d.getUserExampleFeaturesOffline(ctx, userId, userOfflineExampleCh)
 	//get user online example
-	go d.getUserExampleFeaturesRealtime(userId, userOnlineExampleCh)
+	go d.getUserExampleFeaturesRealtime(ctx, userId, userOnlineExampleCh)
 
 	index_ := 0
 
@@ -186,7 +224,7 @@ loop:
 		case userContextExampleFeatures_ := <-userOnlineExampleCh:
 			userContextExampleFeatures = userContextExampleFeatures_
 			index_ += 1
-		case <-time.After(time.Millisecond * 100):
+		case <-ctx.Done():
 			break loop
 		}
 		if index_ == 2 {
@@ -199,7 +237,7 @@ loop:
 		UserContextExampleFeatures: userContextExampleFeatures,
 	}
 
-	if lifeWindowS > 0 {
+	if *lifeWindowS > 0 {
 		// goCache.Set(cacheKeyPrefix, &exampleData, cacheTimeSecond)
 		bigCache.Set(cacheKeyPrefix, []byte(utils.ConvertStructToJson(exampleData)))
 	}
@@ -208,7 +246,7 @@ loop:
 }
 
 // Each model may have multiple ways to create samples, using callback functions to determine which method to call
-func (d *BaseModel) GetInferExampleFeaturesContainItems(userId string, itemList []string) (feature.ExampleFeatures, error) {
+func (d *BaseModel) GetInferExampleFeaturesContainItems(ctx context.Context, userId string, itemList []string) (feature.ExampleFeatures, error) {
 	cacheKeyPrefix := userId + d.serviceConfig.GetServiceId() + d.GetModelName() + "_samples"
 
 	//init examples
@@ -222,13 +260,13 @@ func (d *BaseModel) GetInferExampleFeaturesContainItems(userId string, itemList
 	}
 
 	//set cache
-	bigCache, err := bigcache.NewBigCache(bigCacheConfBaseModel)
+	bigCache, err := bigcache.NewBigCache(bigCacheConfBaseModel())
 	if err != nil {
 		return exampleData, err
 	}
 
 	// if hit cache.
-	if lifeWindowS > 0 {
+	if *lifeWindowS > 0 {
 		exampleDataBytes, _ := bigCache.Get(cacheKeyPrefix)
 		err = json.Unmarshal(exampleDataBytes, &exampleData)
 		if err != nil {
@@ -243,13 +281,15 @@ func (d *BaseModel) GetInferExampleFeaturesContainItems(userId string, itemList
 	userOfflineExampleCh := make(chan *feature.SeqExampleBuff, 1)
 	userOnlineExampleCh := make(chan *feature.SeqExampleBuff, 1)
 	itemListExampleCh := make(chan *[]feature.SeqExampleBuff, 1)
+	ctx, cancel := d.stageContext(ctx, config_schema.StageFeature)
+	defer cancel()
 
 	//get user offline example
-	go d.getUserExampleFeaturesOffline(userId, userOfflineExampleCh)
+	go d.getUserExampleFeaturesOffline(ctx, userId, userOfflineExampleCh)
 	//get user online example
-	go d.getUserExampleFeaturesRealtime(userId, userOnlineExampleCh)
+	go d.getUserExampleFeaturesRealtime(ctx, userId, userOnlineExampleCh)
 	//get items features.
-	go d.getItemExamplesFeatures(itemList, itemListExampleCh)
+	go d.getItemExamplesFeatures(ctx, itemList, itemListExampleCh)
 
 	index_ := 0
 
@@ -265,7 +305,7 @@ loop:
 		case itemExampleFeaturesList_ := <-itemListExampleCh:
 			itemExampleFeaturesList = *itemExampleFeaturesList_
 			index_ += 1
-		case <-time.After(time.Millisecond * 100):
+		case <-ctx.Done():
 			break loop
 		}
 		if index_ == 3 {
@@ -279,14 +319,14 @@ loop:
 		ItemSeqExampleFeatures:     &itemExampleFeaturesList,
 	}
 
-	if lifeWindowS > 0 {
+	if *lifeWindowS > 0 {
 		bigCache.Set(cacheKeyPrefix, []byte(utils.ConvertStructToJson(exampleData)))
 	}
 
 	return exampleData, nil
 }
 
-func (d *BaseModel) getItemExamplesFeatures(itemList []string, ch chan<- *[]feature.SeqExampleBuff) {
+func (d *BaseModel) getItemExamplesFeatures(ctx context.Context, itemList []string, ch chan<- *[]feature.SeqExampleBuff) {
 	//TODO: use bloom filter check items, avoid all items search redis.
 	redisKeyPrefix := d.serviceConfig.GetModelConfig().GetItemRedisKeyPre()
 	itemSeqExampleBuffs := make([]feature.SeqExampleBuff, 0)
@@ -296,7 +336,7 @@ func (d *BaseModel) getItemExamplesFeatures(itemList []string, ch chan<- *[]feat
 		go func(itemId string) {
 			redisKey := redisKeyPrefix + itemId
 			if d.GetItemBloomFilter().Test([]byte(itemId)) {
-				userExampleFeats, err := d.serviceConfig.GetRedisConfig().GetRedisPool().Get(redisKey)
+				userExampleFeats, err := d.redisGet(ctx, redisKey)
 				itemExampleFeatsBuff := make([]byte, 0)
 				if err != nil {
 					logs.Error(err)
@@ -315,7 +355,7 @@ func (d *BaseModel) getItemExamplesFeatures(itemList []string, ch chan<- *[]feat
 	loop:
 		for {
 			select {
-			case <-time.After(time.Millisecond * 100):
+			case <-ctx.Done():
 				break loop
 			case itemCh := <-itemsCh:
 				itemSeqExampleBuff := itemCh
@@ -330,7 +370,7 @@ func (d *BaseModel) getItemExamplesFeatures(itemList []string, ch chan<- *[]feat
 }
 
 // get user tfrecords offline samples
-func (b *BaseModel) getUserExampleFeaturesOffline(userId string, ch chan<- *feature.SeqExampleBuff) {
+func (b *BaseModel) getUserExampleFeaturesOffline(ctx context.Context, userId string, ch chan<- *feature.SeqExampleBuff) {
 	//INFO: use bloom filter check users, avoid all users search redis.
 
 	userSeqExampleBuff := feature.SeqExampleBuff{}
@@ -338,7 +378,7 @@ func (b *BaseModel) getUserExampleFeaturesOffline(userId string, ch chan<- *feat
 
 	redisKey := b.serviceConfig.GetModelConfig().GetUserRedisKeyPreOffline() + userId
 	if b.userBloomFilter.Test([]byte(userId)) {
-		userExampleFeats, err := b.serviceConfig.GetRedisConfig().GetRedisPool().Get(redisKey)
+		userExampleFeats, err := b.redisGet(ctx, redisKey)
 		if err != nil {
 			logs.Error("get item features err", err)
 		} else {
@@ -356,14 +396,14 @@ func (b *BaseModel) getUserExampleFeaturesOffline(userId string, ch chan<- *feat
 }
 
 // get user tfrecords online samples
-func (b *BaseModel) getUserExampleFeaturesRealtime(userId string, ch chan<- *feature.SeqExampleBuff) {
+func (b *BaseModel) getUserExampleFeaturesRealtime(ctx context.Context, userId string, ch chan<- *feature.SeqExampleBuff) {
 	//TODO: use bloom filter check users, avoid all users search redis.
 	userContextSeqExampleBuff := feature.SeqExampleBuff{}
 	userContextExampleFeatsBuff := make([]byte, 0)
 
 	redisKey := b.serviceConfig.GetModelConfig().GetUserRedisKeyPreRealtime() + userId
 	if b.userBloomFilter.Test([]byte(userId)) {
-		userContextSeqExampleBuff, err := b.serviceConfig.GetRedisConfig().GetRedisPool().Get(redisKey)
+		userContextSeqExampleBuff, err := b.redisGet(ctx, redisKey)
 		if err != nil {
 			logs.Error("get item features err", err)
 		} else {
@@ -381,15 +421,10 @@ func (b *BaseModel) getUserExampleFeaturesRealtime(userId string, ch chan<- *fea
 	ch <- &userContextSeqExampleBuff
 }
 
-// request tfserving service by grpc
-func (b *BaseModel) RequestTfservering(userExamples *[][]byte, userContextExamples *[][]byte, itemExamples *[][]byte, tensorName string) (*[]float32, error) {
-	grpcConn, err := b.serviceConfig.GetModelConfig().GetTfservingGrpcPool().Get()
-	defer b.serviceConfig.GetModelConfig().GetTfservingGrpcPool().Put(grpcConn)
-	if err != nil {
-		return nil, err
-	}
-	predictClient := tfserving.NewPredictionServiceClient(grpcConn)
-	version := &types.Int64Value{Value: tfservingModelVersion}
+// request tfserving service by grpc, userId picks the replica for the consistent hash balancer.
+// a slow request is hedged to another replica if the tfserving pool enables hedging.
+func (b *BaseModel) RequestTfservering(ctx context.Context, userId string, userExamples *[][]byte, userContextExamples *[][]byte, itemExamples *[][]byte, tensorName string) (*[]float32, error) {
+	version := &types.Int64Value{Value: *tfservingModelVersion}
 	predictRequest := &tfserving.PredictRequest{
 		ModelSpec: &tfserving.ModelSpec{
 			Name:    b.serviceConfig.GetModelConfig().GetModelName(),
@@ -445,42 +480,104 @@ func (b *BaseModel) RequestTfservering(userExamples *[][]byte, userContextExampl
 
 	predictRequest.OutputFilter = []string{tensorName}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(tfservingTimeout)*time.Millisecond)
+	ctx, cancel := b.stageContext(ctx, config_schema.StageRank)
 	defer cancel()
 
-	predict, err := predictClient.Predict(ctx, predictRequest)
+	var reply interface{}
+	err := retry.Downstream(retry.Tfserving).Do(ctx, func(ctx context.Context) error {
+		var err error
+		reply, err = b.serviceConfig.GetModelConfig().GetTfservingGrpcPool().Invoke(ctx, userId, func(ctx context.Context, conn *grpc.ClientConn) (interface{}, error) {
+			return tfserving.NewPredictionServiceClient(conn).Predict(ctx, predictRequest)
+		})
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	predictOut := predict.Outputs[tensorName]
+	predictOut, ok := reply.(*tfserving.PredictResponse).Outputs[tensorName]
+	if !ok || predictOut == nil {
+		return nil, fmt.Errorf("tfserving response has no output %s", tensorName)
+	}
 
 	return &predictOut.FloatVal, nil
 }
 
-func (b *BaseModel) InferResultFormat(recallResult *[]*faiss_index.ItemInfo) (*[]map[string]interface{}, error) {
-	recall := make([]map[string]interface{}, 0)
-	resultCh := make(chan map[string]interface{}, len(*recallResult))
+// calibrate scores in place with the model_conf calibration spec, return the raw scores of items.
+func (b *BaseModel) CalibrateScores(items *[]string, scores *[]float32, context map[string]string) map[string]float32 {
+	rawScores := make(map[string]float32, len(*items))
+	for idx := 0; idx < len(*items) && idx < len(*scores); idx++ {
+		rawScores[(*items)[idx]] = (*scores)[idx]
+	}
+
+	calibrator := b.serviceConfig.GetModelConfig().GetCalibrator()
+	if calibrator == nil {
+		return rawScores
+	}
+	for idx := 0; idx < len(*scores); idx++ {
+		(*scores)[idx] = calibrator.Calibrate((*scores)[idx], context)
+	}
+
+	return rawScores
+}
+
+// RankItems sort the items by score, drop the ones scored below minScore and keep the first topN.
+// minScore 0 is not filtered, topN 0 keeps all.
+func (b *BaseModel) RankItems(items []*faiss_index.ItemInfo, topN int, minScore float32) []*faiss_index.ItemInfo {
+	ranked := make([]*faiss_index.ItemInfo, 0, len(items))
+	for _, item := range items {
+		if minScore == 0 || item.Score >= minScore {
+			ranked = append(ranked, item)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+	if topN > 0 && len(ranked) > topN {
+		ranked = ranked[:topN]
+	}
+
+	return ranked
+}
+
+// rawScores is only set in debug mode, nil means not return raw scores. the items keep their order, such as by score.
+func (b *BaseModel) InferResultFormat(recallResult *[]*faiss_index.ItemInfo, rawScores map[string]float32) (*[]map[string]interface{}, error) {
+	type formatted struct {
+		idx  int
+		cell map[string]interface{}
+	}
+	recall := make([]map[string]interface{}, 0, len(*recallResult))
+	cells := make([]map[string]interface{}, len(*recallResult))
+	resultCh := make(chan formatted, len(*recallResult))
 
 	for idx := 0; idx < len(*recallResult); idx++ {
 		rawCell := (*recallResult)[idx]
-		go func(raw_cell_ *faiss_index.ItemInfo) {
+		go func(idx int, raw_cell_ *faiss_index.ItemInfo) {
 			returnCell := make(map[string]interface{})
 			returnCell["itemid"] = raw_cell_.ItemId
 			returnCell["score"] = utils.FloatRound(raw_cell_.Score, 4)
-			resultCh <- returnCell
-		}(rawCell)
+			if rawScore, ok := rawScores[raw_cell_.ItemId]; ok {
+				returnCell["rawScore"] = utils.FloatRound(rawScore, 4)
+			}
+			resultCh <- formatted{idx: idx, cell: returnCell}
+		}(idx, rawCell)
 	}
 
+	//the cells formatted in the stage timeout are returned.
+	timeout := time.After(b.stageTimeout(config_schema.StageFormat))
 loop:
-	for {
+	for received := 0; received < len(cells); received++ {
 		select {
-		case <-time.After(time.Millisecond * 100):
+		case <-timeout:
 			break loop
 		case result := <-resultCh:
-			recall = append(recall, result)
+			cells[result.idx] = result.cell
+		}
+	}
+	for _, cell := range cells {
+		if cell != nil {
+			recall = append(recall, cell)
 		}
 	}
-	close(resultCh)
 
 	return &recall, nil
 }
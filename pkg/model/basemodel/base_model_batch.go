@@ -0,0 +1,128 @@
+package basemodel
+
+import (
+	"context"
+	"infer-microservices/internal/logs"
+	"infer-microservices/pkg/config_loader/config_schema"
+	"infer-microservices/pkg/feature"
+)
+
+const (
+	userOfflineFeature = iota
+	userRealtimeFeature
+	itemFeature
+)
+
+// BatchExampleFeatures the examples of the entries of a batch request, the features of a user or an item shared by many
+// entries are fetched once. the features fetched in the feature stage timeout are used, the items without features
+// are not in the examples, as GetInferExampleFeaturesContainItems.
+func (b *BaseModel) BatchExampleFeatures(ctx context.Context, userIds []string, itemLists [][]string) []feature.ExampleFeatures {
+	type fetched struct {
+		kind int
+		key  string
+		buff *feature.SeqExampleBuff
+	}
+
+	users := make(map[string]bool, 0)
+	for _, userId := range userIds {
+		users[userId] = true
+	}
+	items := make(map[string]bool, 0)
+	for _, itemList := range itemLists {
+		for _, itemId := range itemList {
+			items[itemId] = true
+		}
+	}
+
+	fetchCh := make(chan fetched, 2*len(users)+len(items))
+	ctx, cancel := b.stageContext(ctx, config_schema.StageFeature)
+	defer cancel()
+	for userId := range users {
+		go func(userId string) {
+			ch := make(chan *feature.SeqExampleBuff, 1)
+			b.getUserExampleFeaturesOffline(ctx, userId, ch)
+			fetchCh <- fetched{kind: userOfflineFeature, key: userId, buff: <-ch}
+		}(userId)
+		go func(userId string) {
+			ch := make(chan *feature.SeqExampleBuff, 1)
+			b.getUserExampleFeaturesRealtime(ctx, userId, ch)
+			fetchCh <- fetched{kind: userRealtimeFeature, key: userId, buff: <-ch}
+		}(userId)
+	}
+	for itemId := range items {
+		go func(itemId string) {
+			fetchCh <- fetched{kind: itemFeature, key: itemId, buff: b.getItemExampleFeatures(ctx, itemId)}
+		}(itemId)
+	}
+
+	features := map[int]map[string]*feature.SeqExampleBuff{
+		userOfflineFeature:  make(map[string]*feature.SeqExampleBuff, len(users)),
+		userRealtimeFeature: make(map[string]*feature.SeqExampleBuff, len(users)),
+		itemFeature:         make(map[string]*feature.SeqExampleBuff, len(items)),
+	}
+loop:
+	for received := 0; received < cap(fetchCh); received++ {
+		select {
+		case <-ctx.Done():
+			logs.Error("batch features timeout, fetched", received, "of", cap(fetchCh))
+			break loop
+		case f := <-fetchCh:
+			if f.buff != nil {
+				features[f.kind][f.key] = f.buff
+			}
+		}
+	}
+
+	examples := make([]feature.ExampleFeatures, len(userIds))
+	for i, userId := range userIds {
+		itemExampleFeaturesList := make([]feature.SeqExampleBuff, 0)
+		if i < len(itemLists) {
+			for _, itemId := range itemLists[i] {
+				if itemSeqExampleBuff, ok := features[itemFeature][itemId]; ok {
+					itemExampleFeaturesList = append(itemExampleFeaturesList, *itemSeqExampleBuff)
+				}
+			}
+		}
+		examples[i] = feature.ExampleFeatures{
+			UserExampleFeatures:        userExampleFeaturesOrEmpty(features[userOfflineFeature][userId], userId),
+			UserContextExampleFeatures: userExampleFeaturesOrEmpty(features[userRealtimeFeature][userId], userId),
+			ItemSeqExampleFeatures:     &itemExampleFeaturesList,
+		}
+	}
+
+	return examples
+}
+
+// the features of an item, nil if the item is not in the bloom filter.
+func (b *BaseModel) getItemExampleFeatures(ctx context.Context, itemId string) *feature.SeqExampleBuff {
+	if !b.GetItemBloomFilter().Test([]byte(itemId)) {
+		return nil
+	}
+
+	redisKey := b.serviceConfig.GetModelConfig().GetItemRedisKeyPre() + itemId
+	itemExampleFeatsBuff := make([]byte, 0)
+	itemExampleFeats, err := b.redisGet(ctx, redisKey)
+	if err != nil {
+		logs.Error(err)
+	} else {
+		itemExampleFeatsBuff = []byte(itemExampleFeats)
+	}
+
+	return &feature.SeqExampleBuff{
+		Key:  &itemId,
+		Buff: &itemExampleFeatsBuff,
+	}
+}
+
+// the user features which are not fetched in time are empty, as a user out of the bloom filter.
+func userExampleFeaturesOrEmpty(userSeqExampleBuff *feature.SeqExampleBuff, userId string) *feature.SeqExampleBuff {
+	if userSeqExampleBuff != nil {
+		return userSeqExampleBuff
+	}
+
+	userExampleFeatsBuff := make([]byte, 0)
+	return &feature.SeqExampleBuff{
+		Key:  &userId,
+		Buff: &userExampleFeatsBuff,
+	}
+}
@@ -0,0 +1,66 @@
+package basemodel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"infer-microservices/pkg/config_loader/config_schema"
+)
+
+// ItemEmbedding the embedding of an item for the similar items api, from the redis embedding store or
+// the item tower of the tfserving model, see config_schema.ItemEmbeddingConf.
+func (b *BaseModel) ItemEmbedding(ctx context.Context, itemId string) ([]float32, error) {
+	embeddingConf := b.serviceConfig.GetModelConfig().GetItemEmbedding()
+	if embeddingConf == nil {
+		return nil, fmt.Errorf("model %s has no itemEmbedding conf, similar items are not served", b.serviceConfig.GetServiceId())
+	}
+
+	switch embeddingConf.Source {
+	case config_schema.ItemEmbeddingRedis:
+		return b.itemEmbeddingRedis(ctx, embeddingConf.RedisKeyPre+itemId)
+	case config_schema.ItemEmbeddingTfserving:
+		return b.itemEmbeddingTfserving(ctx, itemId, embeddingConf.TensorName)
+	}
+
+	return nil, fmt.Errorf("unknown item embedding source %q", embeddingConf.Source)
+}
+
+// the embedding is a json array, such as [0.1, 0.2].
+func (b *BaseModel) itemEmbeddingRedis(ctx context.Context, redisKey string) ([]float32, error) {
+	value, err := b.redisGet(ctx, redisKey)
+	if err != nil {
+		return nil, fmt.Errorf("item embedding %s: %s", redisKey, err)
+	}
+
+	embedding := make([]float32, 0)
+	if err := json.Unmarshal([]byte(value), &embedding); err != nil {
+		return nil, fmt.Errorf("item embedding %s: %s", redisKey, err)
+	}
+	if len(embedding) == 0 {
+		return nil, fmt.Errorf("item embedding %s is empty", redisKey)
+	}
+
+	return embedding, nil
+}
+
+// the item tower is fed by the item features only, the user inputs are empty.
+func (b *BaseModel) itemEmbeddingTfserving(ctx context.Context, itemId string, tensorName string) ([]float32, error) {
+	redisKey := b.serviceConfig.GetModelConfig().GetItemRedisKeyPre() + itemId
+	itemExample, err := b.redisGet(ctx, redisKey)
+	if err != nil {
+		return nil, fmt.Errorf("item features %s: %s", redisKey, err)
+	}
+
+	userExamples := make([][]byte, 0)
+	userContextExamples := make([][]byte, 0)
+	itemExamples := [][]byte{[]byte(itemExample)}
+	embedding, err := b.RequestTfservering(ctx, itemId, &userExamples, &userContextExamples, &itemExamples, tensorName)
+	if err != nil {
+		return nil, err
+	}
+	if embedding == nil || len(*embedding) == 0 {
+		return nil, fmt.Errorf("tfserving output %s of item %s is empty", tensorName, itemId)
+	}
+
+	return *embedding, nil
+}
@@ -1,35 +1,46 @@
 package basemodel
 
 import (
-	"infer-microservices/internal"
 	"infer-microservices/internal/flags"
 	"infer-microservices/internal/logs"
+	"infer-microservices/pkg/membership"
 
 	"strings"
+	"sync"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 )
 
 var viperConfig *viper.Viper
+var viperConfigOnce sync.Once
+var flagViper *flags.FlagViper
 var baseModelObserver Observer
 var subject *modelSubject
 
 func init() {
 	flagFactory := flags.FlagFactory{}
-	flagViper := flagFactory.CreateFlagViper()
-
-	viperConfig = viper.New()
-	viperConfig.SetConfigName(*flagViper.GetConfigName())
-	viperConfig.SetConfigType(*flagViper.GetConfigType())
-	viperConfig.AddConfigPath(*flagViper.GetConfigPath())
+	flagViper = flagFactory.CreateFlagViper()
 
 	subject = &modelSubject{}
 	baseModelObserver = GetBaseModelInstance()
 	subject.AddObserver(baseModelObserver)
 }
 
+// the viper config of the flags, created by the first watch after the flags are parsed.
+func getViperConfig() *viper.Viper {
+	viperConfigOnce.Do(func() {
+		viperConfig = viper.New()
+		viperConfig.SetConfigName(*flagViper.GetConfigName())
+		viperConfig.SetConfigType(*flagViper.GetConfigType())
+		viperConfig.AddConfigPath(*flagViper.GetConfigPath())
+	})
+
+	return viperConfig
+}
+
 func loadViperConfigFile() {
+	viperConfig := getViperConfig()
 	err := viperConfig.ReadInConfig()
 	if err != nil {
 		logs.Error(err)
@@ -37,15 +48,24 @@ func loadViperConfigFile() {
 	userIdStr := viperConfig.GetString("userIdList") // "user1,user2,user3"
 	itemIdStr := viperConfig.GetString("itemIdList") //"item1,item2,item3"
 
-	userIdList := strings.Split(userIdStr, ",")
-	itemIdList := strings.Split(itemIdStr, ",")
-
-	for _, userId := range userIdList {
-		internal.BloomPush(internal.GetUserBloomFilterInstance(), userId)
+	//INFO: apply as membership events, so the file changes are audited as admin / kafka changes.
+	membershipManager := membership.GetMembershipManagerInstance()
+	if userIdStr != "" {
+		userEvent := membership.MembershipEvent{
+			Action: membership.ActionAdd,
+			Target: membership.TargetUser,
+			Ids:    strings.Split(userIdStr, ","),
+		}
+		membershipManager.Apply(userEvent, membership.SourceFile)
 	}
 
-	for _, itemId := range itemIdList {
-		internal.BloomPush(internal.GetUserBloomFilterInstance(), itemId)
+	if itemIdStr != "" {
+		itemEvent := membership.MembershipEvent{
+			Action: membership.ActionAdd,
+			Target: membership.TargetItem,
+			Ids:    strings.Split(itemIdStr, ","),
+		}
+		membershipManager.Apply(itemEvent, membership.SourceFile)
 	}
 
 	// //update bloom filter
@@ -53,6 +73,7 @@ func loadViperConfigFile() {
 }
 
 func WatchBloomConfig() {
+	viperConfig := getViperConfig()
 	viperConfig.WatchConfig()
 	viperConfig.OnConfigChange(func(e fsnotify.Event) {
 		logs.Info("Config file changed:", e.Name) //the file only contains new users and new items in past 1 hour.
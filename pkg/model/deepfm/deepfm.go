@@ -1,25 +1,28 @@
 package deepfm
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"infer-microservices/internal"
 	faiss_index "infer-microservices/internal/faiss_gogofaster"
 	"infer-microservices/internal/flags"
 	"infer-microservices/internal/logs"
 	"infer-microservices/internal/utils"
+	config_loader "infer-microservices/pkg/config_loader"
 	"infer-microservices/pkg/feature"
 	"infer-microservices/pkg/model/basemodel"
+	"infer-microservices/pkg/services/io"
 	"net/http"
 	"time"
 
 	"github.com/allegro/bigcache"
 )
 
-var bigCacheConfDeepfm bigcache.Config
-var lifeWindowS time.Duration
-var cleanWindowS time.Duration
-var hardMaxCacheSize int
-var maxEntrySize int
+var lifeWindowS *int
+var cleanWindowS *int
+var hardMaxCacheSize *int
+var maxEntrySize *int
 var maxEntriesInWindow int
 var verbose bool
 var shards int
@@ -32,18 +35,22 @@ type DeepFM struct {
 func init() {
 	flagFactory := flags.FlagFactory{}
 	flagCache := flagFactory.CreateFlagCache()
-	lifeWindowS = time.Duration(*flagCache.GetBigcacheLifeWindowS())
-	cleanWindowS = time.Duration(*flagCache.GetBigcacheCleanWindowS())
-	hardMaxCacheSize = *flagCache.GetBigcacheHardMaxCacheSize()
-	maxEntrySize = *flagCache.GetBigcacheMaxEntrySize()
-	bigCacheConfDeepfm = bigcache.Config{
+	lifeWindowS = flagCache.GetBigcacheLifeWindowS()
+	cleanWindowS = flagCache.GetBigcacheCleanWindowS()
+	hardMaxCacheSize = flagCache.GetBigcacheHardMaxCacheSize()
+	maxEntrySize = flagCache.GetBigcacheMaxEntrySize()
+}
+
+// the bigcache config of the flags, read at use after the flags are parsed.
+func bigCacheConfDeepfm() bigcache.Config {
+	return bigcache.Config{
 		Shards:             shards,
-		LifeWindow:         lifeWindowS * time.Minute,
-		CleanWindow:        cleanWindowS * time.Minute,
+		LifeWindow:         time.Duration(*lifeWindowS) * time.Minute,
+		CleanWindow:        time.Duration(*cleanWindowS) * time.Minute,
 		MaxEntriesInWindow: maxEntriesInWindow,
-		MaxEntrySize:       maxEntrySize,
+		MaxEntrySize:       *maxEntrySize,
 		Verbose:            verbose,
-		HardMaxCacheSize:   hardMaxCacheSize,
+		HardMaxCacheSize:   *hardMaxCacheSize,
 		OnRemove:           nil,
 		OnRemoveWithReason: nil,
 	}
@@ -62,19 +69,24 @@ func (d *DeepFM) GetModelType() string {
 	return d.modelType
 }
 
-func (d *DeepFM) ModelInferSkywalking(requestId string, userId string, itemList []string, r *http.Request, createSample basemodel.CreateSampleCallBackFunc) (map[string]interface{}, error) {
+// the service config which the model was built with.
+func (d *DeepFM) GetServiceConfig() *config_loader.ServiceConfig {
+	return d.basemodel.GetServiceConfig()
+}
+
+func (d *DeepFM) ModelInferSkywalking(ctx context.Context, requestId string, in *io.RecRequest, r *http.Request, createSample basemodel.CreateSampleCallBackFunc) (map[string]interface{}, error) {
 	response := make(map[string]interface{}, 0)
 	tensorName := "scores"
-	cacheKeyPrefix := userId + d.basemodel.GetServiceConfig().GetServiceId() + d.basemodel.GetModelName()
+	cacheKeyPrefix := in.GetUserId() + d.basemodel.GetServiceConfig().GetServiceId() + d.basemodel.GetModelName()
 
 	//set cache
-	bigCache, err := bigcache.NewBigCache(bigCacheConfDeepfm)
+	bigCache, err := bigcache.NewBigCache(bigCacheConfDeepfm())
 	if err != nil {
 		logs.Error(requestId, time.Now(), err)
 	}
 
 	// get features from cache.
-	if lifeWindowS > 0 {
+	if *lifeWindowS > 0 {
 		exampleDataBytes, _ := bigCache.Get(cacheKeyPrefix)
 		err = json.Unmarshal(exampleDataBytes, &response)
 		if err != nil {
@@ -90,7 +102,7 @@ func (d *DeepFM) ModelInferSkywalking(requestId string, userId string, itemList
 	}
 	spanUnionEmFv.SetOperationName("get rank infer examples func")
 	spanUnionEmFv.Log(time.Now())
-	examples, err := createSample(userId, itemList) //create sample by callback func
+	examples, err := createSample(ctx, in.GetUserId(), in.GetItemList()) //create sample by callback func
 	if err != nil {
 		return nil, err
 	}
@@ -106,7 +118,7 @@ func (d *DeepFM) ModelInferSkywalking(requestId string, userId string, itemList
 	}
 	spanUnionEmFv.SetOperationName("get rank scores func")
 	spanUnionEmFv.Log(time.Now())
-	items, scores, err := d.rankPredict(examples, tensorName)
+	items, scores, err := d.rankPredict(ctx, examples, tensorName)
 	if err != nil {
 		return nil, err
 	}
@@ -114,6 +126,12 @@ func (d *DeepFM) ModelInferSkywalking(requestId string, userId string, itemList
 	spanUnionEmFv.End()
 	logs.Debug(requestId, time.Now(), "unrank result:", examples)
 
+	//calibrate scores, keep raw scores for debug.
+	rawScores := d.basemodel.CalibrateScores(items, scores, in.GetContext())
+	if !in.GetDebug() {
+		rawScores = nil
+	}
+
 	//build rank result whith tfserving.ItemInfo
 	for idx := 0; idx < len(*items); idx++ {
 		itemInfo := &faiss_index.ItemInfo{
@@ -122,7 +140,8 @@ func (d *DeepFM) ModelInferSkywalking(requestId string, userId string, itemList
 		}
 		rankResult = append(rankResult, itemInfo)
 	}
-	logs.Debug(requestId, time.Now(), "rank result:", examples)
+	rankResult = d.basemodel.RankItems(rankResult, int(in.GetTopN()), in.GetMinScore())
+	logs.Debug(requestId, time.Now(), "rank result:", rankResult)
 
 	//format result.
 	spanUnionEmOut, _, err := internal.GetTracer().CreateLocalSpan(r.Context())
@@ -131,7 +150,7 @@ func (d *DeepFM) ModelInferSkywalking(requestId string, userId string, itemList
 	}
 	spanUnionEmOut.SetOperationName("get rank result func")
 	spanUnionEmOut.Log(time.Now())
-	rankRst, err := d.basemodel.InferResultFormat(&rankResult)
+	rankRst, err := d.basemodel.InferResultFormat(&rankResult, rawScores)
 	if err != nil {
 		return nil, err
 	}
@@ -141,26 +160,26 @@ func (d *DeepFM) ModelInferSkywalking(requestId string, userId string, itemList
 	response["data"] = *rankRst
 	logs.Debug(requestId, time.Now(), "format result", response)
 
-	if lifeWindowS > 0 {
+	if *lifeWindowS > 0 {
 		bigCache.Set(cacheKeyPrefix, []byte(utils.ConvertStructToJson(response)))
 	}
 
 	return response, nil
 }
 
-func (d *DeepFM) ModelInferNoSkywalking(requestId string, userId string, itemList []string, r *http.Request, createSample basemodel.CreateSampleCallBackFunc) (map[string]interface{}, error) {
+func (d *DeepFM) ModelInferNoSkywalking(ctx context.Context, requestId string, in *io.RecRequest, r *http.Request, createSample basemodel.CreateSampleCallBackFunc) (map[string]interface{}, error) {
 	response := make(map[string]interface{}, 0)
 	tensorName := "scores"
-	cacheKeyPrefix := userId + d.basemodel.GetServiceConfig().GetServiceId() + d.basemodel.GetModelName()
+	cacheKeyPrefix := in.GetUserId() + d.basemodel.GetServiceConfig().GetServiceId() + d.basemodel.GetModelName()
 
 	//set cache
-	bigCache, err := bigcache.NewBigCache(bigCacheConfDeepfm)
+	bigCache, err := bigcache.NewBigCache(bigCacheConfDeepfm())
 	if err != nil {
 		logs.Error(requestId, time.Now(), err)
 	}
 
 	// get features from cache.
-	if lifeWindowS > 0 {
+	if *lifeWindowS > 0 {
 		exampleDataBytes, _ := bigCache.Get(cacheKeyPrefix)
 		err = json.Unmarshal(exampleDataBytes, &response)
 		if err != nil {
@@ -172,7 +191,7 @@ func (d *DeepFM) ModelInferNoSkywalking(requestId string, userId string, itemLis
 	}
 
 	//get infer samples.
-	examples, err := createSample(userId, itemList) //create sample by callback func
+	examples, err := createSample(ctx, in.GetUserId(), in.GetItemList()) //create sample by callback func
 	if err != nil {
 		logs.Error(requestId, time.Now(), err)
 		return nil, err
@@ -181,13 +200,19 @@ func (d *DeepFM) ModelInferNoSkywalking(requestId string, userId string, itemLis
 
 	// get rank scores from tfserving model.
 	rankResult := make([]*faiss_index.ItemInfo, 0)
-	items, scores, err := d.rankPredict(examples, tensorName)
+	items, scores, err := d.rankPredict(ctx, examples, tensorName)
 	if err != nil {
 		logs.Error(requestId, time.Now(), err)
 		return nil, err
 	}
 	logs.Debug(requestId, time.Now(), "unrank result:", examples)
 
+	//calibrate scores, keep raw scores for debug.
+	rawScores := d.basemodel.CalibrateScores(items, scores, in.GetContext())
+	if !in.GetDebug() {
+		rawScores = nil
+	}
+
 	//build rank result whith tfserving.ItemInfo
 	for idx := 0; idx < len(*items); idx++ {
 		itemInfo := &faiss_index.ItemInfo{
@@ -196,10 +221,11 @@ func (d *DeepFM) ModelInferNoSkywalking(requestId string, userId string, itemLis
 		}
 		rankResult = append(rankResult, itemInfo)
 	}
-	logs.Debug(requestId, time.Now(), "rank result:", examples)
+	rankResult = d.basemodel.RankItems(rankResult, int(in.GetTopN()), in.GetMinScore())
+	logs.Debug(requestId, time.Now(), "rank result:", rankResult)
 
 	//format result.
-	rankRst, err := d.basemodel.InferResultFormat(&rankResult)
+	rankRst, err := d.basemodel.InferResultFormat(&rankResult, rawScores)
 	if err != nil {
 		logs.Error(requestId, time.Now(), err)
 		return nil, err
@@ -207,7 +233,7 @@ func (d *DeepFM) ModelInferNoSkywalking(requestId string, userId string, itemLis
 	response["data"] = *rankRst
 	logs.Debug(requestId, time.Now(), "format result", response)
 
-	if lifeWindowS > 0 {
+	if *lifeWindowS > 0 {
 		bigCache.Set(cacheKeyPrefix, []byte(utils.ConvertStructToJson(response)))
 	}
 
@@ -215,7 +241,7 @@ func (d *DeepFM) ModelInferNoSkywalking(requestId string, userId string, itemLis
 }
 
 // request rank scores from tfserving
-func (d *DeepFM) rankPredict(examples feature.ExampleFeatures, tensorName string) (*[]string, *[]float32, error) {
+func (d *DeepFM) rankPredict(ctx context.Context, examples feature.ExampleFeatures, tensorName string) (*[]string, *[]float32, error) {
 
 	userExamples := make([][]byte, 0)
 	userContextExamples := make([][]byte, 0)
@@ -229,7 +255,7 @@ func (d *DeepFM) rankPredict(examples feature.ExampleFeatures, tensorName string
 		items = append(items, *(itemExample.Key))
 		itemExamples = append(itemExamples, *(itemExample.Buff))
 	}
-	scores, err := d.basemodel.RequestTfservering(&userExamples, &userContextExamples, &itemExamples, tensorName)
+	scores, err := d.basemodel.RequestTfservering(ctx, examples.UserId(), &userExamples, &userContextExamples, &itemExamples, tensorName)
 
 	if err != nil {
 		return nil, nil, err
@@ -237,3 +263,87 @@ func (d *DeepFM) rankPredict(examples feature.ExampleFeatures, tensorName string
 
 	return &items, scores, nil
 }
+
+// BatchInfer rank the itemList of many requests by one tfserving request, a row of the tensors is an item and its user.
+// the features of a user or an item shared by the requests are fetched once. the results and the errors are per request.
+func (d *DeepFM) BatchInfer(ctx context.Context, requestId string, ins []*io.RecRequest, r *http.Request) ([]map[string]interface{}, []error) {
+	tensorName := "scores"
+	responses := make([]map[string]interface{}, len(ins))
+	errs := make([]error, len(ins))
+
+	userIds := make([]string, len(ins))
+	itemLists := make([][]string, len(ins))
+	for i, in := range ins {
+		userIds[i] = in.GetUserId()
+		itemLists[i] = in.GetItemList()
+	}
+	examples := d.basemodel.BatchExampleFeatures(ctx, userIds, itemLists)
+	logs.Debug(requestId, time.Now(), "batch examples", examples)
+
+	//the rows of request i are rows[i]:rows[i+1].
+	userExamples := make([][]byte, 0)
+	userContextExamples := make([][]byte, 0)
+	itemExamples := make([][]byte, 0)
+	items := make([]string, 0)
+	rows := make([]int, 0, len(ins)+1)
+	for _, example := range examples {
+		rows = append(rows, len(items))
+		for _, itemExample := range *example.ItemSeqExampleFeatures {
+			userExamples = append(userExamples, *(example.UserExampleFeatures.Buff))
+			userContextExamples = append(userContextExamples, *(example.UserContextExampleFeatures.Buff))
+			items = append(items, *(itemExample.Key))
+			itemExamples = append(itemExamples, *(itemExample.Buff))
+		}
+	}
+	rows = append(rows, len(items))
+	if len(items) == 0 {
+		for i := range ins {
+			errs[i] = fmt.Errorf("user %s has no item to rank", ins[i].GetUserId())
+		}
+		return responses, errs
+	}
+
+	scores, err := d.basemodel.RequestTfservering(ctx, userIds[0], &userExamples, &userContextExamples, &itemExamples, tensorName)
+	if err == nil && len(*scores) != len(items) {
+		err = fmt.Errorf("tfserving returns %d scores of %d items", len(*scores), len(items))
+	}
+	if err != nil {
+		logs.Error(requestId, time.Now(), err)
+		for i := range ins {
+			errs[i] = err
+		}
+		return responses, errs
+	}
+
+	for i, in := range ins {
+		requestItems := items[rows[i]:rows[i+1]]
+		requestScores := append([]float32(nil), (*scores)[rows[i]:rows[i+1]]...)
+		if len(requestItems) == 0 {
+			errs[i] = fmt.Errorf("user %s has no item to rank", in.GetUserId())
+			continue
+		}
+
+		//calibrate scores, keep raw scores for debug.
+		rawScores := d.basemodel.CalibrateScores(&requestItems, &requestScores, in.GetContext())
+		if !in.GetDebug() {
+			rawScores = nil
+		}
+		rankResult := make([]*faiss_index.ItemInfo, 0, len(requestItems))
+		for idx := 0; idx < len(requestItems); idx++ {
+			rankResult = append(rankResult, &faiss_index.ItemInfo{
+				ItemId: requestItems[idx],
+				Score:  requestScores[idx],
+			})
+		}
+		rankResult = d.basemodel.RankItems(rankResult, int(in.GetTopN()), in.GetMinScore())
+
+		rankRst, err := d.basemodel.InferResultFormat(&rankResult, rawScores)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		responses[i] = map[string]interface{}{"data": *rankRst}
+	}
+
+	return responses, errs
+}
@@ -1,27 +1,34 @@
 package dssm
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"infer-microservices/internal"
 	faiss_index "infer-microservices/internal/faiss_gogofaster"
 	"infer-microservices/internal/flags"
 	"infer-microservices/internal/logs"
 	"infer-microservices/internal/utils"
+	config_loader "infer-microservices/pkg/config_loader"
+	"infer-microservices/pkg/config_loader/config_schema"
 	"infer-microservices/pkg/config_loader/faiss_config"
 	"infer-microservices/pkg/faiss"
 	"infer-microservices/pkg/feature"
 	"infer-microservices/pkg/model/basemodel"
+	"infer-microservices/pkg/services/io"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/allegro/bigcache"
 )
 
-var bigCacheConfDssm bigcache.Config
-var lifeWindowS time.Duration
-var cleanWindowS time.Duration
-var hardMaxCacheSize int
-var maxEntrySize int
+var lifeWindowS *int
+var cleanWindowS *int
+var hardMaxCacheSize *int
+var maxEntrySize *int
 var maxEntriesInWindow int
 var verbose bool
 var shards int
@@ -35,18 +42,22 @@ type Dssm struct {
 func init() {
 	flagFactory := flags.FlagFactory{}
 	flagCache := flagFactory.CreateFlagCache()
-	lifeWindowS = time.Duration(*flagCache.GetBigcacheLifeWindowS())
-	cleanWindowS = time.Duration(*flagCache.GetBigcacheCleanWindowS())
-	hardMaxCacheSize = *flagCache.GetBigcacheHardMaxCacheSize()
-	maxEntrySize = *flagCache.GetBigcacheMaxEntrySize()
-	bigCacheConfDssm = bigcache.Config{
+	lifeWindowS = flagCache.GetBigcacheLifeWindowS()
+	cleanWindowS = flagCache.GetBigcacheCleanWindowS()
+	hardMaxCacheSize = flagCache.GetBigcacheHardMaxCacheSize()
+	maxEntrySize = flagCache.GetBigcacheMaxEntrySize()
+}
+
+// bigcache config of the cache flags.
+func bigCacheConfDssm() bigcache.Config {
+	return bigcache.Config{
 		Shards:             shards,
-		LifeWindow:         lifeWindowS * time.Minute,
-		CleanWindow:        cleanWindowS * time.Minute,
+		LifeWindow:         time.Duration(*lifeWindowS) * time.Minute,
+		CleanWindow:        time.Duration(*cleanWindowS) * time.Minute,
 		MaxEntriesInWindow: maxEntriesInWindow,
-		MaxEntrySize:       maxEntrySize,
+		MaxEntrySize:       *maxEntrySize,
 		Verbose:            verbose,
-		HardMaxCacheSize:   hardMaxCacheSize,
+		HardMaxCacheSize:   *hardMaxCacheSize,
 		OnRemove:           nil,
 		OnRemoveWithReason: nil,
 	}
@@ -74,20 +85,27 @@ func (d *Dssm) GetModelType() string {
 	return d.modelType
 }
 
-func (d *Dssm) ModelInferSkywalking(requestId string, userId string, itemList []string, r *http.Request, createSample basemodel.CreateSampleCallBackFunc) (map[string]interface{}, error) {
+// the service config which the model was built with.
+func (d *Dssm) GetServiceConfig() *config_loader.ServiceConfig {
+	return d.basemodel.GetServiceConfig()
+}
+
+func (d *Dssm) ModelInferSkywalking(ctx context.Context, requestId string, in *io.RecRequest, r *http.Request, createSample basemodel.CreateSampleCallBackFunc) (map[string]interface{}, error) {
 	response := make(map[string]interface{}, 0)
-	cacheKeyPrefix := userId + d.basemodel.GetServiceConfig().GetServiceId() + d.basemodel.GetModelName()
+	//a rebuilt faiss index changes the key, the cached recall results are not used.
+	cacheKeyPrefix := in.GetUserId() + d.basemodel.GetServiceConfig().GetServiceId() + d.basemodel.GetModelName() +
+		d.basemodel.GetServiceConfig().GetFaissIndexConfigs().IndexVersion() + strconv.Itoa(int(in.GetRecallNum())) + d.filterCacheKey(in)
 
 	tensorName := "user_embedding"
 
 	//set cache
-	bigCache, err := bigcache.NewBigCache(bigCacheConfDssm)
+	bigCache, err := bigcache.NewBigCache(bigCacheConfDssm())
 	if err != nil {
 		logs.Error(requestId, time.Now(), err)
 	}
 
 	// get features from cache.
-	if lifeWindowS > 0 {
+	if *lifeWindowS > 0 {
 		exampleDataBytes, _ := bigCache.Get(cacheKeyPrefix)
 		err = json.Unmarshal(exampleDataBytes, &response)
 		if err != nil {
@@ -104,7 +122,7 @@ func (d *Dssm) ModelInferSkywalking(requestId string, userId string, itemList []
 
 	spanUnionEmFv.SetOperationName("get recall infer examples func")
 	spanUnionEmFv.Log(time.Now())
-	examples, err := createSample(userId, itemList) //create sample by callback func
+	examples, err := createSample(ctx, in.GetUserId(), in.GetItemList()) //create sample by callback func
 	if err != nil {
 		return nil, err
 	}
@@ -120,7 +138,7 @@ func (d *Dssm) ModelInferSkywalking(requestId string, userId string, itemList []
 	spanUnionEmFv.SetOperationName("get recall embedding func")
 	spanUnionEmFv.Log(time.Now())
 
-	embeddingVector, err := d.embedding(examples, tensorName)
+	embeddingVector, err := d.embedding(ctx, examples, tensorName)
 	if err != nil {
 		logs.Error(requestId, time.Now(), err)
 		return nil, err
@@ -129,33 +147,10 @@ func (d *Dssm) ModelInferSkywalking(requestId string, userId string, itemList []
 	spanUnionEmFv.End()
 	logs.Debug(requestId, time.Now(), "embeddingVector:", embeddingVector)
 
-	//Asynchronous RPC request, simultaneous processing of multiple recalls, reduce network cost
-	mergeResult := make([]*faiss_index.ItemInfo, 0)
-	faissIndexConfigs := d.basemodel.GetServiceConfig().GetFaissIndexConfigs()
-	recallCh := make(chan []*faiss_index.ItemInfo, 100)
-	for _, faissIndexConfig := range faissIndexConfigs.GetFaissIndexConfig() {
-		go func(faissIndexConfig *faiss_config.FaissIndexConfig) {
-			recallResult, err := faiss.FaissVectorSearch(faissIndexConfig, examples, *embeddingVector)
-			if err != nil {
-				logs.Error(err)
-			}
-			logs.Debug(requestId, time.Now(), "recall result:", recallResult)
-			recallCh <- recallResult
-		}(&faissIndexConfig)
-	}
-
-loop:
-	for {
-		select {
-		case <-time.After(time.Millisecond * 100):
-			break loop
-		case recall := <-recallCh:
-			for _, item := range recall {
-				mergeResult = append(mergeResult, item)
-			}
-		}
-	}
-	close(recallCh)
+	//the indexes are recalled by one batch rpc, reduce network cost
+	mergeResult := d.recall(ctx, requestId, int(in.GetRecallNum()), func(ctx context.Context, indexConfigs []faiss_config.FaissIndexConfig) ([][]*faiss_index.ItemInfo, error) {
+		return faiss.FaissMultiIndexSearch(ctx, indexConfigs, examples, *embeddingVector, faiss.NewRecallFilter(in), int(in.GetRecallNum()))
+	})
 
 	//format result.
 	spanUnionEmOut, _, err := internal.GetTracer().CreateLocalSpan(r.Context())
@@ -165,7 +160,7 @@ loop:
 	spanUnionEmOut.SetOperationName("get recall result func")
 
 	spanUnionEmOut.Log(time.Now())
-	recallRst, err := d.basemodel.InferResultFormat(&mergeResult)
+	recallRst, err := d.basemodel.InferResultFormat(&mergeResult, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -179,26 +174,27 @@ loop:
 	response["data"] = *recallRst
 	logs.Debug(requestId, time.Now(), "format result:", mergeResult)
 
-	if lifeWindowS > 0 {
+	if *lifeWindowS > 0 {
 		bigCache.Set(cacheKeyPrefix, []byte(utils.ConvertStructToJson(response)))
 	}
 
 	return response, nil
 }
 
-func (d *Dssm) ModelInferNoSkywalking(requestId string, userId string, itemList []string, r *http.Request, createSample basemodel.CreateSampleCallBackFunc) (map[string]interface{}, error) {
+func (d *Dssm) ModelInferNoSkywalking(ctx context.Context, requestId string, in *io.RecRequest, r *http.Request, createSample basemodel.CreateSampleCallBackFunc) (map[string]interface{}, error) {
 	response := make(map[string]interface{}, 0)
-	cacheKeyPrefix := userId + d.basemodel.GetServiceConfig().GetServiceId() + d.basemodel.GetModelName()
+	cacheKeyPrefix := in.GetUserId() + d.basemodel.GetServiceConfig().GetServiceId() + d.basemodel.GetModelName() +
+		d.basemodel.GetServiceConfig().GetFaissIndexConfigs().IndexVersion() + strconv.Itoa(int(in.GetRecallNum())) + d.filterCacheKey(in)
 	tensorName := "user_embedding"
 
 	//set cache
-	bigCache, err := bigcache.NewBigCache(bigCacheConfDssm)
+	bigCache, err := bigcache.NewBigCache(bigCacheConfDssm())
 	if err != nil {
 		return nil, err
 	}
 
 	// get features from cache.
-	if lifeWindowS > 0 {
+	if *lifeWindowS > 0 {
 		exampleDataBytes, _ := bigCache.Get(cacheKeyPrefix)
 		err = json.Unmarshal(exampleDataBytes, &response)
 		if err != nil {
@@ -208,48 +204,25 @@ func (d *Dssm) ModelInferNoSkywalking(requestId string, userId string, itemList
 	}
 
 	//get infer samples.
-	examples, err := createSample(userId, itemList) //create sample by callback func
+	examples, err := createSample(ctx, in.GetUserId(), in.GetItemList()) //create sample by callback func
 	if err != nil {
 		return nil, err
 	}
 
 	// get embedding from tfserving model.
-	embeddingVector, err := d.embedding(examples, tensorName)
+	embeddingVector, err := d.embedding(ctx, examples, tensorName)
 	if err != nil {
 		return nil, err
 	}
 	logs.Debug(requestId, time.Now(), "embeddingVector:", embeddingVector)
 
-	//Asynchronous RPC request, simultaneous processing of multiple recalls, reduce network cost
-	mergeResult := make([]*faiss_index.ItemInfo, 0)
-	faissIndexConfigs := d.basemodel.GetServiceConfig().GetFaissIndexConfigs()
-	recallCh := make(chan []*faiss_index.ItemInfo, 100)
-	for _, faissIndexConfig := range faissIndexConfigs.GetFaissIndexConfig() {
-		go func(faissIndexConfig *faiss_config.FaissIndexConfig) {
-			recallResult, err := faiss.FaissVectorSearch(faissIndexConfig, examples, *embeddingVector)
-			if err != nil {
-				logs.Error(err)
-			}
-			logs.Debug(requestId, time.Now(), "recall result:", recallResult)
-			recallCh <- recallResult
-		}(&faissIndexConfig)
-	}
-
-loop:
-	for {
-		select {
-		case <-time.After(time.Millisecond * 100):
-			break loop
-		case recall := <-recallCh:
-			for _, item := range recall {
-				mergeResult = append(mergeResult, item)
-			}
-		}
-	}
-	close(recallCh)
+	//the indexes are recalled by one batch rpc, reduce network cost
+	mergeResult := d.recall(ctx, requestId, int(in.GetRecallNum()), func(ctx context.Context, indexConfigs []faiss_config.FaissIndexConfig) ([][]*faiss_index.ItemInfo, error) {
+		return faiss.FaissMultiIndexSearch(ctx, indexConfigs, examples, *embeddingVector, faiss.NewRecallFilter(in), int(in.GetRecallNum()))
+	})
 
 	//format result.
-	recallRst, err := d.basemodel.InferResultFormat(&mergeResult)
+	recallRst, err := d.basemodel.InferResultFormat(&mergeResult, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -261,28 +234,298 @@ loop:
 	response["data"] = *recallRst
 	logs.Debug(requestId, time.Now(), "format result:", mergeResult)
 
-	if lifeWindowS > 0 {
+	if *lifeWindowS > 0 {
 		bigCache.Set(cacheKeyPrefix, []byte(utils.ConvertStructToJson(response)))
 	}
 
 	return response, nil
 }
 
+// SimilarItemsInfer recall the similar items of in.itemId by the item embedding, with the merge and the filters of
+// the user recall. the item itself is not recalled.
+func (d *Dssm) SimilarItemsInfer(ctx context.Context, requestId string, in *io.RecRequest, r *http.Request) (map[string]interface{}, error) {
+	response := make(map[string]interface{}, 0)
+
+	embeddingVector, err := d.basemodel.ItemEmbedding(ctx, in.GetItemId())
+	if err != nil {
+		return nil, err
+	}
+	logs.Debug(requestId, time.Now(), "item embeddingVector:", embeddingVector)
+
+	mergeResult := d.recall(ctx, requestId, int(in.GetRecallNum()), func(ctx context.Context, indexConfigs []faiss_config.FaissIndexConfig) ([][]*faiss_index.ItemInfo, error) {
+		return faiss.FaissSimilarItemsSearch(ctx, indexConfigs, in.GetItemId(), embeddingVector, faiss.NewRecallFilter(in), int(in.GetRecallNum()))
+	})
+
+	//format result.
+	recallRst, err := d.basemodel.InferResultFormat(&mergeResult, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(*recallRst) == 0 {
+		return nil, fmt.Errorf("item %s recall 0 similar item, check the faiss index plz", in.GetItemId())
+	}
+	response["data"] = *recallRst
+	logs.Debug(requestId, time.Now(), "format result:", mergeResult)
+
+	return response, nil
+}
+
+// RecallStream recall the items of a user like ModelInferNoSkywalking, the items of each index are passed to partial
+// as soon as the index responds, in the recall stage timeout. the merged result of the indexes is returned.
+func (d *Dssm) RecallStream(ctx context.Context, requestId string, in *io.RecRequest, r *http.Request, partial func(indexName string, result map[string]interface{})) (map[string]interface{}, error) {
+	tensorName := "user_embedding"
+
+	examples, err := d.basemodel.GetInferExampleFeaturesNotContainItems(ctx, in.GetUserId(), in.GetItemList())
+	if err != nil {
+		return nil, err
+	}
+	embeddingVector, err := d.embedding(ctx, examples, tensorName)
+	if err != nil {
+		return nil, err
+	}
+	logs.Debug(requestId, time.Now(), "embeddingVector:", embeddingVector)
+
+	ctx, cancel := context.WithTimeout(ctx, d.basemodel.GetServiceConfig().GetResilienceConfig().GetStageTimeout(config_schema.StageRecall))
+	defer cancel()
+
+	faissIndexConfigs := d.basemodel.GetServiceConfig().GetFaissIndexConfigs().GetFaissIndexConfig()
+	recallResults := make([][]*faiss_index.ItemInfo, 0, len(faissIndexConfigs))
+	indexResults := faiss.FaissMultiIndexSearchStream(ctx, faissIndexConfigs, examples, *embeddingVector, faiss.NewRecallFilter(in), int(in.GetRecallNum()))
+	for indexResult := range indexResults {
+		if indexResult.Err != nil {
+			logs.Error(requestId, time.Now(), indexResult.IndexName, indexResult.Err)
+			continue
+		}
+		recallResults = append(recallResults, indexResult.Items)
+
+		indexItems := faiss.MergeItems([][]*faiss_index.ItemInfo{indexResult.Items}, 0)
+		indexRst, err := d.basemodel.InferResultFormat(&indexItems, nil)
+		if err != nil {
+			logs.Error(requestId, time.Now(), err)
+			continue
+		}
+		partial(indexResult.IndexName, map[string]interface{}{"data": *indexRst})
+	}
+
+	mergeResult := faiss.MergeItems(recallResults, int(in.GetRecallNum()))
+	recallRst, err := d.basemodel.InferResultFormat(&mergeResult, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(*recallRst) == 0 {
+		return nil, fmt.Errorf("user %s recall 0 item, check the faiss index plz", in.GetUserId())
+	}
+	logs.Debug(requestId, time.Now(), "format result:", mergeResult)
+
+	return map[string]interface{}{"data": *recallRst}, nil
+}
+
+// BatchInfer recall the items of many users, the user embeddings are requested by one tfserving request and the
+// features of a user shared by the requests are fetched once. the results and the errors are per request.
+func (d *Dssm) BatchInfer(ctx context.Context, requestId string, ins []*io.RecRequest, r *http.Request) ([]map[string]interface{}, []error) {
+	tensorName := "user_embedding"
+	responses := make([]map[string]interface{}, len(ins))
+	errs := make([]error, len(ins))
+
+	userIds := make([]string, len(ins))
+	for i, in := range ins {
+		userIds[i] = in.GetUserId()
+	}
+	examples := d.basemodel.BatchExampleFeatures(ctx, userIds, nil)
+
+	embeddingVectors, err := d.embeddings(ctx, examples, tensorName)
+	if err != nil {
+		logs.Error(requestId, time.Now(), err)
+		for i := range ins {
+			errs[i] = err
+		}
+		return responses, errs
+	}
+
+	//the vectors of the requests are recalled against each index by one GrpcBatchRecall, per request if the faiss server does not implement it.
+	recallResults, err := d.multiVectorRecall(ctx, requestId, ins, examples, embeddingVectors)
+	if errors.Is(err, faiss.ErrBatchRecallUnimplemented) {
+		recallResults = d.recallEach(ctx, requestId, ins, examples, embeddingVectors)
+	} else if err != nil {
+		logs.Error(requestId, time.Now(), err)
+	}
+
+	for i, in := range ins {
+		recallRst, err := d.basemodel.InferResultFormat(&recallResults[i], nil)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		if len(*recallRst) == 0 {
+			errs[i] = fmt.Errorf("user %s recall 0 item, check the faiss index plz", in.GetUserId())
+			continue
+		}
+		responses[i] = map[string]interface{}{"data": *recallRst}
+	}
+
+	return responses, errs
+}
+
+// recall the vectors of the requests against each index by one FaissMultiVectorSearch, in the recall stage timeout of the request ctx.
+// the results of a request are merged over the indexes, the failed indexes are skipped. the requests without user features recall nothing.
+// ErrBatchRecallUnimplemented is in the errors if a faiss server does not implement GrpcBatchRecall.
+func (d *Dssm) multiVectorRecall(ctx context.Context, requestId string, ins []*io.RecRequest, examples []feature.ExampleFeatures, vectors [][]float32) ([][]*faiss_index.ItemInfo, error) {
+	indexConfigs := d.basemodel.GetServiceConfig().GetFaissIndexConfigs().GetFaissIndexConfig()
+	ctx, cancel := context.WithTimeout(ctx, d.basemodel.GetServiceConfig().GetResilienceConfig().GetStageTimeout(config_schema.StageRecall))
+	defer cancel()
+
+	//group the vectors by index, with the filter and the recall num of the index of each request.
+	positions := make([]int, 0, len(ins))
+	userVectors := make([][]float32, 0, len(ins))
+	filters := make([]*faiss.RecallFilter, 0, len(ins))
+	recallNums := make([][]int, len(indexConfigs))
+	for i, in := range ins {
+		if len(*examples[i].UserExampleFeatures.Buff) == 0 || len(*examples[i].UserContextExampleFeatures.Buff) == 0 {
+			continue
+		}
+		positions = append(positions, i)
+		userVectors = append(userVectors, vectors[i])
+		filters = append(filters, faiss.NewRecallFilter(in))
+		for j, recallNum := range faiss.SplitRecallNum(indexConfigs, int(in.GetRecallNum())) {
+			recallNums[j] = append(recallNums[j], recallNum)
+		}
+	}
+
+	recallCh := make(chan [][][]*faiss_index.ItemInfo, 1)
+	errs := make([]error, len(indexConfigs))
+	go func() {
+		//the results of each request, per index.
+		indexResults := make([][][]*faiss_index.ItemInfo, len(ins))
+		for i := range indexResults {
+			indexResults[i] = make([][]*faiss_index.ItemInfo, len(indexConfigs))
+		}
+		var wg sync.WaitGroup
+		for j := range indexConfigs {
+			wg.Add(1)
+			go func(j int) {
+				defer wg.Done()
+				results, err := faiss.FaissMultiVectorSearch(ctx, &indexConfigs[j], requestId, userVectors, filters, recallNums[j])
+				errs[j] = err
+				for k, position := range positions {
+					indexResults[position][j] = results[k]
+				}
+			}(j)
+		}
+		wg.Wait()
+		recallCh <- indexResults
+	}()
+
+	mergeResults := make([][]*faiss_index.ItemInfo, len(ins))
+	select {
+	case <-ctx.Done():
+		logs.Error(requestId, time.Now(), "recall timeout", ctx.Err())
+		for i := range mergeResults {
+			mergeResults[i] = make([]*faiss_index.ItemInfo, 0)
+		}
+		return mergeResults, nil
+	case indexResults := <-recallCh:
+		for i, in := range ins {
+			mergeResults[i] = faiss.MergeItems(indexResults[i], int(in.GetRecallNum()))
+		}
+		return mergeResults, errors.Join(errs...)
+	}
+}
+
+// recall the requests in parallel, each by FaissMultiIndexSearch in the recall stage timeout.
+func (d *Dssm) recallEach(ctx context.Context, requestId string, ins []*io.RecRequest, examples []feature.ExampleFeatures, vectors [][]float32) [][]*faiss_index.ItemInfo {
+	mergeResults := make([][]*faiss_index.ItemInfo, len(ins))
+	var wg sync.WaitGroup
+	for i := range ins {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			in := ins[i]
+			mergeResults[i] = d.recall(ctx, requestId, int(in.GetRecallNum()), func(ctx context.Context, indexConfigs []faiss_config.FaissIndexConfig) ([][]*faiss_index.ItemInfo, error) {
+				return faiss.FaissMultiIndexSearch(ctx, indexConfigs, examples[i], vectors[i], faiss.NewRecallFilter(in), int(in.GetRecallNum()))
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	return mergeResults
+}
+
+// recall the indexes by search in the recall stage timeout of the request ctx, the results of the failed indexes are skipped.
+// the results are merged and sorted by score, the first recallNum are kept, 0 keeps all.
+func (d *Dssm) recall(ctx context.Context, requestId string, recallNum int, search func(context.Context, []faiss_config.FaissIndexConfig) ([][]*faiss_index.ItemInfo, error)) []*faiss_index.ItemInfo {
+	faissIndexConfigs := d.basemodel.GetServiceConfig().GetFaissIndexConfigs()
+	ctx, cancel := context.WithTimeout(ctx, d.basemodel.GetServiceConfig().GetResilienceConfig().GetStageTimeout(config_schema.StageRecall))
+	defer cancel()
+
+	recallCh := make(chan [][]*faiss_index.ItemInfo, 1)
+	go func() {
+		recallResults, err := search(ctx, faissIndexConfigs.GetFaissIndexConfig())
+		if err != nil {
+			logs.Error(requestId, time.Now(), err)
+		}
+		logs.Debug(requestId, time.Now(), "recall result:", recallResults)
+		recallCh <- recallResults
+	}()
+
+	select {
+	case <-ctx.Done():
+		logs.Error(requestId, time.Now(), "recall timeout", ctx.Err())
+		return make([]*faiss_index.ItemInfo, 0)
+	case recallResults := <-recallCh:
+		return faiss.MergeItems(recallResults, recallNum)
+	}
+}
+
+// the filters of the request, and the context resolves the index rules, are part of the cache key.
+// the results of other filters are not used.
+func (d *Dssm) filterCacheKey(in *io.RecRequest) string {
+	filtered := len(in.GetIncludeItems()) > 0 || len(in.GetExcludeItems()) > 0 || len(in.GetFilters()) > 0
+	for _, faissIndexConfig := range d.basemodel.GetServiceConfig().GetFaissIndexConfigs().GetFaissIndexConfig() {
+		filtered = filtered || len(faissIndexConfig.GetFilters()) > 0
+	}
+	if !filtered {
+		return ""
+	}
+
+	return utils.ConvertStructToJson(faiss.NewRecallFilter(in))
+}
+
 // request embedding vector from tfserving
-func (d *Dssm) embedding(examples feature.ExampleFeatures, tensorName string) (*[]float32, error) {
+func (d *Dssm) embedding(ctx context.Context, examples feature.ExampleFeatures, tensorName string) (*[]float32, error) {
+	embeddings, err := d.embeddings(ctx, []feature.ExampleFeatures{examples}, tensorName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &embeddings[0], nil
+}
+
+// request the embedding vectors of many users by one tfserving request, the output is split into a vector per user.
+func (d *Dssm) embeddings(ctx context.Context, examples []feature.ExampleFeatures, tensorName string) ([][]float32, error) {
 
 	userExamples := make([][]byte, 0)
 	userContextExamples := make([][]byte, 0)
 	itemExamples := make([][]byte, 0)
 
-	userExamples = append(userExamples, *(examples.UserExampleFeatures.Buff))
-	userContextExamples = append(userContextExamples, *(examples.UserContextExampleFeatures.Buff))
+	for _, example := range examples {
+		userExamples = append(userExamples, *(example.UserExampleFeatures.Buff))
+		userContextExamples = append(userContextExamples, *(example.UserContextExampleFeatures.Buff))
+	}
 
-	response, err := d.basemodel.RequestTfservering(&userExamples, &itemExamples, &userContextExamples, tensorName)
+	response, err := d.basemodel.RequestTfservering(ctx, examples[0].UserId(), &userExamples, &itemExamples, &userContextExamples, tensorName)
 	if err != nil {
 		logs.Error(err)
 		return nil, err
 	}
+	if len(*response) == 0 || len(*response)%len(examples) != 0 {
+		return nil, fmt.Errorf("tfserving returns %d floats, not the embeddings of %d users", len(*response), len(examples))
+	}
 
-	return response, nil
+	dim := len(*response) / len(examples)
+	embeddings := make([][]float32, len(examples))
+	for i := range examples {
+		embeddings[i] = (*response)[i*dim : (i+1)*dim]
+	}
+
+	return embeddings, nil
 }
@@ -1,7 +1,9 @@
 package model
 
 import (
+	"context"
 	"infer-microservices/pkg/model/basemodel"
+	"infer-microservices/pkg/services/io"
 	"net/http"
 )
 
@@ -13,12 +15,12 @@ func (m *ModelStrategyContext) SetModelStrategy(strategy ModelStrategyInterface)
 	m.modelStrategy = strategy
 }
 
-func (m *ModelStrategyContext) ModelInferSkywalking(requestId string, userId string, itemList []string, r *http.Request, createSample basemodel.CreateSampleCallBackFunc) (map[string]interface{}, error) {
-	response, err := m.modelStrategy.ModelInferSkywalking(requestId, userId, itemList, r, createSample)
+func (m *ModelStrategyContext) ModelInferSkywalking(ctx context.Context, requestId string, in *io.RecRequest, r *http.Request, createSample basemodel.CreateSampleCallBackFunc) (map[string]interface{}, error) {
+	response, err := m.modelStrategy.ModelInferSkywalking(ctx, requestId, in, r, createSample)
 	return response, err
 }
 
-func (m *ModelStrategyContext) ModelInferNoSkywalking(requestId string, userId string, itemList []string, r *http.Request, createSample basemodel.CreateSampleCallBackFunc) (map[string]interface{}, error) {
-	response, err := m.modelStrategy.ModelInferNoSkywalking(requestId, userId, itemList, r, createSample)
+func (m *ModelStrategyContext) ModelInferNoSkywalking(ctx context.Context, requestId string, in *io.RecRequest, r *http.Request, createSample basemodel.CreateSampleCallBackFunc) (map[string]interface{}, error) {
+	response, err := m.modelStrategy.ModelInferNoSkywalking(ctx, requestId, in, r, createSample)
 	return response, err
 }
@@ -1,59 +1,104 @@
 package model
 
 import (
+	"context"
 	"infer-microservices/internal"
 	config_loader "infer-microservices/pkg/config_loader"
 	"infer-microservices/pkg/model/basemodel"
 	"infer-microservices/pkg/model/deepfm"
 	"infer-microservices/pkg/model/dssm"
+	"infer-microservices/pkg/services/io"
 	"net/http"
+	"sync"
 )
 
-var baseModel *basemodel.BaseModel
-var modelStrategyMap map[string]ModelStrategyInterface
+// the shared models, a model is rebuilt when nacos updated the service config of its dataId.
+var modelStrategyMu sync.Mutex
+var modelStrategyMap map[modelStrategyKey]ModelStrategyInterface
+
+type modelStrategyKey struct {
+	dataId        string
+	modelName     string
+	serviceConfig *config_loader.ServiceConfig
+}
 
 type ModelStrategyInterface interface {
-	//model infer.
+	//model infer, the downstream calls are bounded by the deadline of ctx.
 	GetModelType() string
-	ModelInferSkywalking(requestId string, userId string, itemList []string, r *http.Request, createSample basemodel.CreateSampleCallBackFunc) (map[string]interface{}, error)
-	ModelInferNoSkywalking(requestId string, userId string, itemList []string, r *http.Request, createSample basemodel.CreateSampleCallBackFunc) (map[string]interface{}, error)
+	GetServiceConfig() *config_loader.ServiceConfig
+	ModelInferSkywalking(ctx context.Context, requestId string, in *io.RecRequest, r *http.Request, createSample basemodel.CreateSampleCallBackFunc) (map[string]interface{}, error)
+	ModelInferNoSkywalking(ctx context.Context, requestId string, in *io.RecRequest, r *http.Request, createSample basemodel.CreateSampleCallBackFunc) (map[string]interface{}, error)
+}
+
+// SimilarItemsInterface the models which recall the similar items of an item, such as dssm by the item embedding.
+type SimilarItemsInterface interface {
+	SimilarItemsInfer(ctx context.Context, requestId string, in *io.RecRequest, r *http.Request) (map[string]interface{}, error)
+}
+
+// BatchInferInterface the models which infer many requests of a dataId by one tfserving request. the results and
+// the errors are in the order of ins, an error only fails its request.
+type BatchInferInterface interface {
+	BatchInfer(ctx context.Context, requestId string, ins []*io.RecRequest, r *http.Request) ([]map[string]interface{}, []error)
+}
+
+// RecallStreamInterface the recall models which pass the items of each index to partial as soon as it responds,
+// the merged result is returned.
+type RecallStreamInterface interface {
+	RecallStream(ctx context.Context, requestId string, in *io.RecRequest, r *http.Request, partial func(indexName string, result map[string]interface{})) (map[string]interface{}, error)
 }
 
 type ModelStrategyFactory struct {
 }
 
 func init() {
-	modelStrategyMap = make(map[string]ModelStrategyInterface, 0)
+	modelStrategyMap = make(map[modelStrategyKey]ModelStrategyInterface, 0)
 }
 
-func SetModelStrategyMap(modelStrategy map[string]ModelStrategyInterface) {
-	modelStrategyMap = modelStrategy
-}
+// GetOrCreateModelStrategy the model of the dataId, shared by its requests. it is created by CreateModelStrategy
+// if the dataId has no model of the service config, the models of its former service configs are dropped.
+func (m *ModelStrategyFactory) GetOrCreateModelStrategy(dataId string, modelName string, serverConn *config_loader.ServiceConfig) ModelStrategyInterface {
+	key := modelStrategyKey{dataId: dataId, modelName: modelName, serviceConfig: serverConn}
+	modelStrategyMu.Lock()
+	defer modelStrategyMu.Unlock()
+	if modelStrategy, ok := modelStrategyMap[key]; ok {
+		return modelStrategy
+	}
 
-func GetModelStrategyMap() map[string]ModelStrategyInterface {
-	return modelStrategyMap
+	modelStrategy := m.CreateModelStrategy(modelName, serverConn)
+	if modelStrategy == nil {
+		return nil
+	}
+	for cachedKey := range modelStrategyMap {
+		if cachedKey.dataId == dataId && cachedKey.modelName == modelName {
+			delete(modelStrategyMap, cachedKey)
+		}
+	}
+	modelStrategyMap[key] = modelStrategy
+
+	return modelStrategy
 }
 
+// CreateModelStrategy a new model of the service config, it is not shared.
 func (m *ModelStrategyFactory) CreateModelStrategy(modelName string, serverConn *config_loader.ServiceConfig) ModelStrategyInterface {
-	baseModel = basemodel.GetBaseModelInstance()
+	//INFO: each model has its own base model, the config of a model is not changed by the models built later.
+	baseModel := basemodel.BaseModel{}
 	baseModel.SetUserBloomFilter(internal.GetUserBloomFilterInstance())
 	baseModel.SetItemBloomFilter(internal.GetItemBloomFilterInstance())
 	baseModel.SetServiceConfig(serverConn)
 
-	//dssm model
-	dssmModel := &dssm.Dssm{}
-	dssmModel.SetBaseModel(*baseModel)
-	dssmModel.SetModelType("recall")
-	modelStrategyMap["dssm"] = dssmModel
-
-	//deepfm model
-	deepfmModel := &deepfm.DeepFM{}
-	deepfmModel.SetBaseModel(*baseModel)
-	deepfmModel.SetModelType("rank")
-	modelStrategyMap["deepfm"] = deepfmModel
-
-	// modelStrategyMap["lr"] = lrModel
-	// modelStrategyMap["fm"] = fmModel
+	switch modelName {
+	case "dssm":
+		dssmModel := &dssm.Dssm{}
+		dssmModel.SetBaseModel(baseModel)
+		dssmModel.SetModelType("recall")
+		return dssmModel
+	case "deepfm":
+		deepfmModel := &deepfm.DeepFM{}
+		deepfmModel.SetBaseModel(baseModel)
+		deepfmModel.SetModelType("rank")
+		return deepfmModel
+	}
+	// case "lr", "fm":
 
-	return modelStrategyMap[modelName]
+	return nil
 }
@@ -3,7 +3,6 @@ package nacos
 import (
 	"infer-microservices/internal/flags"
 	"infer-microservices/internal/logs"
-	service_config_loader "infer-microservices/pkg/config_loader"
 	"sync"
 	"time"
 
@@ -13,12 +12,14 @@ import (
 	"github.com/nacos-group/nacos-sdk-go/vo"
 )
 
-var nacosTimeoutMs uint64
-var nacosLogDir string
-var nacosCacheDir string
-var nacosLogLevel string
-var nacosUsername string
-var nacosPassword string
+var nacosTimeoutMs *int
+var nacosLogDir *string
+var nacosCacheDir *string
+var nacosLogLevel *string
+var nacosUsername *string
+var nacosPassword *string
+var nacosIp *string //for naming, the config listeners set their own.
+var nacosPort *int
 var mt sync.Mutex
 var nacosListedMap = make(map[string]bool, 0)
 
@@ -34,12 +35,14 @@ func init() {
 	flagFactory := flags.FlagFactory{}
 	flagNacos := flagFactory.CreateFlagNacos()
 
-	nacosTimeoutMs = uint64(*flagNacos.GetNacosTimeoutMs())
-	nacosLogDir = *flagNacos.GetNacosLogdir()
-	nacosCacheDir = *flagNacos.GetacosCachedir()
-	nacosLogLevel = *flagNacos.GetNacosLoglevel()
-	nacosUsername = *flagNacos.GetNacosUsername()
-	nacosPassword = *flagNacos.GetNacosPassword()
+	nacosTimeoutMs = flagNacos.GetNacosTimeoutMs()
+	nacosLogDir = flagNacos.GetNacosLogdir()
+	nacosCacheDir = flagNacos.GetacosCachedir()
+	nacosLogLevel = flagNacos.GetNacosLoglevel()
+	nacosUsername = flagNacos.GetNacosUsername()
+	nacosPassword = flagNacos.GetNacosPassword()
+	nacosIp = flagNacos.GetNacosIp()
+	nacosPort = flagNacos.GetNacosPort()
 }
 
 // dataId
@@ -87,31 +90,46 @@ func (r *NacosConnConfig) GetPort() uint64 {
 	return r.port
 }
 
-func (n *NacosConnConfig) StartListenNacos() {
+// StartListenNacos get the current content and listen the changes, onChange receive the whole service content. the
+// error of onChange for the current content is returned, the changes are still listened, the errors of the later
+// changes are logged.
+func (n *NacosConnConfig) StartListenNacos(onChange func(dataId string, content string) error) error {
+	mt.Lock()
+	defer mt.Unlock()
+
 	_, ok := nacosListedMap[n.dataId]
-	if !ok {
-		err := n.serviceConfigListen()
-		if err != nil {
-			logs.Fatal(n.dataId, time.Now(), err)
-			panic(err)
-		} else {
-			nacosListedMap[n.dataId] = true
-		}
+	if ok {
+		return nil
+	}
+
+	err := n.serviceConfigListen(onChange)
+	if err != nil {
+		logs.Error(n.dataId, time.Now(), err)
+		return err
 	}
+
+	return nil
 }
 
-func (n *NacosConnConfig) serviceConfigListen() error {
+func (n *NacosConnConfig) serviceConfigListen(onChange func(dataId string, content string) error) error {
 	nacosClient, err := n.getNacosClient()
 	if err != nil {
 		return err
 	}
 
-	err = n.listenNacosConfig(nacosClient)
+	content, err := n.getNacosConfig(nacosClient)
 	if err != nil {
 		return err
 	}
+	updateErr := onChange(n.GetDataId(), content)
 
-	return nil
+	err = n.listenNacosConfig(nacosClient, onChange)
+	if err != nil {
+		return err
+	}
+	nacosListedMap[n.dataId] = true
+
+	return updateErr
 }
 
 func (n *NacosConnConfig) getNacosClient() (config_client.IConfigClient, error) {
@@ -122,13 +140,13 @@ func (n *NacosConnConfig) getNacosClient() (config_client.IConfigClient, error)
 
 	clientConf := constant.ClientConfig{
 		NamespaceId:         n.GetNamespaceId(),
-		TimeoutMs:           nacosTimeoutMs,
+		TimeoutMs:           uint64(*nacosTimeoutMs),
 		NotLoadCacheAtStart: true,
-		LogDir:              nacosLogDir,
-		CacheDir:            nacosCacheDir,
-		LogLevel:            nacosLogLevel,
-		Username:            nacosUsername,
-		Password:            nacosPassword,
+		LogDir:              *nacosLogDir,
+		CacheDir:            *nacosCacheDir,
+		LogLevel:            *nacosLogLevel,
+		Username:            *nacosUsername,
+		Password:            *nacosPassword,
 	}
 	nacosClient, err := clients.CreateConfigClient(map[string]interface{}{
 		"serverConfigs": serviceConf,
@@ -141,26 +159,28 @@ func (n *NacosConnConfig) getNacosClient() (config_client.IConfigClient, error)
 	return nacosClient, nil
 }
 
-// func (n *NacosConnConfig) getNacosConfig(nacosClient config_client.IConfigClient) (string, error) {
-// 	//TODO: VERIFY CONFIG JSON
-// 	content, err := nacosClient.GetConfig(vo.ConfigParam{
-// 		DataId: n.GetDataId(),
-// 		Group:  n.GetGroupId(),
-// 	})
-// 	if err != nil {
-// 		return "", err
-// 	}
-// 	return content, nil
-// }
+func (n *NacosConnConfig) getNacosConfig(nacosClient config_client.IConfigClient) (string, error) {
+	content, err := nacosClient.GetConfig(vo.ConfigParam{
+		DataId: n.GetDataId(),
+		Group:  n.GetGroupId(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return content, nil
+}
 
-func (n *NacosConnConfig) listenNacosConfig(nacosClient config_client.IConfigClient) error {
+func (n *NacosConnConfig) listenNacosConfig(nacosClient config_client.IConfigClient, onChange func(dataId string, content string) error) error {
 	err := nacosClient.ListenConfig(vo.ConfigParam{
 		DataId: n.GetDataId(),
 		Group:  n.GetGroupId(),
 		OnChange: func(namespace, group, dataId, data string) {
 			content := string(data)
 			logs.Debug(n.GetDataId(), time.Now(), "nacos content:", content)
-			n.serviceConfigUpdate(dataId, content)
+			if err := onChange(dataId, content); err != nil {
+				logs.Error(dataId, time.Now(), err)
+			}
 		},
 	})
 	if err != nil {
@@ -169,31 +189,3 @@ func (n *NacosConnConfig) listenNacosConfig(nacosClient config_client.IConfigCli
 
 	return nil
 }
-
-func (n *NacosConnConfig) serviceConfigUpdate(dataId string, content string) error {
-	mt.Lock()
-	defer mt.Unlock()
-
-	builder := service_config_loader.ServiceConfigBuilder{}
-	director := service_config_loader.ServiceConfigDirector{}
-	director.SetConfigBuilder(builder)
-
-	nacosContent := NacosContent{}
-	redisConfStr, modelConfStr, indexConfStr := nacosContent.InputServiceConfigParse(content)
-
-	var serviceConf service_config_loader.ServiceConfig
-	if indexConfStr == "{}" {
-		//recall
-		serviceConf = director.ServiceConfigUpdateContainIndexDirector(dataId, redisConfStr, modelConfStr, indexConfStr)
-	} else {
-		//rank
-		serviceConf = director.ServiceConfigUpdaterNotContainIndexDirector(dataId, redisConfStr, modelConfStr)
-	}
-	logs.Info(dataId, "updated", time.Now(), serviceConf)
-
-	configMap := service_config_loader.GetServiceConfigs()
-	configMap[dataId] = &serviceConf
-	service_config_loader.SetServiceConfigs(configMap)
-
-	return nil
-}
@@ -2,7 +2,6 @@ package nacos
 
 import (
 	"encoding/json"
-	"infer-microservices/internal/logs"
 	"infer-microservices/internal/utils"
 
 	validator "github.com/go-playground/validator/v10"
@@ -12,29 +11,53 @@ type NacosContent struct {
 	// author  string  `validate:"required"`
 	// update  string  `validate:"required"`
 	// version string  `validate:"required"`
-	Config Config_ `validate:"required"`
+	Config Config_ `json:"config" validate:"required"`
 }
 
 type Config_ struct {
-	redisConfNacos map[string]interface{} `validate:"required"` //features redis conf.
-	modelConfNacos map[string]interface{} `validate:"required"` //model trainning and model infer conf.
-	indexConfNacos map[string]interface{} //faiss index conf.
+	RedisConfNacos map[string]interface{} `json:"redis_conf" validate:"required"` //features redis conf.
+	ModelConfNacos map[string]interface{} `json:"model_conf" validate:"required"` //model trainning and model infer conf.
+	IndexConfNacos map[string]interface{} `json:"index_conf"`                     //faiss index conf.
+	//timeouts, hystrix and degradation conf, optional.
+	ResilienceConfNacos map[string]interface{} `json:"resilience_conf"`
 }
 
 // parse service config file, which contains index info、redis info and model info etc.
-func (s *NacosContent) InputServiceConfigParse(content string) (string, string, string) {
+// indexConfStr is empty when the service has no faiss index (rank service).
+func (s *NacosContent) InputServiceConfigParse(content string) (string, string, string, error) {
 	tmpNacos := &NacosContent{}
-	json.Unmarshal([]byte(string(content)), tmpNacos)
+	err := json.Unmarshal([]byte(content), tmpNacos)
+	if err != nil {
+		return "", "", "", err
+	}
+
 	validate := validator.New()
-	err := validate.Struct(s)
+	err = validate.Struct(tmpNacos)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	redisConfStr := utils.ConvertStructToJson(tmpNacos.Config.RedisConfNacos)
+	modelConfStr := utils.ConvertStructToJson(tmpNacos.Config.ModelConfNacos)
+	indexConfStr := ""
+	if len(tmpNacos.Config.IndexConfNacos) > 0 {
+		indexConfStr = utils.ConvertStructToJson(tmpNacos.Config.IndexConfNacos)
+	}
+
+	return redisConfStr, modelConfStr, indexConfStr, nil
+}
+
+// parse the optional resilience conf of the service config, empty when it is not set.
+func (s *NacosContent) InputResilienceConfigParse(content string) (string, error) {
+	tmpNacos := &NacosContent{}
+	err := json.Unmarshal([]byte(content), tmpNacos)
 	if err != nil {
-		logs.Error(err)
-		return "", "", ""
+		return "", err
 	}
 
-	redisConfStr := utils.ConvertStructToJson(s.Config.redisConfNacos)
-	modelConfStr := utils.ConvertStructToJson(s.Config.modelConfNacos)
-	indexConfStr := utils.ConvertStructToJson(s.Config.indexConfNacos)
+	if len(tmpNacos.Config.ResilienceConfNacos) == 0 {
+		return "", nil
+	}
 
-	return redisConfStr, modelConfStr, indexConfStr
+	return utils.ConvertStructToJson(tmpNacos.Config.ResilienceConfNacos), nil
 }
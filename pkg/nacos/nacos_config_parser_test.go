@@ -3,5 +3,36 @@ package nacos
 import "testing"
 
 func TestNacosConfigParser(t *testing.T) {
+	content := `
+	{
+		"author": "loki",
+		"version": "v1.0",
+		"config": {
+			"model_conf": {"model-001": {"userRedisKeyPreOffline": "u_off_"}},
+			"redis_conf": {"redisCluster": {"addrs": []}}
+		}
+	}
+	`
 
+	nacosContent := NacosContent{}
+	redisConfStr, modelConfStr, indexConfStr, err := nacosContent.InputServiceConfigParse(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(redisConfStr, modelConfStr)
+
+	if redisConfStr != `{"redisCluster":{"addrs":[]}}` {
+		t.Errorf("unexpected redis conf %s", redisConfStr)
+	}
+	if modelConfStr != `{"model-001":{"userRedisKeyPreOffline":"u_off_"}}` {
+		t.Errorf("unexpected model conf %s", modelConfStr)
+	}
+	if indexConfStr != "" {
+		t.Errorf("rank service should have no index conf, got %s", indexConfStr)
+	}
+
+	_, _, _, err = nacosContent.InputServiceConfigParse(`{"config": {"model_conf": {}}}`)
+	if err == nil {
+		t.Errorf("missing redis_conf should fail")
+	}
 }
@@ -0,0 +1,195 @@
+package nacos
+
+import (
+	"fmt"
+	"infer-microservices/internal"
+	"infer-microservices/internal/logs"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/clients"
+	"github.com/nacos-group/nacos-sdk-go/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/model"
+	"github.com/nacos-group/nacos-sdk-go/vo"
+)
+
+var namingMu sync.Mutex
+var namingClients = make(map[string]naming_client.INamingClient, 0) //namespace -> naming client
+
+// NacosNamingService a nacos naming service, whose healthy instances are the grpc targets of a pool.
+type NacosNamingService struct {
+	serviceName string
+	groupName   string
+	namespaceId string
+	clusters    []string
+}
+
+// serviceName
+func (n *NacosNamingService) SetServiceName(serviceName string) {
+	n.serviceName = serviceName
+}
+
+func (n *NacosNamingService) GetServiceName() string {
+	return n.serviceName
+}
+
+// groupName
+func (n *NacosNamingService) SetGroupName(groupName string) {
+	n.groupName = groupName
+}
+
+func (n *NacosNamingService) GetGroupName() string {
+	return n.groupName
+}
+
+// namespaceId
+func (n *NacosNamingService) SetNamespaceId(namespaceId string) {
+	n.namespaceId = namespaceId
+}
+
+func (n *NacosNamingService) GetNamespaceId() string {
+	return n.namespaceId
+}
+
+// clusters
+func (n *NacosNamingService) SetClusters(clusters []string) {
+	n.clusters = clusters
+}
+
+func (n *NacosNamingService) GetClusters() []string {
+	return n.clusters
+}
+
+// Discovery the name of the target source, such as nacos://namespace/group/service.
+func (n *NacosNamingService) Discovery() string {
+	return fmt.Sprintf("nacos://%s/%s/%s%v", n.namespaceId, n.groupName, n.serviceName, n.clusters)
+}
+
+// @implement internal.TargetWatch
+// Watch return the healthy instances, and push them into input on every change until stop.
+func (n *NacosNamingService) Watch(input chan<- []internal.Target) ([]internal.Target, func(), error) {
+	namingClient, err := getNamingClient(n.namespaceId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	targets, err := n.selectTargets(namingClient)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(targets) == 0 {
+		return nil, nil, fmt.Errorf("no healthy instance of %s", n.Discovery())
+	}
+
+	done := make(chan struct{})
+	lastTargets := targets
+	subscribeParam := &vo.SubscribeParam{
+		ServiceName: n.serviceName,
+		GroupName:   n.groupName,
+		Clusters:    n.clusters,
+		SubscribeCallback: func(services []model.SubscribeService, err error) {
+			if err != nil {
+				logs.Error(n.Discovery(), time.Now(), err)
+				return
+			}
+			//INFO: the callback may carry only the changed instances, select the whole healthy ones again.
+			targets, err := n.selectTargets(namingClient)
+			if err != nil {
+				logs.Error(n.Discovery(), time.Now(), err)
+				return
+			}
+			//protect the pool from an empty push, such as a nacos server restart.
+			if len(targets) == 0 {
+				logs.Warn(n.Discovery(), time.Now(), "no healthy instance, keep the last", len(lastTargets), "targets.")
+				return
+			}
+			lastTargets = targets
+
+			logs.Info(n.Discovery(), time.Now(), "targets changed:", targets)
+			select {
+			case input <- targets:
+			case <-done:
+			}
+		},
+	}
+	err = namingClient.Subscribe(subscribeParam)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var stopOnce sync.Once
+	stop := func() {
+		stopOnce.Do(func() {
+			close(done)
+			err := namingClient.Unsubscribe(subscribeParam)
+			if err != nil {
+				logs.Error(n.Discovery(), time.Now(), err)
+			}
+		})
+	}
+
+	return targets, stop, nil
+}
+
+func (n *NacosNamingService) selectTargets(namingClient naming_client.INamingClient) ([]internal.Target, error) {
+	instances, err := namingClient.SelectInstances(vo.SelectInstancesParam{
+		ServiceName: n.serviceName,
+		GroupName:   n.groupName,
+		Clusters:    n.clusters,
+		HealthyOnly: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]internal.Target, 0, len(instances))
+	for _, instance := range instances {
+		if !instance.Enable || !instance.Healthy || instance.Weight <= 0 {
+			continue
+		}
+		targets = append(targets, internal.Target{
+			Addr:     instance.Ip + ":" + strconv.FormatUint(instance.Port, 10),
+			Weight:   instance.Weight,
+			Metadata: instance.Metadata,
+		})
+	}
+
+	return targets, nil
+}
+
+// one naming client per namespace, shared by all naming services.
+func getNamingClient(namespaceId string) (naming_client.INamingClient, error) {
+	namingMu.Lock()
+	defer namingMu.Unlock()
+
+	if namingClient, ok := namingClients[namespaceId]; ok {
+		return namingClient, nil
+	}
+
+	serviceConf := []constant.ServerConfig{{
+		IpAddr: *nacosIp,
+		Port:   uint64(*nacosPort),
+	}}
+	clientConf := constant.ClientConfig{
+		NamespaceId:         namespaceId,
+		TimeoutMs:           uint64(*nacosTimeoutMs),
+		NotLoadCacheAtStart: true,
+		LogDir:              *nacosLogDir,
+		CacheDir:            *nacosCacheDir,
+		LogLevel:            *nacosLogLevel,
+		Username:            *nacosUsername,
+		Password:            *nacosPassword,
+	}
+	namingClient, err := clients.CreateNamingClient(map[string]interface{}{
+		"serverConfigs": serviceConf,
+		"clientConfig":  clientConf,
+	})
+	if err != nil {
+		return nil, err
+	}
+	namingClients[namespaceId] = namingClient
+
+	return namingClient, nil
+}
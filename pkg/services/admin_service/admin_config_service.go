@@ -0,0 +1,129 @@
+package admin_service
+
+import (
+	"encoding/json"
+	"errors"
+	"infer-microservices/internal"
+	"infer-microservices/pkg/config_source"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+)
+
+// POST /admin/config/dryrun?dataId=inferid-001&no_connect=true
+//
+// body is the service content {"config": {...}}, or the service_start_config.json format with dataId and content.
+func (s *AdminService) ConfigDryRun(c echo.Context) error {
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return adminFail(c, http.StatusBadRequest, err, nil)
+	}
+	if len(body) == 0 {
+		return adminFail(c, http.StatusBadRequest, errors.New("config document can not be empty"), nil)
+	}
+
+	noConnect := false
+	if noConnectStr := c.QueryParam("no_connect"); noConnectStr != "" {
+		noConnect, err = strconv.ParseBool(noConnectStr)
+		if err != nil {
+			return adminFail(c, http.StatusBadRequest, err, nil)
+		}
+	}
+
+	dataId, content := unwrapConfigDocument(c.QueryParam("dataId"), body)
+	report := config_source.DryRun(dataId, content, !noConnect)
+	if report.HasError() {
+		return adminFail(c, http.StatusUnprocessableEntity, errors.New("config check failed"), report)
+	}
+
+	return adminSuccess(c, report)
+}
+
+// take dataId and content out of the service_start_config.json format.
+func unwrapConfigDocument(dataId string, body []byte) (string, []byte) {
+	document := struct {
+		DataId  string          `json:"dataId"`
+		Content json.RawMessage `json:"content"`
+	}{}
+	err := json.Unmarshal(body, &document)
+	if err != nil || len(document.Content) == 0 {
+		//not wrapped, or malformed which is reported by the check.
+		return dataId, body
+	}
+
+	if dataId == "" {
+		dataId = document.DataId
+	}
+
+	return dataId, document.Content
+}
+
+// GET /admin/config/inferid-001/versions
+func (s *AdminService) ConfigVersions(c echo.Context) error {
+	versions, err := config_source.ServiceConfigVersions(c.Param("dataId"))
+	if err != nil {
+		return adminFail(c, http.StatusNotFound, err, nil)
+	}
+
+	return adminSuccess(c, versions)
+}
+
+// POST /admin/config/inferid-001/rollback
+//
+// body {"version": 3}, version 0 or no body rolls back to the last good version before the active one.
+func (s *AdminService) ConfigRollback(c echo.Context) error {
+	request := struct {
+		Version int64 `json:"version"`
+	}{}
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return adminFail(c, http.StatusBadRequest, err, nil)
+	}
+	if len(body) > 0 {
+		err = json.Unmarshal(body, &request)
+		if err != nil {
+			return adminFail(c, http.StatusBadRequest, err, nil)
+		}
+	}
+
+	version, err := config_source.RollbackServiceConfig(c.Param("dataId"), request.Version)
+	if err != nil {
+		return adminFail(c, http.StatusConflict, err, nil)
+	}
+
+	return adminSuccess(c, version)
+}
+
+// GET /admin/config/inferid-001/backends
+//
+// health of the tfserving and faiss targets of the active config, such as ejected and serving status.
+func (s *AdminService) ConfigBackends(c echo.Context) error {
+	serviceConfig, err := config_source.GetServiceConfig(c.Param("dataId"))
+	if err != nil {
+		return adminFail(c, http.StatusNotFound, err, nil)
+	}
+
+	backends := make(map[string][]internal.TargetStatus, 0)
+	if modelConfig := serviceConfig.GetModelConfig(); modelConfig != nil && modelConfig.GetTfservingGrpcPool() != nil {
+		backends["tfserving"] = modelConfig.GetTfservingGrpcPool().TargetStatus()
+	}
+	if faissConfigs := serviceConfig.GetFaissIndexConfigs(); faissConfigs != nil && faissConfigs.GetFaissGrpcPool() != nil {
+		backends["faiss"] = faissConfigs.GetFaissGrpcPool().TargetStatus() //the pool is shared by all faiss indexes.
+	}
+
+	return adminSuccess(c, backends)
+}
+
+// GET /admin/config/inferid-001/indexes
+//
+// metadata of the faiss indexes from GetIndexInfo, such as md5, dim and size, and its freshness.
+func (s *AdminService) ConfigIndexes(c echo.Context) error {
+	serviceConfig, err := config_source.GetServiceConfig(c.Param("dataId"))
+	if err != nil {
+		return adminFail(c, http.StatusNotFound, err, nil)
+	}
+
+	return adminSuccess(c, serviceConfig.GetFaissIndexConfigs().IndexStatuses())
+}
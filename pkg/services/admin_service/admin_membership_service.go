@@ -0,0 +1,105 @@
+package admin_service
+
+import (
+	"errors"
+	"infer-microservices/internal/jwt"
+	"infer-microservices/pkg/membership"
+	"net/http"
+	"strconv"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/labstack/echo"
+)
+
+type membershipRequest struct {
+	Ids      []string `json:"ids"`
+	Path     string   `json:"path"`
+	Operator string   `json:"operator"`
+}
+
+// POST /admin/filter/:target/add
+func (s *AdminService) AddMembers(c echo.Context) error {
+	return s.applyMembershipEvent(c, membership.ActionAdd)
+}
+
+// POST /admin/filter/:target/remove
+func (s *AdminService) RemoveMembers(c echo.Context) error {
+	return s.applyMembershipEvent(c, membership.ActionRemove)
+}
+
+// POST /admin/filter/:target/reload
+func (s *AdminService) ReloadMembers(c echo.Context) error {
+	return s.applyMembershipEvent(c, membership.ActionReload)
+}
+
+// POST /admin/filter/:target/contains
+func (s *AdminService) ContainsMembers(c echo.Context) error {
+	request := membershipRequest{}
+	if err := c.Bind(&request); err != nil {
+		return adminFail(c, http.StatusBadRequest, err, nil)
+	}
+	if len(request.Ids) == 0 {
+		return adminFail(c, http.StatusBadRequest, errors.New("ids can not be empty"), nil)
+	}
+
+	result, err := membership.GetMembershipManagerInstance().Contains(c.Param("target"), request.Ids)
+	if err != nil {
+		return adminFail(c, http.StatusBadRequest, err, nil)
+	}
+
+	return adminSuccess(c, result)
+}
+
+// GET /admin/filter/events?limit=100
+func (s *AdminService) MembershipEvents(c echo.Context) error {
+	limit := 100
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		limit_, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return adminFail(c, http.StatusBadRequest, err, nil)
+		}
+		limit = limit_
+	}
+
+	return adminSuccess(c, membership.GetMembershipManagerInstance().Events(limit))
+}
+
+func (s *AdminService) applyMembershipEvent(c echo.Context, action string) error {
+	request := membershipRequest{}
+	if err := c.Bind(&request); err != nil {
+		return adminFail(c, http.StatusBadRequest, err, nil)
+	}
+
+	event := membership.MembershipEvent{
+		Action:   action,
+		Target:   c.Param("target"),
+		Ids:      request.Ids,
+		Path:     request.Path,
+		Operator: request.Operator,
+	}
+	if event.Operator == "" {
+		event.Operator = operatorName(c)
+	}
+
+	result, err := membership.GetMembershipManagerInstance().Apply(event, membership.SourceAdmin)
+	if err != nil {
+		return adminFail(c, http.StatusBadRequest, err, result)
+	}
+
+	return adminSuccess(c, result)
+}
+
+// the login user name in jwt claims.
+func operatorName(c echo.Context) string {
+	token, ok := c.Get("user").(*jwtgo.Token)
+	if !ok {
+		return ""
+	}
+
+	claims, ok := token.Claims.(*jwt.JwtCustomClaims)
+	if !ok {
+		return ""
+	}
+
+	return claims.Name
+}
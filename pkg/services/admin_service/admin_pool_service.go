@@ -0,0 +1,14 @@
+package admin_service
+
+import (
+	"infer-microservices/internal"
+
+	"github.com/labstack/echo"
+)
+
+// GET /admin/pools
+//
+// active, idle, waiting, created and closed conns of every grpc pool, such as tfserving and faiss.
+func (s *AdminService) PoolStats(c echo.Context) error {
+	return adminSuccess(c, internal.GrpcPoolStats())
+}
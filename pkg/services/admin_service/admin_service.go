@@ -0,0 +1,33 @@
+package admin_service
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+)
+
+// AdminService manage the running service, such as user / item filters.
+type AdminService struct {
+}
+
+type adminResponse struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func adminSuccess(c echo.Context, data interface{}) error {
+	return c.JSON(http.StatusOK, adminResponse{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    data,
+	})
+}
+
+func adminFail(c echo.Context, httpStatus int, err error, data interface{}) error {
+	return c.JSON(httpStatus, adminResponse{
+		Code:    httpStatus,
+		Message: err.Error(),
+		Data:    data,
+	})
+}
@@ -1,9 +1,13 @@
 package baseservice
 
 import (
+	"context"
+	"fmt"
 	"infer-microservices/internal/logs"
 	"infer-microservices/internal/utils"
 	config_loader "infer-microservices/pkg/config_loader"
+	"infer-microservices/pkg/config_loader/config_schema"
+	"infer-microservices/pkg/config_source"
 	"infer-microservices/pkg/model"
 	"infer-microservices/pkg/model/basemodel"
 	"infer-microservices/pkg/services/io"
@@ -91,13 +95,39 @@ func (s *BaseService) GetLowerRecallNum() int {
 	return s.lowerRecallNum
 }
 
-func (s *BaseService) RecommenderInferHystrix(r *http.Request, serverName string, in *io.RecRequest, ServiceConfig *config_loader.ServiceConfig) (map[string]interface{}, error) {
+// end-to-end deadline of the dataId, the default one if the dataId is unavailable.
+func (s *BaseService) Deadline(dataId string) time.Duration {
+	ServiceConfig, err := config_source.GetServiceConfig(dataId)
+	if err != nil {
+		return config_schema.DefaultDeadline
+	}
+
+	return ServiceConfig.GetResilienceConfig().GetDeadline()
+}
+
+// the hystrix command and the degradation sizes are from the resilience config of the dataId, serverName is the fallback command.
+// the downstream calls are bounded by the deadline of ctx, the end-to-end deadline of the request.
+func (s *BaseService) RecommenderInferHystrix(ctx context.Context, r *http.Request, serverName string, in *io.RecRequest, ServiceConfig *config_loader.ServiceConfig) (map[string]interface{}, error) {
 	response := make(map[string]interface{}, 0)
 	requestId := utils.CreateRequestId(in)
 
-	hystrixErr := hystrix.Do(serverName, func() error {
+	resilienceConfig := ServiceConfig.GetResilienceConfig()
+	commandName := resilienceConfig.GetHystrixCommand()
+	if commandName == "" {
+		commandName = serverName
+	}
+	lowerRecallNum := resilienceConfig.GetLowerRecallNum()
+	if lowerRecallNum <= 0 {
+		lowerRecallNum = s.lowerRecallNum
+	}
+	lowerRankNum := resilienceConfig.GetLowerRankNum()
+	if lowerRankNum <= 0 {
+		lowerRankNum = s.lowerRankNum
+	}
+
+	hystrixErr := hystrix.Do(commandName, func() error {
 		// request recall / rank func.
-		response_, err_ := s.modelInfer(r, in, ServiceConfig)
+		response_, err_ := s.modelInfer(ctx, r, in, ServiceConfig)
 		if err_ != nil {
 			logs.Error(requestId, time.Now(), err_)
 			return err_
@@ -113,9 +143,12 @@ func (s *BaseService) RecommenderInferHystrix(r *http.Request, serverName string
 
 		//INFO:its better not use the same func
 		itemList := in.GetItemList()
-		in.SetRecallNum(int32(s.lowerRecallNum))
-		in.SetItemList(itemList[:s.lowerRankNum])
-		response_, err_ := s.modelInferReduce(r, in, ServiceConfig)
+		if len(itemList) > lowerRankNum {
+			itemList = itemList[:lowerRankNum]
+		}
+		in.SetRecallNum(int32(lowerRecallNum))
+		in.SetItemList(itemList)
+		response_, err_ := s.modelInferReduce(ctx, r, in, ServiceConfig)
 		if err_ != nil {
 			logs.Error(requestId, time.Now(), err_)
 			return err_
@@ -134,7 +167,7 @@ func (s *BaseService) RecommenderInferHystrix(r *http.Request, serverName string
 	return response, nil
 }
 
-func (s *BaseService) modelInfer(r *http.Request, in *io.RecRequest, ServiceConfig *config_loader.ServiceConfig) (map[string]interface{}, error) {
+func (s *BaseService) modelInfer(ctx context.Context, r *http.Request, in *io.RecRequest, ServiceConfig *config_loader.ServiceConfig) (map[string]interface{}, error) {
 	response := make(map[string]interface{}, 0)
 	requestId := utils.CreateRequestId(in)
 
@@ -145,55 +178,23 @@ func (s *BaseService) modelInfer(r *http.Request, in *io.RecRequest, ServiceConf
 	}
 
 	//strategy pattern. share model
-	var modelStrategy model.ModelStrategyInterface
+	modelStrategy := modelStrategyOf(in.GetDataId(), modelName, ServiceConfig)
 	modelStrategyContext := model.ModelStrategyContext{}
-	_, ok := model.GetModelStrategyMap()[in.GetDataId()]
-	if !ok {
-		modelfactory := model.ModelStrategyFactory{}
-		modelStrategy = modelfactory.CreateModelStrategy(modelName, ServiceConfig)
-		model.GetModelStrategyMap()[in.GetDataId()] = modelStrategy
-	} else {
-		modelStrategy = model.GetModelStrategyMap()[in.GetDataId()]
-	}
 	modelStrategyContext.SetModelStrategy(modelStrategy)
 
 	//use callback func to create sample
 	createSampleFunc := basemodel.SampleCallBackFuncMap[strings.ToLower(modelStrategy.GetModelType())]
-	result, err := modelStrategyContext.ModelInferSkywalking(requestId, in.GetUserId(), in.GetItemList(), r, createSampleFunc)
+	result, err := modelStrategyContext.ModelInferSkywalking(ctx, requestId, in, r, createSampleFunc)
 	if err != nil {
 		logs.Error(requestId, time.Now(), err)
 		return response, err
 	}
 
 	//package infer result.
-	itemsScores := make([]*io.ItemInfo, 0)
-	resultList := result["data"].([]map[string]interface{})
-	resultCh := make(chan *io.ItemInfo, len(resultList))
-	if len(resultList) > 0 {
-		for i := 0; i < len(resultList); i++ {
-			go formatDubboResponse(resultList[i], resultCh)
-		}
-
-	loop:
-		for {
-			select {
-			case <-time.After(time.Millisecond * 100):
-				break loop
-			case itemScore := <-resultCh:
-				itemsScores = append(itemsScores, itemScore)
-			}
-		}
-		close(resultCh)
-
-		response["code"] = 200
-		response["message"] = "success"
-		response["data"] = itemsScores
-	}
-
-	return response, nil
+	return formatInferResult(result, ServiceConfig), nil
 }
 
-func (s *BaseService) modelInferReduce(r *http.Request, in *io.RecRequest, ServiceConfig *config_loader.ServiceConfig) (map[string]interface{}, error) {
+func (s *BaseService) modelInferReduce(ctx context.Context, r *http.Request, in *io.RecRequest, ServiceConfig *config_loader.ServiceConfig) (map[string]interface{}, error) {
 	response := make(map[string]interface{}, 0)
 	requestId := utils.CreateRequestId(in)
 
@@ -206,64 +207,127 @@ func (s *BaseService) modelInferReduce(r *http.Request, in *io.RecRequest, Servi
 	modelName := "fm"
 
 	//strategy pattern. share model
-	var modelStrategy model.ModelStrategyInterface
+	modelStrategy := modelStrategyOf(in.GetDataId(), modelName, ServiceConfig)
 	modelStrategyContext := model.ModelStrategyContext{}
-	_, ok := model.GetModelStrategyMap()[in.GetDataId()]
-	if !ok {
-		modelfactory := model.ModelStrategyFactory{}
-		modelStrategy = modelfactory.CreateModelStrategy(modelName, ServiceConfig)
-		model.GetModelStrategyMap()[in.GetDataId()] = modelStrategy
-	} else {
-		modelStrategy = model.GetModelStrategyMap()[in.GetDataId()]
-	}
 	modelStrategyContext.SetModelStrategy(modelStrategy)
 
 	//use callback func to create sample
 	createSampleFunc := basemodel.SampleCallBackFuncMap[strings.ToLower(modelStrategy.GetModelType())]
-	result, err := modelStrategyContext.ModelInferSkywalking(requestId, in.GetDataId(), in.GetItemList(), r, createSampleFunc)
+	result, err := modelStrategyContext.ModelInferSkywalking(ctx, requestId, in, r, createSampleFunc)
 	if err != nil {
 		logs.Error(requestId, time.Now(), err)
 		return response, err
 	}
 	//package infer result.
-	itemsScores := make([]*io.ItemInfo, 0)
-	resultList := result["data"].([]map[string]interface{})
-	resultCh := make(chan *io.ItemInfo, len(resultList))
+	return formatInferResult(result, ServiceConfig), nil
+}
+
+// the model of the dataId, shared by its requests.
+func modelStrategyOf(dataId string, modelName string, ServiceConfig *config_loader.ServiceConfig) model.ModelStrategyInterface {
+	//INFO: rebuild the model when nacos updated the service config, such as calibration spec.
+	modelfactory := model.ModelStrategyFactory{}
+
+	return modelfactory.GetOrCreateModelStrategy(dataId, modelName, ServiceConfig)
+}
+
+// the recall model of the dataId, shared by its similar items and streaming recall requests. the model of its
+// recommend requests may be a rank one, so it is not used.
+func recallModelOf(dataId string, ServiceConfig *config_loader.ServiceConfig) model.ModelStrategyInterface {
+	return modelStrategyOf(dataId, "dssm", ServiceConfig)
+}
+
+// SimilarItemsHystrix the similar items of in.itemId, by the recall model of the dataId. the hystrix command is the one
+// of RecommenderInferHystrix, it has no reduced fallback.
+func (s *BaseService) SimilarItemsHystrix(ctx context.Context, r *http.Request, serverName string, in *io.RecRequest, ServiceConfig *config_loader.ServiceConfig) (map[string]interface{}, error) {
+	response := make(map[string]interface{}, 0)
+	requestId := utils.CreateRequestId(in)
+
+	commandName := ServiceConfig.GetResilienceConfig().GetHystrixCommand()
+	if commandName == "" {
+		commandName = serverName
+	}
+
+	hystrixErr := hystrix.Do(commandName, func() error {
+		response_, err_ := s.similarItemsInfer(ctx, r, in, ServiceConfig)
+		if err_ != nil {
+			logs.Error(requestId, time.Now(), err_)
+			return err_
+		}
+		response = response_
+		logs.Debug(requestId, time.Now(), "similar items response:", response)
+
+		return nil
+	}, nil)
+
+	return response, hystrixErr
+}
 
+func (s *BaseService) similarItemsInfer(ctx context.Context, r *http.Request, in *io.RecRequest, ServiceConfig *config_loader.ServiceConfig) (map[string]interface{}, error) {
+	requestId := utils.CreateRequestId(in)
+
+	similarModel, ok := recallModelOf(in.GetDataId(), ServiceConfig).(model.SimilarItemsInterface)
+	if !ok {
+		return nil, fmt.Errorf("the recall model of %s does not recall similar items", in.GetDataId())
+	}
+
+	result, err := similarModel.SimilarItemsInfer(ctx, requestId, in, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return formatInferResult(result, ServiceConfig), nil
+}
+
+// the items of the model result in their order, formatted in the format stage timeout.
+func formatInferResult(result map[string]interface{}, ServiceConfig *config_loader.ServiceConfig) map[string]interface{} {
+	response := make(map[string]interface{}, 0)
+	resultList, _ := result["data"].([]map[string]interface{})
 	if len(resultList) > 0 {
+		itemsScores := make([]*io.ItemInfo, len(resultList))
+		resultCh := make(chan int, len(resultList))
 		for i := 0; i < len(resultList); i++ {
-			go formatDubboResponse(resultList[i], resultCh)
+			go func(i int) {
+				itemsScores[i] = formatDubboResponse(resultList[i])
+				resultCh <- i
+			}(i)
 		}
 
+		formatted := make([]bool, len(resultList))
+		timeout := time.After(ServiceConfig.GetResilienceConfig().GetStageTimeout(config_schema.StageFormat))
 	loop:
-		for {
+		for received := 0; received < len(resultList); received++ {
 			select {
-			case <-time.After(time.Millisecond * 100):
+			case <-timeout:
 				break loop
-			case itemScore := <-resultCh:
-				itemsScores = append(itemsScores, itemScore)
+			case i := <-resultCh:
+				formatted[i] = true
+			}
+		}
+		items := make([]*io.ItemInfo, 0, len(resultList))
+		for i, ok := range formatted {
+			if ok {
+				items = append(items, itemsScores[i])
 			}
 		}
-		close(resultCh)
 
 		response["code"] = 200
 		response["message"] = "success"
-		response["data"] = itemsScores
+		response["data"] = items
 	}
 
-	return response, nil
+	return response
 }
 
-func formatDubboResponse(itemScore map[string]interface{}, resultCh chan *io.ItemInfo) { //recallCh chan string) {
+func formatDubboResponse(itemScore map[string]interface{}) *io.ItemInfo {
 	itemId := itemScore["itemid"].(string)
 	score := float32(itemScore["score"].(float64))
 
 	itemInfo := io.ItemInfo{}
 	itemInfo.SetItemId(itemId)
 	itemInfo.SetScore(score)
+	if rawScore, ok := itemScore["rawScore"].(float64); ok {
+		itemInfo.SetRawScore(float32(rawScore))
+	}
 
-	//itemScoreStr := utils.ConvertStructToJson(itemInfo)
-	//recallCh <- itemScoreStr
-
-	resultCh <- &itemInfo
+	return &itemInfo
 }
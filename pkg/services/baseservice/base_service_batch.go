@@ -0,0 +1,183 @@
+package baseservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"infer-microservices/internal/logs"
+	"infer-microservices/internal/utils"
+	config_loader "infer-microservices/pkg/config_loader"
+	"infer-microservices/pkg/config_source"
+	"infer-microservices/pkg/model"
+	"infer-microservices/pkg/services/io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/afex/hystrix-go/hystrix"
+)
+
+// the errors of a request which is not inferred, see HttpStatusOf.
+var ErrInvalidRequest = errors.New("invalid request")
+var ErrUnknownDataId = errors.New("unknown dataId")
+
+// HttpStatusOf the http status of an infer error: the invalid requests are 400, the unknown dataIds 404, the hystrix
+// rejections 503, the timeouts 504 and others 500.
+func HttpStatusOf(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrUnknownDataId):
+		return http.StatusNotFound
+	case errors.Is(err, hystrix.ErrCircuitOpen), errors.Is(err, hystrix.ErrMaxConcurrency):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, hystrix.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+
+	return http.StatusInternalServerError
+}
+
+// BatchDeadline the deadline of a batch request, the longest one of its dataIds.
+func (s *BaseService) BatchDeadline(ins []*io.RecRequest) time.Duration {
+	var deadline time.Duration
+	for _, in := range ins {
+		if d := s.Deadline(in.GetDataId()); d > deadline {
+			deadline = d
+		}
+	}
+
+	return deadline
+}
+
+// BatchInferHystrix infer the requests of a batch, the requests of a dataId and a model type are inferred by one
+// BatchInfer of the model in its hystrix command, the groups run in parallel. the results and the errors are in the
+// order of ins, an error only fails its request; the groups not done when ctx is done fail by ctx.Err().
+func (s *BaseService) BatchInferHystrix(ctx context.Context, r *http.Request, serverName string, ins []*io.RecRequest) ([]map[string]interface{}, []error) {
+	type batchGroup struct {
+		indexes       []int
+		serviceConfig *config_loader.ServiceConfig
+		batchModel    model.BatchInferInterface
+	}
+	type groupResult struct {
+		group     int
+		responses []map[string]interface{}
+		errs      []error
+	}
+
+	responses := make([]map[string]interface{}, len(ins))
+	errs := make([]error, len(ins))
+
+	groups := make([]*batchGroup, 0)
+	groupOf := make(map[string]*batchGroup, 0)
+	for i, in := range ins {
+		if err := in.Validate(); err != nil {
+			errs[i] = fmt.Errorf("%w: %s", ErrInvalidRequest, err)
+			continue
+		}
+		ServiceConfig, err := config_source.GetServiceConfig(in.GetDataId())
+		if err != nil {
+			errs[i] = fmt.Errorf("%w: %s", ErrUnknownDataId, err)
+			continue
+		}
+
+		modelName := strings.ToLower(in.GetModelType())
+		key := in.GetDataId() + "/" + modelName
+		group, ok := groupOf[key]
+		if !ok {
+			modelStrategy := modelStrategyOf(in.GetDataId(), modelName, ServiceConfig)
+			batchModel, _ := modelStrategy.(model.BatchInferInterface)
+			group = &batchGroup{serviceConfig: ServiceConfig, batchModel: batchModel}
+			groupOf[key] = group
+			groups = append(groups, group)
+		}
+		if group.batchModel == nil {
+			errs[i] = fmt.Errorf("%w: the model %s of %s does not infer batch requests", ErrInvalidRequest, modelName, in.GetDataId())
+			continue
+		}
+		group.indexes = append(group.indexes, i)
+	}
+
+	resultCh := make(chan groupResult, len(groups))
+	for g, group := range groups {
+		if len(group.indexes) == 0 {
+			resultCh <- groupResult{group: g}
+			continue
+		}
+		go func(g int, group *batchGroup) {
+			groupIns := make([]*io.RecRequest, len(group.indexes))
+			for j, i := range group.indexes {
+				groupIns[j] = ins[i]
+			}
+			requestId := utils.CreateRequestId(groupIns[0])
+
+			commandName := group.serviceConfig.GetResilienceConfig().GetHystrixCommand()
+			if commandName == "" {
+				commandName = serverName
+			}
+			//the result is sent by the command, it may still run after a hystrix timeout.
+			inferCh := make(chan groupResult, 1)
+			hystrixErr := hystrix.Do(commandName, func() error {
+				inferred := groupResult{group: g, responses: make([]map[string]interface{}, len(groupIns)), errs: make([]error, len(groupIns))}
+				groupResponses, groupErrs := group.batchModel.BatchInfer(ctx, requestId, groupIns, r)
+				failed := 0
+				for j := range groupIns {
+					if groupErrs[j] != nil {
+						logs.Error(requestId, time.Now(), groupErrs[j])
+						inferred.errs[j] = groupErrs[j]
+						failed += 1
+						continue
+					}
+					inferred.responses[j] = formatInferResult(groupResponses[j], group.serviceConfig)
+				}
+				inferCh <- inferred
+
+				//the command fails if no request is inferred, such as tfserving is down.
+				if failed == len(groupIns) {
+					return inferred.errs[0]
+				}
+				return nil
+			}, nil)
+
+			result := groupResult{group: g, responses: make([]map[string]interface{}, len(groupIns)), errs: make([]error, len(groupIns))}
+			select {
+			case result = <-inferCh:
+			default:
+			}
+			if hystrixErr != nil {
+				logs.Error(requestId, time.Now(), hystrixErr)
+				for j := range groupIns {
+					if result.errs[j] == nil && result.responses[j] == nil {
+						result.errs[j] = hystrixErr
+					}
+				}
+			}
+			resultCh <- result
+		}(g, group)
+	}
+
+	done := make([]bool, len(groups))
+loop:
+	for received := 0; received < len(groups); received++ {
+		select {
+		case <-ctx.Done():
+			break loop
+		case result := <-resultCh:
+			done[result.group] = true
+			for j, i := range groups[result.group].indexes {
+				responses[i] = result.responses[j]
+				errs[i] = result.errs[j]
+			}
+		}
+	}
+	for g, group := range groups {
+		if done[g] {
+			continue
+		}
+		for _, i := range group.indexes {
+			errs[i] = ctx.Err()
+		}
+	}
+
+	return responses, errs
+}
@@ -0,0 +1,53 @@
+package baseservice
+
+import (
+	"context"
+	"fmt"
+	"infer-microservices/internal/logs"
+	"infer-microservices/internal/utils"
+	config_loader "infer-microservices/pkg/config_loader"
+	"infer-microservices/pkg/model"
+	"infer-microservices/pkg/services/io"
+	"net/http"
+	"time"
+
+	"github.com/afex/hystrix-go/hystrix"
+)
+
+// RecallStreamHystrix recall the items of in.userId in the hystrix command of RecommenderInferHystrix, the formatted
+// items of each faiss index are passed to partial as soon as the index responds, the formatted merged result is
+// returned. it has no reduced fallback, partial may be called after a hystrix timeout and should drop the late ones.
+func (s *BaseService) RecallStreamHystrix(ctx context.Context, r *http.Request, serverName string, in *io.RecRequest, ServiceConfig *config_loader.ServiceConfig,
+	partial func(indexName string, response map[string]interface{})) (map[string]interface{}, error) {
+	requestId := utils.CreateRequestId(in)
+
+	streamModel, ok := recallModelOf(in.GetDataId(), ServiceConfig).(model.RecallStreamInterface)
+	if !ok {
+		return nil, fmt.Errorf("the recall model of %s does not stream recall results", in.GetDataId())
+	}
+
+	commandName := ServiceConfig.GetResilienceConfig().GetHystrixCommand()
+	if commandName == "" {
+		commandName = serverName
+	}
+
+	//the result is sent by the command, it may still run after a hystrix timeout.
+	resultCh := make(chan map[string]interface{}, 1)
+	hystrixErr := hystrix.Do(commandName, func() error {
+		result, err := streamModel.RecallStream(ctx, requestId, in, r, func(indexName string, result map[string]interface{}) {
+			partial(indexName, formatInferResult(result, ServiceConfig))
+		})
+		if err != nil {
+			logs.Error(requestId, time.Now(), err)
+			return err
+		}
+		resultCh <- formatInferResult(result, ServiceConfig)
+
+		return nil
+	}, nil)
+	if hystrixErr != nil {
+		return nil, hystrixErr
+	}
+
+	return <-resultCh, nil
+}
@@ -5,12 +5,13 @@ import (
 	"errors"
 	"fmt"
 	config_loader "infer-microservices/pkg/config_loader"
+	"infer-microservices/pkg/config_source"
 	"infer-microservices/pkg/services/baseservice"
 
 	"infer-microservices/internal/logs"
 	"infer-microservices/internal/utils"
-	nacos "infer-microservices/pkg/nacos"
 	"infer-microservices/pkg/services/io"
+	"net/http"
 	"time"
 
 	_ "dubbo.apache.org/dubbo-go/v3/imports"
@@ -39,13 +40,23 @@ func (s *DubboService) GetBaseService() *baseservice.BaseService {
 
 // Implement interface methods.
 func (s *DubboService) RecommenderInfer(ctx context.Context, in *io.RecRequest) (*io.RecResponse, error) {
+	return s.infer(ctx, in, (*io.RecRequest).Check, s.baseservice.RecommenderInferHystrix)
+}
+
+// the similar items of in.itemId.
+func (s *DubboService) SimilarItemsInfer(ctx context.Context, in *io.RecRequest) (*io.RecResponse, error) {
+	return s.infer(ctx, in, (*io.RecRequest).CheckSimilarItems, s.baseservice.SimilarItemsHystrix)
+}
+
+func (s *DubboService) infer(ctx context.Context, in *io.RecRequest, check func(*io.RecRequest) bool,
+	hystrixInfer func(context.Context, *http.Request, string, *io.RecRequest, *config_loader.ServiceConfig) (map[string]interface{}, error)) (*io.RecResponse, error) {
 	response := &io.RecResponse{}
 	response.SetCode(404)
 	requestId := utils.CreateRequestId(in)
 	logs.Debug(requestId, time.Now(), "RecRequest:", in)
 
 	//check input
-	checkStatus := in.Check()
+	checkStatus := check(in)
 	if !checkStatus {
 		err := errors.New("input check failed")
 		logs.Error(requestId, time.Now(), err)
@@ -53,11 +64,11 @@ func (s *DubboService) RecommenderInfer(ctx context.Context, in *io.RecRequest)
 	}
 
 	//INFO: set timeout by context, degraded service by hystix.
-	ctx, cancelFunc := context.WithTimeout(ctx, time.Millisecond*100)
+	ctx, cancelFunc := context.WithTimeout(ctx, s.baseservice.Deadline(in.GetDataId()))
 	defer cancelFunc()
 
 	respCh := make(chan *io.RecResponse, 100)
-	go s.recommenderInferContext(ctx, in, respCh)
+	go s.inferContext(ctx, in, respCh, hystrixInfer)
 
 	select {
 	case <-ctx.Done():
@@ -75,7 +86,8 @@ func (s *DubboService) RecommenderInfer(ctx context.Context, in *io.RecRequest)
 	}
 }
 
-func (s *DubboService) recommenderInferContext(ctx context.Context, in *io.RecRequest, respCh chan *io.RecResponse) {
+func (s *DubboService) inferContext(ctx context.Context, in *io.RecRequest, respCh chan *io.RecResponse,
+	hystrixInfer func(context.Context, *http.Request, string, *io.RecRequest, *config_loader.ServiceConfig) (map[string]interface{}, error)) {
 	defer func() {
 		if info := recover(); info != nil {
 			logs.Fatal("panic", info)
@@ -88,16 +100,17 @@ func (s *DubboService) recommenderInferContext(ctx context.Context, in *io.RecRe
 	response.SetCode(404)
 	requestId := utils.CreateRequestId(in)
 
-	//nacos listen
-	nacosFactory := nacos.NacosFactory{}
-	nacosConfig := nacosFactory.CreateNacosConfig(s.baseservice.GetNacosIp(), uint64(s.baseservice.GetNacosPort()), in)
-	logs.Debug(requestId, time.Now(), "nacosConfig:", nacosConfig)
-
-	nacosConfig.StartListenNacos()
+	//service config, loaded at startup.
+	ServiceConfig, err := config_source.GetServiceConfig(in.GetDataId())
+	if err != nil {
+		logs.Error(requestId, time.Now(), err)
+		response.SetMessage(err.Error())
+		respCh <- response
+		return
+	}
 
 	//infer
-	ServiceConfig := config_loader.GetServiceConfigs()[in.GetDataId()]
-	response_, err := s.baseservice.RecommenderInferHystrix(nil, "dubboServer", in, ServiceConfig)
+	response_, err := hystrixInfer(ctx, nil, "dubboServer", in, ServiceConfig)
 	if err != nil || len(response_) == 0 {
 		response.SetMessage(fmt.Sprintf("%s", err))
 		panic(err)
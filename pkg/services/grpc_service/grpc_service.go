@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"infer-microservices/internal/utils"
 	config_loader "infer-microservices/pkg/config_loader"
+	"infer-microservices/pkg/config_loader/config_schema"
+	"infer-microservices/pkg/config_source"
+	"net/http"
 	"time"
 
 	"infer-microservices/internal/logs"
-	nacos "infer-microservices/pkg/nacos"
 	"infer-microservices/pkg/services/baseservice"
 	"infer-microservices/pkg/services/io"
 
@@ -30,6 +32,15 @@ func (s *GrpcService) GetBaseService() *baseservice.BaseService {
 
 // INFO: implement grpc func which defined by proto.
 func (s *GrpcService) RecommenderInfer(ctx context.Context, in *RecommendRequest) (*RecommendResponse, error) {
+	return s.infer(ctx, in, s.recommenderInferContext)
+}
+
+// the similar items of in.ItemId.
+func (s *GrpcService) SimilarItemsInfer(ctx context.Context, in *RecommendRequest) (*RecommendResponse, error) {
+	return s.infer(ctx, in, s.similarItemsInferContext)
+}
+
+func (s *GrpcService) infer(ctx context.Context, in *RecommendRequest, inferContext func(context.Context, *RecommendRequest, chan *RecommendResponse)) (*RecommendResponse, error) {
 	//INFO: set timeout by context, degraded service by hystix.
 	response := &RecommendResponse{
 		Code: 404,
@@ -38,11 +49,11 @@ func (s *GrpcService) RecommenderInfer(ctx context.Context, in *RecommendRequest
 	requestId := utils.CreateRequestId(&request)
 	logs.Debug(requestId, time.Now(), "RecRequest:", requestId)
 
-	ctx, cancelFunc := context.WithTimeout(ctx, time.Millisecond*100)
+	ctx, cancelFunc := context.WithTimeout(ctx, s.baseservice.Deadline(request.GetDataId()))
 	defer cancelFunc()
 
 	respCh := make(chan *RecommendResponse, 100)
-	go s.recommenderInferContext(ctx, in, respCh)
+	go inferContext(ctx, in, respCh)
 
 	select {
 	case <-ctx.Done():
@@ -61,6 +72,15 @@ func (s *GrpcService) RecommenderInfer(ctx context.Context, in *RecommendRequest
 }
 
 func (s *GrpcService) recommenderInferContext(ctx context.Context, in *RecommendRequest, respCh chan *RecommendResponse) {
+	s.inferContext(ctx, in, respCh, (*io.RecRequest).Check, s.baseservice.RecommenderInferHystrix)
+}
+
+func (s *GrpcService) similarItemsInferContext(ctx context.Context, in *RecommendRequest, respCh chan *RecommendResponse) {
+	s.inferContext(ctx, in, respCh, (*io.RecRequest).CheckSimilarItems, s.baseservice.SimilarItemsHystrix)
+}
+
+func (s *GrpcService) inferContext(ctx context.Context, in *RecommendRequest, respCh chan *RecommendResponse, check func(*io.RecRequest) bool,
+	hystrixInfer func(context.Context, *http.Request, string, *io.RecRequest, *config_loader.ServiceConfig) (map[string]interface{}, error)) {
 	defer func() {
 		if info := recover(); info != nil {
 			logs.Fatal("panic", info)
@@ -76,47 +96,118 @@ func (s *GrpcService) recommenderInferContext(ctx context.Context, in *Recommend
 	requestId := utils.CreateRequestId(&request)
 
 	//check input
-	checkStatus := request.Check()
+	checkStatus := check(&request)
 	if !checkStatus {
 		err := errors.New("input check failed")
 		logs.Error(requestId, time.Now(), err)
 		panic(err)
 	}
 
-	//nacos listen
-	nacosFactory := nacos.NacosFactory{}
-	nacosConfig := nacosFactory.CreateNacosConfig(s.baseservice.GetNacosIp(), uint64(s.baseservice.GetNacosPort()), &request)
-	logs.Debug(requestId, time.Now(), "nacosConfig:", nacosConfig)
-
-	nacosConfig.StartListenNacos()
+	//service config, loaded at startup.
+	ServiceConfig, err := config_source.GetServiceConfig(request.GetDataId())
+	if err != nil {
+		logs.Error(requestId, time.Now(), err)
+		response.Message = err.Error()
+		respCh <- response
+		return
+	}
 
 	//infer
-	ServiceConfig := config_loader.GetServiceConfigs()[in.GetDataId()]
-	response_, err := s.baseservice.RecommenderInferHystrix(nil, "GrpcService", &request, ServiceConfig)
+	response_, err := hystrixInfer(ctx, nil, "GrpcService", &request, ServiceConfig)
 	if err != nil {
 		response.Message = fmt.Sprintf("%s", err)
 		panic(err)
 	} else {
-		response = &RecommendResponse{
-			Code:    response_["code"].(int32),
-			Message: response_["message"].(string),
-			Data: &ItemInfoList{
-				Iteminfo_: response_["data"].([]*ItemInfo),
-			},
-		}
+		response = convertRecResponseToGrpcResponse(response_)
 
 	}
 	respCh <- response
 }
 
+// the requests of a batch are inferred with the shared features and the batched tfserving tensors, the responses are
+// in the order of the requests, each with its code.
+func (s *GrpcService) BatchRecommenderInfer(ctx context.Context, in *BatchRecommendRequest) (*BatchRecommendResponse, error) {
+	if len(in.GetRequests()) == 0 || len(in.GetRequests()) > io.MaxBatchNum {
+		return nil, fmt.Errorf("%w: batch requests len should be in [1, %d]", baseservice.ErrInvalidRequest, io.MaxBatchNum)
+	}
+
+	requests := make([]*io.RecRequest, 0, len(in.GetRequests()))
+	for _, grpcRequest := range in.GetRequests() {
+		request := convertGrpcRequestToRecRequest(grpcRequest)
+		requests = append(requests, &request)
+	}
+	requestId := utils.CreateRequestId(requests[0])
+	logs.Debug(requestId, time.Now(), "batch RecRequest:", len(requests))
+
+	ctx, cancelFunc := context.WithTimeout(ctx, s.baseservice.BatchDeadline(requests))
+	defer cancelFunc()
+
+	responses_, errs := s.baseservice.BatchInferHystrix(ctx, nil, "GrpcService", requests)
+	response := &BatchRecommendResponse{
+		Responses: make([]*RecommendResponse, len(requests)),
+	}
+	for i := range requests {
+		if errs[i] != nil {
+			response.Responses[i] = &RecommendResponse{
+				Code:    int32(baseservice.HttpStatusOf(errs[i])),
+				Message: errs[i].Error(),
+			}
+			continue
+		}
+		response.Responses[i] = convertRecResponseToGrpcResponse(responses_[i])
+	}
+	logs.Info(requestId, time.Now(), "batch response:", len(response.Responses))
+
+	return response, nil
+}
+
 func convertGrpcRequestToRecRequest(in *RecommendRequest) io.RecRequest {
 	request := io.RecRequest{}
 	request.SetDataId(in.GetDataId())
 	request.SetGroupId(in.GetGroupId())
 	request.SetNamespaceId(in.GetNamespace())
 	request.SetUserId(in.UserId)
+	request.SetItemId(in.GetItemId())
 	request.SetRecallNum(in.RecallNum)
-	request.SetItemList(in.ItemList.Value)
+	request.SetItemList(in.GetItemList().GetValue())
+	request.SetTopN(in.GetTopN())
+	request.SetMinScore(in.GetMinScore())
+	request.SetContext(in.GetContext())
+	request.SetDebug(in.GetDebug())
+	request.SetIncludeItems(in.GetIncludeItems().GetValue())
+	request.SetExcludeItems(in.GetExcludeItems().GetValue())
+	filters := make([]config_schema.AttributeFilter, 0, len(in.GetFilters()))
+	for _, filter := range in.GetFilters() {
+		filters = append(filters, config_schema.AttributeFilter{
+			Attribute: filter.GetAttribute(),
+			Op:        filter.GetOp(),
+			Values:    filter.GetValues(),
+		})
+	}
+	request.SetFilters(filters)
 
 	return request
 }
+
+func convertRecResponseToGrpcResponse(response_ map[string]interface{}) *RecommendResponse {
+	code, _ := response_["code"].(int)
+	message, _ := response_["message"].(string)
+	itemsScores, _ := response_["data"].([]*io.ItemInfo)
+
+	itemInfos := make([]*ItemInfo, 0, len(itemsScores))
+	for _, itemScore := range itemsScores {
+		itemInfos = append(itemInfos, &ItemInfo{
+			Itemid:   itemScore.GetItemId(),
+			Score:    itemScore.GetScore(),
+			Rawscore: itemScore.GetRawScore(),
+		})
+	}
+
+	return &RecommendResponse{
+		Code:    int32(code),
+		Message: message,
+		Data: &ItemInfoList{
+			Iteminfo_: itemInfos,
+		},
+	}
+}
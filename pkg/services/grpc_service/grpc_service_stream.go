@@ -0,0 +1,183 @@
+package grpc_service
+
+import (
+	"fmt"
+	"infer-microservices/internal/logs"
+	"infer-microservices/internal/utils"
+	"infer-microservices/pkg/config_source"
+	"infer-microservices/pkg/services/baseservice"
+	"infer-microservices/pkg/services/io"
+	goio "io"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// MaxStreamInflight the requests of a RecommenderInferStream inferred at once, the later ones wait to be received.
+const MaxStreamInflight = 64
+
+// the sends of a stream from many goroutines, the sends after the last one are dropped.
+type streamSender struct {
+	mu     sync.Mutex
+	closed bool
+}
+
+func (s *streamSender) send(send func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+
+	return send()
+}
+
+func (s *streamSender) sendLast(send func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	return send()
+}
+
+// RecommenderInferStream the requests of the stream are inferred concurrently, each as RecommenderInfer in its
+// deadline. the responses are sent as they are done, with the RequestId of their requests.
+func (s *GrpcService) RecommenderInferStream(stream RecommenderInferService_RecommenderInferStreamServer) error {
+	sender := &streamSender{}
+	inflight := make(chan struct{}, MaxStreamInflight)
+	var wg sync.WaitGroup
+	for {
+		in, err := stream.Recv()
+		if err == goio.EOF {
+			wg.Wait()
+			return nil
+		}
+		if err != nil {
+			sender.sendLast(func() error { return nil })
+			return err
+		}
+
+		select {
+		case inflight <- struct{}{}:
+		case <-stream.Context().Done():
+			sender.sendLast(func() error { return nil })
+			return stream.Context().Err()
+		}
+		wg.Add(1)
+		go func(in *RecommendRequest) {
+			defer func() {
+				<-inflight
+				wg.Done()
+			}()
+			response := s.streamInfer(stream.Context(), in)
+			if err := sender.send(func() error { return stream.Send(response) }); err != nil {
+				logs.Error(response.GetRequestId(), time.Now(), err)
+			}
+		}(in)
+	}
+}
+
+// infer a request of RecommenderInferStream, the errors are in the response code.
+func (s *GrpcService) streamInfer(ctx context.Context, in *RecommendRequest) *RecommendResponse {
+	request := convertGrpcRequestToRecRequest(in)
+	requestId := streamRequestId(in, &request)
+	if err := request.Validate(); err != nil {
+		return &RecommendResponse{Code: http.StatusBadRequest, Message: err.Error(), RequestId: requestId}
+	}
+
+	response, err := s.infer(ctx, in, s.recommenderInferContext)
+	if err != nil {
+		response = &RecommendResponse{Code: int32(baseservice.HttpStatusOf(err)), Message: err.Error()}
+	}
+	response.RequestId = requestId
+
+	return response
+}
+
+// RecallStream recall the items of a user, the items of each faiss index are sent as soon as the index responds, then
+// the merged items are sent by the Final response. the recall is in the deadline of the dataId.
+func (s *GrpcService) RecallStream(in *RecommendRequest, stream RecommenderInferService_RecallStreamServer) error {
+	request := convertGrpcRequestToRecRequest(in)
+	requestId := streamRequestId(in, &request)
+	final := &RecallStreamResponse{RequestId: requestId, Final: true}
+
+	if err := request.Validate(); err != nil {
+		final.Code, final.Message = http.StatusBadRequest, err.Error()
+		return stream.Send(final)
+	}
+	ServiceConfig, err := config_source.GetServiceConfig(request.GetDataId())
+	if err != nil {
+		final.Code, final.Message = http.StatusNotFound, err.Error()
+		return stream.Send(final)
+	}
+
+	ctx, cancelFunc := context.WithTimeout(stream.Context(), s.baseservice.Deadline(request.GetDataId()))
+	defer cancelFunc()
+
+	sender := &streamSender{}
+	partial := func(indexName string, response_ map[string]interface{}) {
+		response := convertRecResponseToRecallStreamResponse(response_)
+		response.Code = http.StatusOK
+		response.RequestId = requestId
+		response.IndexName = indexName
+		if err := sender.send(func() error { return stream.Send(response) }); err != nil {
+			logs.Error(requestId, time.Now(), err)
+		}
+	}
+
+	type recallResult struct {
+		response map[string]interface{}
+		err      error
+	}
+	resultCh := make(chan recallResult, 1)
+	go func() {
+		defer func() {
+			if info := recover(); info != nil {
+				resultCh <- recallResult{err: fmt.Errorf("panic: %v", info)}
+			}
+		}()
+		response_, err := s.baseservice.RecallStreamHystrix(ctx, nil, "GrpcService", &request, ServiceConfig, partial)
+		resultCh <- recallResult{response: response_, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		logs.Error(requestId, time.Now(), ctx.Err())
+		final.Code, final.Message = int32(baseservice.HttpStatusOf(ctx.Err())), ctx.Err().Error()
+	case result := <-resultCh:
+		if result.err != nil {
+			logs.Error(requestId, time.Now(), result.err)
+			final.Code, final.Message = int32(baseservice.HttpStatusOf(result.err)), result.err.Error()
+		} else {
+			final = convertRecResponseToRecallStreamResponse(result.response)
+			final.RequestId = requestId
+			final.Final = true
+		}
+	}
+
+	return sender.sendLast(func() error { return stream.Send(final) })
+}
+
+// the RequestId of a stream request, a generated one if the caller does not set it.
+func streamRequestId(in *RecommendRequest, request *io.RecRequest) string {
+	if in.GetRequestId() != "" {
+		return in.GetRequestId()
+	}
+
+	return utils.CreateRequestId(request)
+}
+
+func convertRecResponseToRecallStreamResponse(response_ map[string]interface{}) *RecallStreamResponse {
+	response := convertRecResponseToGrpcResponse(response_)
+
+	return &RecallStreamResponse{
+		Code:    response.GetCode(),
+		Message: response.GetMessage(),
+		Data:    response.GetData(),
+	}
+}
@@ -0,0 +1,119 @@
+package grpc_service
+
+import (
+	"context"
+	"infer-microservices/pkg/services/baseservice"
+	goio "io"
+	"net"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+func startGrpcService(t *testing.T) RecommenderInferServiceClient {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcService := &GrpcService{}
+	grpcService.SetBaseService(&baseservice.BaseService{})
+	grpcServer := grpc.NewServer()
+	RegisterRecommenderInferServiceServer(grpcServer, grpcService)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return NewRecommenderInferServiceClient(conn)
+}
+
+func TestRecommenderInferStream(t *testing.T) {
+	client := startGrpcService(t)
+	stream, err := client.RecommenderInferStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//the responses may be out of order, they are matched by RequestId.
+	expects := map[string]int32{
+		"no-userid":  http.StatusBadRequest,
+		"recallnum":  http.StatusBadRequest,
+		"no-dataid":  http.StatusNotFound,
+		"no-dataid2": http.StatusNotFound,
+	}
+	requests := []*RecommendRequest{
+		{RequestId: "no-userid", DataId: "inferid-001"},
+		{RequestId: "recallnum", DataId: "inferid-001", UserId: "u1", RecallNum: 2000},
+		{RequestId: "no-dataid", DataId: "no-such-dataid", UserId: "u1"},
+		{RequestId: "no-dataid2", DataId: "no-such-dataid", UserId: "u2"},
+	}
+	for _, request := range requests {
+		if err := stream.Send(request); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+
+	received := 0
+	for {
+		response, err := stream.Recv()
+		if err == goio.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Log(response.GetRequestId(), response.GetCode(), response.GetMessage())
+		if code, ok := expects[response.GetRequestId()]; !ok || code != response.GetCode() {
+			t.Errorf("request %s: code %d, expect %d", response.GetRequestId(), response.GetCode(), code)
+		}
+		received += 1
+	}
+	if received != len(requests) {
+		t.Errorf("%d responses of %d requests", received, len(requests))
+	}
+}
+
+func TestRecallStream(t *testing.T) {
+	client := startGrpcService(t)
+
+	cases := []struct {
+		request *RecommendRequest
+		code    int32
+	}{
+		{&RecommendRequest{DataId: "inferid-001"}, http.StatusBadRequest},
+		{&RecommendRequest{RequestId: "r1", DataId: "no-such-dataid", UserId: "u1"}, http.StatusNotFound},
+	}
+	for _, c := range cases {
+		stream, err := client.RecallStream(context.Background(), c.request)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		//the stream ends by one Final response.
+		responses := make([]*RecallStreamResponse, 0)
+		for {
+			response, err := stream.Recv()
+			if err == goio.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			responses = append(responses, response)
+		}
+		if len(responses) != 1 || !responses[0].GetFinal() || responses[0].GetCode() != c.code || responses[0].GetRequestId() == "" {
+			t.Errorf("%v: responses %v, expect one final response of code %d", c.request, responses, c.code)
+		}
+		if c.request.GetRequestId() != "" && responses[0].GetRequestId() != c.request.GetRequestId() {
+			t.Errorf("request id %s, expect %s", responses[0].GetRequestId(), c.request.GetRequestId())
+		}
+	}
+}
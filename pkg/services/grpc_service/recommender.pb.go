@@ -72,8 +72,9 @@ func (m *StringList) GetValue() []string {
 }
 
 type ItemInfo struct {
-	Itemid string  `protobuf:"bytes,1,opt,name=itemid,proto3" json:"itemid,omitempty"`
-	Score  float32 `protobuf:"fixed32,2,opt,name=score,proto3" json:"score,omitempty"`
+	Itemid   string  `protobuf:"bytes,1,opt,name=itemid,proto3" json:"itemid,omitempty"`
+	Score    float32 `protobuf:"fixed32,2,opt,name=score,proto3" json:"score,omitempty"`
+	Rawscore float32 `protobuf:"fixed32,3,opt,name=rawscore,proto3" json:"rawscore,omitempty"`
 }
 
 func (m *ItemInfo) Reset()         { *m = ItemInfo{} }
@@ -123,6 +124,13 @@ func (m *ItemInfo) GetScore() float32 {
 	return 0
 }
 
+func (m *ItemInfo) GetRawscore() float32 {
+	if m != nil {
+		return m.Rawscore
+	}
+	return 0
+}
+
 type ItemInfoList struct {
 	Iteminfo_ []*ItemInfo `protobuf:"bytes,1,rep,name=iteminfo_,json=iteminfo,proto3" json:"iteminfo_,omitempty"`
 }
@@ -167,21 +175,90 @@ func (m *ItemInfoList) GetIteminfo_() []*ItemInfo {
 	return nil
 }
 
+type RecommendAttributeFilter struct {
+	Attribute string   `protobuf:"bytes,1,opt,name=Attribute,proto3" json:"Attribute,omitempty"`
+	Op        string   `protobuf:"bytes,2,opt,name=Op,proto3" json:"Op,omitempty"`
+	Values    []string `protobuf:"bytes,3,rep,name=Values,proto3" json:"Values,omitempty"`
+}
+
+func (m *RecommendAttributeFilter) Reset()         { *m = RecommendAttributeFilter{} }
+func (m *RecommendAttributeFilter) String() string { return proto.CompactTextString(m) }
+func (*RecommendAttributeFilter) ProtoMessage()    {}
+func (*RecommendAttributeFilter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9c68bee5ca3d81c8, []int{3}
+}
+func (m *RecommendAttributeFilter) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RecommendAttributeFilter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RecommendAttributeFilter.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RecommendAttributeFilter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RecommendAttributeFilter.Merge(m, src)
+}
+func (m *RecommendAttributeFilter) XXX_Size() int {
+	return m.Size()
+}
+func (m *RecommendAttributeFilter) XXX_DiscardUnknown() {
+	xxx_messageInfo_RecommendAttributeFilter.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RecommendAttributeFilter proto.InternalMessageInfo
+
+func (m *RecommendAttributeFilter) GetAttribute() string {
+	if m != nil {
+		return m.Attribute
+	}
+	return ""
+}
+
+func (m *RecommendAttributeFilter) GetOp() string {
+	if m != nil {
+		return m.Op
+	}
+	return ""
+}
+
+func (m *RecommendAttributeFilter) GetValues() []string {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
 type RecommendRequest struct {
-	DataId    string      `protobuf:"bytes,1,opt,name=DataId,proto3" json:"DataId,omitempty"`
-	GroupId   string      `protobuf:"bytes,2,opt,name=GroupId,proto3" json:"GroupId,omitempty"`
-	Namespace string      `protobuf:"bytes,3,opt,name=Namespace,proto3" json:"Namespace,omitempty"`
-	ModelType string      `protobuf:"bytes,4,opt,name=ModelType,proto3" json:"ModelType,omitempty"`
-	UserId    string      `protobuf:"bytes,5,opt,name=UserId,proto3" json:"UserId,omitempty"`
-	RecallNum int32       `protobuf:"varint,6,opt,name=RecallNum,proto3" json:"RecallNum,omitempty"`
-	ItemList  *StringList `protobuf:"bytes,7,opt,name=ItemList,proto3" json:"ItemList,omitempty"`
+	DataId       string                      `protobuf:"bytes,1,opt,name=DataId,proto3" json:"DataId,omitempty"`
+	GroupId      string                      `protobuf:"bytes,2,opt,name=GroupId,proto3" json:"GroupId,omitempty"`
+	Namespace    string                      `protobuf:"bytes,3,opt,name=Namespace,proto3" json:"Namespace,omitempty"`
+	ModelType    string                      `protobuf:"bytes,4,opt,name=ModelType,proto3" json:"ModelType,omitempty"`
+	UserId       string                      `protobuf:"bytes,5,opt,name=UserId,proto3" json:"UserId,omitempty"`
+	RecallNum    int32                       `protobuf:"varint,6,opt,name=RecallNum,proto3" json:"RecallNum,omitempty"`
+	ItemList     *StringList                 `protobuf:"bytes,7,opt,name=ItemList,proto3" json:"ItemList,omitempty"`
+	Context      map[string]string           `protobuf:"bytes,8,rep,name=Context,proto3" json:"Context,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Debug        bool                        `protobuf:"varint,9,opt,name=Debug,proto3" json:"Debug,omitempty"`
+	IncludeItems *StringList                 `protobuf:"bytes,10,opt,name=IncludeItems,proto3" json:"IncludeItems,omitempty"`
+	ExcludeItems *StringList                 `protobuf:"bytes,11,opt,name=ExcludeItems,proto3" json:"ExcludeItems,omitempty"`
+	Filters      []*RecommendAttributeFilter `protobuf:"bytes,12,rep,name=Filters,proto3" json:"Filters,omitempty"`
+	ItemId       string                      `protobuf:"bytes,13,opt,name=ItemId,proto3" json:"ItemId,omitempty"`
+	TopN         int32                       `protobuf:"varint,14,opt,name=TopN,proto3" json:"TopN,omitempty"`
+	MinScore     float32                     `protobuf:"fixed32,15,opt,name=MinScore,proto3" json:"MinScore,omitempty"`
+	RequestId    string                      `protobuf:"bytes,16,opt,name=RequestId,proto3" json:"RequestId,omitempty"`
 }
 
 func (m *RecommendRequest) Reset()         { *m = RecommendRequest{} }
 func (m *RecommendRequest) String() string { return proto.CompactTextString(m) }
 func (*RecommendRequest) ProtoMessage()    {}
 func (*RecommendRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_9c68bee5ca3d81c8, []int{3}
+	return fileDescriptor_9c68bee5ca3d81c8, []int{4}
 }
 func (m *RecommendRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -259,17 +336,81 @@ func (m *RecommendRequest) GetItemList() *StringList {
 	return nil
 }
 
+func (m *RecommendRequest) GetContext() map[string]string {
+	if m != nil {
+		return m.Context
+	}
+	return nil
+}
+
+func (m *RecommendRequest) GetDebug() bool {
+	if m != nil {
+		return m.Debug
+	}
+	return false
+}
+
+func (m *RecommendRequest) GetIncludeItems() *StringList {
+	if m != nil {
+		return m.IncludeItems
+	}
+	return nil
+}
+
+func (m *RecommendRequest) GetExcludeItems() *StringList {
+	if m != nil {
+		return m.ExcludeItems
+	}
+	return nil
+}
+
+func (m *RecommendRequest) GetFilters() []*RecommendAttributeFilter {
+	if m != nil {
+		return m.Filters
+	}
+	return nil
+}
+
+func (m *RecommendRequest) GetItemId() string {
+	if m != nil {
+		return m.ItemId
+	}
+	return ""
+}
+
+func (m *RecommendRequest) GetTopN() int32 {
+	if m != nil {
+		return m.TopN
+	}
+	return 0
+}
+
+func (m *RecommendRequest) GetMinScore() float32 {
+	if m != nil {
+		return m.MinScore
+	}
+	return 0
+}
+
+func (m *RecommendRequest) GetRequestId() string {
+	if m != nil {
+		return m.RequestId
+	}
+	return ""
+}
+
 type RecommendResponse struct {
-	Code    int32         `protobuf:"varint,1,opt,name=Code,proto3" json:"Code,omitempty"`
-	Message string        `protobuf:"bytes,2,opt,name=Message,proto3" json:"Message,omitempty"`
-	Data    *ItemInfoList `protobuf:"bytes,3,opt,name=Data,proto3" json:"Data,omitempty"`
+	Code      int32         `protobuf:"varint,1,opt,name=Code,proto3" json:"Code,omitempty"`
+	Message   string        `protobuf:"bytes,2,opt,name=Message,proto3" json:"Message,omitempty"`
+	Data      *ItemInfoList `protobuf:"bytes,3,opt,name=Data,proto3" json:"Data,omitempty"`
+	RequestId string        `protobuf:"bytes,4,opt,name=RequestId,proto3" json:"RequestId,omitempty"`
 }
 
 func (m *RecommendResponse) Reset()         { *m = RecommendResponse{} }
 func (m *RecommendResponse) String() string { return proto.CompactTextString(m) }
 func (*RecommendResponse) ProtoMessage()    {}
 func (*RecommendResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_9c68bee5ca3d81c8, []int{4}
+	return fileDescriptor_9c68bee5ca3d81c8, []int{5}
 }
 func (m *RecommendResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -319,43 +460,250 @@ func (m *RecommendResponse) GetData() *ItemInfoList {
 	return nil
 }
 
+func (m *RecommendResponse) GetRequestId() string {
+	if m != nil {
+		return m.RequestId
+	}
+	return ""
+}
+
+type RecallStreamResponse struct {
+	Code      int32         `protobuf:"varint,1,opt,name=Code,proto3" json:"Code,omitempty"`
+	Message   string        `protobuf:"bytes,2,opt,name=Message,proto3" json:"Message,omitempty"`
+	RequestId string        `protobuf:"bytes,3,opt,name=RequestId,proto3" json:"RequestId,omitempty"`
+	IndexName string        `protobuf:"bytes,4,opt,name=IndexName,proto3" json:"IndexName,omitempty"`
+	Data      *ItemInfoList `protobuf:"bytes,5,opt,name=Data,proto3" json:"Data,omitempty"`
+	Final     bool          `protobuf:"varint,6,opt,name=Final,proto3" json:"Final,omitempty"`
+}
+
+func (m *RecallStreamResponse) Reset()         { *m = RecallStreamResponse{} }
+func (m *RecallStreamResponse) String() string { return proto.CompactTextString(m) }
+func (*RecallStreamResponse) ProtoMessage()    {}
+func (*RecallStreamResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9c68bee5ca3d81c8, []int{6}
+}
+func (m *RecallStreamResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RecallStreamResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RecallStreamResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RecallStreamResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RecallStreamResponse.Merge(m, src)
+}
+func (m *RecallStreamResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *RecallStreamResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RecallStreamResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RecallStreamResponse proto.InternalMessageInfo
+
+func (m *RecallStreamResponse) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RecallStreamResponse) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *RecallStreamResponse) GetRequestId() string {
+	if m != nil {
+		return m.RequestId
+	}
+	return ""
+}
+
+func (m *RecallStreamResponse) GetIndexName() string {
+	if m != nil {
+		return m.IndexName
+	}
+	return ""
+}
+
+func (m *RecallStreamResponse) GetData() *ItemInfoList {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *RecallStreamResponse) GetFinal() bool {
+	if m != nil {
+		return m.Final
+	}
+	return false
+}
+
+type BatchRecommendRequest struct {
+	Requests []*RecommendRequest `protobuf:"bytes,1,rep,name=Requests,proto3" json:"Requests,omitempty"`
+}
+
+func (m *BatchRecommendRequest) Reset()         { *m = BatchRecommendRequest{} }
+func (m *BatchRecommendRequest) String() string { return proto.CompactTextString(m) }
+func (*BatchRecommendRequest) ProtoMessage()    {}
+func (*BatchRecommendRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9c68bee5ca3d81c8, []int{7}
+}
+func (m *BatchRecommendRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *BatchRecommendRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_BatchRecommendRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *BatchRecommendRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchRecommendRequest.Merge(m, src)
+}
+func (m *BatchRecommendRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *BatchRecommendRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchRecommendRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchRecommendRequest proto.InternalMessageInfo
+
+func (m *BatchRecommendRequest) GetRequests() []*RecommendRequest {
+	if m != nil {
+		return m.Requests
+	}
+	return nil
+}
+
+type BatchRecommendResponse struct {
+	Responses []*RecommendResponse `protobuf:"bytes,1,rep,name=Responses,proto3" json:"Responses,omitempty"`
+}
+
+func (m *BatchRecommendResponse) Reset()         { *m = BatchRecommendResponse{} }
+func (m *BatchRecommendResponse) String() string { return proto.CompactTextString(m) }
+func (*BatchRecommendResponse) ProtoMessage()    {}
+func (*BatchRecommendResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9c68bee5ca3d81c8, []int{8}
+}
+func (m *BatchRecommendResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *BatchRecommendResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_BatchRecommendResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *BatchRecommendResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchRecommendResponse.Merge(m, src)
+}
+func (m *BatchRecommendResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *BatchRecommendResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchRecommendResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchRecommendResponse proto.InternalMessageInfo
+
+func (m *BatchRecommendResponse) GetResponses() []*RecommendResponse {
+	if m != nil {
+		return m.Responses
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*StringList)(nil), "StringList")
 	proto.RegisterType((*ItemInfo)(nil), "ItemInfo")
 	proto.RegisterType((*ItemInfoList)(nil), "ItemInfoList")
+	proto.RegisterType((*RecommendAttributeFilter)(nil), "RecommendAttributeFilter")
 	proto.RegisterType((*RecommendRequest)(nil), "RecommendRequest")
+	proto.RegisterMapType((map[string]string)(nil), "RecommendRequest.ContextEntry")
 	proto.RegisterType((*RecommendResponse)(nil), "RecommendResponse")
+	proto.RegisterType((*RecallStreamResponse)(nil), "RecallStreamResponse")
+	proto.RegisterType((*BatchRecommendRequest)(nil), "BatchRecommendRequest")
+	proto.RegisterType((*BatchRecommendResponse)(nil), "BatchRecommendResponse")
 }
 
 func init() { proto.RegisterFile("recommender.proto", fileDescriptor_9c68bee5ca3d81c8) }
 
 var fileDescriptor_9c68bee5ca3d81c8 = []byte{
-	// 400 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x52, 0xc1, 0xaa, 0xd3, 0x40,
-	0x14, 0xed, 0xb4, 0x4d, 0xdb, 0x4c, 0x14, 0xed, 0x20, 0x3a, 0x88, 0x84, 0x98, 0x85, 0xc6, 0x4d,
-	0x84, 0x08, 0xa2, 0xb8, 0x53, 0x41, 0x02, 0xb6, 0x8b, 0x69, 0xdd, 0xb8, 0x29, 0x31, 0xb9, 0x2d,
-	0x81, 0x24, 0x13, 0x67, 0x92, 0x82, 0x7f, 0xe1, 0x67, 0xb9, 0xec, 0xd2, 0x9d, 0xd2, 0xfe, 0xc8,
-	0x63, 0x66, 0x9a, 0xa6, 0xbc, 0xb7, 0x9b, 0x73, 0xcf, 0xbd, 0x73, 0xee, 0x39, 0x33, 0x78, 0x2e,
-	0x20, 0xe5, 0x65, 0x09, 0x55, 0x06, 0x22, 0xac, 0x05, 0x6f, 0xb8, 0xef, 0x63, 0xbc, 0x6a, 0x44,
-	0x5e, 0xed, 0xbe, 0xe6, 0xb2, 0x21, 0x8f, 0xb0, 0xb5, 0x4f, 0x8a, 0x16, 0x28, 0xf2, 0x46, 0x81,
-	0xcd, 0x0c, 0xf0, 0xdf, 0xe1, 0x59, 0xdc, 0x40, 0x19, 0x57, 0x5b, 0x4e, 0x1e, 0xe3, 0x49, 0xde,
-	0x40, 0x99, 0x67, 0x14, 0x79, 0x28, 0xb0, 0xd9, 0x19, 0xa9, 0x49, 0x99, 0x72, 0x01, 0x74, 0xe8,
-	0xa1, 0x60, 0xc8, 0x0c, 0xf0, 0xdf, 0xe2, 0x7b, 0xdd, 0xa4, 0xbe, 0xff, 0x05, 0xb6, 0x75, 0x7f,
-	0xb5, 0xe5, 0x1b, 0xad, 0xe1, 0x44, 0x76, 0xd8, 0x75, 0xb0, 0x59, 0xc7, 0xf9, 0xff, 0x10, 0x7e,
-	0xc8, 0xba, 0x5d, 0x19, 0xfc, 0x6c, 0x41, 0x36, 0x4a, 0xfa, 0x73, 0xd2, 0x24, 0xf1, 0x45, 0xda,
-	0x20, 0x42, 0xf1, 0xf4, 0x8b, 0xe0, 0x6d, 0x1d, 0x67, 0x5a, 0xdc, 0x66, 0x1d, 0x24, 0xcf, 0xb0,
-	0xbd, 0x4c, 0x4a, 0x90, 0x75, 0x92, 0x02, 0x1d, 0x69, 0xae, 0x2f, 0x28, 0x76, 0xc1, 0x33, 0x28,
-	0xd6, 0xbf, 0x6a, 0xa0, 0x63, 0xc3, 0x5e, 0x0a, 0x4a, 0xed, 0x9b, 0x04, 0x11, 0x67, 0xd4, 0x32,
-	0x6a, 0x06, 0xa9, 0x29, 0x06, 0x69, 0x52, 0x14, 0xcb, 0xb6, 0xa4, 0x13, 0x0f, 0x05, 0x16, 0xeb,
-	0x0b, 0xe4, 0xa5, 0x89, 0x4a, 0x99, 0xa5, 0x53, 0x0f, 0x05, 0x4e, 0xe4, 0x84, 0x7d, 0xbe, 0xec,
-	0x42, 0xfa, 0x19, 0x9e, 0x5f, 0x19, 0x94, 0x35, 0xaf, 0x24, 0x10, 0x82, 0xc7, 0x9f, 0x78, 0x06,
-	0xda, 0x9f, 0xc5, 0xf4, 0x59, 0xb9, 0x5b, 0x80, 0x94, 0xc9, 0x0e, 0x3a, 0x77, 0x67, 0x48, 0x9e,
-	0xe3, 0xb1, 0x4a, 0x40, 0x1b, 0x73, 0xa2, 0xfb, 0xe1, 0x75, 0xd2, 0x4c, 0x53, 0xd1, 0x1a, 0x3f,
-	0x61, 0xfd, 0x93, 0xc7, 0xd5, 0x16, 0xc4, 0x0a, 0xc4, 0x3e, 0x4f, 0x81, 0xbc, 0xbf, 0x4a, 0xf8,
-	0x4c, 0x91, 0x79, 0x78, 0x3b, 0xf4, 0xa7, 0x24, 0xbc, 0xb3, 0xe6, 0xc7, 0x57, 0x7f, 0x8e, 0x2e,
-	0x3a, 0x1c, 0x5d, 0xf4, 0xff, 0xe8, 0xa2, 0xdf, 0x27, 0x77, 0x70, 0x38, 0xb9, 0x83, 0xbf, 0x27,
-	0x77, 0xf0, 0xfd, 0x41, 0xf8, 0xfa, 0xc3, 0x4e, 0xd4, 0xe9, 0x46, 0x1a, 0x95, 0x1f, 0x13, 0xfd,
-	0xcb, 0xde, 0xdc, 0x04, 0x00, 0x00, 0xff, 0xff, 0x72, 0xf0, 0x68, 0x68, 0x7a, 0x02, 0x00, 0x00,
+	// 767 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x55, 0xcb, 0x6e, 0xeb, 0x44,
+	0x18, 0xee, 0xe4, 0xd2, 0xc4, 0x7f, 0xd2, 0x36, 0x19, 0xb5, 0xe9, 0x10, 0xa1, 0x28, 0x78, 0x01,
+	0x61, 0x81, 0x5b, 0xa5, 0x12, 0x2a, 0x61, 0x45, 0x6f, 0x60, 0x44, 0x5b, 0x69, 0x52, 0x58, 0xb0,
+	0x29, 0xae, 0x3d, 0x0d, 0x16, 0xbe, 0x31, 0x9e, 0x94, 0x76, 0xcb, 0x13, 0xf0, 0x3a, 0xbc, 0x01,
+	0xcb, 0x2e, 0x91, 0xd8, 0xa0, 0x76, 0x73, 0x1e, 0xe3, 0x68, 0x66, 0x6c, 0x27, 0x4d, 0x52, 0xe9,
+	0x9c, 0xb3, 0x9b, 0xef, 0xbf, 0xff, 0xdf, 0x7c, 0x63, 0x43, 0x9b, 0x33, 0x37, 0x0e, 0x43, 0x16,
+	0x79, 0x8c, 0x5b, 0x09, 0x8f, 0x45, 0x6c, 0x9a, 0x00, 0x63, 0xc1, 0xfd, 0x68, 0xf2, 0x83, 0x9f,
+	0x0a, 0xbc, 0x0d, 0xd5, 0x3b, 0x27, 0x98, 0x32, 0x82, 0xfa, 0xe5, 0x81, 0x41, 0x35, 0x30, 0xaf,
+	0xa0, 0x6e, 0x0b, 0x16, 0xda, 0xd1, 0x6d, 0x8c, 0x3b, 0xb0, 0xee, 0x0b, 0x16, 0xfa, 0x1e, 0x41,
+	0x7d, 0x34, 0x30, 0x68, 0x86, 0x64, 0x66, 0xea, 0xc6, 0x9c, 0x91, 0x52, 0x1f, 0x0d, 0x4a, 0x54,
+	0x03, 0xdc, 0x85, 0x3a, 0x77, 0xfe, 0xd0, 0x8e, 0xb2, 0x72, 0x14, 0xd8, 0xfc, 0x12, 0x9a, 0x79,
+	0x55, 0xd5, 0xfb, 0x53, 0x30, 0x54, 0xad, 0xe8, 0x36, 0xbe, 0x56, 0xfd, 0x1b, 0x43, 0xc3, 0xca,
+	0x23, 0x68, 0x3d, 0xf7, 0x99, 0xbf, 0x00, 0xa1, 0xf9, 0x1a, 0xdf, 0x08, 0xc1, 0xfd, 0x9b, 0xa9,
+	0x60, 0x67, 0x7e, 0x20, 0x18, 0xc7, 0x1f, 0x83, 0x51, 0x98, 0xb2, 0x01, 0x67, 0x06, 0xbc, 0x09,
+	0xa5, 0xcb, 0x44, 0x0d, 0x68, 0xd0, 0xd2, 0x65, 0x22, 0x77, 0xf9, 0x49, 0x2e, 0x98, 0x92, 0xb2,
+	0x5a, 0x37, 0x43, 0xe6, 0x9b, 0x0a, 0xb4, 0x8a, 0x16, 0x94, 0xfd, 0x3e, 0x65, 0xa9, 0x90, 0xc1,
+	0x27, 0x8e, 0x70, 0xec, 0x62, 0x71, 0x8d, 0x30, 0x81, 0xda, 0xb7, 0x3c, 0x9e, 0x26, 0xb6, 0x97,
+	0x55, 0xce, 0xa1, 0x1c, 0xe6, 0xc2, 0x09, 0x59, 0x9a, 0x38, 0xae, 0xde, 0xde, 0xa0, 0x33, 0x83,
+	0xf4, 0x9e, 0xc7, 0x1e, 0x0b, 0xae, 0x1e, 0x12, 0x46, 0x2a, 0xda, 0x5b, 0x18, 0x64, 0xb7, 0x1f,
+	0x53, 0xc6, 0x6d, 0x8f, 0x54, 0x75, 0x37, 0x8d, 0x64, 0x16, 0x65, 0xae, 0x13, 0x04, 0x17, 0xd3,
+	0x90, 0xac, 0xf7, 0xd1, 0xa0, 0x4a, 0x67, 0x06, 0xfc, 0x99, 0xbe, 0x28, 0x49, 0x27, 0xa9, 0xf5,
+	0xd1, 0xa0, 0x31, 0x6c, 0x58, 0xb3, 0xdb, 0xa5, 0x85, 0x13, 0x1f, 0x42, 0xed, 0x38, 0x8e, 0x04,
+	0xbb, 0x17, 0xa4, 0xae, 0x98, 0xee, 0x59, 0x8b, 0x0b, 0x5b, 0x59, 0xc0, 0x69, 0x24, 0xf8, 0x03,
+	0xcd, 0xc3, 0xe5, 0x3d, 0x9f, 0xb0, 0x9b, 0xe9, 0x84, 0x18, 0x7d, 0x34, 0xa8, 0x53, 0x0d, 0xf0,
+	0x1e, 0x34, 0xed, 0xc8, 0x0d, 0xa6, 0x1e, 0x93, 0x2d, 0x52, 0x02, 0xcb, 0xcd, 0x5f, 0x04, 0xc8,
+	0x84, 0xd3, 0xfb, 0xb9, 0x84, 0xc6, 0x8a, 0x84, 0xf9, 0x00, 0x7c, 0x00, 0x35, 0x7d, 0xc7, 0x29,
+	0x69, 0xaa, 0x89, 0x3f, 0xb2, 0x5e, 0x53, 0x01, 0xcd, 0x23, 0x25, 0x8b, 0x4a, 0x40, 0x1e, 0xd9,
+	0xd0, 0x2c, 0x6a, 0x84, 0x31, 0x54, 0xae, 0xe2, 0xe4, 0x82, 0x6c, 0x2a, 0x02, 0xd5, 0x59, 0x4a,
+	0xf5, 0xdc, 0x8f, 0xc6, 0x4a, 0xaa, 0x5b, 0x5a, 0xaa, 0x39, 0xd6, 0xac, 0x2b, 0x56, 0x6c, 0x8f,
+	0xb4, 0xf4, 0x5d, 0x15, 0x86, 0xee, 0x08, 0x9a, 0xf3, 0x5c, 0xe1, 0x16, 0x94, 0x7f, 0x63, 0x0f,
+	0x99, 0x4c, 0xe4, 0x71, 0xf6, 0xac, 0xb4, 0x42, 0x34, 0x18, 0x95, 0x0e, 0x91, 0xf9, 0x27, 0x82,
+	0xf6, 0x1c, 0xf3, 0x69, 0x12, 0x47, 0x29, 0x93, 0xf3, 0x1d, 0xc7, 0x9e, 0x56, 0x70, 0x95, 0xaa,
+	0xb3, 0xd4, 0xd9, 0x39, 0x4b, 0x53, 0x67, 0x92, 0x57, 0xc9, 0x21, 0xfe, 0x04, 0x2a, 0x52, 0x8b,
+	0x4a, 0x62, 0x8d, 0xe1, 0x86, 0x35, 0xff, 0xaa, 0xa8, 0x72, 0xbd, 0x5c, 0xa0, 0xb2, 0xb0, 0x80,
+	0xf9, 0x37, 0x82, 0x6d, 0x2d, 0xa2, 0xb1, 0xe0, 0xcc, 0x09, 0x3f, 0x70, 0x8e, 0x17, 0x4d, 0xca,
+	0x0b, 0x4d, 0xa4, 0xd7, 0x8e, 0x3c, 0x76, 0x2f, 0x5f, 0x40, 0x3e, 0x42, 0x61, 0x28, 0x76, 0xa8,
+	0xbe, 0xbe, 0xc3, 0x36, 0x54, 0xcf, 0xfc, 0xc8, 0x09, 0x94, 0xec, 0xeb, 0x54, 0x03, 0xf3, 0x0c,
+	0x76, 0x8e, 0x1c, 0xe1, 0xfe, 0xba, 0xf4, 0x5e, 0xbf, 0x80, 0x7a, 0x76, 0x4c, 0xb3, 0xaf, 0x49,
+	0x7b, 0x49, 0xe3, 0xb4, 0x08, 0x31, 0xbf, 0x87, 0xce, 0x62, 0x9d, 0x8c, 0x84, 0x7d, 0x30, 0xf2,
+	0x73, 0x5e, 0x09, 0x5b, 0x4b, 0x61, 0x74, 0x16, 0x34, 0xfc, 0xaf, 0x04, 0xbb, 0x74, 0xf6, 0xa5,
+	0xb5, 0xa3, 0x5b, 0xc6, 0xc7, 0x8c, 0xdf, 0xf9, 0x2e, 0xc3, 0x5f, 0x41, 0x6b, 0xd1, 0x85, 0x97,
+	0x07, 0xeb, 0xae, 0xe8, 0x80, 0x47, 0xd0, 0x1e, 0xfb, 0xa1, 0x1f, 0x38, 0x5c, 0x3d, 0x89, 0xf7,
+	0xca, 0xfd, 0x6e, 0x91, 0xa6, 0xbc, 0x77, 0xc7, 0x5a, 0x49, 0x5f, 0x77, 0xd7, 0x7a, 0x85, 0x8e,
+	0x63, 0xe8, 0x2c, 0xed, 0xa6, 0x54, 0xf3, 0x8e, 0xa3, 0x0c, 0xd0, 0x3e, 0xc2, 0x23, 0x68, 0xce,
+	0x0b, 0x6e, 0x55, 0xea, 0x8e, 0xb5, 0x4a, 0x92, 0xfb, 0xe8, 0xe8, 0xf3, 0x7f, 0x9e, 0x7a, 0xe8,
+	0xf1, 0xa9, 0x87, 0xfe, 0x7f, 0xea, 0xa1, 0xbf, 0x9e, 0x7b, 0x6b, 0x8f, 0xcf, 0xbd, 0xb5, 0x7f,
+	0x9f, 0x7b, 0x6b, 0x3f, 0x6f, 0x59, 0x7b, 0x5f, 0x4f, 0x78, 0xe2, 0x5e, 0xa7, 0x9a, 0xec, 0x9b,
+	0x75, 0xf5, 0x8f, 0x3b, 0x78, 0x3b, 0x00, 0x44, 0x4b, 0x06, 0x8b, 0xf8, 0x06, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -371,6 +719,10 @@ const _ = grpc.SupportPackageIsVersion4
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type RecommenderInferServiceClient interface {
 	RecommenderInfer(ctx context.Context, in *RecommendRequest, opts ...grpc.CallOption) (*RecommendResponse, error)
+	SimilarItemsInfer(ctx context.Context, in *RecommendRequest, opts ...grpc.CallOption) (*RecommendResponse, error)
+	BatchRecommenderInfer(ctx context.Context, in *BatchRecommendRequest, opts ...grpc.CallOption) (*BatchRecommendResponse, error)
+	RecommenderInferStream(ctx context.Context, opts ...grpc.CallOption) (RecommenderInferService_RecommenderInferStreamClient, error)
+	RecallStream(ctx context.Context, in *RecommendRequest, opts ...grpc.CallOption) (RecommenderInferService_RecallStreamClient, error)
 }
 
 type recommenderInferServiceClient struct {
@@ -390,9 +742,94 @@ func (c *recommenderInferServiceClient) RecommenderInfer(ctx context.Context, in
 	return out, nil
 }
 
+func (c *recommenderInferServiceClient) SimilarItemsInfer(ctx context.Context, in *RecommendRequest, opts ...grpc.CallOption) (*RecommendResponse, error) {
+	out := new(RecommendResponse)
+	err := c.cc.Invoke(ctx, "/RecommenderInferService/SimilarItemsInfer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *recommenderInferServiceClient) BatchRecommenderInfer(ctx context.Context, in *BatchRecommendRequest, opts ...grpc.CallOption) (*BatchRecommendResponse, error) {
+	out := new(BatchRecommendResponse)
+	err := c.cc.Invoke(ctx, "/RecommenderInferService/BatchRecommenderInfer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *recommenderInferServiceClient) RecommenderInferStream(ctx context.Context, opts ...grpc.CallOption) (RecommenderInferService_RecommenderInferStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_RecommenderInferService_serviceDesc.Streams[0], "/RecommenderInferService/RecommenderInferStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &recommenderInferServiceRecommenderInferStreamClient{stream}
+	return x, nil
+}
+
+type RecommenderInferService_RecommenderInferStreamClient interface {
+	Send(*RecommendRequest) error
+	Recv() (*RecommendResponse, error)
+	grpc.ClientStream
+}
+
+type recommenderInferServiceRecommenderInferStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *recommenderInferServiceRecommenderInferStreamClient) Send(m *RecommendRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *recommenderInferServiceRecommenderInferStreamClient) Recv() (*RecommendResponse, error) {
+	m := new(RecommendResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *recommenderInferServiceClient) RecallStream(ctx context.Context, in *RecommendRequest, opts ...grpc.CallOption) (RecommenderInferService_RecallStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_RecommenderInferService_serviceDesc.Streams[1], "/RecommenderInferService/RecallStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &recommenderInferServiceRecallStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RecommenderInferService_RecallStreamClient interface {
+	Recv() (*RecallStreamResponse, error)
+	grpc.ClientStream
+}
+
+type recommenderInferServiceRecallStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *recommenderInferServiceRecallStreamClient) Recv() (*RecallStreamResponse, error) {
+	m := new(RecallStreamResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // RecommenderInferServiceServer is the server API for RecommenderInferService service.
 type RecommenderInferServiceServer interface {
 	RecommenderInfer(context.Context, *RecommendRequest) (*RecommendResponse, error)
+	SimilarItemsInfer(context.Context, *RecommendRequest) (*RecommendResponse, error)
+	BatchRecommenderInfer(context.Context, *BatchRecommendRequest) (*BatchRecommendResponse, error)
+	RecommenderInferStream(RecommenderInferService_RecommenderInferStreamServer) error
+	RecallStream(*RecommendRequest, RecommenderInferService_RecallStreamServer) error
 }
 
 // UnimplementedRecommenderInferServiceServer can be embedded to have forward compatible implementations.
@@ -402,6 +839,18 @@ type UnimplementedRecommenderInferServiceServer struct {
 func (*UnimplementedRecommenderInferServiceServer) RecommenderInfer(ctx context.Context, req *RecommendRequest) (*RecommendResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RecommenderInfer not implemented")
 }
+func (*UnimplementedRecommenderInferServiceServer) SimilarItemsInfer(ctx context.Context, req *RecommendRequest) (*RecommendResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SimilarItemsInfer not implemented")
+}
+func (*UnimplementedRecommenderInferServiceServer) BatchRecommenderInfer(ctx context.Context, req *BatchRecommendRequest) (*BatchRecommendResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchRecommenderInfer not implemented")
+}
+func (*UnimplementedRecommenderInferServiceServer) RecommenderInferStream(srv RecommenderInferService_RecommenderInferStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method RecommenderInferStream not implemented")
+}
+func (*UnimplementedRecommenderInferServiceServer) RecallStream(req *RecommendRequest, srv RecommenderInferService_RecallStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method RecallStream not implemented")
+}
 
 func RegisterRecommenderInferServiceServer(s *grpc.Server, srv RecommenderInferServiceServer) {
 	s.RegisterService(&_RecommenderInferService_serviceDesc, srv)
@@ -425,16 +874,119 @@ func _RecommenderInferService_RecommenderInfer_Handler(srv interface{}, ctx cont
 	return interceptor(ctx, in, info, handler)
 }
 
-var _RecommenderInferService_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "RecommenderInferService",
-	HandlerType: (*RecommenderInferServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "RecommenderInfer",
-			Handler:    _RecommenderInferService_RecommenderInfer_Handler,
-		},
+func _RecommenderInferService_SimilarItemsInfer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecommendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RecommenderInferServiceServer).SimilarItemsInfer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/RecommenderInferService/SimilarItemsInfer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RecommenderInferServiceServer).SimilarItemsInfer(ctx, req.(*RecommendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RecommenderInferService_BatchRecommenderInfer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchRecommendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RecommenderInferServiceServer).BatchRecommenderInfer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/RecommenderInferService/BatchRecommenderInfer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RecommenderInferServiceServer).BatchRecommenderInfer(ctx, req.(*BatchRecommendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RecommenderInferService_RecommenderInferStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RecommenderInferServiceServer).RecommenderInferStream(&recommenderInferServiceRecommenderInferStreamServer{stream})
+}
+
+type RecommenderInferService_RecommenderInferStreamServer interface {
+	Send(*RecommendResponse) error
+	Recv() (*RecommendRequest, error)
+	grpc.ServerStream
+}
+
+type recommenderInferServiceRecommenderInferStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *recommenderInferServiceRecommenderInferStreamServer) Send(m *RecommendResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *recommenderInferServiceRecommenderInferStreamServer) Recv() (*RecommendRequest, error) {
+	m := new(RecommendRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _RecommenderInferService_RecallStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RecommendRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RecommenderInferServiceServer).RecallStream(m, &recommenderInferServiceRecallStreamServer{stream})
+}
+
+type RecommenderInferService_RecallStreamServer interface {
+	Send(*RecallStreamResponse) error
+	grpc.ServerStream
+}
+
+type recommenderInferServiceRecallStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *recommenderInferServiceRecallStreamServer) Send(m *RecallStreamResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _RecommenderInferService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "RecommenderInferService",
+	HandlerType: (*RecommenderInferServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RecommenderInfer",
+			Handler:    _RecommenderInferService_RecommenderInfer_Handler,
+		},
+		{
+			MethodName: "SimilarItemsInfer",
+			Handler:    _RecommenderInferService_SimilarItemsInfer_Handler,
+		},
+		{
+			MethodName: "BatchRecommenderInfer",
+			Handler:    _RecommenderInferService_BatchRecommenderInfer_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "RecommenderInferStream",
+			Handler:       _RecommenderInferService_RecommenderInferStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "RecallStream",
+			Handler:       _RecommenderInferService_RecallStream_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "recommender.proto",
 }
 
@@ -490,6 +1042,12 @@ func (m *ItemInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Rawscore != 0 {
+		i -= 4
+		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.Rawscore))))
+		i--
+		dAtA[i] = 0x1d
+	}
 	if m.Score != 0 {
 		i -= 4
 		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.Score))))
@@ -543,6 +1101,52 @@ func (m *ItemInfoList) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *RecommendAttributeFilter) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RecommendAttributeFilter) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RecommendAttributeFilter) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Values) > 0 {
+		for iNdEx := len(m.Values) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Values[iNdEx])
+			copy(dAtA[i:], m.Values[iNdEx])
+			i = encodeVarintRecommender(dAtA, i, uint64(len(m.Values[iNdEx])))
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if len(m.Op) > 0 {
+		i -= len(m.Op)
+		copy(dAtA[i:], m.Op)
+		i = encodeVarintRecommender(dAtA, i, uint64(len(m.Op)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Attribute) > 0 {
+		i -= len(m.Attribute)
+		copy(dAtA[i:], m.Attribute)
+		i = encodeVarintRecommender(dAtA, i, uint64(len(m.Attribute)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *RecommendRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -563,6 +1167,100 @@ func (m *RecommendRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.RequestId) > 0 {
+		i -= len(m.RequestId)
+		copy(dAtA[i:], m.RequestId)
+		i = encodeVarintRecommender(dAtA, i, uint64(len(m.RequestId)))
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x82
+	}
+	if m.MinScore != 0 {
+		i -= 4
+		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.MinScore))))
+		i--
+		dAtA[i] = 0x7d
+	}
+	if m.TopN != 0 {
+		i = encodeVarintRecommender(dAtA, i, uint64(m.TopN))
+		i--
+		dAtA[i] = 0x70
+	}
+	if len(m.ItemId) > 0 {
+		i -= len(m.ItemId)
+		copy(dAtA[i:], m.ItemId)
+		i = encodeVarintRecommender(dAtA, i, uint64(len(m.ItemId)))
+		i--
+		dAtA[i] = 0x6a
+	}
+	if len(m.Filters) > 0 {
+		for iNdEx := len(m.Filters) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Filters[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintRecommender(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x62
+		}
+	}
+	if m.ExcludeItems != nil {
+		{
+			size, err := m.ExcludeItems.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRecommender(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x5a
+	}
+	if m.IncludeItems != nil {
+		{
+			size, err := m.IncludeItems.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRecommender(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x52
+	}
+	if m.Debug {
+		i--
+		if m.Debug {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x48
+	}
+	if len(m.Context) > 0 {
+		for k := range m.Context {
+			v := m.Context[k]
+			baseI := i
+			i -= len(v)
+			copy(dAtA[i:], v)
+			i = encodeVarintRecommender(dAtA, i, uint64(len(v)))
+			i--
+			dAtA[i] = 0x12
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintRecommender(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintRecommender(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x42
+		}
+	}
 	if m.ItemList != nil {
 		{
 			size, err := m.ItemList.MarshalToSizedBuffer(dAtA[:i])
@@ -638,6 +1336,13 @@ func (m *RecommendResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.RequestId) > 0 {
+		i -= len(m.RequestId)
+		copy(dAtA[i:], m.RequestId)
+		i = encodeVarintRecommender(dAtA, i, uint64(len(m.RequestId)))
+		i--
+		dAtA[i] = 0x22
+	}
 	if m.Data != nil {
 		{
 			size, err := m.Data.MarshalToSizedBuffer(dAtA[:i])
@@ -665,65 +1370,236 @@ func (m *RecommendResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func encodeVarintRecommender(dAtA []byte, offset int, v uint64) int {
-	offset -= sovRecommender(v)
-	base := offset
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+func (m *RecallStreamResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
-	dAtA[offset] = uint8(v)
-	return base
+	return dAtA[:n], nil
 }
-func (m *StringList) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+
+func (m *RecallStreamResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RecallStreamResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
 	var l int
 	_ = l
-	if len(m.Value) > 0 {
-		for _, s := range m.Value {
-			l = len(s)
-			n += 1 + l + sovRecommender(uint64(l))
+	if m.Final {
+		i--
+		if m.Final {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
 		}
+		i--
+		dAtA[i] = 0x30
 	}
-	return n
-}
-
-func (m *ItemInfo) Size() (n int) {
-	if m == nil {
-		return 0
+	if m.Data != nil {
+		{
+			size, err := m.Data.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRecommender(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x2a
 	}
-	var l int
-	_ = l
-	l = len(m.Itemid)
-	if l > 0 {
-		n += 1 + l + sovRecommender(uint64(l))
+	if len(m.IndexName) > 0 {
+		i -= len(m.IndexName)
+		copy(dAtA[i:], m.IndexName)
+		i = encodeVarintRecommender(dAtA, i, uint64(len(m.IndexName)))
+		i--
+		dAtA[i] = 0x22
 	}
-	if m.Score != 0 {
-		n += 5
+	if len(m.RequestId) > 0 {
+		i -= len(m.RequestId)
+		copy(dAtA[i:], m.RequestId)
+		i = encodeVarintRecommender(dAtA, i, uint64(len(m.RequestId)))
+		i--
+		dAtA[i] = 0x1a
 	}
-	return n
+	if len(m.Message) > 0 {
+		i -= len(m.Message)
+		copy(dAtA[i:], m.Message)
+		i = encodeVarintRecommender(dAtA, i, uint64(len(m.Message)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.Code != 0 {
+		i = encodeVarintRecommender(dAtA, i, uint64(m.Code))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
 }
 
-func (m *ItemInfoList) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *BatchRecommendRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *BatchRecommendRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *BatchRecommendRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
 	var l int
 	_ = l
-	if len(m.Iteminfo_) > 0 {
-		for _, e := range m.Iteminfo_ {
-			l = e.Size()
-			n += 1 + l + sovRecommender(uint64(l))
+	if len(m.Requests) > 0 {
+		for iNdEx := len(m.Requests) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Requests[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintRecommender(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
 		}
 	}
-	return n
+	return len(dAtA) - i, nil
 }
 
-func (m *RecommendRequest) Size() (n int) {
-	if m == nil {
+func (m *BatchRecommendResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *BatchRecommendResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *BatchRecommendResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Responses) > 0 {
+		for iNdEx := len(m.Responses) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Responses[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintRecommender(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintRecommender(dAtA []byte, offset int, v uint64) int {
+	offset -= sovRecommender(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *StringList) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Value) > 0 {
+		for _, s := range m.Value {
+			l = len(s)
+			n += 1 + l + sovRecommender(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *ItemInfo) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Itemid)
+	if l > 0 {
+		n += 1 + l + sovRecommender(uint64(l))
+	}
+	if m.Score != 0 {
+		n += 5
+	}
+	if m.Rawscore != 0 {
+		n += 5
+	}
+	return n
+}
+
+func (m *ItemInfoList) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Iteminfo_) > 0 {
+		for _, e := range m.Iteminfo_ {
+			l = e.Size()
+			n += 1 + l + sovRecommender(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *RecommendAttributeFilter) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Attribute)
+	if l > 0 {
+		n += 1 + l + sovRecommender(uint64(l))
+	}
+	l = len(m.Op)
+	if l > 0 {
+		n += 1 + l + sovRecommender(uint64(l))
+	}
+	if len(m.Values) > 0 {
+		for _, s := range m.Values {
+			l = len(s)
+			n += 1 + l + sovRecommender(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *RecommendRequest) Size() (n int) {
+	if m == nil {
 		return 0
 	}
 	var l int
@@ -755,6 +1631,45 @@ func (m *RecommendRequest) Size() (n int) {
 		l = m.ItemList.Size()
 		n += 1 + l + sovRecommender(uint64(l))
 	}
+	if len(m.Context) > 0 {
+		for k, v := range m.Context {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovRecommender(uint64(len(k))) + 1 + len(v) + sovRecommender(uint64(len(v)))
+			n += mapEntrySize + 1 + sovRecommender(uint64(mapEntrySize))
+		}
+	}
+	if m.Debug {
+		n += 2
+	}
+	if m.IncludeItems != nil {
+		l = m.IncludeItems.Size()
+		n += 1 + l + sovRecommender(uint64(l))
+	}
+	if m.ExcludeItems != nil {
+		l = m.ExcludeItems.Size()
+		n += 1 + l + sovRecommender(uint64(l))
+	}
+	if len(m.Filters) > 0 {
+		for _, e := range m.Filters {
+			l = e.Size()
+			n += 1 + l + sovRecommender(uint64(l))
+		}
+	}
+	l = len(m.ItemId)
+	if l > 0 {
+		n += 1 + l + sovRecommender(uint64(l))
+	}
+	if m.TopN != 0 {
+		n += 1 + sovRecommender(uint64(m.TopN))
+	}
+	if m.MinScore != 0 {
+		n += 5
+	}
+	l = len(m.RequestId)
+	if l > 0 {
+		n += 2 + l + sovRecommender(uint64(l))
+	}
 	return n
 }
 
@@ -775,6 +1690,71 @@ func (m *RecommendResponse) Size() (n int) {
 		l = m.Data.Size()
 		n += 1 + l + sovRecommender(uint64(l))
 	}
+	l = len(m.RequestId)
+	if l > 0 {
+		n += 1 + l + sovRecommender(uint64(l))
+	}
+	return n
+}
+
+func (m *RecallStreamResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Code != 0 {
+		n += 1 + sovRecommender(uint64(m.Code))
+	}
+	l = len(m.Message)
+	if l > 0 {
+		n += 1 + l + sovRecommender(uint64(l))
+	}
+	l = len(m.RequestId)
+	if l > 0 {
+		n += 1 + l + sovRecommender(uint64(l))
+	}
+	l = len(m.IndexName)
+	if l > 0 {
+		n += 1 + l + sovRecommender(uint64(l))
+	}
+	if m.Data != nil {
+		l = m.Data.Size()
+		n += 1 + l + sovRecommender(uint64(l))
+	}
+	if m.Final {
+		n += 2
+	}
+	return n
+}
+
+func (m *BatchRecommendRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Requests) > 0 {
+		for _, e := range m.Requests {
+			l = e.Size()
+			n += 1 + l + sovRecommender(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *BatchRecommendResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Responses) > 0 {
+		for _, e := range m.Responses {
+			l = e.Size()
+			n += 1 + l + sovRecommender(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -899,7 +1879,1004 @@ func (m *ItemInfo) Unmarshal(dAtA []byte) error {
 			if wireType != 2 {
 				return fmt.Errorf("proto: wrong wireType = %d for field Itemid", wireType)
 			}
-			var stringLen uint64
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Itemid = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Score", wireType)
+			}
+			var v uint32
+			if (iNdEx + 4) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
+			iNdEx += 4
+			m.Score = float32(math.Float32frombits(v))
+		case 3:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Rawscore", wireType)
+			}
+			var v uint32
+			if (iNdEx + 4) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
+			iNdEx += 4
+			m.Rawscore = float32(math.Float32frombits(v))
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRecommender(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ItemInfoList) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRecommender
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ItemInfoList: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ItemInfoList: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Iteminfo_", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Iteminfo_ = append(m.Iteminfo_, &ItemInfo{})
+			if err := m.Iteminfo_[len(m.Iteminfo_)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRecommender(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RecommendAttributeFilter) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRecommender
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RecommendAttributeFilter: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RecommendAttributeFilter: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Attribute", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Attribute = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Op", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Op = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Values", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Values = append(m.Values, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRecommender(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RecommendRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRecommender
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RecommendRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RecommendRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DataId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DataId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GroupId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.GroupId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Namespace", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Namespace = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ModelType", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ModelType = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field UserId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.UserId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RecallNum", wireType)
+			}
+			m.RecallNum = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.RecallNum |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ItemList", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ItemList == nil {
+				m.ItemList = &StringList{}
+			}
+			if err := m.ItemList.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Context", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Context == nil {
+				m.Context = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowRecommender
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRecommender
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthRecommender
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthRecommender
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRecommender
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthRecommender
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue < 0 {
+						return ErrInvalidLengthRecommender
+					}
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipRecommender(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if (skippy < 0) || (iNdEx+skippy) < 0 {
+						return ErrInvalidLengthRecommender
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Context[mapkey] = mapvalue
+			iNdEx = postIndex
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Debug", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Debug = bool(v != 0)
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IncludeItems", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.IncludeItems == nil {
+				m.IncludeItems = &StringList{}
+			}
+			if err := m.IncludeItems.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExcludeItems", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ExcludeItems == nil {
+				m.ExcludeItems = &StringList{}
+			}
+			if err := m.ExcludeItems.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Filters", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Filters = append(m.Filters, &RecommendAttributeFilter{})
+			if err := m.Filters[len(m.Filters)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 13:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ItemId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ItemId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 14:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TopN", wireType)
+			}
+			m.TopN = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TopN |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 15:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MinScore", wireType)
+			}
+			var v uint32
+			if (iNdEx + 4) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
+			iNdEx += 4
+			m.MinScore = float32(math.Float32frombits(v))
+		case 16:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RequestId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RequestId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRecommender(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RecommendResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRecommender
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RecommendResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RecommendResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Code", wireType)
+			}
+			m.Code = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Code |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRecommender
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Message = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowRecommender
@@ -909,90 +2886,33 @@ func (m *ItemInfo) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthRecommender
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthRecommender
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Itemid = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 2:
-			if wireType != 5 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Score", wireType)
-			}
-			var v uint32
-			if (iNdEx + 4) > l {
-				return io.ErrUnexpectedEOF
+			if m.Data == nil {
+				m.Data = &ItemInfoList{}
 			}
-			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
-			iNdEx += 4
-			m.Score = float32(math.Float32frombits(v))
-		default:
-			iNdEx = preIndex
-			skippy, err := skipRecommender(dAtA[iNdEx:])
-			if err != nil {
+			if err := m.Data.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
-				return ErrInvalidLengthRecommender
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
-			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *ItemInfoList) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowRecommender
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
-			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
-			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: ItemInfoList: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ItemInfoList: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
+			iNdEx = postIndex
+		case 4:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Iteminfo_", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field RequestId", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowRecommender
@@ -1002,25 +2922,23 @@ func (m *ItemInfoList) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthRecommender
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthRecommender
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Iteminfo_ = append(m.Iteminfo_, &ItemInfo{})
-			if err := m.Iteminfo_[len(m.Iteminfo_)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.RequestId = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -1043,7 +2961,7 @@ func (m *ItemInfoList) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *RecommendRequest) Unmarshal(dAtA []byte) error {
+func (m *RecallStreamResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1066,17 +2984,17 @@ func (m *RecommendRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: RecommendRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: RecallStreamResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: RecommendRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: RecallStreamResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field DataId", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Code", wireType)
 			}
-			var stringLen uint64
+			m.Code = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowRecommender
@@ -1086,27 +3004,14 @@ func (m *RecommendRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				m.Code |= int32(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthRecommender
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
-				return ErrInvalidLengthRecommender
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.DataId = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field GroupId", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -1134,11 +3039,11 @@ func (m *RecommendRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.GroupId = string(dAtA[iNdEx:postIndex])
+			m.Message = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Namespace", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field RequestId", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -1166,11 +3071,11 @@ func (m *RecommendRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Namespace = string(dAtA[iNdEx:postIndex])
+			m.RequestId = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 4:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ModelType", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field IndexName", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -1198,13 +3103,13 @@ func (m *RecommendRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.ModelType = string(dAtA[iNdEx:postIndex])
+			m.IndexName = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 5:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field UserId", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowRecommender
@@ -1214,29 +3119,33 @@ func (m *RecommendRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthRecommender
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthRecommender
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.UserId = string(dAtA[iNdEx:postIndex])
+			if m.Data == nil {
+				m.Data = &ItemInfoList{}
+			}
+			if err := m.Data.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
 		case 6:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field RecallNum", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Final", wireType)
 			}
-			m.RecallNum = 0
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowRecommender
@@ -1246,14 +3155,65 @@ func (m *RecommendRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.RecallNum |= int32(b&0x7F) << shift
+				v |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 7:
+			m.Final = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRecommender(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthRecommender
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *BatchRecommendRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRecommender
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: BatchRecommendRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: BatchRecommendRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ItemList", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Requests", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -1280,10 +3240,8 @@ func (m *RecommendRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.ItemList == nil {
-				m.ItemList = &StringList{}
-			}
-			if err := m.ItemList.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			m.Requests = append(m.Requests, &RecommendRequest{})
+			if err := m.Requests[len(m.Requests)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -1308,7 +3266,7 @@ func (m *RecommendRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *RecommendResponse) Unmarshal(dAtA []byte) error {
+func (m *BatchRecommendResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1331,66 +3289,15 @@ func (m *RecommendResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: RecommendResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: BatchRecommendResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: RecommendResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: BatchRecommendResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Code", wireType)
-			}
-			m.Code = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowRecommender
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.Code |= int32(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
-			}
-			var stringLen uint64
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowRecommender
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthRecommender
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
-				return ErrInvalidLengthRecommender
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Message = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Responses", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -1417,10 +3324,8 @@ func (m *RecommendResponse) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Data == nil {
-				m.Data = &ItemInfoList{}
-			}
-			if err := m.Data.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			m.Responses = append(m.Responses, &RecommendResponse{})
+			if err := m.Responses[len(m.Responses)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
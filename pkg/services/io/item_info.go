@@ -1,8 +1,11 @@
 package io
 
+import "encoding/json"
+
 type ItemInfo struct {
-	itemId string
-	score  float32
+	itemId   string
+	score    float32
+	rawScore float32 //score before calibration, only in debug mode.
 }
 
 // itemId
@@ -22,3 +25,25 @@ func (i *ItemInfo) SetScore(score float32) {
 func (i *ItemInfo) GetScore() float32 {
 	return i.score
 }
+
+// rawScore
+func (i *ItemInfo) SetRawScore(rawScore float32) {
+	i.rawScore = rawScore
+}
+
+func (i *ItemInfo) GetRawScore() float32 {
+	return i.rawScore
+}
+
+// rest response, rawScore is omitted out of debug mode.
+func (i *ItemInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ItemId   string  `json:"itemid"`
+		Score    float32 `json:"score"`
+		RawScore float32 `json:"rawScore,omitempty"`
+	}{
+		ItemId:   i.itemId,
+		Score:    i.score,
+		RawScore: i.rawScore,
+	})
+}
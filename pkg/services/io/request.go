@@ -2,18 +2,33 @@ package io
 
 import (
 	"errors"
-	"infer-microservices/pkg/logs"
+	"fmt"
+	"infer-microservices/internal/logs"
+	"infer-microservices/pkg/config_loader/config_schema"
 	"strings"
 )
 
+const MaxRecallNum = 1000      //max recallNum of a request.
+const MaxRankItemNum = 200     //max itemList len of a rank request.
+const MaxFilterItemNum = 10000 //max includeItems / excludeItems len of a request.
+const MaxBatchNum = 100        //max requests of a batch request.
+
 type RecRequest struct {
-	dataId      string //nacos dataid
-	groupId     string
-	namespaceId string
-	modelType   string //recall or rank
-	userId      string
-	recallNum   int32    //recall num
-	itemList    []string //rank items
+	dataId       string //nacos dataid
+	groupId      string
+	namespaceId  string
+	modelType    string //recall or rank
+	userId       string
+	recallNum    int32                           //recall num
+	itemList     []string                        //rank items
+	context      map[string]string               //request context features, such as country / platform.
+	debug        bool                            //return debug info, such as raw scores.
+	includeItems []string                        //recall only these items, such as a candidate pool.
+	excludeItems []string                        //do not recall these items, such as the seen ones.
+	filters      []config_schema.AttributeFilter //attribute predicates of the recall items.
+	itemId       string                          //the source item of the similar items api.
+	topN         int32                           //rank: keep the first topN items by score, 0 keeps all.
+	minScore     float32                         //rank: drop the items scored below minScore, 0 is not filtered.
 }
 
 // dataId
@@ -79,37 +94,170 @@ func (r *RecRequest) GetItemList() []string {
 	return r.itemList
 }
 
+// context
+func (r *RecRequest) SetContext(context map[string]string) {
+	r.context = context
+}
+
+func (r *RecRequest) GetContext() map[string]string {
+	return r.context
+}
+
+// debug
+func (r *RecRequest) SetDebug(debug bool) {
+	r.debug = debug
+}
+
+func (r *RecRequest) GetDebug() bool {
+	return r.debug
+}
+
+// includeItems
+func (r *RecRequest) SetIncludeItems(includeItems []string) {
+	r.includeItems = includeItems
+}
+
+func (r *RecRequest) GetIncludeItems() []string {
+	return r.includeItems
+}
+
+// excludeItems
+func (r *RecRequest) SetExcludeItems(excludeItems []string) {
+	r.excludeItems = excludeItems
+}
+
+func (r *RecRequest) GetExcludeItems() []string {
+	return r.excludeItems
+}
+
+// filters
+func (r *RecRequest) SetFilters(filters []config_schema.AttributeFilter) {
+	r.filters = filters
+}
+
+func (r *RecRequest) GetFilters() []config_schema.AttributeFilter {
+	return r.filters
+}
+
+// itemId
+func (r *RecRequest) SetItemId(itemId string) {
+	r.itemId = itemId
+}
+
+func (r *RecRequest) GetItemId() string {
+	return r.itemId
+}
+
+// topN
+func (r *RecRequest) SetTopN(topN int32) {
+	r.topN = topN
+}
+
+func (r *RecRequest) GetTopN() int32 {
+	return r.topN
+}
+
+// minScore
+func (r *RecRequest) SetMinScore(minScore float32) {
+	r.minScore = minScore
+}
+
+func (r *RecRequest) GetMinScore() float32 {
+	return r.minScore
+}
+
 func (r *RecRequest) JavaClassName() string {
 	return "com.loki.www.infer.RecRequest"
 }
 
 func (r *RecRequest) Check() bool {
-	//check dataid
-	if r.dataId == "" {
-		err := errors.New("dataid can not be empty")
+	err := r.Validate()
+	if err != nil {
 		logs.Error(err)
 		return false
 	}
 
-	//check userid
-	if r.userId == "" {
-		err := errors.New("userid can not be empty")
+	return true
+}
+
+// CheckSimilarItems check a request of the similar items api, it needs an itemId instead of a userId.
+func (r *RecRequest) CheckSimilarItems() bool {
+	err := r.ValidateSimilarItems()
+	if err != nil {
 		logs.Error(err)
 		return false
 	}
 
-	if r.recallNum > 1000 {
-		err := errors.New("recallNum should less than 2000 ")
-		logs.Error(err)
-		return false
+	return true
+}
+
+// Validate the error of the first invalid field of a recall / rank request.
+func (r *RecRequest) Validate() error {
+	//check dataid
+	if r.dataId == "" {
+		return errors.New("dataid can not be empty")
+	}
+
+	//check userid
+	if r.userId == "" {
+		return errors.New("userid can not be empty")
+	}
+
+	if err := r.validateRecallNum(); err != nil {
+		return err
+	}
+	if r.topN < 0 {
+		return errors.New("topN can not be negative")
 	}
 
 	//itemList
-	if strings.ToLower(r.modelType) == "rank" && len(r.itemList) > 200 {
-		err := errors.New("itemList's len should less than 300 ")
-		logs.Error(err)
-		return false
+	if strings.ToLower(r.modelType) == "rank" && len(r.itemList) > MaxRankItemNum {
+		return fmt.Errorf("itemList's len should not be more than %d", MaxRankItemNum)
 	}
 
-	return true
+	return r.validateFilters()
+}
+
+// ValidateSimilarItems the error of the first invalid field of a similar items request.
+func (r *RecRequest) ValidateSimilarItems() error {
+	//check dataid
+	if r.dataId == "" {
+		return errors.New("dataid can not be empty")
+	}
+
+	//check itemid
+	if r.itemId == "" {
+		return errors.New("itemid can not be empty")
+	}
+
+	if err := r.validateRecallNum(); err != nil {
+		return err
+	}
+
+	return r.validateFilters()
+}
+
+func (r *RecRequest) validateRecallNum() error {
+	if r.recallNum < 0 {
+		return errors.New("recallNum can not be negative")
+	}
+	if r.recallNum > MaxRecallNum {
+		return fmt.Errorf("recallNum should not be more than %d", MaxRecallNum)
+	}
+
+	return nil
+}
+
+// recall filters
+func (r *RecRequest) validateFilters() error {
+	if len(r.includeItems) > MaxFilterItemNum || len(r.excludeItems) > MaxFilterItemNum {
+		return fmt.Errorf("includeItems / excludeItems len should not be more than %d", MaxFilterItemNum)
+	}
+	for _, filter := range r.filters {
+		if err := filter.Check(); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
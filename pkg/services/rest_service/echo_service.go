@@ -1,11 +1,14 @@
 package rest_service
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"infer-microservices/internal/logs"
 	"infer-microservices/internal/utils"
 	config_loader "infer-microservices/pkg/config_loader"
-	nacos "infer-microservices/pkg/nacos"
+	"infer-microservices/pkg/config_loader/config_schema"
+	"infer-microservices/pkg/config_source"
 	"infer-microservices/pkg/services/baseservice"
 	"infer-microservices/pkg/services/io"
 	"net/http"
@@ -30,11 +33,30 @@ func (s *EchoService) GetBaseService() *baseservice.BaseService {
 
 // sync server
 func (s *EchoService) SyncRecommenderInfer(c echo.Context) error {
+	return s.syncInfer(c, s.RecommenderInfer)
+}
+
+// sync server of the similar items api.
+func (s *EchoService) SyncSimilarItemsInfer(c echo.Context) error {
+	return s.syncInfer(c, s.SimilarItemsInfer)
+}
+
+func (s *EchoService) syncInfer(c echo.Context, infer func(context.Context, echo.Context, *io.RecRequest, chan<- map[string]interface{})) error {
+	//INFO: convert http string data to struct data.
+	request, err := s.convertHttpRequstToRecRequest(c)
+	if err != nil {
+		logs.Error(utils.CreateRequestId(&request), time.Now(), err)
+		rsp := make(map[string]interface{}, 0)
+		return c.JSON(http.StatusOK, rsp)
+	}
+
 	respCh := make(chan map[string]interface{}, 100)
-	go s.RecommenderInfer(c, respCh)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), s.baseservice.Deadline(request.GetDataId()))
+	defer cancel()
+	go infer(ctx, c, &request, respCh)
 
 	select {
-	case <-time.After(time.Millisecond * 100):
+	case <-ctx.Done():
 		rsp := make(map[string]interface{}, 0)
 		return c.JSON(http.StatusRequestTimeout, rsp)
 	case responseCh := <-respCh:
@@ -43,8 +65,18 @@ func (s *EchoService) SyncRecommenderInfer(c echo.Context) error {
 
 }
 
-// infer
-func (s *EchoService) RecommenderInfer(c echo.Context, ch chan<- map[string]interface{}) {
+// infer, the downstream calls are bounded by the deadline of ctx.
+func (s *EchoService) RecommenderInfer(ctx context.Context, c echo.Context, request *io.RecRequest, ch chan<- map[string]interface{}) {
+	s.infer(ctx, c, request, ch, (*io.RecRequest).Check, s.baseservice.RecommenderInferHystrix)
+}
+
+// infer the similar items of the request itemId.
+func (s *EchoService) SimilarItemsInfer(ctx context.Context, c echo.Context, request *io.RecRequest, ch chan<- map[string]interface{}) {
+	s.infer(ctx, c, request, ch, (*io.RecRequest).CheckSimilarItems, s.baseservice.SimilarItemsHystrix)
+}
+
+func (s *EchoService) infer(ctx context.Context, c echo.Context, request *io.RecRequest, ch chan<- map[string]interface{}, check func(*io.RecRequest) bool,
+	hystrixInfer func(context.Context, *http.Request, string, *io.RecRequest, *config_loader.ServiceConfig) (map[string]interface{}, error)) {
 	defer func() {
 		if info := recover(); info != nil {
 			logs.Fatal("panic", info)
@@ -56,14 +88,7 @@ func (s *EchoService) RecommenderInfer(c echo.Context, ch chan<- map[string]inte
 	}()
 
 	rsp := make(map[string]interface{}, 0)
-	//INFO: convert http string data to struct data.
-	request, err := s.convertHttpRequstToRecRequest(c)
-	requestId := utils.CreateRequestId(&request)
-
-	if err != nil {
-		logs.Error(requestId, time.Now(), err)
-		panic(err)
-	}
+	requestId := utils.CreateRequestId(request)
 
 	//check http input
 	checkStatus := s.check(c, requestId)
@@ -73,23 +98,25 @@ func (s *EchoService) RecommenderInfer(c echo.Context, ch chan<- map[string]inte
 		panic(err)
 	}
 	//check input
-	checkStatus = request.Check()
+	checkStatus = check(request)
 	if !checkStatus {
 		err := errors.New("input check failed")
 		logs.Error(requestId, time.Now(), err)
 		panic(err)
 	}
 
-	//nacos listen
-	nacosFactory := nacos.NacosFactory{}
-	nacosConfig := nacosFactory.CreateNacosConfig(s.baseservice.GetNacosIp(), uint64(s.baseservice.GetNacosPort()), &request)
-	logs.Debug(requestId, time.Now(), "nacosConfig:", nacosConfig)
-
-	nacosConfig.StartListenNacos()
+	//service config, loaded at startup.
+	ServiceConfig, err := config_source.GetServiceConfig(request.GetDataId())
+	if err != nil {
+		logs.Error(requestId, time.Now(), err)
+		rsp["code"] = http.StatusNotFound
+		rsp["message"] = err.Error()
+		ch <- rsp
+		return
+	}
 
 	//infer
-	ServiceConfig := config_loader.GetServiceConfigs()[request.GetDataId()]
-	response, err := s.baseservice.RecommenderInferHystrix(c.Request(), "restServer", &request, ServiceConfig)
+	response, err := hystrixInfer(ctx, c.Request(), "restServer", request, ServiceConfig)
 	if err != nil {
 		logs.Error(requestId, time.Now(), err)
 		panic(err)
@@ -124,12 +151,80 @@ func (s *EchoService) check(c echo.Context, requestId string) bool {
 
 func (s *EchoService) convertHttpRequstToRecRequest(c echo.Context) (io.RecRequest, error) {
 	request := io.RecRequest{}
-	data := c.Request().Form["data"]
+	data := []string{c.FormValue("data")}
 	requestMap := make(map[string]interface{}, 0)
 	err := jsoniter.Unmarshal([]byte(data[0]), &requestMap)
 	if err != nil {
 		return request, err
 	}
 
+	request.SetDataId(requestString(requestMap, "dataId"))
+	request.SetGroupId(requestString(requestMap, "groupId"))
+	request.SetNamespaceId(requestString(requestMap, "namespace"))
+	request.SetModelType(requestString(requestMap, "modelType"))
+	request.SetUserId(requestString(requestMap, "userId"))
+	request.SetItemId(requestString(requestMap, "itemId"))
+	if recallNum, ok := requestMap["recallNum"].(float64); ok {
+		request.SetRecallNum(int32(recallNum))
+	}
+	if itemList, ok := requestStrings(requestMap, "itemList"); ok {
+		request.SetItemList(itemList)
+	}
+	if topN, ok := requestMap["topN"].(float64); ok {
+		request.SetTopN(int32(topN))
+	}
+	if minScore, ok := requestMap["minScore"].(float64); ok {
+		request.SetMinScore(float32(minScore))
+	}
+	if includeItems, ok := requestStrings(requestMap, "includeItems"); ok {
+		request.SetIncludeItems(includeItems)
+	}
+	if excludeItems, ok := requestStrings(requestMap, "excludeItems"); ok {
+		request.SetExcludeItems(excludeItems)
+	}
+	if context, ok := requestMap["context"].(map[string]interface{}); ok {
+		contextMap := make(map[string]string, len(context))
+		for k, v := range context {
+			contextMap[k] = fmt.Sprint(v)
+		}
+		request.SetContext(contextMap)
+	}
+	if debug, ok := requestMap["debug"].(bool); ok {
+		request.SetDebug(debug)
+	}
+	if filters, ok := requestMap["filters"].([]interface{}); ok {
+		attributeFilters := make([]config_schema.AttributeFilter, 0, len(filters))
+		for _, filter := range filters {
+			filterMap, _ := filter.(map[string]interface{})
+			values, _ := requestStrings(filterMap, "values")
+			attributeFilters = append(attributeFilters, config_schema.AttributeFilter{
+				Attribute: requestString(filterMap, "attribute"),
+				Op:        requestString(filterMap, "op"),
+				Values:    values,
+			})
+		}
+		request.SetFilters(attributeFilters)
+	}
+
 	return request, nil
 }
+
+func requestString(requestMap map[string]interface{}, key string) string {
+	value, _ := requestMap[key].(string)
+	return value
+}
+
+// the values of an array, numbers are formatted as strings.
+func requestStrings(requestMap map[string]interface{}, key string) ([]string, bool) {
+	list, ok := requestMap[key].([]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	values := make([]string, 0, len(list))
+	for _, value := range list {
+		values = append(values, fmt.Sprint(value))
+	}
+
+	return values, true
+}
@@ -0,0 +1,154 @@
+package rest_service
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// OpenApiV2 the openapi 3 document of the v2 routes. the schemas are generated from the request / response structs:
+// the json tags are the properties, the doc tags the descriptions, and the validate tags required / min / max.
+func OpenApiV2(routes []RouteV2) map[string]interface{} {
+	schemas := make(map[string]interface{}, 0)
+	paths := make(map[string]interface{}, 0)
+	for _, route := range routes {
+		responses := map[string]interface{}{
+			strconv.Itoa(http.StatusOK): jsonContent("success", schemaOf(reflect.TypeOf(route.Response), schemas)),
+		}
+		for _, status := range route.Errors {
+			responses[strconv.Itoa(status)] = jsonContent(http.StatusText(status), schemaOf(reflect.TypeOf(ErrorResponseV2{}), schemas))
+		}
+
+		operation := map[string]interface{}{
+			"summary":   route.Summary,
+			"responses": responses,
+		}
+		if route.Request != nil {
+			body := jsonContent("", schemaOf(reflect.TypeOf(route.Request), schemas))
+			body["required"] = true
+			delete(body, "description")
+			operation["requestBody"] = body
+		}
+
+		pathItem, ok := paths[route.Path].(map[string]interface{})
+		if !ok {
+			pathItem = make(map[string]interface{}, 0)
+			paths[route.Path] = pathItem
+		}
+		pathItem[strings.ToLower(route.Method)] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "infer-microservices",
+			"version": "2",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+		},
+	}
+}
+
+func jsonContent(description string, schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": schema},
+		},
+	}
+}
+
+// the schema of a type, a named struct is added to schemas and referred.
+func schemaOf(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32:
+		return map[string]interface{}{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]interface{}{"type": "number", "format": "double"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem(), schemas)}
+	case reflect.Map:
+		schema := map[string]interface{}{"type": "object"}
+		if t.Elem().Kind() != reflect.Interface {
+			schema["additionalProperties"] = schemaOf(t.Elem(), schemas)
+		}
+		return schema
+	case reflect.Struct:
+		if _, ok := schemas[t.Name()]; !ok {
+			schemas[t.Name()] = map[string]interface{}{} //a struct refers to itself.
+			schemas[t.Name()] = structSchema(t, schemas)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	}
+
+	return map[string]interface{}{}
+}
+
+func structSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{}, 0)
+	required := make([]string, 0)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := jsonName(field)
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+
+		property := schemaOf(field.Type, schemas)
+		if doc := field.Tag.Get("doc"); doc != "" {
+			property["description"] = doc
+		}
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			key, value, _ := strings.Cut(rule, "=")
+			switch {
+			case key == "required":
+				required = append(required, name)
+			case key == "min" || key == "max":
+				bound, err := strconv.Atoi(value)
+				if err != nil {
+					continue
+				}
+				if field.Type.Kind() == reflect.Slice {
+					property[key+"Items"] = bound
+				} else if key == "min" {
+					property["minimum"] = bound
+				} else {
+					property["maximum"] = bound
+				}
+			}
+		}
+		properties[name] = property
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// the json name of a field, the field name if it has no json tag.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
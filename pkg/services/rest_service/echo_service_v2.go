@@ -0,0 +1,343 @@
+package rest_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"infer-microservices/internal/logs"
+	"infer-microservices/internal/utils"
+	config_loader "infer-microservices/pkg/config_loader"
+	"infer-microservices/pkg/config_loader/config_schema"
+	"infer-microservices/pkg/config_source"
+	"infer-microservices/pkg/services/baseservice"
+	"infer-microservices/pkg/services/io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	validator "github.com/go-playground/validator/v10"
+	"github.com/labstack/echo"
+)
+
+// the v2 api takes a json body of a typed request, and returns a typed json response with the http status of the result.
+// the schemas of the openapi document are generated from these structs, see OpenApiV2.
+
+// RecommendRequestV2 the body of POST /v2/recommend, the fields of the grpc RecommendRequest.
+type RecommendRequestV2 struct {
+	DataId       string                          `json:"dataId" validate:"required" doc:"nacos dataId of the service config."`
+	GroupId      string                          `json:"groupId" doc:"nacos group of the service config."`
+	Namespace    string                          `json:"namespace" doc:"nacos namespace of the service config."`
+	ModelType    string                          `json:"modelType" doc:"recall or rank."`
+	UserId       string                          `json:"userId" validate:"required"`
+	RecallNum    int32                           `json:"recallNum" validate:"min=0,max=1000" doc:"recall num, split across the indexes. 0 recalls the recall num of each index."`
+	ItemList     []string                        `json:"itemList" validate:"max=200" doc:"rank items."`
+	Context      map[string]string               `json:"context" doc:"request context features, such as country / platform."`
+	Debug        bool                            `json:"debug" doc:"return the raw scores before calibration."`
+	IncludeItems []string                        `json:"includeItems" validate:"max=10000" doc:"recall only these items."`
+	ExcludeItems []string                        `json:"excludeItems" validate:"max=10000" doc:"do not recall these items."`
+	Filters      []config_schema.AttributeFilter `json:"filters" doc:"attribute predicates of the recall items."`
+	TopN         int32                           `json:"topN" validate:"min=0" doc:"rank: keep the first topN items by score, 0 keeps all."`
+	MinScore     float32                         `json:"minScore" doc:"rank: drop the items scored below minScore, 0 is not filtered."`
+}
+
+// SimilarItemsRequestV2 the body of POST /v2/similar.
+type SimilarItemsRequestV2 struct {
+	DataId       string                          `json:"dataId" validate:"required" doc:"nacos dataId of the service config."`
+	GroupId      string                          `json:"groupId" doc:"nacos group of the service config."`
+	Namespace    string                          `json:"namespace" doc:"nacos namespace of the service config."`
+	ItemId       string                          `json:"itemId" validate:"required" doc:"the source item."`
+	RecallNum    int32                           `json:"recallNum" validate:"min=0,max=1000" doc:"recall num, split across the indexes. 0 recalls the recall num of each index."`
+	Context      map[string]string               `json:"context" doc:"request context, the values of the index filter rules."`
+	Debug        bool                            `json:"debug"`
+	IncludeItems []string                        `json:"includeItems" validate:"max=10000" doc:"recall only these items."`
+	ExcludeItems []string                        `json:"excludeItems" validate:"max=10000" doc:"do not recall these items, the source item is never recalled."`
+	Filters      []config_schema.AttributeFilter `json:"filters" doc:"attribute predicates of the recall items."`
+}
+
+// BatchRequestV2 the body of POST /v2/batch, a request is inferred as the body of /v2/recommend.
+type BatchRequestV2 struct {
+	Requests []RecommendRequestV2 `json:"requests" validate:"required,min=1,max=100" doc:"the requests of many users, the requests of a dataId are ranked / recalled by one tfserving request."`
+}
+
+// ItemV2 an item of a v2 response.
+type ItemV2 struct {
+	ItemId   string   `json:"itemId"`
+	Score    float32  `json:"score"`
+	RawScore *float32 `json:"rawScore,omitempty" doc:"score before calibration, only in debug mode."`
+}
+
+// RecommendResponseV2 the items sorted by score.
+type RecommendResponseV2 struct {
+	RequestId string   `json:"requestId"`
+	Degraded  bool     `json:"degraded,omitempty" doc:"the items are from the degraded model, such as on the hystrix timeout."`
+	Items     []ItemV2 `json:"items"`
+}
+
+// BatchResultV2 the result of a request of a batch, an error only fails its request.
+type BatchResultV2 struct {
+	RequestId string   `json:"requestId"`
+	Code      int      `json:"code" doc:"the http status of the request, such as 200, 400 or 404."`
+	Message   string   `json:"message,omitempty" doc:"the error of the request which is not 200."`
+	Items     []ItemV2 `json:"items,omitempty"`
+}
+
+// BatchResponseV2 the results in the order of the requests.
+type BatchResponseV2 struct {
+	Results []BatchResultV2 `json:"results"`
+}
+
+// ErrorResponseV2 the body of a v2 response which is not 200.
+type ErrorResponseV2 struct {
+	RequestId string `json:"requestId"`
+	Code      int    `json:"code" doc:"the http status."`
+	Message   string `json:"message"`
+}
+
+// RouteV2 a route of the v2 api, the routes are registered and documented from one table.
+type RouteV2 struct {
+	Method   string
+	Path     string
+	Summary  string
+	Request  interface{} //the json body, nil if none.
+	Response interface{} //the body of 200.
+	Errors   []int       //the statuses of ErrorResponseV2.
+	Handler  echo.HandlerFunc
+}
+
+var inferErrorsV2 = []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError,
+	http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+// RoutesV2 the routes of the v2 api.
+func (s *EchoService) RoutesV2() []RouteV2 {
+	return []RouteV2{
+		{
+			Method:   http.MethodPost,
+			Path:     "/v2/recommend",
+			Summary:  "recall the items of a user by the recall model, or rank the itemList by the rank model of the dataId.",
+			Request:  RecommendRequestV2{},
+			Response: RecommendResponseV2{},
+			Errors:   inferErrorsV2,
+			Handler:  s.RecommendV2,
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/v2/similar",
+			Summary:  "recall the similar items of an item by its embedding.",
+			Request:  SimilarItemsRequestV2{},
+			Response: RecommendResponseV2{},
+			Errors:   inferErrorsV2,
+			Handler:  s.SimilarItemsV2,
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/v2/batch",
+			Summary:  "recommend for many users in one call, the results and the errors are per request.",
+			Request:  BatchRequestV2{},
+			Response: BatchResponseV2{},
+			Errors:   []int{http.StatusBadRequest},
+			Handler:  s.BatchRecommendV2,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/v2/openapi.json",
+			Summary:  "the openapi document of the v2 api.",
+			Response: map[string]interface{}{},
+			Handler:  s.OpenApiDocumentV2,
+		},
+	}
+}
+
+// POST /v2/recommend
+func (s *EchoService) RecommendV2(c echo.Context) error {
+	body := RecommendRequestV2{}
+	if err := bindV2(c, &body); err != nil {
+		return failV2(c, "", http.StatusBadRequest, err)
+	}
+
+	request := body.recRequest()
+	return s.inferV2(c, request, request.Validate, s.baseservice.RecommenderInferHystrix)
+}
+
+func (b *RecommendRequestV2) recRequest() *io.RecRequest {
+	request := io.RecRequest{}
+	request.SetDataId(b.DataId)
+	request.SetGroupId(b.GroupId)
+	request.SetNamespaceId(b.Namespace)
+	request.SetModelType(b.ModelType)
+	request.SetUserId(b.UserId)
+	request.SetRecallNum(b.RecallNum)
+	request.SetItemList(b.ItemList)
+	request.SetContext(b.Context)
+	request.SetDebug(b.Debug)
+	request.SetIncludeItems(b.IncludeItems)
+	request.SetExcludeItems(b.ExcludeItems)
+	request.SetFilters(b.Filters)
+	request.SetTopN(b.TopN)
+	request.SetMinScore(b.MinScore)
+
+	return &request
+}
+
+// POST /v2/similar
+func (s *EchoService) SimilarItemsV2(c echo.Context) error {
+	body := SimilarItemsRequestV2{}
+	if err := bindV2(c, &body); err != nil {
+		return failV2(c, "", http.StatusBadRequest, err)
+	}
+
+	request := io.RecRequest{}
+	request.SetDataId(body.DataId)
+	request.SetGroupId(body.GroupId)
+	request.SetNamespaceId(body.Namespace)
+	request.SetItemId(body.ItemId)
+	request.SetRecallNum(body.RecallNum)
+	request.SetContext(body.Context)
+	request.SetDebug(body.Debug)
+	request.SetIncludeItems(body.IncludeItems)
+	request.SetExcludeItems(body.ExcludeItems)
+	request.SetFilters(body.Filters)
+
+	return s.inferV2(c, &request, request.ValidateSimilarItems, s.baseservice.SimilarItemsHystrix)
+}
+
+// POST /v2/batch
+func (s *EchoService) BatchRecommendV2(c echo.Context) error {
+	body := BatchRequestV2{}
+	if err := bindV2(c, &body); err != nil {
+		return failV2(c, "", http.StatusBadRequest, err)
+	}
+
+	requests := make([]*io.RecRequest, 0, len(body.Requests))
+	for i := range body.Requests {
+		requests = append(requests, body.Requests[i].recRequest())
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), s.baseservice.BatchDeadline(requests))
+	defer cancel()
+
+	responses, errs := s.baseservice.BatchInferHystrix(ctx, c.Request(), "restServer", requests)
+	results := make([]BatchResultV2, len(requests))
+	for i, request := range requests {
+		results[i] = BatchResultV2{RequestId: utils.CreateRequestId(request), Code: http.StatusOK}
+		if errs[i] != nil {
+			results[i].Code = baseservice.HttpStatusOf(errs[i])
+			results[i].Message = errs[i].Error()
+			continue
+		}
+		results[i].Items = itemsV2(responses[i], request.GetDebug())
+	}
+
+	return c.JSON(http.StatusOK, BatchResponseV2{Results: results})
+}
+
+// GET /v2/openapi.json
+func (s *EchoService) OpenApiDocumentV2(c echo.Context) error {
+	return c.JSON(http.StatusOK, OpenApiV2(s.RoutesV2()))
+}
+
+// infer in the deadline of the dataId, a degraded result of the hystrix fallback is still 200.
+func (s *EchoService) inferV2(c echo.Context, request *io.RecRequest, validate func() error,
+	hystrixInfer func(context.Context, *http.Request, string, *io.RecRequest, *config_loader.ServiceConfig) (map[string]interface{}, error)) error {
+	requestId := utils.CreateRequestId(request)
+	if err := validate(); err != nil {
+		return failV2(c, requestId, http.StatusBadRequest, err)
+	}
+
+	//service config, loaded at startup.
+	ServiceConfig, err := config_source.GetServiceConfig(request.GetDataId())
+	if err != nil {
+		return failV2(c, requestId, http.StatusNotFound, err)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), s.baseservice.Deadline(request.GetDataId()))
+	defer cancel()
+
+	type inferResult struct {
+		response map[string]interface{}
+		err      error
+	}
+	resultCh := make(chan inferResult, 1)
+	go func() {
+		defer func() {
+			if info := recover(); info != nil {
+				resultCh <- inferResult{err: fmt.Errorf("panic: %v", info)}
+			}
+		}()
+		response, err := hystrixInfer(ctx, c.Request(), "restServer", request, ServiceConfig)
+		resultCh <- inferResult{response: response, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return failV2(c, requestId, http.StatusGatewayTimeout, ctx.Err())
+	case result := <-resultCh:
+		if result.err != nil {
+			logs.Error(requestId, time.Now(), result.err)
+		}
+		_, ok := result.response["data"].([]*io.ItemInfo)
+		if result.err != nil && !ok {
+			return failV2(c, requestId, baseservice.HttpStatusOf(result.err), result.err)
+		}
+
+		return c.JSON(http.StatusOK, RecommendResponseV2{RequestId: requestId, Degraded: result.err != nil, Items: itemsV2(result.response, request.GetDebug())})
+	}
+}
+
+// the items of an infer response, with the raw scores in debug mode.
+func itemsV2(response map[string]interface{}, debug bool) []ItemV2 {
+	itemsScores, _ := response["data"].([]*io.ItemInfo)
+	items := make([]ItemV2, 0, len(itemsScores))
+	for _, itemScore := range itemsScores {
+		item := ItemV2{ItemId: itemScore.GetItemId(), Score: itemScore.GetScore()}
+		if debug {
+			rawScore := itemScore.GetRawScore()
+			item.RawScore = &rawScore
+		}
+		items = append(items, item)
+	}
+
+	return items
+}
+
+func failV2(c echo.Context, requestId string, httpStatus int, err error) error {
+	return c.JSON(httpStatus, ErrorResponseV2{RequestId: requestId, Code: httpStatus, Message: err.Error()})
+}
+
+// the validator of the v2 requests, the fields are named by their json tags.
+var validateV2 = newValidatorV2()
+
+func newValidatorV2() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return jsonName(field)
+	})
+
+	return validate
+}
+
+// bind the json body, the unknown fields are rejected.
+func bindV2(c echo.Context, body interface{}) error {
+	decoder := json.NewDecoder(c.Request().Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(body); err != nil {
+		return fmt.Errorf("invalid json body: %s", err)
+	}
+
+	err := validateV2.Struct(body)
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		messages := make([]string, 0, len(validationErrors))
+		for _, fieldError := range validationErrors {
+			if fieldError.Param() != "" {
+				messages = append(messages, fmt.Sprintf("%s should be %s=%s", fieldError.Field(), fieldError.Tag(), fieldError.Param()))
+			} else {
+				messages = append(messages, fmt.Sprintf("%s is %s", fieldError.Field(), fieldError.Tag()))
+			}
+		}
+		return errors.New(strings.Join(messages, ", "))
+	}
+
+	return err
+}
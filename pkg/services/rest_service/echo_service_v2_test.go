@@ -0,0 +1,299 @@
+package rest_service
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"infer-microservices/pkg/config_source"
+	"infer-microservices/pkg/services/baseservice"
+	"infer-microservices/pkg/services/io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+)
+
+func newEchoServiceV2() (*EchoService, *echo.Echo) {
+	s := &EchoService{}
+	s.SetBaseService(&baseservice.BaseService{})
+	e := echo.New()
+	for _, route := range s.RoutesV2() {
+		e.Add(route.Method, route.Path, route.Handler)
+	}
+
+	return s, e
+}
+
+// the document is decoded from json, as the clients read it.
+func openApiDocument(t *testing.T, s *EchoService) map[string]interface{} {
+	content, err := json.Marshal(OpenApiV2(s.RoutesV2()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	document := make(map[string]interface{}, 0)
+	if err := json.Unmarshal(content, &document); err != nil {
+		t.Fatal(err)
+	}
+
+	return document
+}
+
+// the component schema of a $ref.
+func component(document map[string]interface{}, schema map[string]interface{}) map[string]interface{} {
+	ref, _ := schema["$ref"].(string)
+	schemas := document["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	component, _ := schemas[strings.TrimPrefix(ref, "#/components/schemas/")].(map[string]interface{})
+
+	return component
+}
+
+func operation(document map[string]interface{}, method string, path string) map[string]interface{} {
+	pathItem, _ := document["paths"].(map[string]interface{})[path].(map[string]interface{})
+	operation, _ := pathItem[strings.ToLower(method)].(map[string]interface{})
+
+	return operation
+}
+
+func jsonSchema(content map[string]interface{}) map[string]interface{} {
+	return content["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"].(map[string]interface{})
+}
+
+func TestOpenApiV2Schemas(t *testing.T) {
+	s, _ := newEchoServiceV2()
+	document := openApiDocument(t, s)
+
+	for _, route := range s.RoutesV2() {
+		operation := operation(document, route.Method, route.Path)
+		if operation == nil {
+			t.Fatalf("%s %s is not documented", route.Method, route.Path)
+		}
+		if route.Request == nil {
+			continue
+		}
+
+		//the properties are the json fields of the request.
+		request := component(document, jsonSchema(operation["requestBody"].(map[string]interface{})))
+		properties := request["properties"].(map[string]interface{})
+		requestType := reflect.TypeOf(route.Request)
+		if len(properties) != requestType.NumField() {
+			t.Errorf("%s documents %d fields, the request has %d", route.Path, len(properties), requestType.NumField())
+		}
+		for i := 0; i < requestType.NumField(); i++ {
+			if _, ok := properties[jsonName(requestType.Field(i))]; !ok {
+				t.Errorf("%s field %s is not documented", route.Path, jsonName(requestType.Field(i)))
+			}
+		}
+
+		//the limits of the validate tags are the ones of RecRequest.
+		limits := map[string][2]interface{}{
+			"recallNum":    {"maximum", float64(io.MaxRecallNum)},
+			"itemList":     {"maxItems", float64(io.MaxRankItemNum)},
+			"includeItems": {"maxItems", float64(io.MaxFilterItemNum)},
+			"excludeItems": {"maxItems", float64(io.MaxFilterItemNum)},
+			"requests":     {"maxItems", float64(io.MaxBatchNum)},
+		}
+		for name, limit := range limits {
+			property, ok := properties[name].(map[string]interface{})
+			if ok && property[limit[0].(string)] != limit[1] {
+				t.Errorf("%s %s %s is %v, expect %v", route.Path, name, limit[0], property[limit[0].(string)], limit[1])
+			}
+		}
+	}
+}
+
+func TestHandlersV2AgainstOpenApi(t *testing.T) {
+	s, e := newEchoServiceV2()
+	document := openApiDocument(t, s)
+
+	cases := []struct {
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{http.MethodPost, "/v2/recommend", `{"dataId": "inferid-001"`, http.StatusBadRequest},
+		{http.MethodPost, "/v2/recommend", `{"dataId": "inferid-001", "userId": "u1", "uid": "u1"}`, http.StatusBadRequest},
+		{http.MethodPost, "/v2/recommend", `{"userId": "u1"}`, http.StatusBadRequest},
+		{http.MethodPost, "/v2/recommend", `{"dataId": "inferid-001", "userId": "u1", "recallNum": 2000}`, http.StatusBadRequest},
+		{http.MethodPost, "/v2/recommend", `{"dataId": "inferid-001", "userId": "u1", "filters": [{"attribute": "region", "op": "between"}]}`, http.StatusBadRequest},
+		{http.MethodPost, "/v2/recommend", `{"dataId": "no-such-dataid", "userId": "u1", "context": {"country": "us"}}`, http.StatusNotFound},
+		{http.MethodPost, "/v2/similar", `{"dataId": "inferid-001"}`, http.StatusBadRequest},
+		{http.MethodPost, "/v2/similar", `{"dataId": "no-such-dataid", "itemId": "i1"}`, http.StatusNotFound},
+		{http.MethodPost, "/v2/batch", `{"requests": []}`, http.StatusBadRequest},
+		{http.MethodPost, "/v2/batch", `{"requests": [{"dataId": "no-such-dataid", "userId": "u1"}]}`, http.StatusOK},
+		{http.MethodGet, "/v2/openapi.json", ``, http.StatusOK},
+	}
+	for _, c := range cases {
+		request := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		recorder := httptest.NewRecorder()
+		e.ServeHTTP(recorder, request)
+
+		if recorder.Code != c.status {
+			t.Errorf("%s %s: status %d, expect %d, body %s", c.path, c.body, recorder.Code, c.status, recorder.Body.String())
+			continue
+		}
+
+		//the status and the body are the documented ones.
+		responses := operation(document, c.method, c.path)["responses"].(map[string]interface{})
+		response, ok := responses[strconv.Itoa(recorder.Code)].(map[string]interface{})
+		if !ok {
+			t.Errorf("%s status %d is not documented", c.path, recorder.Code)
+			continue
+		}
+		schema := component(document, jsonSchema(response))
+		if schema == nil {
+			continue
+		}
+		body := make(map[string]interface{}, 0)
+		if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+			t.Errorf("%s body is not a json object: %v", c.path, err)
+			continue
+		}
+		properties := schema["properties"].(map[string]interface{})
+		for key := range body {
+			if _, ok := properties[key]; !ok {
+				t.Errorf("%s responds %s, which is not documented", c.path, key)
+			}
+		}
+		for key := range properties {
+			if _, ok := body[key]; !ok && !strings.Contains(key, "degraded") {
+				t.Errorf("%s response has no %s", c.path, key)
+			}
+		}
+	}
+}
+
+func TestBatchRecommendV2(t *testing.T) {
+	_, e := newEchoServiceV2()
+
+	body := `{"requests": [{"dataId": "no-such-dataid", "userId": "u1"}, {"dataId": "inferid-001"}, {"dataId": "no-such-dataid", "userId": "u2", "recallNum": 2000}]}`
+	request := httptest.NewRequest(http.MethodPost, "/v2/batch", strings.NewReader(body))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	recorder := httptest.NewRecorder()
+	e.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", recorder.Code, recorder.Body.String())
+	}
+
+	//a bad request only fails itself.
+	response := BatchResponseV2{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	expects := []int{http.StatusNotFound, http.StatusBadRequest, http.StatusBadRequest}
+	if len(response.Results) != len(expects) {
+		t.Fatalf("%d results of %d requests", len(response.Results), len(expects))
+	}
+	for i, result := range response.Results {
+		t.Log(result.Code, result.Message)
+		if result.Code != expects[i] || result.Message == "" || result.RequestId == "" {
+			t.Errorf("request %d: %+v, expect code %d", i, result, expects[i])
+		}
+	}
+
+	//too many requests.
+	requests := make([]string, io.MaxBatchNum+1)
+	for i := range requests {
+		requests[i] = `{"dataId": "inferid-001", "userId": "u1"}`
+	}
+	request = httptest.NewRequest(http.MethodPost, "/v2/batch", strings.NewReader(`{"requests": [`+strings.Join(requests, ",")+`]}`))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	recorder = httptest.NewRecorder()
+	e.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("%d requests: status %d, expect %d", len(requests), recorder.Code, http.StatusBadRequest)
+	}
+}
+
+// a one node redis cluster which answers the probe of a service config, CLUSTER SLOTS, READONLY and PING.
+func startRedisClusterStub(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					//a command is an array of bulk strings.
+					header, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					n, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+					args := make([]string, 0, n)
+					for i := 0; i < n; i++ {
+						reader.ReadString('\n')
+						arg, _ := reader.ReadString('\n')
+						args = append(args, strings.ToUpper(strings.TrimSpace(arg)))
+					}
+					switch strings.Join(args, " ") {
+					case "CLUSTER SLOTS":
+						fmt.Fprintf(conn, "*1\r\n*3\r\n:0\r\n:16383\r\n*2\r\n$%d\r\n%s\r\n:%s\r\n", len(host), host, port)
+					case "READONLY":
+						fmt.Fprint(conn, "+OK\r\n")
+					case "PING":
+						fmt.Fprint(conn, "+PONG\r\n")
+					default:
+						fmt.Fprint(conn, "-ERR unknown command\r\n")
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+// a batch request of a model which does not infer batch requests is a bad request.
+func TestBatchRecommendV2ModelNotBatch(t *testing.T) {
+	dataId := "test-batch-not-batch"
+	dir := t.TempDir()
+	content := fmt.Sprintf(`{"config": {"redis_conf": {"redisCluster": {"addrs": [%q]}}, "model_conf": {"lr": {"tfservingGrpcAddr": {"tfservingModelName": "lr", "addrs": []},
+		"userRedisKeyPreOffline": "u_", "userRedisKeyPreRealtime": "r_", "itemRedisKeyPre": "i_"}}}}`, startRedisClusterStub(t))
+	if err := os.WriteFile(filepath.Join(dir, dataId+".json"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest := &config_source.ServiceManifest{Services: []config_source.ServiceManifestEntry{{DataId: dataId}}}
+	if err := config_source.LoadServiceConfigs(config_source.NewLocalConfigSource(dir), manifest); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config_source.GetServiceConfig(dataId); err != nil {
+		t.Fatal(err)
+	}
+
+	_, e := newEchoServiceV2()
+	body := `{"requests": [{"dataId": "` + dataId + `", "userId": "u1", "modelType": "lr"}]}`
+	request := httptest.NewRequest(http.MethodPost, "/v2/batch", strings.NewReader(body))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	recorder := httptest.NewRecorder()
+	e.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", recorder.Code, recorder.Body.String())
+	}
+
+	response := BatchResponseV2{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Results) != 1 || response.Results[0].Code != http.StatusBadRequest {
+		t.Errorf("results %+v, expect one with code %d", response.Results, http.StatusBadRequest)
+	}
+}
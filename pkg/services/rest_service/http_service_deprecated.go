@@ -7,8 +7,7 @@ import (
 	"infer-microservices/internal/utils"
 	"net/http"
 
-	config_loader "infer-microservices/pkg/config_loader"
-	nacos "infer-microservices/pkg/nacos"
+	"infer-microservices/pkg/config_source"
 	"infer-microservices/pkg/services/baseservice"
 	"infer-microservices/pkg/services/io"
 	"time"
@@ -103,16 +102,19 @@ func (s *HttpService) RecommenderInfer(w http.ResponseWriter, r *http.Request, c
 		panic(err)
 	}
 
-	//nacos listen
-	nacosFactory := nacos.NacosFactory{}
-	nacosConfig := nacosFactory.CreateNacosConfig(s.baseservice.GetNacosIp(), uint64(s.baseservice.GetNacosPort()), &request)
-	logs.Debug(requestId, time.Now(), "nacosConfig:", nacosConfig)
-
-	nacosConfig.StartListenNacos()
+	//service config, loaded at startup.
+	ServiceConfig, err := config_source.GetServiceConfig(request.GetDataId())
+	if err != nil {
+		logs.Error(requestId, time.Now(), err)
+		rsp["code"] = http.StatusNotFound
+		rsp["message"] = err.Error()
+		buff, _ := jsoniter.Marshal(rsp)
+		ch <- buff
+		return
+	}
 
 	//infer
-	ServiceConfig := config_loader.GetServiceConfigs()[request.GetDataId()]
-	response, err := s.baseservice.RecommenderInferHystrix(r, "restServer", &request, ServiceConfig)
+	response, err := s.baseservice.RecommenderInferHystrix(r.Context(), r, "restServer", &request, ServiceConfig)
 	if err != nil {
 		logs.Error(requestId, time.Now(), err)
 		panic(err)
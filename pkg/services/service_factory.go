@@ -2,20 +2,24 @@ package services
 
 import (
 	"infer-microservices/internal/flags"
+	"infer-microservices/pkg/config_source"
+	admin_service "infer-microservices/pkg/services/admin_service"
 	"infer-microservices/pkg/services/baseservice"
 	dubbo_service "infer-microservices/pkg/services/dubbo_service"
 	grpc_service "infer-microservices/pkg/services/grpc_service"
 	rest_service "infer-microservices/pkg/services/rest_service"
 )
 
-var skywalkingWeatherOpen bool
-var skywalkingIp string
-var skywalkingPort int
-var skywalkingServerName string
-var nacosIp string
-var nacosPort uint64
-var lowerRankNum int
-var lowerRecallNum int
+var skywalkingWeatherOpen *bool
+var skywalkingIp *string
+var skywalkingPort *int
+var skywalkingServerName *string
+var nacosIp *string
+var nacosPort *int
+var lowerRankNum *int
+var lowerRecallNum *int
+var configSourceType *string
+var configLocalDir *string
 
 type RecommenderInferInterface interface {
 	RecommenderInfer()
@@ -24,32 +28,43 @@ type RecommenderInferInterface interface {
 type ServiceFactory struct {
 }
 
+// the flags are read when the services are created, after the flags are parsed.
 func init() {
 	flagFactory := flags.FlagFactory{}
 	//flagSkywalking
 	flagSkywalking := flagFactory.CreateFlagSkywalking()
-	skywalkingWeatherOpen = *flagSkywalking.GetSkywalkingWhetheropen()
-	skywalkingIp = *flagSkywalking.GetSkywalkingIp()
-	skywalkingPort = *flagSkywalking.GetSkywalkingPort()
-	skywalkingServerName = *flagSkywalking.GetSkywalkingServername()
+	skywalkingWeatherOpen = flagSkywalking.GetSkywalkingWhetheropen()
+	skywalkingIp = flagSkywalking.GetSkywalkingIp()
+	skywalkingPort = flagSkywalking.GetSkywalkingPort()
+	skywalkingServerName = flagSkywalking.GetSkywalkingServername()
 
 	//flagHystrix
 	flagHystrix := flagFactory.CreateFlagHystrix()
-	lowerRecallNum = *flagHystrix.GetHystrixLowerRecallNum()
-	lowerRankNum = *flagHystrix.GetHystrixLowerRankNum()
+	lowerRecallNum = flagHystrix.GetHystrixLowerRecallNum()
+	lowerRankNum = flagHystrix.GetHystrixLowerRankNum()
+
+	//flagNacos
+	flagNacos := flagFactory.CreateFlagNacos()
+	nacosIp = flagNacos.GetNacosIp()
+	nacosPort = flagNacos.GetNacosPort()
+
+	//flagConfigSource
+	flagConfigSource := flagFactory.CreateFlagConfigSource()
+	configSourceType = flagConfigSource.GetConfigSource()
+	configLocalDir = flagConfigSource.GetConfigLocalDir()
 }
 
 // create base server
 func (f ServiceFactory) createBaseServiceSkywalking() *baseservice.BaseService {
 	baseService := new(baseservice.BaseService)
-	baseService.SetNacosIp(nacosIp)
-	baseService.SetNacosPort(uint(nacosPort))
-	baseService.SetSkywalkingWeatherOpen(skywalkingWeatherOpen)
-	baseService.SetSkywalkingIp(skywalkingIp)
-	baseService.SetSkywalkingPort(uint(skywalkingPort))
-	baseService.SetSkywalkingServerName(skywalkingServerName)
-	baseService.SetLowerRankNum(lowerRankNum)
-	baseService.SetLowerRecallNum(lowerRecallNum)
+	baseService.SetNacosIp(*nacosIp)
+	baseService.SetNacosPort(uint(*nacosPort))
+	baseService.SetSkywalkingWeatherOpen(*skywalkingWeatherOpen)
+	baseService.SetSkywalkingIp(*skywalkingIp)
+	baseService.SetSkywalkingPort(uint(*skywalkingPort))
+	baseService.SetSkywalkingServerName(*skywalkingServerName)
+	baseService.SetLowerRankNum(*lowerRankNum)
+	baseService.SetLowerRecallNum(*lowerRecallNum)
 
 	return baseService
 }
@@ -57,15 +72,29 @@ func (f ServiceFactory) createBaseServiceSkywalking() *baseservice.BaseService {
 // create base server
 func (f ServiceFactory) createBaseServiceNoSkywalking() *baseservice.BaseService {
 	baseService := new(baseservice.BaseService)
-	baseService.SetNacosIp(nacosIp)
-	baseService.SetNacosPort(uint(nacosPort))
+	baseService.SetNacosIp(*nacosIp)
+	baseService.SetNacosPort(uint(*nacosPort))
 	baseService.SetSkywalkingWeatherOpen(false)
-	baseService.SetLowerRankNum(lowerRankNum)
-	baseService.SetLowerRecallNum(lowerRecallNum)
+	baseService.SetLowerRankNum(*lowerRankNum)
+	baseService.SetLowerRecallNum(*lowerRecallNum)
 
 	return baseService
 }
 
+// load all service configs of the manifest, before the services start.
+func (f ServiceFactory) LoadServiceConfigs(manifestFile string) error {
+	manifest, err := config_source.LoadServiceManifest(manifestFile)
+	if err != nil {
+		return err
+	}
+
+	//all services share one config source.
+	configSourceFactory := config_source.ConfigSourceFactory{}
+	configSource := configSourceFactory.CreateConfigSource(*configSourceType, *nacosIp, uint64(*nacosPort), *configLocalDir)
+
+	return config_source.LoadServiceConfigs(configSource, manifest)
+}
+
 // create dubbo server
 func (f ServiceFactory) CreateDubboService() *dubbo_service.DubboService {
 	dubboService := new(dubbo_service.DubboService)
@@ -98,3 +127,10 @@ func (f ServiceFactory) CreateEchoService() *rest_service.EchoService {
 
 	return echoService
 }
+
+// create admin server
+func (f ServiceFactory) CreateAdminService() *admin_service.AdminService {
+	adminService := new(admin_service.AdminService)
+
+	return adminService
+}